@@ -0,0 +1,371 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// PreviousSuffix is appended to the path of a translated config to keep its previous version
+const PreviousSuffix = ".prev"
+
+// The plugin sections of the toml config, their tables are keyed by the plugin name
+var pluginSections = []string{"inputs", "processors", "aggregators", "outputs"}
+
+// The keys identifying the entries of an array of tables, e.g. the file_config of logfile, the entries are matched by
+// index when they have none of them
+var entryKeys = []string{"file_path", "event_name", "metric_name", "name"}
+
+var logGroupKeys = map[string]bool{
+	"log_group_name":        true,
+	"events_log_group_name": true,
+}
+
+var dimensionKeys = map[string]bool{
+	"tags":                  true,
+	"tagexclude":            true,
+	"taginclude":            true,
+	"dimensions":            true,
+	"rollup_dimensions":     true,
+	"ec2_instance_tag_keys": true,
+	"ec2_metadata_tags":     true,
+}
+
+// Toml returns the changes between two toml configs generated by the translator. The changes are described per plugin,
+// e.g. an input added or removed, a log group or a dimension changed.
+func Toml(previous, current string) ([]string, error) {
+	var p, c map[string]interface{}
+	if _, err := toml.Decode(previous, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse the previous toml config: %v", err)
+	}
+	if _, err := toml.Decode(current, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse the current toml config: %v", err)
+	}
+
+	d := &differ{}
+	for _, key := range unionKeys(p, c) {
+		if isPluginSection(key) {
+			continue
+		}
+		d.value(key, "", p[key], c[key])
+	}
+	for _, section := range pluginSections {
+		ps, _ := p[section].(map[string]interface{})
+		cs, _ := c[section].(map[string]interface{})
+		for _, name := range unionKeys(ps, cs) {
+			d.plugin(section+"."+name, tables(ps[name]), tables(cs[name]))
+		}
+	}
+	return d.changes, nil
+}
+
+// Json returns the changes between two json configs, e.g. the json configs downloaded from the parameter store
+func Json(previous, current string) ([]string, error) {
+	var p, c map[string]interface{}
+	if err := json.Unmarshal([]byte(previous), &p); err != nil {
+		return nil, fmt.Errorf("failed to parse the previous json config: %v", err)
+	}
+	if err := json.Unmarshal([]byte(current), &c); err != nil {
+		return nil, fmt.Errorf("failed to parse the current json config: %v", err)
+	}
+	d := &differ{}
+	d.table("", p, c)
+	return d.changes, nil
+}
+
+// Env returns the changes between two env configs generated by the translator
+func Env(previous, current string) ([]string, error) {
+	var p, c map[string]string
+	if err := json.Unmarshal([]byte(previous), &p); err != nil {
+		return nil, fmt.Errorf("failed to parse the previous env config: %v", err)
+	}
+	if err := json.Unmarshal([]byte(current), &c); err != nil {
+		return nil, fmt.Errorf("failed to parse the current env config: %v", err)
+	}
+	var changes []string
+	keys := make(map[string]bool)
+	for k := range p {
+		keys[k] = true
+	}
+	for k := range c {
+		keys[k] = true
+	}
+	for _, k := range sortedKeys(keys) {
+		pv, pok := p[k]
+		cv, cok := c[k]
+		switch {
+		case !pok:
+			changes = append(changes, fmt.Sprintf("added env %s=%q", k, cv))
+		case !cok:
+			changes = append(changes, fmt.Sprintf("removed env %s", k))
+		case pv != cv:
+			changes = append(changes, fmt.Sprintf("changed env %s: %q -> %q", k, pv, cv))
+		}
+	}
+	return changes, nil
+}
+
+type differ struct {
+	changes []string
+}
+
+func (d *differ) add(format string, a ...interface{}) {
+	d.changes = append(d.changes, fmt.Sprintf(format, a...))
+}
+
+// plugin compares the instances of a plugin, they are matched by index as telegraf keeps their order
+func (d *differ) plugin(path string, previous, current []map[string]interface{}) {
+	for i := 0; i < len(previous) || i < len(current); i++ {
+		instance := path
+		if len(previous) > 1 || len(current) > 1 {
+			instance = fmt.Sprintf("%s[%d]", path, i)
+		}
+		switch {
+		case i >= len(previous):
+			d.add("added %s%s", instance, describe(current[i]))
+		case i >= len(current):
+			d.add("removed %s%s", instance, describe(previous[i]))
+		default:
+			d.table(instance, previous[i], current[i])
+		}
+	}
+}
+
+func (d *differ) table(path string, previous, current map[string]interface{}) {
+	for _, key := range unionKeys(previous, current) {
+		d.value(path, key, previous[key], current[key])
+	}
+}
+
+func (d *differ) value(path, key string, previous, current interface{}) {
+	if reflect.DeepEqual(previous, current) {
+		return
+	}
+	sub := join(path, key)
+	pt, pok := previous.(map[string]interface{})
+	ct, cok := current.(map[string]interface{})
+	if pok && cok && !dimensionKeys[key] {
+		d.table(sub, pt, ct)
+		return
+	}
+	pe, pok := entries(previous)
+	ce, cok := entries(current)
+	if pok && cok {
+		d.entries(sub, pe, ce)
+		return
+	}
+
+	switch {
+	case logGroupKeys[key]:
+		d.add("changed log group of %s: %s -> %s", path, format(previous), format(current))
+	case dimensionKeys[key]:
+		d.add("changed dimensions of %s %s: %s -> %s", path, key, format(previous), format(current))
+	case previous == nil && isTable(current):
+		d.add("added %s%s", sub, describe(current))
+	case current == nil && isTable(previous):
+		d.add("removed %s%s", sub, describe(previous))
+	case previous == nil:
+		d.add("added %s: %s", sub, format(current))
+	case current == nil:
+		d.add("removed %s: %s", sub, format(previous))
+	default:
+		d.add("changed %s: %s -> %s", sub, format(previous), format(current))
+	}
+}
+
+// entries compares the entries of an array of tables, e.g. the file_config of logfile
+func (d *differ) entries(path string, previous, current []map[string]interface{}) {
+	key := entryKey(previous, current)
+	if key == "" {
+		for i := 0; i < len(previous) || i < len(current); i++ {
+			entry := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(previous):
+				d.add("added %s%s", entry, describe(current[i]))
+			case i >= len(current):
+				d.add("removed %s%s", entry, describe(previous[i]))
+			default:
+				d.table(entry, previous[i], current[i])
+			}
+		}
+		return
+	}
+
+	pm := make(map[string]map[string]interface{})
+	for _, e := range previous {
+		pm[fmt.Sprint(e[key])] = e
+	}
+	cm := make(map[string]map[string]interface{})
+	for _, e := range current {
+		cm[fmt.Sprint(e[key])] = e
+	}
+	for _, e := range previous {
+		id := fmt.Sprint(e[key])
+		if _, ok := cm[id]; !ok {
+			d.add("removed %s[%s]%s", path, id, describe(e))
+		}
+	}
+	for _, e := range current {
+		id := fmt.Sprint(e[key])
+		entry := fmt.Sprintf("%s[%s]", path, id)
+		if p, ok := pm[id]; ok {
+			d.table(entry, p, e)
+		} else {
+			d.add("added %s%s", entry, describe(e))
+		}
+	}
+}
+
+// entryKey returns the key identifying every entry of both arrays
+func entryKey(previous, current []map[string]interface{}) string {
+	for _, key := range entryKeys {
+		found := true
+		for _, list := range [][]map[string]interface{}{previous, current} {
+			ids := make(map[string]bool)
+			for _, e := range list {
+				v, ok := e[key]
+				id := fmt.Sprint(v)
+				if !ok || ids[id] {
+					found = false
+					break
+				}
+				ids[id] = true
+			}
+		}
+		if found {
+			return key
+		}
+	}
+	return ""
+}
+
+func isTable(v interface{}) bool {
+	if _, ok := v.(map[string]interface{}); ok {
+		return true
+	}
+	_, ok := entries(v)
+	return ok
+}
+
+// describe returns the log groups of an added or removed table, which are worth mentioning
+func describe(t interface{}) string {
+	groups := make(map[string]bool)
+	collectLogGroups(t, groups)
+	if len(groups) == 0 {
+		return ""
+	}
+	var quoted []string
+	for _, g := range sortedKeys(groups) {
+		quoted = append(quoted, fmt.Sprintf("%q", g))
+	}
+	return fmt.Sprintf(" (log group %s)", strings.Join(quoted, ", "))
+}
+
+func collectLogGroups(v interface{}, groups map[string]bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range t {
+			if s, ok := sub.(string); ok && logGroupKeys[k] && s != "" {
+				groups[s] = true
+			} else {
+				collectLogGroups(sub, groups)
+			}
+		}
+	case []map[string]interface{}:
+		for _, sub := range t {
+			collectLogGroups(sub, groups)
+		}
+	case []interface{}:
+		for _, sub := range t {
+			collectLogGroups(sub, groups)
+		}
+	}
+}
+
+// tables returns the instances of a plugin
+func tables(v interface{}) []map[string]interface{} {
+	if t, ok := v.(map[string]interface{}); ok {
+		return []map[string]interface{}{t}
+	}
+	e, _ := entries(v)
+	return e
+}
+
+// entries returns the value as an array of tables, the arrays of the json configs are []interface{}
+func entries(v interface{}) ([]map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case []map[string]interface{}:
+		return t, true
+	case []interface{}:
+		if len(t) == 0 {
+			return nil, false
+		}
+		res := make([]map[string]interface{}, 0, len(t))
+		for _, e := range t {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			res = append(res, m)
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+func format(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	if key == "" {
+		return path
+	}
+	return path + "." + key
+}
+
+func isPluginSection(key string) bool {
+	for _, s := range pluginSections {
+		if s == key {
+			return true
+		}
+	}
+	return false
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return sortedKeys(keys)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,132 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const previousToml = `
+[agent]
+  interval = "60s"
+
+[inputs]
+
+  [[inputs.cpu]]
+    fieldpass = ["usage_idle"]
+    [inputs.cpu.tags]
+      metricPath = "metrics"
+
+  [[inputs.logfile]]
+    destination = "cloudwatchlogs"
+
+    [[inputs.logfile.file_config]]
+      file_path = "/var/log/a.log"
+      log_group_name = "a"
+
+    [[inputs.logfile.file_config]]
+      file_path = "/var/log/b.log"
+      log_group_name = "b"
+
+  [[inputs.swap]]
+    fieldpass = ["used_percent"]
+
+[outputs]
+
+  [[outputs.cloudwatch]]
+    namespace = "CWAgent"
+
+[processors]
+
+  [[processors.ec2tagger]]
+    ec2_metadata_tags = ["InstanceId"]
+`
+
+const currentToml = `
+[agent]
+  interval = "30s"
+
+[inputs]
+
+  [[inputs.cpu]]
+    fieldpass = ["usage_idle", "usage_user"]
+    [inputs.cpu.tags]
+      metricPath = "metrics"
+      team = "core"
+
+  [[inputs.logfile]]
+    destination = "cloudwatchlogs"
+
+    [[inputs.logfile.file_config]]
+      file_path = "/var/log/a.log"
+      log_group_name = "a2"
+
+    [[inputs.logfile.file_config]]
+      file_path = "/var/log/c.log"
+      log_group_name = "c"
+
+  [[inputs.statsd]]
+    service_address = ":8125"
+
+[outputs]
+
+  [[outputs.cloudwatch]]
+    namespace = "CWAgent"
+
+[processors]
+
+  [[processors.ec2tagger]]
+    ec2_metadata_tags = ["InstanceId", "InstanceType"]
+`
+
+func TestToml(t *testing.T) {
+	changes, err := Toml(previousToml, currentToml)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`changed agent.interval: "60s" -> "30s"`,
+		`changed inputs.cpu.fieldpass: ["usage_idle"] -> ["usage_idle","usage_user"]`,
+		`changed dimensions of inputs.cpu tags: {"metricPath":"metrics"} -> {"metricPath":"metrics","team":"core"}`,
+		`removed inputs.logfile.file_config[/var/log/b.log] (log group "b")`,
+		`changed log group of inputs.logfile.file_config[/var/log/a.log]: "a" -> "a2"`,
+		`added inputs.logfile.file_config[/var/log/c.log] (log group "c")`,
+		`added inputs.statsd`,
+		`removed inputs.swap`,
+		`changed dimensions of processors.ec2tagger ec2_metadata_tags: ["InstanceId"] -> ["InstanceId","InstanceType"]`,
+	}, changes)
+}
+
+func TestTomlNoChanges(t *testing.T) {
+	changes, err := Toml(previousToml, previousToml)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestTomlInvalid(t *testing.T) {
+	_, err := Toml(previousToml, "[inputs")
+	assert.Error(t, err)
+}
+
+func TestJson(t *testing.T) {
+	changes, err := Json(
+		`{"logs":{"logs_collected":{"files":{"collect_list":[{"file_path":"/a","log_group_name":"a"}]}}}}`,
+		`{"logs":{"logs_collected":{"files":{"collect_list":[{"file_path":"/a","log_group_name":"a"},{"file_path":"/b","log_group_name":"b"}]}}},"metrics":{"metrics_collected":{"mem":{}}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`added logs.logs_collected.files.collect_list[/b] (log group "b")`,
+		`added metrics`,
+	}, changes)
+}
+
+func TestEnv(t *testing.T) {
+	changes, err := Env(`{"A":"1","B":"2"}`, `{"A":"3","C":"4"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`changed env A: "1" -> "3"`,
+		`removed env B`,
+		`added env C="4"`,
+	}, changes)
+}
@@ -5,11 +5,12 @@ package envconfig
 
 const (
 	//the following are the names of environment variables
-	HTTP_PROXY         = "HTTP_PROXY"
-	HTTPS_PROXY        = "HTTPS_PROXY"
-	NO_PROXY           = "NO_PROXY"
-	AWS_CSM_ENABLED    = "AWS_CSM_ENABLED"
-	AWS_CA_BUNDLE      = "AWS_CA_BUNDLE"
-	CWAGENT_USER_AGENT = "CWAGENT_USER_AGENT"
-	CWAGENT_LOG_LEVEL  = "CWAGENT_LOG_LEVEL"
+	HTTP_PROXY          = "HTTP_PROXY"
+	HTTPS_PROXY         = "HTTPS_PROXY"
+	NO_PROXY            = "NO_PROXY"
+	AWS_CSM_ENABLED     = "AWS_CSM_ENABLED"
+	AWS_CA_BUNDLE       = "AWS_CA_BUNDLE"
+	CWAGENT_USER_AGENT  = "CWAGENT_USER_AGENT"
+	CWAGENT_LOG_LEVEL   = "CWAGENT_LOG_LEVEL"
+	CWAGENT_HEALTH_ADDR = "CWAGENT_HEALTH_ADDR"
 )
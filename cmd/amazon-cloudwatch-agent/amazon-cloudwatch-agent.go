@@ -25,7 +25,9 @@ import (
 	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent/cfg/agentinfo"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/configdiff"
 	"github.com/aws/amazon-cloudwatch-agent/cfg/migrate"
+	"github.com/aws/amazon-cloudwatch-agent/health"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/profiler"
 
@@ -34,6 +36,7 @@ import (
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/models"
 	//_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	//_ "github.com/influxdata/telegraf/plugins/inputs/all"
@@ -50,6 +53,8 @@ var fDebug = flag.Bool("debug", false,
 	"turn on debug logging")
 var pprofAddr = flag.String("pprof-addr", "",
 	"pprof address to listen on, not activate pprof if empty")
+var fHealthAddr = flag.String("health-addr", "",
+	"address of the health and introspection endpoint, listens on localhost if the host is omitted, not activated if empty. Defaults to the "+envconfig.CWAGENT_HEALTH_ADDR+" environment variable")
 var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "enable test mode: gather metrics, print them out, and exit")
@@ -103,8 +108,10 @@ func reloadLoop(
 ) {
 	reload := make(chan bool, 1)
 	reload <- true
+	previous := &loadedConfig{}
 	for <-reload {
 		reload <- false
+		previous = logConfigChanges(previous)
 
 		ctx, cancel := context.WithCancel(context.Background())
 
@@ -177,6 +184,50 @@ func reloadLoop(
 	}
 }
 
+// loadedConfig is the content of the config files loaded by the agent, it is kept to log the changes on reload
+type loadedConfig struct {
+	toml string
+	env  string
+}
+
+// logConfigChanges logs the changes of the config files since they were previously loaded, and returns their content
+func logConfigChanges(previous *loadedConfig) *loadedConfig {
+	current := &loadedConfig{}
+	if b, err := ioutil.ReadFile(*fConfig); err == nil {
+		current.toml = string(b)
+	}
+	if envConfigPath, err := getEnvConfigPath(*fConfig, *fEnvConfig); err == nil {
+		if b, err := ioutil.ReadFile(envConfigPath); err == nil {
+			current.env = string(b)
+		}
+	}
+
+	var changes []string
+	if previous.toml != "" && current.toml != "" {
+		c, err := configdiff.Toml(previous.toml, current.toml)
+		if err != nil {
+			log.Printf("W! Unable to diff the config: %v", err)
+		}
+		changes = append(changes, c...)
+	}
+	if previous.env != "" && current.env != "" {
+		c, err := configdiff.Env(previous.env, current.env)
+		if err != nil {
+			log.Printf("W! Unable to diff the env config: %v", err)
+		}
+		changes = append(changes, c...)
+	}
+	if previous.toml != "" {
+		if len(changes) == 0 {
+			log.Printf("I! No config changes since the last load")
+		}
+		for _, c := range changes {
+			log.Printf("I! Config change: %s", c)
+		}
+	}
+	return current
+}
+
 func loadEnvironmentVariables(path string) error {
 	if path == "" {
 		return fmt.Errorf("No env config file specified")
@@ -332,6 +383,24 @@ func runAgent(ctx context.Context,
 			}()
 		}
 	}
+
+	healthAddr := *fHealthAddr
+	if healthAddr == "" {
+		healthAddr = os.Getenv(envconfig.CWAGENT_HEALTH_ADDR)
+	}
+	if healthAddr != "" {
+		healthServer := health.NewServer(healthAddr)
+		if err := healthServer.Start(); err != nil {
+			log.Printf("E! Unable to start the health endpoint: %v", err)
+		} else {
+			defer healthServer.Stop()
+		}
+	}
+
+	// the agent is ready once the outputs are connected and the service inputs are started by ag.Run
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&health.ReadinessInput{}, &models.InputConfig{Name: "health"}))
+	defer health.Status.SetReady(false)
+
 	logAgent := logs.NewLogAgent(c)
 	go logAgent.Run(ctx)
 	return ag.Run(ctx)
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 
 	commonconfig "github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/configdiff"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -83,6 +84,28 @@ func readFromFile(filePath string) (string, error) {
 	return string(bytes), err
 }
 
+// printChanges prints the changes of the downloaded json config since the config was previously downloaded
+func printChanges(previousFilePath, config string) {
+	previous, err := readFromFile(previousFilePath)
+	if err != nil {
+		fmt.Printf("No previous config %s to diff with\n", previousFilePath)
+		return
+	}
+	changes, err := configdiff.Json(previous, config)
+	if err != nil {
+		fmt.Printf("Failed to diff the config with %s: %v\n", previousFilePath, err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Printf("No changes since %s\n", previousFilePath)
+		return
+	}
+	fmt.Printf("Changes since %s:\n", previousFilePath)
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+}
+
 func EscapeFilePath(filePath string) (escapedFilePath string) {
 	escapedFilePath = filepath.ToSlash(filePath)
 	escapedFilePath = strings.Replace(escapedFilePath, "/", "_", -1)
@@ -109,6 +132,7 @@ func main() {
 	}()
 
 	var region, mode, downloadLocation, outputDir, inputConfig, multiConfig string
+	var showDiff bool
 
 	flag.StringVar(&mode, "mode", "ec2", "The mode value, i.e. ec2 or onPrem")
 	flag.StringVar(&downloadLocation, "download-source", "",
@@ -116,6 +140,7 @@ func main() {
 	flag.StringVar(&outputDir, "output-dir", "", "Path of output json config directory.")
 	flag.StringVar(&inputConfig, "config", "", "Please provide the common-config file")
 	flag.StringVar(&multiConfig, "multi-config", "default", "valid values: default, append, remove")
+	flag.BoolVar(&showDiff, "diff", false, "Print the changes of the downloaded config since the config was previously downloaded")
 	flag.Parse()
 
 	cc := commonconfig.New()
@@ -214,6 +239,10 @@ func main() {
 	}
 
 	if multiConfig != "remove" {
+		if showDiff {
+			// the previous config is kept without the tmp suffix once the agent has applied it
+			printChanges(filepath.Join(outputDir, outputFilePath), config)
+		}
 		outputFilePath = filepath.Join(outputDir, outputFilePath+context.TmpFileSuffix)
 		err = ioutil.WriteFile(outputFilePath, []byte(config), 0644)
 		if err != nil {
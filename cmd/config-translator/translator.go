@@ -4,19 +4,23 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/user"
 	"path/filepath"
 
 	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/configdiff"
 	"github.com/aws/amazon-cloudwatch-agent/translator"
 	"github.com/aws/amazon-cloudwatch-agent/translator/cmdutil"
 	"github.com/aws/amazon-cloudwatch-agent/translator/config"
 	"github.com/aws/amazon-cloudwatch-agent/translator/context"
 	translatorUtil "github.com/aws/amazon-cloudwatch-agent/translator/util"
+	"github.com/aws/amazon-cloudwatch-agent/translator/validate"
 )
 
 const (
@@ -25,6 +29,9 @@ const (
 	envConfigFileName = "env-config.json"
 )
 
+var validateOnly *bool
+var showDiff *bool
+
 func initFlags() {
 	var inputOs = flag.String("os", "", "Please provide the os preference, valid value: windows/linux.")
 	var inputJsonFile = flag.String("input", "", "Please provide the path of input agent json config file")
@@ -33,6 +40,8 @@ func initFlags() {
 	var inputMode = flag.String("mode", "ec2", "Please provide the mode, i.e. ec2, onPrem")
 	var inputConfig = flag.String("config", "", "Please provide the common-config file")
 	var multiConfig = flag.String("multi-config", "remove", "valid values: default, append, remove")
+	showDiff = flag.Bool("diff", false, "Keep the previous output files with the "+configdiff.PreviousSuffix+" suffix and print the changes of the effective config")
+	validateOnly = flag.Bool("validate", false, "Validate the json config without writing the output files, the errors are printed in json with the JSON pointer of the offending key")
 	flag.Parse()
 
 	ctx := context.CurrentContext()
@@ -62,12 +71,16 @@ func initFlags() {
 
 /**
  *	config-translator --input ${JSON} --input-dir ${JSON_DIR} --output ${TOML} --mode ${param_mode} --config ${COMMON_CONFIG}
- *  --multi-config [default|append|remove]
+ *  --multi-config [default|append|remove] --diff
  *
  *		multi-config:
  *			default:	only process .tmp files
  *			append:		process both existing files and .tmp files
  *			remove:		only process existing files
+ *
+ *	config-translator --validate --input ${JSON} --input-dir ${JSON_DIR} --os ${OS}
+ *
+ *		validate:	check the json config offline, print the errors and exit with 1 if the config is invalid
  */
 func main() {
 	initFlags()
@@ -88,6 +101,11 @@ func main() {
 	}()
 	ctx := context.CurrentContext()
 
+	if *validateOnly {
+		runValidation(ctx)
+		return
+	}
+
 	mergedJsonConfigMap, err := cmdutil.GenerateMergedJsonConfigMap(ctx)
 	if err != nil {
 		panic(fmt.Sprintf("E! Failed to generate merged json config: %v", err))
@@ -106,8 +124,84 @@ func main() {
 	}
 
 	tomlConfigPath := cmdutil.GetTomlConfigPath(ctx.OutputTomlFilePath())
-	cmdutil.TranslateJsonMapToTomlFile(mergedJsonConfigMap, tomlConfigPath)
 	//put env config into the same folder as the toml config
 	envConfigPath := filepath.Join(filepath.Dir(tomlConfigPath), envConfigFileName)
+	previousToml := readPrevious(tomlConfigPath)
+	previousEnv := readPrevious(envConfigPath)
+
+	cmdutil.TranslateJsonMapToTomlFile(mergedJsonConfigMap, tomlConfigPath)
 	cmdutil.TranslateJsonMapToEnvConfigFile(mergedJsonConfigMap, envConfigPath)
+
+	printChanges(tomlConfigPath, previousToml, configdiff.Toml)
+	printChanges(envConfigPath, previousEnv, configdiff.Env)
+}
+
+// readPrevious reads the output file of the last translation when the diff is asked
+func readPrevious(path string) string {
+	if !*showDiff {
+		return ""
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// printChanges keeps the previous output file and prints the changes of the new one
+func printChanges(path, previous string, diff func(string, string) ([]string, error)) {
+	if !*showDiff {
+		return
+	}
+	if previous == "" {
+		fmt.Printf("No previous %s to diff with\n", path)
+		return
+	}
+	if err := ioutil.WriteFile(path+configdiff.PreviousSuffix, []byte(previous), 0644); err != nil {
+		log.Printf("E! Failed to keep the previous %s: %v", path, err)
+	}
+	current, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Printf("E! Failed to read %s: %v", path, err)
+		return
+	}
+	changes, err := diff(previous, string(current))
+	if err != nil {
+		log.Printf("E! Failed to diff %s: %v", path, err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Printf("No changes in %s\n", path)
+		return
+	}
+	fmt.Printf("Changes in %s:\n", path)
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+}
+
+// validationResult is the output of the validate mode
+type validationResult struct {
+	Valid  bool             `json:"valid"`
+	Errors []validate.Error `json:"errors"`
+}
+
+func runValidation(ctx *context.Context) {
+	result := validationResult{Errors: []validate.Error{}}
+	mergedJsonConfigMap, err := cmdutil.MergeJsonConfigFiles(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, validate.Error{Message: fmt.Sprintf("failed to generate merged json config: %v", err)})
+	} else if errs := validate.Validate(mergedJsonConfigMap); len(errs) > 0 {
+		result.Errors = errs
+	}
+	result.Valid = len(result.Errors) == 0
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("E! Failed to marshal the validation result: %v", err)
+	}
+	fmt.Println(string(out))
+	if !result.Valid {
+		os.Exit(1)
+	}
 }
@@ -53,18 +53,22 @@ require (
 	github.com/influxdata/toml v0.0.0-20190415235208-270119a8ce65
 	github.com/influxdata/wlog v0.0.0-20160411224016-7c63b0a71ef8
 	github.com/kardianos/service v1.0.0
+	github.com/klauspost/compress v1.9.2
 	github.com/oklog/run v1.1.0
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.5.1
 	github.com/prometheus/common v0.9.1
 	github.com/prometheus/prometheus v1.8.2-0.20200420081721-18254838fbe2
 	github.com/shirou/gopsutil v2.20.5+incompatible
-	github.com/stretchr/testify v1.5.1
+	github.com/stretchr/testify v1.7.0 // the minimum required by github.com/envoyproxy/go-control-plane of google.golang.org/grpc
 	github.com/xeipuuv/gojsonschema v1.2.0
-	golang.org/x/net v0.0.0-20200301022130-244492dfa37a
-	golang.org/x/sync v0.0.0-20200317015054-43a5402ce75a
-	golang.org/x/sys v0.0.0-20200316230553-a7d97aace0b0
-	golang.org/x/text v0.3.3
+	go.opentelemetry.io/proto/otlp v0.19.0
+	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4
+	golang.org/x/sync v0.0.0-20200625203802-6e8e738ad208
+	golang.org/x/sys v0.0.0-20210510120138-977fb7262007
+	golang.org/x/text v0.3.5
+	google.golang.org/grpc v1.42.0 // the minimum required by go.opentelemetry.io/proto/otlp since its exponential histograms (v0.11.0)
+	google.golang.org/protobuf v1.27.1
 	gopkg.in/fsnotify.v1 v1.4.7
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
 	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7
@@ -74,3 +78,12 @@ require (
 	k8s.io/client-go v0.17.4
 	k8s.io/klog v1.0.0
 )
+
+//The genproto required by google.golang.org/grpc requires the 2021 golang.org/x/sys and golang.org/x/net above, so the
+//require lines cannot be lower, but the builds use the versions pinned here:
+//x/sys is pinned for the patched gopsutil, which uses the darwin types of x/sys before their int8 fields became bytes.
+replace golang.org/x/sys => golang.org/x/sys v0.0.0-20200803210538-64077c9b5642
+
+//x/net is pinned to a revision built against the pinned x/sys, the later ones use constants it does not have,
+//e.g. unix.ICMP_FILTER on linux and unix.MCAST_JOIN_GROUP on darwin.
+replace golang.org/x/net => golang.org/x/net v0.0.0-20200707034311-ab3426394381
@@ -0,0 +1,174 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	// Status is the state of the running agent, it is updated by the plugins and reported by the health endpoint
+	Status = newStatus()
+)
+
+// LogSrc describes a log source piped by the log agent
+type LogSrc struct {
+	Group       string    `json:"log_group_name"`
+	Stream      string    `json:"log_stream_name"`
+	Destination string    `json:"destination"`
+	Description string    `json:"description"`
+	Since       time.Time `json:"since"`
+}
+
+func (s LogSrc) key() string {
+	return fmt.Sprintf("%s/%s/%s(%s)", s.Destination, s.Group, s.Stream, s.Description)
+}
+
+type status struct {
+	sync.Mutex
+	ready       bool
+	startTime   time.Time
+	logSrcs     map[string]LogSrc
+	queues      map[string]func() int
+	lastSuccess map[string]time.Time
+	throttles   map[string]int64
+}
+
+func newStatus() *status {
+	return &status{
+		startTime:   time.Now(),
+		logSrcs:     make(map[string]LogSrc),
+		queues:      make(map[string]func() int),
+		lastSuccess: make(map[string]time.Time),
+		throttles:   make(map[string]int64),
+	}
+}
+
+// SetReady marks the agent as ready once its plugins are running, and not ready while it stops or reloads
+func (s *status) SetReady(ready bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.ready = ready
+}
+
+func (s *status) IsReady() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.ready
+}
+
+// ReadinessInput is a service input marking the agent as ready when it is started. It is added after the other
+// inputs, which the agent starts in order once the outputs are connected.
+type ReadinessInput struct{}
+
+func (*ReadinessInput) SampleConfig() string {
+	return ""
+}
+
+func (*ReadinessInput) Description() string {
+	return "Mark the agent as ready once its plugins are started"
+}
+
+func (*ReadinessInput) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (*ReadinessInput) Start(_ telegraf.Accumulator) error {
+	Status.SetReady(true)
+	return nil
+}
+
+func (*ReadinessInput) Stop() {
+	Status.SetReady(false)
+}
+
+// AddLogSrc records a log source piped by the log agent, the returned function removes it when the source stops
+func (s *status) AddLogSrc(src LogSrc) func() {
+	if src.Since.IsZero() {
+		src.Since = time.Now()
+	}
+	key := src.key()
+	s.Lock()
+	defer s.Unlock()
+	s.logSrcs[key] = src
+	return func() {
+		s.Lock()
+		defer s.Unlock()
+		delete(s.logSrcs, key)
+	}
+}
+
+// SetQueue registers the function returning the number of entries waiting to be published by the named queue,
+// e.g. the pusher of a log stream
+func (s *status) SetQueue(name string, depth func() int) {
+	s.Lock()
+	defer s.Unlock()
+	s.queues[name] = depth
+}
+
+func (s *status) RemoveQueue(name string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.queues, name)
+}
+
+// RecordSuccess records the time of the last successful call of the AWS API operation, e.g. PutMetricData
+func (s *status) RecordSuccess(operation string) {
+	s.Lock()
+	defer s.Unlock()
+	s.lastSuccess[operation] = time.Now()
+}
+
+// RecordThrottle counts the throttled calls of the AWS API operation
+func (s *status) RecordThrottle(operation string) {
+	s.Lock()
+	defer s.Unlock()
+	s.throttles[operation]++
+}
+
+// report is the snapshot of the status returned by the health endpoint
+type report struct {
+	Ready       bool                 `json:"ready"`
+	StartTime   time.Time            `json:"start_time"`
+	LogSrcs     []LogSrc             `json:"log_sources"`
+	Queues      map[string]int       `json:"queue_depths"`
+	LastSuccess map[string]time.Time `json:"last_success"`
+	Throttles   map[string]int64     `json:"throttles"`
+}
+
+func (s *status) report() report {
+	s.Lock()
+	r := report{
+		Ready:       s.ready,
+		StartTime:   s.startTime,
+		LogSrcs:     make([]LogSrc, 0, len(s.logSrcs)),
+		Queues:      make(map[string]int, len(s.queues)),
+		LastSuccess: make(map[string]time.Time, len(s.lastSuccess)),
+		Throttles:   make(map[string]int64, len(s.throttles)),
+	}
+	for _, src := range s.logSrcs {
+		r.LogSrcs = append(r.LogSrcs, src)
+	}
+	queues := make(map[string]func() int, len(s.queues))
+	for name, depth := range s.queues {
+		queues[name] = depth
+	}
+	for op, t := range s.lastSuccess {
+		r.LastSuccess[op] = t
+	}
+	for op, count := range s.throttles {
+		r.Throttles[op] = count
+	}
+	s.Unlock()
+
+	// the queue functions may take the locks of their plugins
+	for name, depth := range queues {
+		r.Queues[name] = depth()
+	}
+	return r
+}
@@ -0,0 +1,44 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	s := newStatus()
+	s.SetReady(true)
+	remove := s.AddLogSrc(LogSrc{Group: "group", Stream: "stream", Destination: "cloudwatchlogs", Description: "/var/log/app.log"})
+	s.SetQueue("cloudwatch", func() int { return 3 })
+	s.RecordSuccess("PutMetricData")
+	s.RecordThrottle("PutLogEvents")
+	s.RecordThrottle("PutLogEvents")
+
+	r := s.report()
+	assert.True(t, r.Ready)
+	assert.Equal(t, 1, len(r.LogSrcs))
+	assert.Equal(t, "/var/log/app.log", r.LogSrcs[0].Description)
+	assert.False(t, r.LogSrcs[0].Since.IsZero())
+	assert.Equal(t, map[string]int{"cloudwatch": 3}, r.Queues)
+	assert.Contains(t, r.LastSuccess, "PutMetricData")
+	assert.Equal(t, map[string]int64{"PutLogEvents": 2}, r.Throttles)
+
+	remove()
+	s.RemoveQueue("cloudwatch")
+	r = s.report()
+	assert.Equal(t, 0, len(r.LogSrcs))
+	assert.Equal(t, 0, len(r.Queues))
+}
+
+func TestReadinessInput(t *testing.T) {
+	defer Status.SetReady(false)
+	input := &ReadinessInput{}
+	assert.NoError(t, input.Start(nil))
+	assert.True(t, Status.IsReady())
+	input.Stop()
+	assert.False(t, Status.IsReady())
+}
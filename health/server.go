@@ -0,0 +1,176 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/profiler"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+	MetricsPath   = "/metrics"
+	StatusPath    = "/status"
+
+	metricPrefix    = "cwagent_"
+	shutdownTimeout = 5 * time.Second
+)
+
+// Server is the local HTTP server of the health and introspection endpoints
+type Server struct {
+	address  string
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer creates the server listening on the address, the server listens on localhost when the host is omitted,
+// e.g. ":8080"
+func NewServer(address string) *Server {
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+	return &Server{address: address}
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", s.address, err)
+	}
+	s.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, handleLiveness)
+	mux.HandleFunc(ReadinessPath, handleReadiness)
+	mux.HandleFunc(MetricsPath, handleMetrics)
+	mux.HandleFunc(StatusPath, handleStatus)
+	s.server = &http.Server{Handler: mux}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("E! [health] Server stopped: %v", err)
+		}
+	}()
+	log.Printf("I! [health] Listening on http://%v", listener.Addr())
+	return nil
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("W! [health] Failed to shut down the server: %v", err)
+	}
+}
+
+func handleLiveness(w http.ResponseWriter, _ *http.Request) {
+	io.WriteString(w, "ok\n")
+}
+
+func handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	if !Status.IsReady() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
+
+func handleStatus(w http.ResponseWriter, _ *http.Request) {
+	r := Status.report()
+	sort.Slice(r.LogSrcs, func(i, j int) bool { return r.LogSrcs[i].key() < r.LogSrcs[j].key() })
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(r)
+}
+
+// handleMetrics writes the status and the profiler stats in the Prometheus text format
+func handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	r := Status.report()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	ready := 0
+	if r.Ready {
+		ready = 1
+	}
+	writeMetric(w, "ready", "gauge", "Whether the agent is running its plugins.", []sample{{value: float64(ready)}})
+	writeMetric(w, "start_time_seconds", "gauge", "Start time of the agent since unix epoch in seconds.",
+		[]sample{{value: float64(r.StartTime.Unix())}})
+	writeMetric(w, "log_sources", "gauge", "Number of the log sources piped by the log agent.",
+		[]sample{{value: float64(len(r.LogSrcs))}})
+
+	var samples []sample
+	for name, depth := range r.Queues {
+		samples = append(samples, sample{labels: []string{"queue", name}, value: float64(depth)})
+	}
+	writeMetric(w, "queue_depth", "gauge", "Number of the entries waiting to be published.", samples)
+
+	samples = nil
+	for op, t := range r.LastSuccess {
+		samples = append(samples, sample{labels: []string{"operation", op}, value: float64(t.Unix())})
+	}
+	writeMetric(w, "last_success_timestamp_seconds", "gauge", "Time of the last successful call of the AWS API operation.", samples)
+
+	samples = nil
+	for op, count := range r.Throttles {
+		samples = append(samples, sample{labels: []string{"operation", op}, value: float64(count)})
+	}
+	writeMetric(w, "throttles_total", "counter", "Number of the throttled calls of the AWS API operation.", samples)
+
+	samples = nil
+	for key, value := range profiler.Profiler.Snapshot() {
+		samples = append(samples, sample{labels: []string{"stat", key}, value: value})
+	}
+	writeMetric(w, "profiler_stats_total", "counter", "Stats of the agent profiler since the agent started.", samples)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+type sample struct {
+	// labels are the pairs of label name and value
+	labels []string
+	value  float64
+}
+
+func (s sample) String() string {
+	if len(s.labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(s.labels)/2)
+	for i := 0; i+1 < len(s.labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, s.labels[i], labelValueEscaper.Replace(s.labels[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func writeMetric(w io.Writer, name, metricType, help string, samples []sample) {
+	if len(samples) == 0 {
+		return
+	}
+	name = metricPrefix + name
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+	lines := make([]string, 0, len(samples))
+	for _, s := range samples {
+		lines = append(lines, fmt.Sprintf("%s%s %v", name, s, s.value))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
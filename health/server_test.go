@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/profiler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, s *Server, path string) (int, string) {
+	resp, err := http.Get("http://" + s.Addr() + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestNewServer(t *testing.T) {
+	assert.Equal(t, "localhost:8099", NewServer(":8099").address)
+	assert.Equal(t, "0.0.0.0:8099", NewServer("0.0.0.0:8099").address)
+}
+
+func TestServer(t *testing.T) {
+	Status = newStatus()
+	s := NewServer("127.0.0.1:0")
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	code, _ := get(t, s, LivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = get(t, s, ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	Status.SetReady(true)
+	code, _ = get(t, s, ReadinessPath)
+	assert.Equal(t, http.StatusOK, code)
+
+	Status.AddLogSrc(LogSrc{Group: "group", Stream: "stream", Destination: "cloudwatchlogs", Description: "/var/log/app.log"})
+	Status.SetQueue(`cloudwatchlogs/group/"stream"`, func() int { return 7 })
+	Status.RecordThrottle("PutLogEvents")
+	profiler.Profiler.AddStats([]string{"cloudwatchlogs", "group", "rawSize"}, 1024)
+
+	code, body := get(t, s, MetricsPath)
+	assert.Equal(t, http.StatusOK, code)
+	lines := strings.Split(body, "\n")
+	assert.Contains(t, lines, "# TYPE cwagent_ready gauge")
+	assert.Contains(t, lines, "cwagent_ready 1")
+	assert.Contains(t, lines, "cwagent_log_sources 1")
+	assert.Contains(t, lines, `cwagent_queue_depth{queue="cloudwatchlogs/group/\"stream\""} 7`)
+	assert.Contains(t, lines, `cwagent_throttles_total{operation="PutLogEvents"} 1`)
+	assert.Contains(t, lines, `cwagent_profiler_stats_total{stat="cloudwatchlogs_group_rawSize"} 1024`)
+	assert.NotContains(t, body, "cwagent_last_success_timestamp_seconds")
+
+	code, body = get(t, s, StatusPath)
+	assert.Equal(t, http.StatusOK, code)
+	var r report
+	require.NoError(t, json.Unmarshal([]byte(body), &r))
+	assert.True(t, r.Ready)
+	assert.Equal(t, "group", r.LogSrcs[0].Group)
+	assert.Equal(t, 7, r.Queues[`cloudwatchlogs/group/"stream"`])
+}
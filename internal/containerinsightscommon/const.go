@@ -77,6 +77,15 @@ const (
 	TypeClusterNamespace = "ClusterNamespace"
 	TypeService          = "Service"
 
+	TypeClusterDeployment  = "ClusterDeployment"
+	TypeClusterDaemonSet   = "ClusterDaemonSet"
+	TypeClusterStatefulSet = "ClusterStatefulSet"
+	TypeClusterJob         = "ClusterJob"
+	TypeClusterPendingPod  = "ClusterPendingPod"
+	TypeClusterPVC         = "ClusterPersistentVolumeClaim"
+	// the leadership status of the agents taking part in the leader election of k8sapiserver
+	TypeClusterLeaderElection = "ClusterLeaderElection"
+
 	// Both TypeInstance and TypeNode mean EC2 Instance, they are used in ECS and EKS separately
 	TypeInstance       = "Instance"
 	TypeNode           = "Node"
@@ -24,6 +24,21 @@ const (
 	NodeCount             = "node_count"
 	FailedNodeCount       = "failed_node_count"
 	ContainerRestartCount = "number_of_container_restarts"
+	PendingPodCount       = "number_of_pending_pods"
+	PVCCount              = "number_of_persistent_volume_claims"
+
+	DesiredReplicas     = "desired_replicas"
+	AvailableReplicas   = "available_replicas"
+	UnavailableReplicas = "unavailable_replicas"
+
+	ActiveJobCount    = "number_of_active_jobs"
+	SucceededJobCount = "number_of_succeeded_jobs"
+	FailedJobCount    = "number_of_failed_jobs"
+
+	IsLeader = "is_leader"
+
+	PendingReasonKey = "PendingReason"
+	PVCPhaseKey      = "PersistentVolumeClaimPhase"
 
 	PodStatus       = "pod_status"
 	ContainerStatus = "container_status"
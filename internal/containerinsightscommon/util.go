@@ -32,6 +32,10 @@ func MetricName(mType string, name string) string {
 	service := "service_"
 	cluster := "cluster_"
 	namespace := "namespace_"
+	deployment := "deployment_"
+	daemonSet := "daemonset_"
+	statefulSet := "statefulset_"
+	leaderElection := "leader_election_"
 
 	switch mType {
 	case TypeInstance:
@@ -64,8 +68,16 @@ func MetricName(mType string, name string) string {
 		prefix = service
 	case TypeCluster:
 		prefix = cluster
-	case K8sNamespace:
+	case K8sNamespace, TypeClusterJob, TypeClusterPendingPod, TypeClusterPVC:
 		prefix = namespace
+	case TypeClusterDeployment:
+		prefix = deployment
+	case TypeClusterDaemonSet:
+		prefix = daemonSet
+	case TypeClusterStatefulSet:
+		prefix = statefulSet
+	case TypeClusterLeaderElection:
+		prefix = leaderElection
 	default:
 		log.Printf("E! Unexpected MetricType: %s", mType)
 	}
@@ -40,6 +40,12 @@ type K8sClient struct {
 	Node NodeClient
 
 	ReplicaSet ReplicaSetClient
+
+	Deployment  DeploymentClient
+	DaemonSet   DaemonSetClient
+	StatefulSet StatefulSetClient
+	Job         JobClient
+	PVC         PVCClient
 }
 
 func (c *K8sClient) init() {
@@ -68,6 +74,11 @@ func (c *K8sClient) init() {
 	c.Pod = new(podClient)
 	c.Node = new(nodeClient)
 	c.ReplicaSet = new(replicaSetClient)
+	c.Deployment = new(deploymentClient)
+	c.DaemonSet = new(daemonSetClient)
+	c.StatefulSet = new(statefulSetClient)
+	c.Job = new(jobClient)
+	c.PVC = new(pvcClient)
 	c.inited = true
 }
 
@@ -89,6 +100,21 @@ func (c *K8sClient) shutdown() {
 	if c.ReplicaSet != nil {
 		c.ReplicaSet.Shutdown()
 	}
+	if c.Deployment != nil {
+		c.Deployment.Shutdown()
+	}
+	if c.DaemonSet != nil {
+		c.DaemonSet.Shutdown()
+	}
+	if c.StatefulSet != nil {
+		c.StatefulSet.Shutdown()
+	}
+	if c.Job != nil {
+		c.Job.Shutdown()
+	}
+	if c.PVC != nil {
+		c.PVC.Shutdown()
+	}
 	c.inited = false
 }
 
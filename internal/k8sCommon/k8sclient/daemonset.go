@@ -0,0 +1,128 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+type DaemonSetClient interface {
+	DaemonSetToReplicaStatus() map[Workload]ReplicaStatus
+
+	Init()
+	Shutdown()
+}
+
+type daemonSetClient struct {
+	sync.RWMutex
+
+	stopChan chan struct{}
+	store    *ObjStore
+
+	inited bool
+
+	daemonSetToReplicaStatusMap map[Workload]ReplicaStatus
+}
+
+func (c *daemonSetClient) DaemonSetToReplicaStatus() map[Workload]ReplicaStatus {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.daemonSetToReplicaStatusMap
+}
+
+func (c *daemonSetClient) refresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	objsList := c.store.List()
+
+	tmpMap := make(map[Workload]ReplicaStatus)
+	for _, obj := range objsList {
+		daemonSet := obj.(*daemonSetInfo)
+		tmpMap[NewWorkload(daemonSet.name, daemonSet.namespace)] = daemonSet.status
+	}
+	c.daemonSetToReplicaStatusMap = tmpMap
+}
+
+func (c *daemonSetClient) Init() {
+	c.Lock()
+	defer c.Unlock()
+	if c.inited {
+		return
+	}
+
+	c.stopChan = make(chan struct{})
+
+	c.store = NewObjStore(transformFuncDaemonSet)
+
+	lw := createDaemonSetListWatch(Get().ClientSet, metav1.NamespaceAll)
+	reflector := cache.NewReflector(lw, &appsv1.DaemonSet{}, c.store, 0)
+	go reflector.Run(c.stopChan)
+
+	if err := wait.Poll(50*time.Millisecond, 2*time.Second, func() (done bool, err error) {
+		return reflector.LastSyncResourceVersion() != "", nil
+	}); err != nil {
+		log.Printf("W! DaemonSet initial sync timeout: %v", err)
+	}
+
+	c.inited = true
+}
+
+func (c *daemonSetClient) Shutdown() {
+	c.Lock()
+	defer c.Unlock()
+	if !c.inited {
+		return
+	}
+
+	close(c.stopChan)
+
+	c.inited = false
+}
+
+func transformFuncDaemonSet(obj interface{}) (interface{}, error) {
+	daemonSet, ok := obj.(*appsv1.DaemonSet)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("input obj %v is not DaemonSet type", obj))
+	}
+	info := new(daemonSetInfo)
+	info.name = daemonSet.Name
+	info.namespace = daemonSet.Namespace
+	info.status = ReplicaStatus{
+		Desired:     int(daemonSet.Status.DesiredNumberScheduled),
+		Available:   int(daemonSet.Status.NumberAvailable),
+		Unavailable: int(daemonSet.Status.NumberUnavailable),
+	}
+	return info, nil
+}
+
+func createDaemonSetListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.ResourceVersion = ""
+			return client.AppsV1().DaemonSets(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.AppsV1().DaemonSets(ns).Watch(opts)
+		},
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDaemonSetClient_DaemonSetToReplicaStatus(t *testing.T) {
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	client := &daemonSetClient{
+		stopChan: stopChan,
+		store:    NewObjStore(transformFuncDaemonSet),
+		inited:   true, //make it true to avoid further initialization invocation.
+	}
+
+	client.store.Replace([]interface{}{
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{UID: "4f1c2b3a-9d8e-4f7a-8b6c-5d4e3f2a1b0c", Name: "cloudwatch-agent", Namespace: "amazon-cloudwatch"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberAvailable: 2, NumberUnavailable: 1},
+		},
+	}, "")
+
+	expectedMap := map[Workload]ReplicaStatus{
+		NewWorkload("cloudwatch-agent", "amazon-cloudwatch"): {Desired: 3, Available: 2, Unavailable: 1},
+	}
+	assert.DeepEqual(t, client.DaemonSetToReplicaStatus(), expectedMap)
+}
@@ -0,0 +1,133 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+type DeploymentClient interface {
+	DeploymentToReplicaStatus() map[Workload]ReplicaStatus
+
+	Init()
+	Shutdown()
+}
+
+type deploymentClient struct {
+	sync.RWMutex
+
+	stopChan chan struct{}
+	store    *ObjStore
+
+	inited bool
+
+	deploymentToReplicaStatusMap map[Workload]ReplicaStatus
+}
+
+func (c *deploymentClient) DeploymentToReplicaStatus() map[Workload]ReplicaStatus {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.deploymentToReplicaStatusMap
+}
+
+func (c *deploymentClient) refresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	objsList := c.store.List()
+
+	tmpMap := make(map[Workload]ReplicaStatus)
+	for _, obj := range objsList {
+		deployment := obj.(*deploymentInfo)
+		tmpMap[NewWorkload(deployment.name, deployment.namespace)] = deployment.status
+	}
+	c.deploymentToReplicaStatusMap = tmpMap
+}
+
+func (c *deploymentClient) Init() {
+	c.Lock()
+	defer c.Unlock()
+	if c.inited {
+		return
+	}
+
+	c.stopChan = make(chan struct{})
+
+	c.store = NewObjStore(transformFuncDeployment)
+
+	lw := createDeploymentListWatch(Get().ClientSet, metav1.NamespaceAll)
+	reflector := cache.NewReflector(lw, &appsv1.Deployment{}, c.store, 0)
+	go reflector.Run(c.stopChan)
+
+	if err := wait.Poll(50*time.Millisecond, 2*time.Second, func() (done bool, err error) {
+		return reflector.LastSyncResourceVersion() != "", nil
+	}); err != nil {
+		log.Printf("W! Deployment initial sync timeout: %v", err)
+	}
+
+	c.inited = true
+}
+
+func (c *deploymentClient) Shutdown() {
+	c.Lock()
+	defer c.Unlock()
+	if !c.inited {
+		return
+	}
+
+	close(c.stopChan)
+
+	c.inited = false
+}
+
+func transformFuncDeployment(obj interface{}) (interface{}, error) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("input obj %v is not Deployment type", obj))
+	}
+	info := new(deploymentInfo)
+	info.name = deployment.Name
+	info.namespace = deployment.Namespace
+	// the desired replicas defaults to 1 when it is not specified
+	desired := 1
+	if deployment.Spec.Replicas != nil {
+		desired = int(*deployment.Spec.Replicas)
+	}
+	info.status = ReplicaStatus{
+		Desired:     desired,
+		Available:   int(deployment.Status.AvailableReplicas),
+		Unavailable: int(deployment.Status.UnavailableReplicas),
+	}
+	return info, nil
+}
+
+func createDeploymentListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.ResourceVersion = ""
+			return client.AppsV1().Deployments(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.AppsV1().Deployments(ns).Watch(opts)
+		},
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+type deploymentInfo struct {
+	name      string
+	namespace string
+	status    ReplicaStatus
+}
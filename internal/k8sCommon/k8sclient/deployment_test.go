@@ -0,0 +1,41 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentClient_DeploymentToReplicaStatus(t *testing.T) {
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	client := &deploymentClient{
+		stopChan: stopChan,
+		store:    NewObjStore(transformFuncDeployment),
+		inited:   true, //make it true to avoid further initialization invocation.
+	}
+
+	replicas := int32(3)
+	client.store.Replace([]interface{}{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{UID: "219887d3-8d2e-11e9-9cbd-064a0c5a2714", Name: "cloudwatch-agent-statsd", Namespace: "amazon-cloudwatch"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2, UnavailableReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{UID: "7e0d8f3a-5c2b-4d1e-9f8a-6b3c2d1e0f9a", Name: "coredns", Namespace: "kube-system"},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+		},
+	}, "")
+
+	expectedMap := map[Workload]ReplicaStatus{
+		NewWorkload("cloudwatch-agent-statsd", "amazon-cloudwatch"): {Desired: 3, Available: 2, Unavailable: 1},
+		NewWorkload("coredns", "kube-system"):                       {Desired: 1, Available: 1},
+	}
+	assert.DeepEqual(t, client.DeploymentToReplicaStatus(), expectedMap)
+}
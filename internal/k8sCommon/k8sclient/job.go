@@ -0,0 +1,150 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+type JobClient interface {
+	NamespaceToJobCount() map[string]JobCount
+
+	Init()
+	Shutdown()
+}
+
+type jobClient struct {
+	sync.RWMutex
+
+	stopChan chan struct{}
+	store    *ObjStore
+
+	inited bool
+
+	namespaceToJobCountMap map[string]JobCount
+}
+
+func (c *jobClient) NamespaceToJobCount() map[string]JobCount {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.namespaceToJobCountMap
+}
+
+func (c *jobClient) refresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	objsList := c.store.List()
+
+	tmpMap := make(map[string]JobCount)
+	for _, obj := range objsList {
+		job := obj.(*jobInfo)
+		count := tmpMap[job.namespace]
+		switch job.status {
+		case jobSucceeded:
+			count.Succeeded++
+		case jobFailed:
+			count.Failed++
+		default:
+			count.Active++
+		}
+		tmpMap[job.namespace] = count
+	}
+	c.namespaceToJobCountMap = tmpMap
+}
+
+func (c *jobClient) Init() {
+	c.Lock()
+	defer c.Unlock()
+	if c.inited {
+		return
+	}
+
+	c.stopChan = make(chan struct{})
+
+	c.store = NewObjStore(transformFuncJob)
+
+	lw := createJobListWatch(Get().ClientSet, metav1.NamespaceAll)
+	reflector := cache.NewReflector(lw, &batchv1.Job{}, c.store, 0)
+	go reflector.Run(c.stopChan)
+
+	if err := wait.Poll(50*time.Millisecond, 2*time.Second, func() (done bool, err error) {
+		return reflector.LastSyncResourceVersion() != "", nil
+	}); err != nil {
+		log.Printf("W! Job initial sync timeout: %v", err)
+	}
+
+	c.inited = true
+}
+
+func (c *jobClient) Shutdown() {
+	c.Lock()
+	defer c.Unlock()
+	if !c.inited {
+		return
+	}
+
+	close(c.stopChan)
+
+	c.inited = false
+}
+
+func transformFuncJob(obj interface{}) (interface{}, error) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("input obj %v is not Job type", obj))
+	}
+	info := new(jobInfo)
+	info.name = job.Name
+	info.namespace = job.Namespace
+	info.owners = []*jobOwner{}
+	for _, owner := range job.OwnerReferences {
+		info.owners = append(info.owners, &jobOwner{kind: owner.Kind, name: owner.Name})
+	}
+	// a job is finished when it has the Complete or the Failed condition
+	info.status = jobActive
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			info.status = jobSucceeded
+		case batchv1.JobFailed:
+			info.status = jobFailed
+		}
+	}
+	return info, nil
+}
+
+func createJobListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.ResourceVersion = ""
+			return client.BatchV1().Jobs(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.BatchV1().Jobs(ns).Watch(opts)
+		},
+	}
+}
@@ -4,11 +4,28 @@
 package k8sclient
 
 type jobInfo struct {
-	name   string
-	owners []*jobOwner
+	name      string
+	namespace string
+	owners    []*jobOwner
+	status    jobStatus
 }
 
 type jobOwner struct {
 	kind string
 	name string
 }
+
+type jobStatus int
+
+const (
+	jobActive jobStatus = iota
+	jobSucceeded
+	jobFailed
+)
+
+// JobCount is the number of the jobs of a namespace per status
+type JobCount struct {
+	Active    int
+	Succeeded int
+	Failed    int
+}
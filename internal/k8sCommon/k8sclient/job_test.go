@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJobClient_NamespaceToJobCount(t *testing.T) {
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	client := &jobClient{
+		stopChan: stopChan,
+		store:    NewObjStore(transformFuncJob),
+		inited:   true, //make it true to avoid further initialization invocation.
+	}
+
+	client.store.Replace([]interface{}{
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{UID: "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d", Name: "backup-1", Namespace: "default"},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{UID: "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5e", Name: "backup-2", Namespace: "default"},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{UID: "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5f", Name: "backup-3", Namespace: "default"},
+			Status:     batchv1.JobStatus{Active: 1},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{UID: "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c60", Name: "migrate", Namespace: "kube-system"},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}},
+		},
+	}, "")
+
+	expectedMap := map[string]JobCount{
+		"default":     {Active: 1, Succeeded: 1, Failed: 1},
+		"kube-system": {Succeeded: 1},
+	}
+	assert.DeepEqual(t, client.NamespaceToJobCount(), expectedMap)
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,8 +20,14 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
+const (
+	PrometheusAnnotationPrefix = "prometheus.io/"
+)
+
 type PodClient interface {
 	NamespaceToRunningPodNum() map[string]int
+	RunningPods() []*PodMetadata
+	NamespaceToPendingPodNumByReason() map[string]map[string]int
 
 	Init()
 	Shutdown()
@@ -34,7 +41,9 @@ type podClient struct {
 
 	inited bool
 
-	namespaceToRunningPodNumMap map[string]int
+	namespaceToRunningPodNumMap         map[string]int
+	runningPods                         []*PodMetadata
+	namespaceToPendingPodNumByReasonMap map[string]map[string]int
 }
 
 func (c *podClient) NamespaceToRunningPodNum() map[string]int {
@@ -49,15 +58,55 @@ func (c *podClient) NamespaceToRunningPodNum() map[string]int {
 	return c.namespaceToRunningPodNumMap
 }
 
+func (c *podClient) RunningPods() []*PodMetadata {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.runningPods
+}
+
+func (c *podClient) NamespaceToPendingPodNumByReason() map[string]map[string]int {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.namespaceToPendingPodNumByReasonMap
+}
+
 func (c *podClient) refresh() {
 	c.Lock()
 	defer c.Unlock()
 
 	objsList := c.store.List()
 	namespaceToRunningPodNumMapNew := make(map[string]int)
+	var runningPodsNew []*PodMetadata
+	namespaceToPendingPodNumByReasonMapNew := make(map[string]map[string]int)
 	for _, obj := range objsList {
 		pod := obj.(*podInfo)
+		if pod.phase == v1.PodPending {
+			if _, ok := namespaceToPendingPodNumByReasonMapNew[pod.namespace]; !ok {
+				namespaceToPendingPodNumByReasonMapNew[pod.namespace] = make(map[string]int)
+			}
+			namespaceToPendingPodNumByReasonMapNew[pod.namespace][pod.pendingReason]++
+		}
 		if pod.phase == v1.PodRunning {
+			runningPodsNew = append(runningPodsNew, &PodMetadata{
+				Name:           pod.name,
+				Namespace:      pod.namespace,
+				PodIP:          pod.podIP,
+				Labels:         pod.labels,
+				Annotations:    pod.annotations,
+				ContainerPorts: pod.containerPorts,
+			})
 			if podNum, ok := namespaceToRunningPodNumMapNew[pod.namespace]; !ok {
 				namespaceToRunningPodNumMapNew[pod.namespace] = 1
 			} else {
@@ -66,6 +115,8 @@ func (c *podClient) refresh() {
 		}
 	}
 	c.namespaceToRunningPodNumMap = namespaceToRunningPodNumMapNew
+	c.runningPods = runningPodsNew
+	c.namespaceToPendingPodNumByReasonMap = namespaceToPendingPodNumByReasonMapNew
 }
 
 func (c *podClient) Init() {
@@ -110,11 +161,54 @@ func transformFuncPod(obj interface{}) (interface{}, error) {
 		return nil, errors.New(fmt.Sprintf("input obj %v is not Pod type", obj))
 	}
 	info := new(podInfo)
+	info.name = pod.Name
 	info.namespace = pod.Namespace
 	info.phase = pod.Status.Phase
+	info.podIP = pod.Status.PodIP
+	if pod.Status.Phase == v1.PodPending {
+		info.pendingReason = pendingReason(pod)
+	}
+	info.labels = pod.Labels
+	// keep only the annotations used for service discovery to limit the memory usage
+	for k, v := range pod.Annotations {
+		if strings.HasPrefix(k, PrometheusAnnotationPrefix) {
+			if info.annotations == nil {
+				info.annotations = make(map[string]string)
+			}
+			info.annotations[k] = v
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Protocol == "" || port.Protocol == v1.ProtocolTCP {
+				info.containerPorts = append(info.containerPorts, port.ContainerPort)
+			}
+		}
+	}
 	return info, nil
 }
 
+// pendingReason returns why the pod is pending, e.g. Unschedulable when it cannot be scheduled, or the waiting reason
+// of its containers such as ImagePullBackOff
+func pendingReason(pod *v1.Pod) string {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse && condition.Reason != "" {
+			return condition.Reason
+		}
+	}
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				return status.State.Waiting.Reason
+			}
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return "Unknown"
+}
+
 func createPodListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -8,6 +8,24 @@ import (
 )
 
 type podInfo struct {
-	namespace string
-	phase     v1.PodPhase
+	name           string
+	namespace      string
+	phase          v1.PodPhase
+	pendingReason  string
+	podIP          string
+	labels         map[string]string
+	annotations    map[string]string
+	containerPorts []int32
+}
+
+// PodMetadata describes a running pod for the Prometheus service discovery
+type PodMetadata struct {
+	Name      string
+	Namespace string
+	PodIP     string
+	Labels    map[string]string
+	// only the annotations with the PrometheusAnnotationPrefix are kept
+	Annotations map[string]string
+	// the TCP ports declared by the containers of the pod
+	ContainerPorts []int32
 }
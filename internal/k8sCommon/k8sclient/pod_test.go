@@ -183,3 +183,94 @@ func TestPodClient_NamespaceToRunningPodNum(t *testing.T) {
 	log.Printf("NamespaceToRunningPodNum (len=%v): %v", len(resultMap), awsutil.Prettify(resultMap))
 	assert.DeepEqual(t, resultMap, expectedMap)
 }
+
+func TestPodClient_RunningPods(t *testing.T) {
+	client, stopChan := setUpPodClient()
+	defer close(stopChan)
+
+	pods := []interface{}{
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:         "5b3c9a4e-1d2f-4c7b-9e8a-0f6d2c1b3a47",
+				Name:        "exporter-7d4f9",
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "exporter"},
+				Annotations: map[string]string{"prometheus.io/scrape": "true", "kubernetes.io/psp": "eks.privileged"},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Ports: []v1.ContainerPort{{ContainerPort: 9100}, {ContainerPort: 53, Protocol: v1.ProtocolUDP}}},
+				},
+			},
+			Status: v1.PodStatus{
+				Phase: "Running",
+				PodIP: "10.0.0.1",
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:       "e2a7d1c9-3b4f-4a6e-8d5c-7f9b0a1e2c3d",
+				Name:      "job-bz6k2",
+				Namespace: "default",
+			},
+			Status: v1.PodStatus{
+				Phase: "Succeeded",
+			},
+		},
+	}
+	client.store.Replace(pods, "")
+
+	expected := []*PodMetadata{
+		{
+			Name:           "exporter-7d4f9",
+			Namespace:      "default",
+			PodIP:          "10.0.0.1",
+			Labels:         map[string]string{"app": "exporter"},
+			Annotations:    map[string]string{"prometheus.io/scrape": "true"},
+			ContainerPorts: []int32{9100},
+		},
+	}
+	assert.DeepEqual(t, client.RunningPods(), expected)
+}
+
+func TestPodClient_NamespaceToPendingPodNumByReason(t *testing.T) {
+	client, stopChan := setUpPodClient()
+	defer close(stopChan)
+
+	pods := []interface{}{
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{UID: "0d0e3c1a-6f3e-4b8c-9a55-5b0f9e1c2d01", Name: "unschedulable", Namespace: "default"},
+			Status: v1.PodStatus{
+				Phase:      "Pending",
+				Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: "Unschedulable"}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{UID: "0d0e3c1a-6f3e-4b8c-9a55-5b0f9e1c2d02", Name: "image-pull", Namespace: "default"},
+			Status: v1.PodStatus{
+				Phase:             "Pending",
+				Conditions:        []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue}},
+				ContainerStatuses: []v1.ContainerStatus{{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{UID: "0d0e3c1a-6f3e-4b8c-9a55-5b0f9e1c2d03", Name: "unknown", Namespace: "kube-system"},
+			Status: v1.PodStatus{
+				Phase: "Pending",
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{UID: "0d0e3c1a-6f3e-4b8c-9a55-5b0f9e1c2d04", Name: "running", Namespace: "default"},
+			Status: v1.PodStatus{
+				Phase: "Running",
+			},
+		},
+	}
+	client.store.Replace(pods, "")
+
+	expectedMap := map[string]map[string]int{
+		"default":     {"Unschedulable": 1, "ImagePullBackOff": 1},
+		"kube-system": {"Unknown": 1},
+	}
+	assert.DeepEqual(t, client.NamespaceToPendingPodNumByReason(), expectedMap)
+}
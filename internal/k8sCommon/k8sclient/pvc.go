@@ -0,0 +1,127 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+type PVCClient interface {
+	NamespaceToPVCNumByPhase() map[string]map[string]int
+
+	Init()
+	Shutdown()
+}
+
+type pvcClient struct {
+	sync.RWMutex
+
+	stopChan chan struct{}
+	store    *ObjStore
+
+	inited bool
+
+	namespaceToPVCNumByPhaseMap map[string]map[string]int
+}
+
+func (c *pvcClient) NamespaceToPVCNumByPhase() map[string]map[string]int {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.namespaceToPVCNumByPhaseMap
+}
+
+func (c *pvcClient) refresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	objsList := c.store.List()
+
+	tmpMap := make(map[string]map[string]int)
+	for _, obj := range objsList {
+		pvc := obj.(*pvcInfo)
+		if _, ok := tmpMap[pvc.namespace]; !ok {
+			tmpMap[pvc.namespace] = make(map[string]int)
+		}
+		tmpMap[pvc.namespace][string(pvc.phase)]++
+	}
+	c.namespaceToPVCNumByPhaseMap = tmpMap
+}
+
+func (c *pvcClient) Init() {
+	c.Lock()
+	defer c.Unlock()
+	if c.inited {
+		return
+	}
+
+	c.stopChan = make(chan struct{})
+
+	c.store = NewObjStore(transformFuncPVC)
+
+	lw := createPVCListWatch(Get().ClientSet, metav1.NamespaceAll)
+	reflector := cache.NewReflector(lw, &v1.PersistentVolumeClaim{}, c.store, 0)
+	go reflector.Run(c.stopChan)
+
+	if err := wait.Poll(50*time.Millisecond, 2*time.Second, func() (done bool, err error) {
+		return reflector.LastSyncResourceVersion() != "", nil
+	}); err != nil {
+		log.Printf("W! PersistentVolumeClaim initial sync timeout: %v", err)
+	}
+
+	c.inited = true
+}
+
+func (c *pvcClient) Shutdown() {
+	c.Lock()
+	defer c.Unlock()
+	if !c.inited {
+		return
+	}
+
+	close(c.stopChan)
+
+	c.inited = false
+}
+
+func transformFuncPVC(obj interface{}) (interface{}, error) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("input obj %v is not PersistentVolumeClaim type", obj))
+	}
+	info := new(pvcInfo)
+	info.name = pvc.Name
+	info.namespace = pvc.Namespace
+	info.phase = pvc.Status.Phase
+	return info, nil
+}
+
+func createPVCListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.ResourceVersion = ""
+			return client.CoreV1().PersistentVolumeClaims(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().PersistentVolumeClaims(ns).Watch(opts)
+		},
+	}
+}
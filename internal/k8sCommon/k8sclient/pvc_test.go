@@ -0,0 +1,42 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPVCClient_NamespaceToPVCNumByPhase(t *testing.T) {
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	client := &pvcClient{
+		stopChan: stopChan,
+		store:    NewObjStore(transformFuncPVC),
+		inited:   true, //make it true to avoid further initialization invocation.
+	}
+
+	client.store.Replace([]interface{}{
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{UID: "9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e6f", Name: "data-redis-0", Namespace: "default"},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{UID: "9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e70", Name: "data-redis-1", Namespace: "default"},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending},
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{UID: "9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e71", Name: "data-redis-2", Namespace: "default"},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+		},
+	}, "")
+
+	expectedMap := map[string]map[string]int{
+		"default": {"Bound": 2, "Pending": 1},
+	}
+	assert.DeepEqual(t, client.NamespaceToPVCNumByPhase(), expectedMap)
+}
@@ -0,0 +1,139 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+type StatefulSetClient interface {
+	StatefulSetToReplicaStatus() map[Workload]ReplicaStatus
+
+	Init()
+	Shutdown()
+}
+
+type statefulSetClient struct {
+	sync.RWMutex
+
+	stopChan chan struct{}
+	store    *ObjStore
+
+	inited bool
+
+	statefulSetToReplicaStatusMap map[Workload]ReplicaStatus
+}
+
+func (c *statefulSetClient) StatefulSetToReplicaStatus() map[Workload]ReplicaStatus {
+	if !c.inited {
+		c.Init()
+	}
+	if c.store.Refreshed() {
+		c.refresh()
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.statefulSetToReplicaStatusMap
+}
+
+func (c *statefulSetClient) refresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	objsList := c.store.List()
+
+	tmpMap := make(map[Workload]ReplicaStatus)
+	for _, obj := range objsList {
+		statefulSet := obj.(*statefulSetInfo)
+		tmpMap[NewWorkload(statefulSet.name, statefulSet.namespace)] = statefulSet.status
+	}
+	c.statefulSetToReplicaStatusMap = tmpMap
+}
+
+func (c *statefulSetClient) Init() {
+	c.Lock()
+	defer c.Unlock()
+	if c.inited {
+		return
+	}
+
+	c.stopChan = make(chan struct{})
+
+	c.store = NewObjStore(transformFuncStatefulSet)
+
+	lw := createStatefulSetListWatch(Get().ClientSet, metav1.NamespaceAll)
+	reflector := cache.NewReflector(lw, &appsv1.StatefulSet{}, c.store, 0)
+	go reflector.Run(c.stopChan)
+
+	if err := wait.Poll(50*time.Millisecond, 2*time.Second, func() (done bool, err error) {
+		return reflector.LastSyncResourceVersion() != "", nil
+	}); err != nil {
+		log.Printf("W! StatefulSet initial sync timeout: %v", err)
+	}
+
+	c.inited = true
+}
+
+func (c *statefulSetClient) Shutdown() {
+	c.Lock()
+	defer c.Unlock()
+	if !c.inited {
+		return
+	}
+
+	close(c.stopChan)
+
+	c.inited = false
+}
+
+func transformFuncStatefulSet(obj interface{}) (interface{}, error) {
+	statefulSet, ok := obj.(*appsv1.StatefulSet)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("input obj %v is not StatefulSet type", obj))
+	}
+	info := new(statefulSetInfo)
+	info.name = statefulSet.Name
+	info.namespace = statefulSet.Namespace
+	// the desired replicas defaults to 1 when it is not specified
+	desired := 1
+	if statefulSet.Spec.Replicas != nil {
+		desired = int(*statefulSet.Spec.Replicas)
+	}
+	// the status of a StatefulSet has no available replicas, the ready replicas are used instead
+	available := int(statefulSet.Status.ReadyReplicas)
+	unavailable := desired - available
+	if unavailable < 0 {
+		unavailable = 0
+	}
+	info.status = ReplicaStatus{
+		Desired:     desired,
+		Available:   available,
+		Unavailable: unavailable,
+	}
+	return info, nil
+}
+
+func createStatefulSetListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.ResourceVersion = ""
+			return client.AppsV1().StatefulSets(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.AppsV1().StatefulSets(ns).Watch(opts)
+		},
+	}
+}
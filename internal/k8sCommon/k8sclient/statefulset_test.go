@@ -0,0 +1,36 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatefulSetClient_StatefulSetToReplicaStatus(t *testing.T) {
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	client := &statefulSetClient{
+		stopChan: stopChan,
+		store:    NewObjStore(transformFuncStatefulSet),
+		inited:   true, //make it true to avoid further initialization invocation.
+	}
+
+	replicas := int32(3)
+	client.store.Replace([]interface{}{
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{UID: "8a7b6c5d-4e3f-4a1b-9c8d-7e6f5a4b3c2d", Name: "redis", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+	}, "")
+
+	expectedMap := map[Workload]ReplicaStatus{
+		NewWorkload("redis", "default"): {Desired: 3, Available: 1, Unavailable: 2},
+	}
+	assert.DeepEqual(t, client.StatefulSetToReplicaStatus(), expectedMap)
+}
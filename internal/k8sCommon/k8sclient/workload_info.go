@@ -0,0 +1,21 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sclient
+
+// Workload identifies a Deployment, a DaemonSet or a StatefulSet
+type Workload struct {
+	Name      string
+	Namespace string
+}
+
+func NewWorkload(name, namespace string) Workload {
+	return Workload{Name: name, Namespace: namespace}
+}
+
+// ReplicaStatus is the rollout health of a workload
+type ReplicaStatus struct {
+	Desired     int
+	Available   int
+	Unavailable int
+}
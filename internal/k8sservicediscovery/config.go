@@ -0,0 +1,10 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sservicediscovery
+
+type ServiceDiscoveryConfig struct {
+	Frequency  string `toml:"sd_frequency"`
+	ResultFile string `toml:"sd_result_file"`
+	JobName    string `toml:"sd_job_name"`
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sservicediscovery
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+)
+
+type ServiceDiscovery struct {
+	Config *ServiceDiscoveryConfig
+
+	podClient k8sclient.PodClient
+	epClient  k8sclient.EpClient
+	exporter  *TargetsExporter
+}
+
+func (sd *ServiceDiscovery) init() bool {
+	client := k8sclient.Get()
+	if client.Pod == nil || client.Ep == nil {
+		log.Printf("E! Kubernetes client is not available, Kubernetes service discovery is disabled.\n")
+		return false
+	}
+	sd.podClient = client.Pod
+	sd.epClient = client.Ep
+	sd.exporter = NewTargetsExporter(sd.Config)
+	return true
+}
+
+func StartK8sServiceDiscovery(sd *ServiceDiscovery, shutDownChan chan interface{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if !sd.validateConfig() {
+		return
+	}
+
+	if !sd.init() {
+		return
+	}
+
+	frequency, _ := time.ParseDuration(sd.Config.Frequency)
+	t := time.NewTicker(frequency)
+	defer t.Stop()
+	for {
+		select {
+		case <-shutDownChan:
+			return
+		case <-t.C:
+			sd.work()
+		}
+	}
+}
+
+func (sd *ServiceDiscovery) work() {
+	pods := sd.podClient.RunningPods()
+	podKeyToServiceNames := sd.epClient.PodKeyToServiceNames()
+	if err := sd.exporter.Export(pods, podKeyToServiceNames); err != nil {
+		// Keep the existing targets when failing to export the new ones
+		log.Printf("E! Kubernetes SD got error: %v \n", err)
+	}
+}
+
+func (sd *ServiceDiscovery) validateConfig() bool {
+	if sd.Config == nil {
+		return false
+	}
+
+	if sd.Config.ResultFile == "" {
+		log.Printf("E! Kubernetes service discovery result file is not configured.\n")
+		return false
+	}
+
+	_, err := time.ParseDuration(sd.Config.Frequency)
+	if err != nil {
+		log.Printf("E! Invalid Kubernetes service discovery frequency: %v.\n", sd.Config.Frequency)
+		return false
+	}
+
+	return true
+}
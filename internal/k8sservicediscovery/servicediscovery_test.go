@@ -0,0 +1,17 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sservicediscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ServiceDiscovery_ValidateConfig(t *testing.T) {
+	assert.False(t, (&ServiceDiscovery{}).validateConfig())
+	assert.False(t, (&ServiceDiscovery{Config: &ServiceDiscoveryConfig{Frequency: "1m"}}).validateConfig())
+	assert.False(t, (&ServiceDiscovery{Config: &ServiceDiscoveryConfig{Frequency: "1", ResultFile: "/tmp/sd.yaml"}}).validateConfig())
+	assert.True(t, (&ServiceDiscovery{Config: &ServiceDiscoveryConfig{Frequency: "1m", ResultFile: "/tmp/sd.yaml"}}).validateConfig())
+}
@@ -0,0 +1,161 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sservicediscovery
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sutil"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Prometheus <labelname> definition: a string matching the regular expression [a-zA-Z_][a-zA-Z0-9_]*
+	// Regex pattern to filter out invalid labels
+	prometheusLabelNamePattern = "^[a-zA-Z_][a-zA-Z0-9_]*$"
+
+	// The annotations used by the Prometheus kubernetes-pods example configuration
+	scrapeAnnotation = k8sclient.PrometheusAnnotationPrefix + "scrape"
+	portAnnotation   = k8sclient.PrometheusAnnotationPrefix + "port"
+	pathAnnotation   = k8sclient.PrometheusAnnotationPrefix + "path"
+	schemeAnnotation = k8sclient.PrometheusAnnotationPrefix + "scheme"
+
+	namespaceLabel   = "namespace"
+	podNameLabel     = "pod_name"
+	serviceLabel     = "Service"
+	jobNameLabel     = "job"
+	metricsPathLabel = "__metrics_path__"
+	schemeLabel      = "__scheme__"
+
+	//https://prometheus.io/docs/prometheus/latest/configuration/configuration/#scrape_config
+	defaultPrometheusMetricsPath = "/metrics"
+)
+
+type PrometheusTarget struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// TargetsExporter writes the scrape targets of the annotated pods into a Prometheus file_sd file
+type TargetsExporter struct {
+	config *ServiceDiscoveryConfig
+
+	labelRegex        *regexp.Regexp
+	tmpResultFilePath string
+}
+
+func NewTargetsExporter(sdConfig *ServiceDiscoveryConfig) *TargetsExporter {
+	return &TargetsExporter{
+		config:            sdConfig,
+		labelRegex:        regexp.MustCompile(prometheusLabelNamePattern),
+		tmpResultFilePath: sdConfig.ResultFile + "_temp",
+	}
+}
+
+func (e *TargetsExporter) Export(pods []*k8sclient.PodMetadata, podKeyToServiceNames map[string][]string) error {
+	// Dedup Key for Targets: target + metricsPath
+	// e.g. 10.0.0.28:9404/metrics
+	//      10.0.0.28:9404/stats/metrics
+	targets := make(map[string]*PrometheusTarget)
+	for _, pod := range pods {
+		e.exportPodTargets(pod, podKeyToServiceNames[k8sutil.CreatePodKey(pod.Namespace, pod.Name)], targets)
+	}
+
+	targetKeys := make([]string, 0, len(targets))
+	for k := range targets {
+		targetKeys = append(targetKeys, k)
+	}
+	sort.Strings(targetKeys)
+	targetsArr := make([]*PrometheusTarget, 0, len(targets))
+	for _, k := range targetKeys {
+		targetsArr = append(targetsArr, targets[k])
+	}
+
+	m, err := yaml.Marshal(targetsArr)
+	if err != nil {
+		return fmt.Errorf("fail to marshal Prometheus targets: %v", err)
+	}
+	log.Printf("D! Kubernetes SD discovered %v Prometheus targets\n", len(targetsArr))
+
+	err = ioutil.WriteFile(e.tmpResultFilePath, m, 0644)
+	if err != nil {
+		return fmt.Errorf("fail to write Prometheus targets into file %v: %v", e.tmpResultFilePath, err)
+	}
+	err = os.Rename(e.tmpResultFilePath, e.config.ResultFile)
+	if err != nil {
+		os.Remove(e.tmpResultFilePath)
+		return fmt.Errorf("fail to rename tmp result file %v to %v: %v", e.tmpResultFilePath, e.config.ResultFile, err)
+	}
+	return nil
+}
+
+func (e *TargetsExporter) exportPodTargets(pod *k8sclient.PodMetadata, serviceNames []string, targets map[string]*PrometheusTarget) {
+	if pod.PodIP == "" || pod.Annotations[scrapeAnnotation] != "true" {
+		return
+	}
+
+	// the port annotation takes precedence over the ports declared by the containers
+	var ports []int32
+	if v, ok := pod.Annotations[portAnnotation]; ok {
+		if port, err := strconv.Atoi(v); err != nil || port <= 0 {
+			log.Printf("W! Invalid %v annotation %q for pod %v/%v\n", portAnnotation, v, pod.Namespace, pod.Name)
+			return
+		} else {
+			ports = append(ports, int32(port))
+		}
+	} else {
+		ports = pod.ContainerPorts
+	}
+
+	metricsPath := defaultPrometheusMetricsPath
+	if v, ok := pod.Annotations[pathAnnotation]; ok && v != "" {
+		metricsPath = v
+	}
+
+	for _, port := range ports {
+		targetKey := fmt.Sprintf("%s:%d%s", pod.PodIP, port, metricsPath)
+		if _, ok := targets[targetKey]; ok {
+			continue
+		}
+		targets[targetKey] = &PrometheusTarget{
+			Targets: []string{fmt.Sprintf("%s:%d", pod.PodIP, port)},
+			Labels:  e.podTargetLabels(pod, serviceNames, metricsPath),
+		}
+	}
+}
+
+func (e *TargetsExporter) podTargetLabels(pod *k8sclient.PodMetadata, serviceNames []string, metricsPath string) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range pod.Labels {
+		if e.labelRegex.MatchString(k) {
+			addTargetLabel(labels, k, v)
+		}
+	}
+	addTargetLabel(labels, namespaceLabel, pod.Namespace)
+	addTargetLabel(labels, podNameLabel, pod.Name)
+	if len(serviceNames) > 0 {
+		names := append([]string(nil), serviceNames...)
+		sort.Strings(names)
+		addTargetLabel(labels, serviceLabel, strings.Join(names, ","))
+	}
+	addTargetLabel(labels, metricsPathLabel, metricsPath)
+	addTargetLabel(labels, schemeLabel, pod.Annotations[schemeAnnotation])
+	// handle customized job label at last, so the conflict job pod label is overriden
+	addTargetLabel(labels, jobNameLabel, e.config.JobName)
+	return labels
+}
+
+func addTargetLabel(labels map[string]string, labelKey string, labelValue string) {
+	if labelValue != "" {
+		labels[labelKey] = labelValue
+	}
+}
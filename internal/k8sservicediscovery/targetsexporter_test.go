@@ -0,0 +1,93 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sservicediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetsExporter_Export(t *testing.T) {
+	dir, err := ioutil.TempDir("", "k8ssd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := &ServiceDiscoveryConfig{ResultFile: filepath.Join(dir, "targets.yaml"), JobName: "kubernetes-pods"}
+	pods := []*k8sclient.PodMetadata{
+		{
+			Name:      "nginx-6db489d4b7-xk4zq",
+			Namespace: "default",
+			PodIP:     "10.0.0.1",
+			Labels:    map[string]string{"app": "nginx", "app.kubernetes.io/name": "nginx"},
+			Annotations: map[string]string{
+				"prometheus.io/scrape": "true",
+				"prometheus.io/port":   "9113",
+				"prometheus.io/path":   "/stats",
+			},
+			ContainerPorts: []int32{80},
+		},
+		{
+			Name:           "redis-0",
+			Namespace:      "cache",
+			PodIP:          "10.0.0.2",
+			Annotations:    map[string]string{"prometheus.io/scrape": "true", "prometheus.io/scheme": "https"},
+			ContainerPorts: []int32{6379, 9121},
+		},
+		{
+			Name:           "not-scraped",
+			Namespace:      "default",
+			PodIP:          "10.0.0.3",
+			ContainerPorts: []int32{8080},
+		},
+		{
+			Name:        "invalid-port",
+			Namespace:   "default",
+			PodIP:       "10.0.0.4",
+			Annotations: map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "http"},
+		},
+	}
+	podKeyToServiceNames := map[string][]string{
+		"namespace:default,podName:nginx-6db489d4b7-xk4zq": {"nginx-public", "nginx"},
+	}
+
+	require.NoError(t, NewTargetsExporter(config).Export(pods, podKeyToServiceNames))
+
+	content, err := ioutil.ReadFile(config.ResultFile)
+	require.NoError(t, err)
+	expected := `- targets:
+  - 10.0.0.1:9113
+  labels:
+    __metrics_path__: /stats
+    Service: nginx,nginx-public
+    app: nginx
+    job: kubernetes-pods
+    namespace: default
+    pod_name: nginx-6db489d4b7-xk4zq
+- targets:
+  - 10.0.0.2:6379
+  labels:
+    __metrics_path__: /metrics
+    __scheme__: https
+    job: kubernetes-pods
+    namespace: cache
+    pod_name: redis-0
+- targets:
+  - 10.0.0.2:9121
+  labels:
+    __metrics_path__: /metrics
+    __scheme__: https
+    job: kubernetes-pods
+    namespace: cache
+    pod_name: redis-0
+`
+	assert.Equal(t, expected, string(content))
+	_, err = os.Stat(config.ResultFile + "_temp")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/health"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/influxdata/telegraf"
@@ -50,6 +51,7 @@ func (r *LogThrottleRetryer) ShouldRetry(req *request.Request) bool {
 		if req.Operation != nil {
 			te.Operation = req.Operation.Name
 		}
+		health.Status.RecordThrottle(te.Operation)
 		r.throttleChan <- te
 	}
 
@@ -0,0 +1,135 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a log event generated by a plugin rather than read from a file, e.g. a message received by a listener.
+// It is not acknowledged since it is not persisted by its source.
+type Event struct {
+	msg    string
+	t      time.Time
+	fields map[string]interface{}
+}
+
+// NewEvent returns the log event of the message, fields are the fields parsed from the message if any
+func NewEvent(msg string, t time.Time, fields map[string]interface{}) *Event {
+	return &Event{msg: msg, t: t, fields: fields}
+}
+
+func (e *Event) Message() string {
+	return e.msg
+}
+
+func (e *Event) Time() time.Time {
+	return e.t
+}
+
+// Fields returns the fields parsed from the message, nil if it was not parsed
+func (e *Event) Fields() map[string]interface{} {
+	return e.fields
+}
+
+func (e *Event) Done() {}
+
+// EventSrc is a LogSrc whose log events are published by a plugin, e.g. the events received by a service input.
+// The log events are queued until they are piped by the log agent to the destination.
+type EventSrc struct {
+	group, stream, destination, description string
+	retention                               int
+
+	events    chan LogEvent
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewEventSrc(group, stream, destination, description string, retention, queueSize int) *EventSrc {
+	return &EventSrc{
+		group:       group,
+		stream:      stream,
+		destination: destination,
+		description: description,
+		retention:   retention,
+		events:      make(chan LogEvent, queueSize),
+		done:        make(chan struct{}),
+	}
+}
+
+func (s *EventSrc) SetOutput(fn func(LogEvent)) {
+	if fn == nil {
+		return
+	}
+	s.startOnce.Do(func() { go s.run(fn) })
+}
+
+func (s *EventSrc) run(fn func(LogEvent)) {
+	for {
+		select {
+		case e := <-s.events:
+			fn(e)
+		case <-s.done:
+			fn(nil) // inform the log agent of the src's exit
+			return
+		}
+	}
+}
+
+// Publish queues the log event, it returns false when the queue is full
+func (s *EventSrc) Publish(e LogEvent) bool {
+	select {
+	case s.events <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// PublishWait queues the log event once there is room in the queue, it returns false when the src is stopped or
+// cancel is closed first
+func (s *EventSrc) PublishWait(e LogEvent, cancel <-chan struct{}) bool {
+	select {
+	case <-s.done:
+		return false
+	case <-cancel:
+		return false
+	default:
+	}
+	select {
+	case s.events <- e:
+		return true
+	case <-s.done:
+	case <-cancel:
+	}
+	return false
+}
+
+func (s *EventSrc) Group() string {
+	return s.group
+}
+
+func (s *EventSrc) Stream() string {
+	return s.stream
+}
+
+func (s *EventSrc) Destination() string {
+	return s.destination
+}
+
+func (s *EventSrc) Description() string {
+	return s.description
+}
+
+func (s *EventSrc) Retention() int {
+	return s.retention
+}
+
+// Stop ends the src, it is called by the log agent when the destination stops, or by the plugin when it stops
+// unless the log events already queued should still be published
+func (s *EventSrc) Stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSrc(t *testing.T) {
+	src := NewEventSrc("group", "stream", "cloudwatchlogs", "events", -1, 1)
+	assert.Equal(t, "events", src.Description())
+	assert.Equal(t, -1, src.Retention())
+
+	assert.True(t, src.Publish(NewEvent("first", time.Now(), nil)))
+	assert.False(t, src.Publish(NewEvent("dropped", time.Now(), nil)), "the queue is full")
+
+	received := make(chan LogEvent, 10)
+	src.SetOutput(func(e LogEvent) { received <- e })
+	select {
+	case e := <-received:
+		assert.Equal(t, "first", e.Message())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event was not sent to the output")
+	}
+
+	fields := map[string]interface{}{"level": "info"}
+	assert.True(t, src.PublishWait(NewEvent("second", time.Now(), fields), nil))
+	select {
+	case e := <-received:
+		assert.Equal(t, fields, e.(StructuredLogEvent).Fields())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event was not sent to the output")
+	}
+
+	src.Stop()
+	src.Stop()
+	select {
+	case e := <-received:
+		assert.Nil(t, e)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the exit of the src was not sent to the output")
+	}
+	assert.False(t, src.PublishWait(NewEvent("stopped", time.Now(), nil), nil))
+}
@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// dropProcessor drops the log events matching the pattern
+type dropProcessor struct {
+	pattern *regexp.Regexp
+}
+
+func newDropProcessor(c Config) (*dropProcessor, error) {
+	if c.Pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+	pattern, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern regex has issue, regexp: Compile( %v ): %v", c.Pattern, err)
+	}
+	return &dropProcessor{pattern: pattern}, nil
+}
+
+func (p *dropProcessor) Process(msg string) (string, bool) {
+	if p.pattern.MatchString(msg) {
+		return "", false
+	}
+	return msg, true
+}
@@ -0,0 +1,98 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+)
+
+const messageKey = "message"
+
+// addFieldsProcessor adds static fields to the log events.
+// A JSON object log event gets the fields added to it, the existing keys are kept as is.
+// Any other log event is turned into a JSON object with the original content in the "message" key.
+type addFieldsProcessor struct {
+	fields map[string]string
+}
+
+func newAddFieldsProcessor(c Config) (*addFieldsProcessor, error) {
+	if len(c.Fields) == 0 {
+		return nil, errors.New("fields are required")
+	}
+	return &addFieldsProcessor{fields: c.Fields}, nil
+}
+
+func (p *addFieldsProcessor) Process(msg string) (string, bool) {
+	content, ok := parseJSONObject(msg)
+	if !ok {
+		content = map[string]interface{}{messageKey: msg}
+	}
+	for k, v := range p.fields {
+		if _, ok := content[k]; !ok {
+			content[k] = v
+		}
+	}
+	return marshalJSONObject(content, msg), true
+}
+
+// jsonProcessor renames and removes the keys of the JSON object log events.
+// The other log events are left unchanged.
+type jsonProcessor struct {
+	renameKeys map[string]string
+	removeKeys []string
+}
+
+func newJSONProcessor(c Config) (*jsonProcessor, error) {
+	if len(c.RenameKeys) == 0 && len(c.RemoveKeys) == 0 {
+		return nil, errors.New("rename_keys or remove_keys is required")
+	}
+	return &jsonProcessor{renameKeys: c.RenameKeys, removeKeys: c.RemoveKeys}, nil
+}
+
+func (p *jsonProcessor) Process(msg string) (string, bool) {
+	content, ok := parseJSONObject(msg)
+	if !ok {
+		return msg, true
+	}
+	for _, k := range p.removeKeys {
+		delete(content, k)
+	}
+	for from, to := range p.renameKeys {
+		if v, ok := content[from]; ok {
+			delete(content, from)
+			content[to] = v
+		}
+	}
+	return marshalJSONObject(content, msg), true
+}
+
+func parseJSONObject(msg string) (map[string]interface{}, bool) {
+	trimmed := strings.TrimSpace(msg)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, false
+	}
+	var content map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(trimmed))
+	// keep the numbers as they are instead of converting them to float64
+	d.UseNumber()
+	if err := d.Decode(&content); err != nil || d.More() {
+		return nil, false
+	}
+	return content, true
+}
+
+func marshalJSONObject(content map[string]interface{}, original string) string {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(content); err != nil {
+		log.Printf("E! [logprocessor] Failed to marshal log event %q: %v", original, err)
+		return original
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
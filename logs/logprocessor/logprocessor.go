@@ -0,0 +1,65 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logprocessor
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	TypeDrop      = "drop"
+	TypeMask      = "mask"
+	TypeAddFields = "add_fields"
+	TypeJSON      = "json"
+)
+
+// Config is the configuration of a single processor of a file_config processing chain.
+// Only the fields relevant to the processor type are used.
+type Config struct {
+	Type string `toml:"type"`
+
+	// drop and mask: the regex matched against the log event message
+	Pattern string `toml:"pattern"`
+	// mask: one of the predefined patterns, used when pattern is not set
+	Preset string `toml:"preset"`
+	// mask: the replacement of the matched content, it can reference the pattern groups with ${1}
+	Replacement string `toml:"replacement"`
+
+	// add_fields: the static fields added to the log event
+	Fields map[string]string `toml:"fields"`
+
+	// json: the keys to be renamed and the keys to be removed from a JSON log event
+	RenameKeys map[string]string `toml:"rename_keys"`
+	RemoveKeys []string          `toml:"remove_keys"`
+}
+
+// New creates the processors in the same order as their configs
+func New(configs []Config) ([]logs.LogProcessor, error) {
+	processors := make([]logs.LogProcessor, 0, len(configs))
+	for i, c := range configs {
+		p, err := newProcessor(c)
+		if err != nil {
+			return nil, fmt.Errorf("processor %d (%v) has issue: %v", i, c.Type, err)
+		}
+		processors = append(processors, p)
+	}
+	return processors, nil
+}
+
+func newProcessor(c Config) (logs.LogProcessor, error) {
+	switch c.Type {
+	case TypeDrop:
+		return newDropProcessor(c)
+	case TypeMask:
+		return newMaskProcessor(c)
+	case TypeAddFields:
+		return newAddFieldsProcessor(c)
+	case TypeJSON:
+		return newJSONProcessor(c)
+	default:
+		return nil, fmt.Errorf("unknown processor type %q", c.Type)
+	}
+}
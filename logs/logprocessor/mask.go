@@ -0,0 +1,97 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	PresetCreditCard   = "credit_card"
+	PresetAWSAccessKey = "aws_access_key"
+	PresetAWSSecretKey = "aws_secret_key"
+
+	defaultMaskReplacement = "****"
+)
+
+type maskPreset struct {
+	pattern string
+	// the groups kept in front of the replacement, e.g. the key name of a secret
+	prefix string
+	// extra check of the matched content to limit false positives
+	validate func(string) bool
+}
+
+var maskPresets = map[string]maskPreset{
+	// 13 to 19 digits optionally separated by spaces or dashes, which pass the Luhn check
+	PresetCreditCard:   {pattern: `\b\d(?:[ -]?\d){12,18}\b`, validate: isLuhnValid},
+	PresetAWSAccessKey: {pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	PresetAWSSecretKey: {pattern: `(?i)(aws_?secret_?access_?key|secret_?access_?key)("?\s*[:=]\s*"?)[A-Za-z0-9/+=]{40}`, prefix: "${1}${2}"},
+}
+
+// maskProcessor replaces the content matching the pattern, e.g. to hide the secrets in the log events
+type maskProcessor struct {
+	pattern     *regexp.Regexp
+	replacement string
+	validate    func(string) bool
+}
+
+func newMaskProcessor(c Config) (*maskProcessor, error) {
+	replacement := c.Replacement
+	if replacement == "" {
+		replacement = defaultMaskReplacement
+	}
+
+	p := &maskProcessor{replacement: replacement}
+	pattern := c.Pattern
+	if pattern == "" {
+		preset, ok := maskPresets[c.Preset]
+		if !ok {
+			return nil, errors.New("either pattern or a valid preset is required")
+		}
+		pattern = preset.pattern
+		p.replacement = preset.prefix + replacement
+		p.validate = preset.validate
+	}
+
+	var err error
+	if p.pattern, err = regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("pattern regex has issue, regexp: Compile( %v ): %v", pattern, err)
+	}
+	return p, nil
+}
+
+func (p *maskProcessor) Process(msg string) (string, bool) {
+	if p.validate == nil {
+		return p.pattern.ReplaceAllString(msg, p.replacement), true
+	}
+	return p.pattern.ReplaceAllStringFunc(msg, func(s string) string {
+		if !p.validate(s) {
+			return s
+		}
+		return p.pattern.ReplaceAllString(s, p.replacement)
+	}), true
+}
+
+// isLuhnValid checks the digits of s with the Luhn algorithm used by the credit card numbers
+func isLuhnValid(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
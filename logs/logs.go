@@ -9,6 +9,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/health"
+	"github.com/hashicorp/golang-lru/simplelru"
 	"github.com/influxdata/telegraf/config"
 )
 
@@ -25,6 +27,13 @@ type LogEvent interface {
 	Done()
 }
 
+// A StructuredLogEvent is a LogEvent whose message was parsed into fields, e.g. by the parser of a log file.
+// Fields returns nil when the message could not be parsed.
+type StructuredLogEvent interface {
+	LogEvent
+	Fields() map[string]interface{}
+}
+
 // A LogSrc is a single source where log events are generated
 // e.g. a single log file
 type LogSrc interface {
@@ -37,31 +46,72 @@ type LogSrc interface {
 	Stop()
 }
 
+// A LogProcessor modifies a log event message before it is published.
+// It returns false when the log event should be dropped.
+type LogProcessor interface {
+	Process(msg string) (string, bool)
+}
+
+// A StructuredLogProcessor is a LogProcessor which can also use the fields of a StructuredLogEvent,
+// e.g. to extract a metric from a field of a log event published as it was read.
+type StructuredLogProcessor interface {
+	LogProcessor
+	ProcessFields(msg string, fields map[string]interface{}) (string, bool)
+}
+
+// A ProcessedLogSrc is a LogSrc whose log events go through a chain of LogProcessor
+// before they are published to the LogDest.
+type ProcessedLogSrc interface {
+	LogSrc
+	Processors() []LogProcessor
+}
+
+// A RoutedLogSrc is a LogSrc whose log events are published to the log group and stream
+// returned by Route, e.g. when their names are derived from the content of the log events.
+// Route(nil) returns the fallback log group and stream, used once the src is routed to too many of them.
+type RoutedLogSrc interface {
+	LogSrc
+	Route(e LogEvent) (group, stream string)
+}
+
 // A LogBackend is able to return a LogDest of a given name.
 // The same name should always return the same LogDest.
 type LogBackend interface {
 	CreateDest(string, string, int) LogDest
 }
 
+// A LogDestReleaser is a LogBackend which can release the LogDest returned by CreateDest once it is no longer
+// used, e.g. when a RoutedLogSrc stops routing log events to it. Each CreateDest should be matched by a ReleaseDest.
+type LogDestReleaser interface {
+	LogBackend
+	ReleaseDest(LogDest)
+}
+
 // A LogDest represents a final endpoint where log events are published to.
 // e.g. a particualr log stream in cloudwatchlogs.
 type LogDest interface {
 	Publish(events []LogEvent) error
 }
 
+var (
+	// the number of log groups and streams a RoutedLogSrc is routed to at once, the log events of the other ones
+	// are published to the fallback log group and stream of the src
+	maxRoutedDests = 1000
+	// the dest of a RoutedLogSrc which did not get a log event for this long can be released for a new one
+	routedDestIdleTimeout = time.Hour
+)
+
 // LogAgent is the agent handles pure log pipelines
 type LogAgent struct {
 	Config      *config.Config
 	backends    map[string]LogBackend
-	destNames   map[LogDest]string
 	collections []LogCollection
 }
 
 func NewLogAgent(c *config.Config) *LogAgent {
 	return &LogAgent{
-		Config:    c,
-		backends:  make(map[string]LogBackend),
-		destNames: make(map[LogDest]string),
+		Config:   c,
+		backends: make(map[string]LogBackend),
 	}
 }
 
@@ -104,10 +154,14 @@ func (l *LogAgent) Run(ctx context.Context) {
 						log.Printf("E! [logagent] Failed to find destination %v for log source %v/%v(%v) ", dname, src.Group(), src.Stream(), src.Description())
 						continue
 					}
+					if routed, ok := src.(RoutedLogSrc); ok {
+						log.Printf("I! [logagent] piping log from %v/%v(%v) to the routed log groups and streams of %v with retention %v", src.Group(), src.Stream(), src.Description(), dname, src.Retention())
+						go l.runSrcToRoutedDests(routed, backend, dname)
+						continue
+					}
 					dest := backend.CreateDest(src.Group(), src.Stream(), src.Retention())
-					l.destNames[dest] = dname
 					log.Printf("I! [logagent] piping log from %v/%v(%v) to %v with retention %v", src.Group(), src.Stream(), src.Description(), dname, src.Retention())
-					go l.runSrcToDest(src, dest)
+					go l.runSrcToDest(src, dest, dname)
 				}
 			}
 		case <-ctx.Done():
@@ -116,9 +170,69 @@ func (l *LogAgent) Run(ctx context.Context) {
 	}
 }
 
-func (l *LogAgent) runSrcToDest(src LogSrc, dest LogDest) {
+func (l *LogAgent) runSrcToDest(src LogSrc, dest LogDest, dname string) {
+	l.pipe(src, dname, func(LogEvent) LogDest { return dest })
+}
+
+// runSrcToRoutedDests publishes each log event to the dest of the log group and stream it is routed to.
+// The dests are capped to maxRoutedDests, the least recently used one is released for a new log group and stream
+// once it is idle, otherwise the log events are published to the fallback log group and stream of the src.
+func (l *LogAgent) runSrcToRoutedDests(src RoutedLogSrc, backend LogBackend, dname string) {
+	type target struct{ group, stream string }
+	type routedDest struct {
+		dest     LogDest
+		lastUsed time.Time
+	}
+	// the dests are only released once idle since the log events still queued by a dest are dropped, the other
+	// dests are kept until the backend closes like the dests of the other srcs
+	dests, _ := simplelru.NewLRU(maxRoutedDests, func(key interface{}, value interface{}) {
+		t := key.(target)
+		log.Printf("I! [logagent] releasing the idle log group and stream %v/%v of %v(%v)", t.group, t.stream, src.Group(), src.Description())
+		if r, ok := backend.(LogDestReleaser); ok {
+			r.ReleaseDest(value.(*routedDest).dest)
+		}
+	})
+	var fallback LogDest
+
+	l.pipe(src, dname, func(e LogEvent) LogDest {
+		group, stream := src.Route(e)
+		t := target{group, stream}
+		now := time.Now()
+		if v, ok := dests.Get(t); ok {
+			d := v.(*routedDest)
+			d.lastUsed = now
+			return d.dest
+		}
+		if dests.Len() >= maxRoutedDests {
+			if _, v, _ := dests.GetOldest(); now.Sub(v.(*routedDest).lastUsed) < routedDestIdleTimeout {
+				if fallback == nil {
+					group, stream = src.Route(nil)
+					log.Printf("W! [logagent] log from %v(%v) is routed to more than %v log groups and streams, publishing the log events of the new ones to %v/%v of %v", src.Group(), src.Description(), maxRoutedDests, group, stream, dname)
+					fallback = backend.CreateDest(group, stream, src.Retention())
+				}
+				return fallback
+			}
+			dests.RemoveOldest()
+		}
+		dest := backend.CreateDest(group, stream, src.Retention())
+		dests.Add(t, &routedDest{dest: dest, lastUsed: now})
+		log.Printf("I! [logagent] routing log from %v(%v) to %v/%v of %v", src.Group(), src.Description(), group, stream, dname)
+		return dest
+	})
+}
+
+// pipe publishes the log events of the src to the dest returned by destOf until the src or the dest stops,
+// dname is the name of the backend of the dests
+func (l *LogAgent) pipe(src LogSrc, dname string, destOf func(LogEvent) LogDest) {
 	eventsCh := make(chan LogEvent)
 	defer src.Stop()
+	// report the source on the health endpoint while it is piped
+	defer health.Status.AddLogSrc(health.LogSrc{
+		Group:       src.Group(),
+		Stream:      src.Stream(),
+		Destination: src.Destination(),
+		Description: src.Description(),
+	})()
 
 	src.SetOutput(func(e LogEvent) {
 		if e == nil {
@@ -129,15 +243,71 @@ func (l *LogAgent) runSrcToDest(src LogSrc, dest LogDest) {
 		eventsCh <- e
 	})
 
+	var processors []LogProcessor
+	if ps, ok := src.(ProcessedLogSrc); ok {
+		processors = ps.Processors()
+	}
+
 	for e := range eventsCh {
+		if len(processors) > 0 {
+			if e = processLogEvent(e, processors); e == nil {
+				continue
+			}
+		}
+		dest := destOf(e)
 		err := dest.Publish([]LogEvent{e})
 		if err == ErrOutputStopped {
-			log.Printf("I! [logagent] Log destination %v has stopped, finalizing %v/%v", l.destNames[dest], src.Group(), src.Stream())
+			log.Printf("I! [logagent] Log destination %v has stopped, finalizing %v/%v", dname, src.Group(), src.Stream())
 			return
 		}
 		if err != nil {
-			log.Printf("E! [logagent] Failed to publish log to %v, error: %v", l.destNames[dest], err)
+			log.Printf("E! [logagent] Failed to publish log to %v, error: %v", dname, err)
 			return
 		}
 	}
 }
+
+// processedLogEvent is a LogEvent whose message has been changed by the processors
+type processedLogEvent struct {
+	LogEvent
+	msg string
+}
+
+func (e *processedLogEvent) Message() string {
+	return e.msg
+}
+
+// Fields returns the fields parsed from the message before it was processed
+func (e *processedLogEvent) Fields() map[string]interface{} {
+	if se, ok := e.LogEvent.(StructuredLogEvent); ok {
+		return se.Fields()
+	}
+	return nil
+}
+
+// processLogEvent runs the processors on the log event, it returns nil when the log event is dropped.
+// A dropped log event is marked as done so its source can move on.
+func processLogEvent(e LogEvent, processors []LogProcessor) LogEvent {
+	original := e.Message()
+	msg := original
+	var fields map[string]interface{}
+	if se, ok := e.(StructuredLogEvent); ok {
+		fields = se.Fields()
+	}
+	for _, p := range processors {
+		var ok bool
+		if sp, isStructured := p.(StructuredLogProcessor); isStructured && fields != nil {
+			msg, ok = sp.ProcessFields(msg, fields)
+		} else {
+			msg, ok = p.Process(msg)
+		}
+		if !ok {
+			e.Done()
+			return nil
+		}
+	}
+	if msg == original {
+		return e
+	}
+	return &processedLogEvent{LogEvent: e, msg: msg}
+}
@@ -0,0 +1,209 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent struct {
+	msg  string
+	done *bool
+}
+
+func (e testEvent) Message() string { return e.msg }
+func (e testEvent) Time() time.Time { return time.Time{} }
+func (e testEvent) Done()           { *e.done = true }
+
+type processorFunc func(string) (string, bool)
+
+func (f processorFunc) Process(msg string) (string, bool) { return f(msg) }
+
+func TestProcessLogEvent(t *testing.T) {
+	processors := []LogProcessor{
+		processorFunc(func(msg string) (string, bool) { return msg, !strings.Contains(msg, "DEBUG") }),
+		processorFunc(func(msg string) (string, bool) { return strings.Replace(msg, "secret", "***", -1), true }),
+	}
+
+	var done bool
+	e := testEvent{msg: "ERROR secret leaked", done: &done}
+	processed := processLogEvent(e, processors)
+	assert.Equal(t, "ERROR *** leaked", processed.Message())
+	assert.False(t, done)
+	processed.Done()
+	assert.True(t, done, "Done should be passed to the original event")
+
+	e = testEvent{msg: "INFO nothing to change", done: &done}
+	assert.Equal(t, e, processLogEvent(e, processors))
+
+	done = false
+	e = testEvent{msg: "DEBUG dropped", done: &done}
+	assert.Nil(t, processLogEvent(e, processors))
+	assert.True(t, done, "Dropped events should be marked as done")
+}
+
+type testStructuredEvent struct {
+	testEvent
+	fields map[string]interface{}
+}
+
+func (e testStructuredEvent) Fields() map[string]interface{} { return e.fields }
+
+type structuredProcessorFunc func(string, map[string]interface{}) (string, bool)
+
+func (f structuredProcessorFunc) Process(msg string) (string, bool) { return f(msg, nil) }
+func (f structuredProcessorFunc) ProcessFields(msg string, fields map[string]interface{}) (string, bool) {
+	return f(msg, fields)
+}
+
+func TestProcessStructuredLogEvent(t *testing.T) {
+	var received []map[string]interface{}
+	processors := []LogProcessor{
+		structuredProcessorFunc(func(msg string, fields map[string]interface{}) (string, bool) {
+			received = append(received, fields)
+			return msg, fields == nil || fields["level"] != "DEBUG"
+		}),
+	}
+
+	var done bool
+	fields := map[string]interface{}{"level": "ERROR"}
+	e := testStructuredEvent{testEvent{msg: "level=ERROR", done: &done}, fields}
+	assert.Equal(t, e, processLogEvent(e, processors))
+
+	e = testStructuredEvent{testEvent{msg: "level=DEBUG", done: &done}, map[string]interface{}{"level": "DEBUG"}}
+	assert.Nil(t, processLogEvent(e, processors))
+	assert.True(t, done, "Dropped events should be marked as done")
+
+	// the log events which could not be parsed are processed as messages
+	processLogEvent(testStructuredEvent{testEvent{msg: "unparsed", done: &done}, nil}, processors)
+	processLogEvent(testEvent{msg: "plain", done: &done}, processors)
+	assert.Equal(t, []map[string]interface{}{fields, {"level": "DEBUG"}, nil, nil}, received)
+
+	// the fields of the processed log events are still available
+	processors = append(processors, processorFunc(func(msg string) (string, bool) { return "changed", true }))
+	processed := processLogEvent(testStructuredEvent{testEvent{msg: "level=ERROR", done: &done}, fields}, processors)
+	se, ok := processed.(StructuredLogEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "changed", se.Message())
+	assert.Equal(t, fields, se.Fields())
+}
+
+type testRoutedSrc struct {
+	outputs chan func(LogEvent)
+	stopped bool
+}
+
+func (s *testRoutedSrc) SetOutput(f func(LogEvent)) { s.outputs <- f }
+func (s *testRoutedSrc) Group() string              { return "{json:$.group}" }
+func (s *testRoutedSrc) Stream() string             { return "stream" }
+func (s *testRoutedSrc) Destination() string        { return "test" }
+func (s *testRoutedSrc) Description() string        { return "routed" }
+func (s *testRoutedSrc) Retention() int             { return 7 }
+func (s *testRoutedSrc) Stop()                      { s.stopped = true }
+func (s *testRoutedSrc) Route(e LogEvent) (string, string) {
+	if e == nil {
+		return "fallback", "stream"
+	}
+	return strings.SplitN(e.Message(), " ", 2)[0], "stream"
+}
+
+type testDest struct {
+	events []string
+}
+
+func (d *testDest) Publish(events []LogEvent) error {
+	for _, e := range events {
+		d.events = append(d.events, e.Message())
+	}
+	return nil
+}
+
+type testBackend struct {
+	dests      map[string]*testDest
+	retentions []int
+	released   []*testDest
+}
+
+func (b *testBackend) CreateDest(group, stream string, retention int) LogDest {
+	b.retentions = append(b.retentions, retention)
+	d, ok := b.dests[group+"/"+stream]
+	if !ok {
+		d = &testDest{}
+		b.dests[group+"/"+stream] = d
+	}
+	return d
+}
+
+func (b *testBackend) ReleaseDest(d LogDest) {
+	b.released = append(b.released, d.(*testDest))
+}
+
+func TestRunSrcToRoutedDests(t *testing.T) {
+	l := NewLogAgent(nil)
+	backend := &testBackend{dests: map[string]*testDest{}}
+	src := &testRoutedSrc{outputs: make(chan func(LogEvent))}
+
+	finished := make(chan struct{})
+	go func() {
+		l.runSrcToRoutedDests(src, backend, "test")
+		close(finished)
+	}()
+
+	output := <-src.outputs
+	var done bool
+	output(testEvent{msg: "payments charged", done: &done})
+	output(testEvent{msg: "orders created", done: &done})
+	output(testEvent{msg: "payments refunded", done: &done})
+	output(nil)
+	<-finished
+
+	assert.True(t, src.stopped)
+	assert.Len(t, backend.dests, 2)
+	assert.Equal(t, []string{"payments charged", "payments refunded"}, backend.dests["payments/stream"].events)
+	assert.Equal(t, []string{"orders created"}, backend.dests["orders/stream"].events)
+	// the dests are created once per log group and stream, with the retention of the src
+	assert.Equal(t, []int{7, 7}, backend.retentions)
+	assert.Empty(t, backend.released)
+}
+
+func TestRunSrcToRoutedDests_MaxRoutedDests(t *testing.T) {
+	defer func(max int, idle time.Duration) {
+		maxRoutedDests, routedDestIdleTimeout = max, idle
+	}(maxRoutedDests, routedDestIdleTimeout)
+	maxRoutedDests = 2
+	routedDestIdleTimeout = time.Hour
+
+	l := NewLogAgent(nil)
+	backend := &testBackend{dests: map[string]*testDest{}}
+	src := &testRoutedSrc{outputs: make(chan func(LogEvent))}
+	finished := make(chan struct{})
+	go func() {
+		l.runSrcToRoutedDests(src, backend, "test")
+		close(finished)
+	}()
+
+	output := <-src.outputs
+	var done bool
+	output(testEvent{msg: "a 1", done: &done})
+	output(testEvent{msg: "b 1", done: &done})
+	// the dests are all in use, the new log groups go to the fallback one
+	output(testEvent{msg: "c 1", done: &done})
+	output(testEvent{msg: "d 1", done: &done})
+	output(testEvent{msg: "a 2", done: &done})
+	// the least recently used dest is released once idle
+	routedDestIdleTimeout = 0
+	output(testEvent{msg: "e 1", done: &done})
+	output(nil)
+	<-finished
+
+	assert.Equal(t, []string{"a 1", "a 2"}, backend.dests["a/stream"].events)
+	assert.Equal(t, []string{"c 1", "d 1"}, backend.dests["fallback/stream"].events)
+	assert.Equal(t, []string{"e 1"}, backend.dests["e/stream"].events)
+	assert.Equal(t, []*testDest{backend.dests["b/stream"]}, backend.released)
+	assert.Nil(t, backend.dests["c/stream"])
+}
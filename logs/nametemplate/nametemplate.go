@@ -0,0 +1,169 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package nametemplate resolves the placeholders of the log group and stream names, e.g. "/apps/{json:$.service}".
+// A placeholder is written {kind:key}, or {kind:key|default} with the value used when it cannot be resolved.
+package nametemplate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/parser"
+)
+
+const (
+	// KindJSON {json:$.service.name} is the value at the JSON path of the log event, or of the fields of the
+	// parsed log event
+	KindJSON = "json"
+
+	// the value of a placeholder which cannot be resolved and does not have a default, e.g. {json:$.service|default}
+	unresolvedValue = "unknown"
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{([a-z0-9_]+):([^{}|]+)(?:\|([^{}]*))?\}`)
+
+	// the characters not allowed in the names, they are replaced by _ in the resolved values
+	InvalidLogGroupNameChars  = regexp.MustCompile(`[^\.\-_/#A-Za-z0-9]`)
+	InvalidLogStreamNameChars = regexp.MustCompile(`[:*]`)
+)
+
+// Resolver returns the value of the placeholder {kind:key}, false when it cannot be resolved
+type Resolver func(kind, key string) (string, bool)
+
+type segment struct {
+	literal      string
+	kind, key    string
+	defaultValue string
+}
+
+// Template is a log group or stream name with placeholders
+type Template struct {
+	segments []segment
+	invalid  *regexp.Regexp
+}
+
+// New returns the template of the name whose placeholders are of the kinds, invalid matches the characters not
+// allowed in the name
+func New(name string, invalid *regexp.Regexp, kinds ...string) (*Template, error) {
+	t := &Template{invalid: invalid}
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(name, -1) {
+		s := segment{kind: name[m[2]:m[3]], key: strings.TrimSpace(name[m[4]:m[5]]), defaultValue: unresolvedValue}
+		if !containsString(kinds, s.kind) {
+			return nil, fmt.Errorf("unknown placeholder %v, its kind must be one of %v", name[m[0]:m[1]], strings.Join(kinds, ", "))
+		}
+		if m[6] >= 0 {
+			s.defaultValue = name[m[6]:m[7]]
+		}
+		if m[0] > last {
+			t.segments = append(t.segments, segment{literal: name[last:m[0]]})
+		}
+		t.segments = append(t.segments, s)
+		last = m[1]
+	}
+	if last < len(name) {
+		t.segments = append(t.segments, segment{literal: name[last:]})
+	}
+	return t, nil
+}
+
+// Keys returns the keys of the placeholders of the kind
+func (t *Template) Keys(kind string) []string {
+	var keys []string
+	for _, s := range t.segments {
+		if s.kind == kind {
+			keys = append(keys, s.key)
+		}
+	}
+	return keys
+}
+
+// IsStatic returns whether the name does not have placeholders
+func (t *Template) IsStatic() bool {
+	for _, s := range t.segments {
+		if s.kind != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveKind returns the template whose placeholders of the kind are resolved, e.g. from the file name
+func (t *Template) ResolveKind(kind string, r Resolver) *Template {
+	resolved := &Template{invalid: t.invalid}
+	for _, s := range t.segments {
+		if s.kind == kind {
+			value, _ := r(s.kind, s.key)
+			s = segment{literal: t.sanitize(value, s.defaultValue)}
+		}
+		resolved.segments = append(resolved.segments, s)
+	}
+	return resolved
+}
+
+// Resolve returns the name, the placeholders which cannot be resolved are replaced by their default
+func (t *Template) Resolve(r Resolver) string {
+	var b strings.Builder
+	for _, s := range t.segments {
+		if s.kind == "" {
+			b.WriteString(s.literal)
+			continue
+		}
+		value, _ := r(s.kind, s.key)
+		b.WriteString(t.sanitize(value, s.defaultValue))
+	}
+	return b.String()
+}
+
+// String returns the name with its placeholders, e.g. to describe the log src
+func (t *Template) String() string {
+	var b strings.Builder
+	for _, s := range t.segments {
+		if s.kind == "" {
+			b.WriteString(s.literal)
+			continue
+		}
+		b.WriteString("{" + s.kind + ":" + s.key + "}")
+	}
+	return b.String()
+}
+
+// sanitize replaces the characters not allowed in the name, an empty value is replaced by the default
+func (t *Template) sanitize(value, defaultValue string) string {
+	if value == "" {
+		value = defaultValue
+	}
+	return t.invalid.ReplaceAllString(value, "_")
+}
+
+// EventResolver resolves the json placeholders from the log event, they are replaced by their default for a nil
+// log event, e.g. for the fallback log group and stream of a RoutedLogSrc
+func EventResolver(e logs.LogEvent) Resolver {
+	if e == nil {
+		return func(string, string) (string, bool) { return "", false }
+	}
+	var fields map[string]interface{}
+	if se, ok := e.(logs.StructuredLogEvent); ok {
+		fields = se.Fields()
+	}
+	doc := parser.NewDocument(e.Message(), fields)
+	return func(kind, key string) (string, bool) {
+		if kind != KindJSON {
+			return "", false
+		}
+		return doc.Lookup(key)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
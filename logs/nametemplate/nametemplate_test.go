@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package nametemplate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+func (e *testEvent) Message() string                { return e.msg }
+func (e *testEvent) Time() time.Time                { return time.Time{} }
+func (e *testEvent) Done()                          {}
+func (e *testEvent) Fields() map[string]interface{} { return e.fields }
+
+func TestTemplate_JSON(t *testing.T) {
+	tmpl, err := New("{json:$.service.name|default}-{json:$.env}", InvalidLogStreamNameChars, KindJSON)
+	require.NoError(t, err)
+	assert.False(t, tmpl.IsStatic())
+	assert.Equal(t, []string{"$.service.name", "$.env"}, tmpl.Keys(KindJSON))
+	assert.Equal(t, "{json:$.service.name}-{json:$.env}", tmpl.String())
+
+	assert.Equal(t, "payments-prod", tmpl.Resolve(EventResolver(&testEvent{msg: `{"service":{"name":"payments"},"env":"prod"}`})))
+	assert.Equal(t, "default-unknown", tmpl.Resolve(EventResolver(&testEvent{msg: `{"env":""}`})))
+	assert.Equal(t, "default-unknown", tmpl.Resolve(EventResolver(&testEvent{msg: "not json"})))
+	assert.Equal(t, "default-unknown", tmpl.Resolve(EventResolver(nil)))
+	// the characters not allowed in the log stream names are replaced
+	assert.Equal(t, "a_b_c-1", tmpl.Resolve(EventResolver(&testEvent{msg: `{"service":{"name":"a:b*c"},"env":1}`})))
+	// the fields of the parsed log events are used when the message is not JSON
+	assert.Equal(t, "api-dev", tmpl.Resolve(EventResolver(&testEvent{msg: "api dev", fields: map[string]interface{}{"service": map[string]interface{}{"name": "api"}, "env": "dev"}})))
+}
+
+func TestTemplate_ResolveKind(t *testing.T) {
+	tmpl, err := New("/apps/{path:app}/{json:$.env}", InvalidLogGroupNameChars, "path", KindJSON)
+	require.NoError(t, err)
+
+	resolved := tmpl.ResolveKind("path", func(kind, key string) (string, bool) { return "my app", true })
+	assert.False(t, resolved.IsStatic())
+	assert.Equal(t, "/apps/my_app/{json:$.env}", resolved.String())
+	assert.Equal(t, "/apps/my_app/prod", resolved.Resolve(EventResolver(&testEvent{msg: `{"env":"prod"}`})))
+}
+
+func TestTemplate_Static(t *testing.T) {
+	tmpl, err := New("/aws/{instance_id}/group", InvalidLogGroupNameChars, KindJSON)
+	require.NoError(t, err)
+	assert.True(t, tmpl.IsStatic())
+	assert.Equal(t, "/aws/{instance_id}/group", tmpl.String())
+	assert.Equal(t, "/aws/{instance_id}/group", tmpl.Resolve(EventResolver(nil)))
+
+	tmpl, err = New("", InvalidLogGroupNameChars, KindJSON)
+	require.NoError(t, err)
+	assert.Equal(t, "", tmpl.String())
+}
+
+func TestTemplate_UnknownKind(t *testing.T) {
+	_, err := New("/apps/{ec2_tag:Name}", InvalidLogGroupNameChars, KindJSON)
+	assert.Error(t, err)
+}
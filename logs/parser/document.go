@@ -0,0 +1,65 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package parser
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JSONPathPrefix starts the JSON paths into the log events, e.g. $.request.latency
+const JSONPathPrefix = "$."
+
+// Document looks up the JSON paths of a log event, the message is decoded once for all the lookups. The fields of
+// the parsed log event are used when the message is not JSON.
+type Document struct {
+	msg     string
+	fields  map[string]interface{}
+	doc     interface{}
+	decoded bool
+}
+
+func NewDocument(msg string, fields map[string]interface{}) *Document {
+	return &Document{msg: msg, fields: fields}
+}
+
+// Lookup returns the scalar value at the JSON path, with or without the JSON path prefix
+func (d *Document) Lookup(path string) (string, bool) {
+	if !d.decoded {
+		d.decoded = true
+		dec := json.NewDecoder(strings.NewReader(d.msg))
+		dec.UseNumber()
+		if err := dec.Decode(&d.doc); err != nil {
+			d.doc = nil
+			if d.fields != nil {
+				d.doc = d.fields
+			}
+		}
+	}
+	return lookupJSONPath(d.doc, strings.TrimPrefix(path, JSONPathPrefix))
+}
+
+// lookupJSONPath returns the scalar value at the dotted path of the decoded JSON document
+func lookupJSONPath(doc interface{}, path string) (string, bool) {
+	for _, k := range strings.Split(path, ".") {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if doc, ok = obj[k]; !ok {
+			return "", false
+		}
+	}
+	switch v := doc.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
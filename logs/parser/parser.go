@@ -0,0 +1,362 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package parser parses the log events into fields, e.g. the log events of the files tailed by the logfile input
+// or the messages received by the syslog input.
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	JSON          = "json"
+	Logfmt        = "logfmt"
+	Regex         = "regex"
+	SyslogRFC3164 = "syslog_rfc3164"
+	SyslogRFC5424 = "syslog_rfc5424"
+	// CLF parses the Common Log Format, and the combined format of the nginx and Apache access logs
+	CLF = "clf"
+
+	// OutputRaw publishes the log events as they are read
+	OutputRaw = "raw"
+	// OutputJSON publishes the fields of the parsed log events as JSON, e.g. for CloudWatch Logs Insights
+	OutputJSON = "json"
+)
+
+// Config parses the log events into fields, the fields of a log event which is not published as JSON are still
+// used by the metric extractors and the log group and stream name templates
+type Config struct {
+	Type string `toml:"type"`
+	// regex: the pattern whose named groups are the fields of the log events
+	Pattern string `toml:"pattern"`
+	// The field of the log event timestamp, the timestamp_regex is used when it is not found
+	TimestampField string `toml:"timestamp_field"`
+	// The layout of the timestamp field, RFC3339 and the Unix epoch in seconds or milliseconds are parsed without it
+	TimestampLayout string `toml:"timestamp_layout"`
+	// raw (default) or json
+	OutputFormat string `toml:"output_format"`
+}
+
+var (
+	// <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG, the PRI is usually not written to the files
+	syslogRFC3164Pattern = regexp.MustCompile(`(?s)^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^\s:\[]+)(?:\[([^\]]*)\])?:? ?(.*)$`)
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	syslogRFC5424Pattern = regexp.MustCompile(`(?s)^(?:<(\d{1,3})>)?(\d{1,2}) (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]\\"]|\\.|"(?:[^"\\]|\\.)*")*\])+)(?: (.*))?$`)
+	sdElementPattern     = regexp.MustCompile(`\[([^\s\]]+)((?: [^\s=\]]+="(?:[^"\\]|\\.)*")*)\]`)
+	sdParamPattern       = regexp.MustCompile(`([^\s=\]]+)="((?:[^"\\]|\\.)*)"`)
+	sdEscapePattern      = regexp.MustCompile(`\\(["\\\]])`)
+	// host ident user [time] "request" status bytes, followed by "referer" "user agent" in the combined format
+	clfPattern = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+)
+
+// the default timestamp field and layout of the parsers of the standard formats
+var parserTimestamps = map[string]struct{ field, layout string }{
+	SyslogRFC3164: {"timestamp", time.Stamp},
+	SyslogRFC5424: {"timestamp", time.RFC3339Nano},
+	CLF:           {"time_local", "02/Jan/2006:15:04:05 -0700"},
+}
+
+// Parser parses the log events of a Config, it can be shared by the log srcs
+type Parser struct {
+	config      Config
+	parseFields func(string) (map[string]interface{}, bool)
+	location    *time.Location
+}
+
+func New(config Config, location *time.Location) (*Parser, error) {
+	p := &Parser{config: config, location: location}
+	switch config.Type {
+	case JSON:
+		p.parseFields = parseJSON
+	case Logfmt:
+		p.parseFields = parseLogfmt
+	case Regex:
+		if config.Pattern == "" {
+			return nil, fmt.Errorf("pattern is required for parser %v", config.Type)
+		}
+		pattern, err := regexp.Compile(config.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern has issue, regexp: Compile( %v ): %v", config.Pattern, err)
+		}
+		named := false
+		for _, name := range pattern.SubexpNames() {
+			named = named || name != ""
+		}
+		if !named {
+			return nil, fmt.Errorf("pattern %v has no named group", config.Pattern)
+		}
+		p.parseFields = func(msg string) (map[string]interface{}, bool) {
+			return parseRegex(pattern, msg)
+		}
+	case SyslogRFC3164:
+		p.parseFields = parseSyslogRFC3164
+	case SyslogRFC5424:
+		p.parseFields = parseSyslogRFC5424
+	case CLF:
+		p.parseFields = parseCLF
+	default:
+		return nil, fmt.Errorf("unknown parser type %q", config.Type)
+	}
+
+	switch config.OutputFormat {
+	case "", OutputRaw, OutputJSON:
+	default:
+		return nil, fmt.Errorf("output_format %v is not supported", config.OutputFormat)
+	}
+	if defaults, ok := parserTimestamps[config.Type]; ok && p.config.TimestampField == "" {
+		p.config.TimestampField = defaults.field
+		if p.config.TimestampLayout == "" {
+			p.config.TimestampLayout = defaults.layout
+		}
+	}
+	return p, nil
+}
+
+// Parse returns the fields of the log event, nil if it cannot be parsed, the timestamp found in its fields and
+// the message to publish
+func (p *Parser) Parse(msg string) (map[string]interface{}, time.Time, string) {
+	fields, ok := p.parseFields(msg)
+	if !ok {
+		return nil, time.Time{}, msg
+	}
+	var timestamp time.Time
+	if p.config.TimestampField != "" {
+		timestamp = p.timestamp(fields[p.config.TimestampField])
+	}
+	if p.config.OutputFormat == OutputJSON {
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(fields); err == nil {
+			msg = strings.TrimSuffix(b.String(), "\n")
+		}
+	}
+	return fields, timestamp, msg
+}
+
+func (p *Parser) timestamp(value interface{}) time.Time {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	default:
+		return time.Time{}
+	}
+	if p.config.TimestampLayout != "" {
+		timestamp, err := time.ParseInLocation(p.config.TimestampLayout, s, p.location)
+		if err != nil {
+			return time.Time{}
+		}
+		return WithCurrentYear(timestamp)
+	}
+	if timestamp, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return timestamp
+	}
+	if epoch, err := strconv.ParseFloat(s, 64); err == nil && epoch > 0 {
+		// the Unix epoch in milliseconds, seconds would be after the year 5000
+		if epoch > 1e11 {
+			return time.Unix(0, int64(epoch*float64(time.Millisecond)))
+		}
+		return time.Unix(0, int64(epoch*float64(time.Second)))
+	}
+	return time.Time{}
+}
+
+// WithCurrentYear sets the timestamp parsed with a layout without year, e.g. the syslog one, in the current year
+func WithCurrentYear(timestamp time.Time) time.Time {
+	if timestamp.Year() == 0 {
+		now := time.Now()
+		timestamp = timestamp.AddDate(now.Year(), 0, 0)
+		// If now is very early January and we are pushing logs from very late
+		// December, there will be a very large number of hours different
+		// between the dates. 30 * 24 hours will be sufficient.
+		if timestamp.Sub(now) > 30*24*time.Hour {
+			timestamp = timestamp.AddDate(-1, 0, 0)
+		}
+	}
+	return timestamp
+}
+
+func parseJSON(msg string) (map[string]interface{}, bool) {
+	var fields map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(msg))
+	d.UseNumber()
+	if err := d.Decode(&fields); err != nil || fields == nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// parseLogfmt parses the key=value pairs separated by spaces, the values can be double quoted
+func parseLogfmt(msg string) (map[string]interface{}, bool) {
+	fields := make(map[string]interface{})
+	pairs := 0
+	s := strings.TrimLeft(msg, " \t")
+	for s != "" {
+		i := strings.IndexAny(s, "= \t")
+		if i == 0 {
+			return nil, false
+		}
+		if i < 0 || s[i] != '=' {
+			// a key without value
+			if i < 0 {
+				i = len(s)
+			}
+			fields[s[:i]] = ""
+			s = strings.TrimLeft(s[i:], " \t")
+			continue
+		}
+		key := s[:i]
+		s = s[i+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := quotedEnd(s)
+			if end < 0 {
+				return nil, false
+			}
+			var err error
+			if value, err = strconv.Unquote(s[:end]); err != nil {
+				return nil, false
+			}
+			s = s[end:]
+			if s != "" && s[0] != ' ' && s[0] != '\t' {
+				return nil, false
+			}
+		} else {
+			end := strings.IndexAny(s, " \t")
+			if end < 0 {
+				end = len(s)
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+		fields[key] = value
+		pairs++
+		s = strings.TrimLeft(s, " \t")
+	}
+	return fields, pairs > 0
+}
+
+// quotedEnd returns the index following the closing double quote of the string starting with a double quote
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func parseRegex(pattern *regexp.Regexp, msg string) (map[string]interface{}, bool) {
+	groups := pattern.FindStringSubmatch(msg)
+	if groups == nil {
+		return nil, false
+	}
+	fields := make(map[string]interface{})
+	for i, name := range pattern.SubexpNames() {
+		if name != "" && groups[i] != "" {
+			fields[name] = groups[i]
+		}
+	}
+	return fields, true
+}
+
+func parseSyslogRFC3164(msg string) (map[string]interface{}, bool) {
+	groups := syslogRFC3164Pattern.FindStringSubmatch(msg)
+	if groups == nil {
+		return nil, false
+	}
+	fields := make(map[string]interface{})
+	addPriority(fields, groups[1])
+	addField(fields, "timestamp", groups[2])
+	addField(fields, "hostname", groups[3])
+	addField(fields, "appname", groups[4])
+	addField(fields, "procid", groups[5])
+	addField(fields, "message", groups[6])
+	return fields, true
+}
+
+func parseSyslogRFC5424(msg string) (map[string]interface{}, bool) {
+	groups := syslogRFC5424Pattern.FindStringSubmatch(msg)
+	if groups == nil {
+		return nil, false
+	}
+	fields := map[string]interface{}{"version": json.Number(groups[2])}
+	addPriority(fields, groups[1])
+	addField(fields, "timestamp", groups[3])
+	addField(fields, "hostname", groups[4])
+	addField(fields, "appname", groups[5])
+	addField(fields, "procid", groups[6])
+	addField(fields, "msgid", groups[7])
+	if groups[8] != "-" {
+		// [id param="value"...] -> {"id": {"param": "value"}}
+		sd := make(map[string]interface{})
+		for _, element := range sdElementPattern.FindAllStringSubmatch(groups[8], -1) {
+			params := make(map[string]interface{})
+			for _, param := range sdParamPattern.FindAllStringSubmatch(element[2], -1) {
+				params[param[1]] = sdEscapePattern.ReplaceAllString(param[2], "$1")
+			}
+			sd[element[1]] = params
+		}
+		fields["structured_data"] = sd
+	}
+	// the message may start with a UTF-8 BOM
+	addField(fields, "message", strings.TrimPrefix(groups[9], "\ufeff"))
+	return fields, true
+}
+
+func parseCLF(msg string) (map[string]interface{}, bool) {
+	groups := clfPattern.FindStringSubmatch(msg)
+	if groups == nil {
+		return nil, false
+	}
+	fields := make(map[string]interface{})
+	addField(fields, "remote_addr", groups[1])
+	addField(fields, "ident", groups[2])
+	addField(fields, "remote_user", groups[3])
+	addField(fields, "time_local", groups[4])
+	addField(fields, "request", groups[5])
+	if request := strings.Fields(groups[5]); len(request) == 3 {
+		fields["method"], fields["path"], fields["protocol"] = request[0], request[1], request[2]
+	}
+	fields["status"] = json.Number(groups[6])
+	if groups[7] != "-" {
+		fields["body_bytes_sent"] = json.Number(groups[7])
+	}
+	addField(fields, "http_referer", groups[8])
+	addField(fields, "http_user_agent", groups[9])
+	return fields, true
+}
+
+// addPriority adds the facility and severity of the syslog priority
+func addPriority(fields map[string]interface{}, priority string) {
+	if priority == "" {
+		return
+	}
+	pri, err := strconv.Atoi(priority)
+	if err != nil {
+		return
+	}
+	fields["priority"] = json.Number(priority)
+	fields["facility"] = json.Number(strconv.Itoa(pri / 8))
+	fields["severity"] = json.Number(strconv.Itoa(pri % 8))
+}
+
+// addField adds the field unless its value is empty or the nil value "-" of the syslog and access logs
+func addField(fields map[string]interface{}, key, value string) {
+	if value != "" && value != "-" {
+		fields[key] = value
+	}
+}
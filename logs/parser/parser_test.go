@@ -0,0 +1,203 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogParser_Fields(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		msg      string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "json",
+			config:   Config{Type: JSON},
+			msg:      `{"level":"info","latency":12.5,"user":{"id":"u1"}}`,
+			expected: map[string]interface{}{"level": "info", "latency": json.Number("12.5"), "user": map[string]interface{}{"id": "u1"}},
+		},
+		{
+			name:     "json not an object",
+			config:   Config{Type: JSON},
+			msg:      `["a","b"]`,
+			expected: nil,
+		},
+		{
+			name:     "logfmt",
+			config:   Config{Type: Logfmt},
+			msg:      `level=warn msg="disk \"/\" almost full" used=91% dry-run`,
+			expected: map[string]interface{}{"level": "warn", "msg": `disk "/" almost full`, "used": "91%", "dry-run": ""},
+		},
+		{
+			name:     "logfmt without pair",
+			config:   Config{Type: Logfmt},
+			msg:      "just some text",
+			expected: nil,
+		},
+		{
+			name:     "logfmt unterminated quote",
+			config:   Config{Type: Logfmt},
+			msg:      `msg="unterminated`,
+			expected: nil,
+		},
+		{
+			name:     "regex",
+			config:   Config{Type: Regex, Pattern: `^(?P<level>[A-Z]+) \[(?P<thread>[^\]]*)\] (?P<message>.*)$`},
+			msg:      "ERROR [] connection refused",
+			expected: map[string]interface{}{"level": "ERROR", "message": "connection refused"},
+		},
+		{
+			name:     "regex no match",
+			config:   Config{Type: Regex, Pattern: `^(?P<level>[A-Z]+) `},
+			msg:      "lowercase",
+			expected: nil,
+		},
+		{
+			name:   "syslog rfc3164",
+			config: Config{Type: SyslogRFC3164},
+			msg:    "<34>Oct  3 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8",
+			expected: map[string]interface{}{
+				"priority": json.Number("34"), "facility": json.Number("4"), "severity": json.Number("2"),
+				"timestamp": "Oct  3 22:14:15", "hostname": "mymachine", "appname": "su", "procid": "230",
+				"message": "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name:   "syslog rfc3164 without priority",
+			config: Config{Type: SyslogRFC3164},
+			msg:    "Oct 11 22:14:15 ip-10-0-0-1 systemd: Started Session 1 of user ec2-user.",
+			expected: map[string]interface{}{
+				"timestamp": "Oct 11 22:14:15", "hostname": "ip-10-0-0-1", "appname": "systemd",
+				"message": "Started Session 1 of user ec2-user.",
+			},
+		},
+		{
+			name:   "syslog rfc5424",
+			config: Config{Type: SyslogRFC5424},
+			msg:    `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application \"A\""][origin ip="10.0.0.1"] ` + "\ufeff" + `An application event`,
+			expected: map[string]interface{}{
+				"priority": json.Number("165"), "facility": json.Number("20"), "severity": json.Number("5"),
+				"version": json.Number("1"), "timestamp": "2003-10-11T22:14:15.003Z", "hostname": "mymachine.example.com",
+				"appname": "evntslog", "msgid": "ID47",
+				"structured_data": map[string]interface{}{
+					"exampleSDID@32473": map[string]interface{}{"iut": "3", "eventSource": `Application "A"`},
+					"origin":            map[string]interface{}{"ip": "10.0.0.1"},
+				},
+				"message": "An application event",
+			},
+		},
+		{
+			name:   "syslog rfc5424 without structured data and message",
+			config: Config{Type: SyslogRFC5424},
+			msg:    "<13>1 2003-10-11T22:14:15Z host app 1234 - -",
+			expected: map[string]interface{}{
+				"priority": json.Number("13"), "facility": json.Number("1"), "severity": json.Number("5"),
+				"version": json.Number("1"), "timestamp": "2003-10-11T22:14:15Z", "hostname": "host",
+				"appname": "app", "procid": "1234",
+			},
+		},
+		{
+			name:   "clf combined",
+			config: Config{Type: CLF},
+			msg:    `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			expected: map[string]interface{}{
+				"remote_addr": "127.0.0.1", "remote_user": "frank", "time_local": "10/Oct/2000:13:55:36 -0700",
+				"request": "GET /apache_pb.gif HTTP/1.0", "method": "GET", "path": "/apache_pb.gif", "protocol": "HTTP/1.0",
+				"status": json.Number("200"), "body_bytes_sent": json.Number("2326"),
+				"http_referer": "http://www.example.com/start.html", "http_user_agent": "Mozilla/4.08 [en] (Win98; I ;Nav)",
+			},
+		},
+		{
+			name:   "clf common",
+			config: Config{Type: CLF},
+			msg:    `10.0.0.2 - - [10/Oct/2000:13:55:36 +0000] "-" 400 -`,
+			expected: map[string]interface{}{
+				"remote_addr": "10.0.0.2", "time_local": "10/Oct/2000:13:55:36 +0000", "status": json.Number("400"),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := New(test.config, time.UTC)
+			require.NoError(t, err)
+			fields, _, msg := p.Parse(test.msg)
+			assert.Equal(t, test.expected, fields)
+			assert.Equal(t, test.msg, msg, "the log event is published as it was read by default")
+		})
+	}
+}
+
+func TestLogParser_Timestamp(t *testing.T) {
+	expected := time.Date(2020, 10, 11, 22, 14, 15, 0, time.UTC)
+	tests := []struct {
+		config Config
+		msg    string
+	}{
+		{Config{Type: JSON, TimestampField: "ts"}, `{"ts":"2020-10-11T22:14:15Z"}`},
+		{Config{Type: JSON, TimestampField: "ts"}, `{"ts":1602454455}`},
+		{Config{Type: JSON, TimestampField: "ts"}, `{"ts":1602454455000}`},
+		{Config{Type: Logfmt, TimestampField: "time", TimestampLayout: "2006/01/02 15:04:05"}, `time="2020/10/11 22:14:15" level=info`},
+		{Config{Type: SyslogRFC5424}, "<13>1 2020-10-11T22:14:15Z host app - - - message"},
+		{Config{Type: CLF}, `127.0.0.1 - - [11/Oct/2020:22:14:15 +0000] "GET / HTTP/1.1" 200 12`},
+	}
+	for _, test := range tests {
+		p, err := New(test.config, time.UTC)
+		require.NoError(t, err)
+		_, timestamp, _ := p.Parse(test.msg)
+		assert.True(t, expected.Equal(timestamp), "%v != %v for %v", timestamp, expected, test.msg)
+	}
+
+	// the year of the syslog timestamps is the current one
+	p, err := New(Config{Type: SyslogRFC3164}, time.UTC)
+	require.NoError(t, err)
+	_, timestamp, _ := p.Parse("Jan  2 03:04:05 host app: message")
+	assert.Equal(t, time.Now().Year(), timestamp.Year())
+	assert.Equal(t, time.January, timestamp.Month())
+
+	// the timestamp is not found in the fields
+	p, err = New(Config{Type: JSON, TimestampField: "ts"}, time.UTC)
+	require.NoError(t, err)
+	for _, msg := range []string{`{"other":"2020-10-11T22:14:15Z"}`, `{"ts":"yesterday"}`, `{"ts":true}`} {
+		_, timestamp, _ = p.Parse(msg)
+		assert.True(t, timestamp.IsZero(), "unexpected timestamp %v for %v", timestamp, msg)
+	}
+}
+
+func TestLogParser_OutputJSON(t *testing.T) {
+	p, err := New(Config{Type: Logfmt, OutputFormat: OutputJSON}, time.UTC)
+	require.NoError(t, err)
+
+	_, _, msg := p.Parse(`level=error path=/a&b msg="<nil> value"`)
+	assert.Equal(t, `{"level":"error","msg":"<nil> value","path":"/a&b"}`, msg)
+
+	// the log events which cannot be parsed are published as they were read
+	_, _, msg = p.Parse("not logfmt")
+	assert.Equal(t, "not logfmt", msg)
+
+	p, err = New(Config{Type: CLF, OutputFormat: OutputJSON}, time.UTC)
+	require.NoError(t, err)
+	_, _, msg = p.Parse(`10.0.0.2 - - [10/Oct/2000:13:55:36 +0000] "GET / HTTP/1.1" 304 0`)
+	assert.Equal(t, `{"body_bytes_sent":0,"method":"GET","path":"/","protocol":"HTTP/1.1","remote_addr":"10.0.0.2","request":"GET / HTTP/1.1","status":304,"time_local":"10/Oct/2000:13:55:36 +0000"}`, msg)
+}
+
+func TestLogParser_InvalidConfig(t *testing.T) {
+	for _, config := range []Config{
+		{Type: "xml"},
+		{Type: Regex},
+		{Type: Regex, Pattern: "(?P<unclosed"},
+		{Type: Regex, Pattern: `^(\S+) (.*)$`},
+		{Type: JSON, OutputFormat: "yaml"},
+	} {
+		_, err := New(config, time.UTC)
+		assert.Error(t, err, "%+v should be invalid", config)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf_http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/parsers/emf"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const (
+	emfPath             = "/emf"
+	metricName          = "emf"
+	maxRequestSize      = 4 * 1024 * 1024
+	httpShutdownTimeout = 5 * time.Second
+	// the maximum size of a CloudWatch Logs event, which is its message plus 26 bytes
+	maxDocumentSize = 256*1024 - 26
+)
+
+type EMFHTTP struct {
+	// Address & Port of the HTTP server, e.g. "127.0.0.1:25889"
+	ServiceAddress string `toml:"service_address"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	acc          telegraf.Accumulator
+	parser       *emf.EMFParser
+	httpServer   *http.Server
+	httpListener net.Listener
+	wg           sync.WaitGroup
+}
+
+// lineError is the error of a rejected document, the line starts at 1
+type lineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type response struct {
+	Accepted int         `json:"accepted"`
+	Errors   []lineError `json:"errors,omitempty"`
+}
+
+const sampleConfig = `
+  ## Address and port of the HTTP server, the EMF documents are posted to /emf
+  service_address = "127.0.0.1:25889"
+`
+
+func (e *EMFHTTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EMFHTTP) Description() string {
+	return "Receive embedded metric format documents over HTTP"
+}
+
+func (e *EMFHTTP) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (e *EMFHTTP) Start(acc telegraf.Accumulator) error {
+	if e.ServiceAddress == "" {
+		return fmt.Errorf("service_address is required")
+	}
+	e.acc = acc
+	e.parser = &emf.EMFParser{MetricName: metricName}
+
+	listener, err := net.Listen("tcp", e.ServiceAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", e.ServiceAddress, err)
+	}
+	e.httpListener = listener
+	mux := http.NewServeMux()
+	mux.HandleFunc(emfPath, e.handleHTTP)
+	e.httpServer = &http.Server{Handler: mux}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if err := e.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			e.Log.Errorf("EMF HTTP server stopped: %v", err)
+		}
+	}()
+	e.Log.Infof("Listening for EMF over HTTP on %v", listener.Addr())
+	return nil
+}
+
+func (e *EMFHTTP) Stop() {
+	if e.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := e.httpServer.Shutdown(ctx); err != nil {
+			e.Log.Warnf("Failed to shut down the EMF HTTP server: %v", err)
+		}
+	}
+	e.wg.Wait()
+}
+
+// handleHTTP accepts a single EMF document or newline delimited EMF documents. The request is rejected as a whole
+// when any document is invalid, so the producer can retry it once fixed without duplicating the valid documents.
+func (e *EMFHTTP) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		http.Error(w, "only the gzip content encoding is supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(payload) > maxRequestSize {
+		http.Error(w, "the request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	documents := splitDocuments(payload)
+	if len(documents) == 0 {
+		writeResponse(w, http.StatusBadRequest, response{Errors: []lineError{{Line: 1, Message: "no EMF document in the request"}}})
+		return
+	}
+	var metrics []telegraf.Metric
+	var errs []lineError
+	for _, d := range documents {
+		m, err := e.parseDocument(d.text)
+		if err != nil {
+			errs = append(errs, lineError{Line: d.line, Message: err.Error()})
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	if len(errs) > 0 {
+		writeResponse(w, http.StatusBadRequest, response{Errors: errs})
+		return
+	}
+	for _, m := range metrics {
+		e.acc.AddMetric(m)
+	}
+	writeResponse(w, http.StatusOK, response{Accepted: len(metrics)})
+}
+
+func (e *EMFHTTP) parseDocument(text string) (telegraf.Metric, error) {
+	if len(text) > maxDocumentSize {
+		return nil, fmt.Errorf("the document has %d bytes, the limit is %d", len(text), maxDocumentSize)
+	}
+	if err := emf.Validate(text); err != nil {
+		return nil, err
+	}
+	return e.parser.ParseLine(text)
+}
+
+type document struct {
+	line int
+	text string
+}
+
+// splitDocuments returns the documents of the payload, a single document may span multiple lines
+func splitDocuments(payload []byte) []document {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return nil
+	}
+	var single map[string]interface{}
+	if json.Unmarshal(payload, &single) == nil {
+		var compact bytes.Buffer
+		if json.Compact(&compact, payload) == nil {
+			return []document{{line: 1, text: compact.String()}}
+		}
+	}
+	var documents []document
+	for i, line := range bytes.Split(payload, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		documents = append(documents, document{line: i + 1, text: string(line)})
+	}
+	return documents
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp response) {
+	out, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(out)
+}
+
+func init() {
+	inputs.Add("emf_http", func() telegraf.Input {
+		return &EMFHTTP{}
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf_http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/logscommon"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validDocument   = `{"_aws":{"Timestamp":1566164466581,"LogGroupName":"lg","LogStreamName":"ls","CloudWatchMetrics":[{"Namespace":"ns","Dimensions":[["Service"]],"Metrics":[{"Name":"Latency","Unit":"Milliseconds"}]}]},"Service":"checkout","Latency":12}`
+	invalidDocument = `{"_aws":{"Timestamp":1566164466581,"LogGroupName":"lg","CloudWatchMetrics":[{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"Latency","Unit":"Hours"}]}]},"Latency":12}`
+	noLogGroup      = `{"message":"hello"}`
+)
+
+func startEMFHTTP(t *testing.T) (*EMFHTTP, *testutil.Accumulator, string) {
+	e := &EMFHTTP{ServiceAddress: "127.0.0.1:0", Log: testutil.Logger{}}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+	return e, acc, "http://" + e.httpListener.Addr().String() + emfPath
+}
+
+func post(t *testing.T, req *http.Request) (int, response) {
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var r response
+	if resp.Header.Get("Content-Type") == "application/json" {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&r))
+	}
+	return resp.StatusCode, r
+}
+
+func newRequest(t *testing.T, url string, body string) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	return req
+}
+
+func TestEMFHTTP_Batch(t *testing.T) {
+	e, acc, url := startEMFHTTP(t)
+	defer e.Stop()
+
+	status, resp := post(t, newRequest(t, url, validDocument+"\n\n"+validDocument+"\n"))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, response{Accepted: 2}, resp)
+	assert.Len(t, acc.GetTelegrafMetrics(), 2)
+	acc.AssertContainsTaggedFields(t, metricName, map[string]interface{}{"value": validDocument},
+		map[string]string{logscommon.LogGroupNameTag: "lg", logscommon.LogStreamNameTag: "ls"})
+}
+
+func TestEMFHTTP_PrettyPrintedDocument(t *testing.T) {
+	e, acc, url := startEMFHTTP(t)
+	defer e.Stop()
+
+	var pretty bytes.Buffer
+	require.NoError(t, json.Indent(&pretty, []byte(validDocument), "", "  "))
+	status, resp := post(t, newRequest(t, url, pretty.String()))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, response{Accepted: 1}, resp)
+	acc.AssertContainsFields(t, metricName, map[string]interface{}{"value": validDocument})
+}
+
+func TestEMFHTTP_Gzip(t *testing.T) {
+	e, acc, url := startEMFHTTP(t)
+	defer e.Stop()
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	gz.Write([]byte(validDocument))
+	require.NoError(t, gz.Close())
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	status, resp := post(t, req)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, response{Accepted: 1}, resp)
+	assert.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestEMFHTTP_RejectInvalidLines(t *testing.T) {
+	e, acc, url := startEMFHTTP(t)
+	defer e.Stop()
+
+	status, resp := post(t, newRequest(t, url, strings.Join([]string{validDocument, invalidDocument, noLogGroup, "{"}, "\n")))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, 0, resp.Accepted)
+	require.Len(t, resp.Errors, 3)
+	assert.Equal(t, 2, resp.Errors[0].Line)
+	assert.Contains(t, resp.Errors[0].Message, "Unit Hours is not a CloudWatch unit")
+	assert.Equal(t, 3, resp.Errors[1].Line)
+	assert.Contains(t, resp.Errors[1].Message, "log group name is required")
+	assert.Equal(t, 4, resp.Errors[2].Line)
+	assert.Contains(t, resp.Errors[2].Message, "cannot serialize")
+	assert.Empty(t, acc.GetTelegrafMetrics())
+
+	status, resp = post(t, newRequest(t, url, "  \n"))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Len(t, resp.Errors, 1)
+
+	status, resp = post(t, newRequest(t, url, `{"_aws":{"LogGroupName":"lg"},"message":"`+strings.Repeat("a", maxDocumentSize)+`"}`))
+	assert.Equal(t, http.StatusBadRequest, status)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "the limit is")
+}
+
+func TestEMFHTTP_RejectRequest(t *testing.T) {
+	e, _, url := startEMFHTTP(t)
+	defer e.Stop()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	req := newRequest(t, url, validDocument)
+	req.Header.Set("Content-Encoding", "br")
+	status, _ := post(t, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+	status, _ = post(t, newRequest(t, url, strings.Repeat("a", maxRequestSize+1)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+}
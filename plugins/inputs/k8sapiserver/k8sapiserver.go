@@ -6,11 +6,13 @@ package k8sapiserver
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal"
 	"github.com/aws/amazon-cloudwatch-agent/internal/containerinsightscommon"
 	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
 	"github.com/influxdata/telegraf"
@@ -28,38 +30,66 @@ import (
 
 const (
 	lockName = "cwagent-clusterleader"
+
+	lockTypeConfigMaps = "configmaps"
+	lockTypeLeases     = "leases"
+
+	defaultLeaseDuration = 60 * time.Second
+	defaultRenewDeadline = 15 * time.Second
+	defaultRetryPeriod   = 5 * time.Second
 )
 
 type K8sAPIServer struct {
 	NodeName string `toml:"node_name"`
+	// LockType is the resource used as the leader election lock, either configmaps or leases
+	LockType      string            `toml:"leader_lock_type"`
+	LeaseDuration internal.Duration `toml:"leader_lease_duration"`
+	RenewDeadline internal.Duration `toml:"leader_renew_deadline"`
+	RetryPeriod   internal.Duration `toml:"leader_retry_period"`
 
-	cancel  context.CancelFunc
-	leading bool
+	cancel context.CancelFunc
+	// done is closed when the leader election has ended and the lock has been released
+	done chan struct{}
 }
 
 var sampleConfig = `
+  ## The resource used as the leader election lock, either "configmaps" or "leases".
+  ## The leases are lighter on the api server, they require coordination.k8s.io permissions.
+  # leader_lock_type = "configmaps"
+  ## How long the non-leader agents wait before taking over the lock of a leader which stopped renewing it.
+  # leader_lease_duration = "60s"
+  ## How long the leader keeps retrying to renew the lock before it gives up the leadership.
+  # leader_renew_deadline = "15s"
+  ## How long the agents wait between the attempts to acquire or renew the lock.
+  # leader_retry_period = "5s"
 `
 
 func init() {
 	inputs.Add("k8sapiserver", func() telegraf.Input {
-		return &K8sAPIServer{}
+		return &K8sAPIServer{
+			LockType:      lockTypeConfigMaps,
+			LeaseDuration: internal.Duration{Duration: defaultLeaseDuration},
+			RenewDeadline: internal.Duration{Duration: defaultRenewDeadline},
+			RetryPeriod:   internal.Duration{Duration: defaultRetryPeriod},
+		}
 	})
 }
 
-//SampleConfig returns a sample config
+// SampleConfig returns a sample config
 func (k *K8sAPIServer) SampleConfig() string {
 	return sampleConfig
 }
 
-//Description returns the description of this plugin
+// Description returns the description of this plugin
 func (k *K8sAPIServer) Description() string {
 	return "Calculate cluster level metrics from the k8s api server"
 }
 
 func (k *K8sAPIServer) Gather(acc telegraf.Accumulator) error {
-	if k.leading {
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/1e6, 10)
+	k.gatherLeadership(acc, timestamp)
+	if IsLeading() {
 		log.Printf("D! collect data from K8s API Server...")
-		timestamp := strconv.FormatInt(time.Now().UnixNano()/1e6, 10)
 		client := k8sclient.Get()
 		acc.AddFields("k8sapiserver",
 			map[string]interface{}{
@@ -93,13 +123,105 @@ func (k *K8sAPIServer) Gather(acc telegraf.Accumulator) error {
 					containerinsightscommon.K8sNamespace: namespace,
 				})
 		}
+		k.gatherWorkloads(acc, client, timestamp)
+		for namespace, reasonToPodNum := range client.Pod.NamespaceToPendingPodNumByReason() {
+			for reason, podNum := range reasonToPodNum {
+				acc.AddFields("k8sapiserver",
+					map[string]interface{}{
+						containerinsightscommon.MetricName(containerinsightscommon.TypeClusterPendingPod, containerinsightscommon.PendingPodCount): podNum,
+					},
+					map[string]string{
+						containerinsightscommon.MetricType:       containerinsightscommon.TypeClusterPendingPod,
+						"Timestamp":                              timestamp,
+						containerinsightscommon.K8sNamespace:     namespace,
+						containerinsightscommon.PendingReasonKey: reason,
+					})
+			}
+		}
+		for namespace, jobCount := range client.Job.NamespaceToJobCount() {
+			mType := containerinsightscommon.TypeClusterJob
+			acc.AddFields("k8sapiserver",
+				map[string]interface{}{
+					containerinsightscommon.MetricName(mType, containerinsightscommon.ActiveJobCount):    jobCount.Active,
+					containerinsightscommon.MetricName(mType, containerinsightscommon.SucceededJobCount): jobCount.Succeeded,
+					containerinsightscommon.MetricName(mType, containerinsightscommon.FailedJobCount):    jobCount.Failed,
+				},
+				map[string]string{
+					containerinsightscommon.MetricType:   mType,
+					"Timestamp":                          timestamp,
+					containerinsightscommon.K8sNamespace: namespace,
+				})
+		}
+		for namespace, phaseToPVCNum := range client.PVC.NamespaceToPVCNumByPhase() {
+			for phase, pvcNum := range phaseToPVCNum {
+				acc.AddFields("k8sapiserver",
+					map[string]interface{}{
+						containerinsightscommon.MetricName(containerinsightscommon.TypeClusterPVC, containerinsightscommon.PVCCount): pvcNum,
+					},
+					map[string]string{
+						containerinsightscommon.MetricType:   containerinsightscommon.TypeClusterPVC,
+						"Timestamp":                          timestamp,
+						containerinsightscommon.K8sNamespace: namespace,
+						containerinsightscommon.PVCPhaseKey:  phase,
+					})
+			}
+		}
 	}
 	return nil
 }
 
+// gatherLeadership reports whether this agent is the leader, every agent reports it so the cluster is known to have
+// exactly one leader collecting the cluster level metrics
+func (k *K8sAPIServer) gatherLeadership(acc telegraf.Accumulator, timestamp string) {
+	isLeader := 0
+	if IsLeading() {
+		isLeader = 1
+	}
+	acc.AddFields("k8sapiserver",
+		map[string]interface{}{
+			containerinsightscommon.MetricName(containerinsightscommon.TypeClusterLeaderElection, containerinsightscommon.IsLeader): isLeader,
+		},
+		map[string]string{
+			containerinsightscommon.MetricType:  containerinsightscommon.TypeClusterLeaderElection,
+			"Timestamp":                         timestamp,
+			containerinsightscommon.NodeNameKey: k.NodeName,
+		})
+}
+
+// gatherWorkloads reports the desired and the available replicas of the Deployments, DaemonSets and StatefulSets
+func (k *K8sAPIServer) gatherWorkloads(acc telegraf.Accumulator, client *k8sclient.K8sClient, timestamp string) {
+	workloads := []struct {
+		mType    string
+		kind     string
+		statuses map[k8sclient.Workload]k8sclient.ReplicaStatus
+	}{
+		{containerinsightscommon.TypeClusterDeployment, containerinsightscommon.Deployment, client.Deployment.DeploymentToReplicaStatus()},
+		{containerinsightscommon.TypeClusterDaemonSet, containerinsightscommon.DaemonSet, client.DaemonSet.DaemonSetToReplicaStatus()},
+		{containerinsightscommon.TypeClusterStatefulSet, containerinsightscommon.StatefulSet, client.StatefulSet.StatefulSetToReplicaStatus()},
+	}
+	for _, w := range workloads {
+		for workload, status := range w.statuses {
+			acc.AddFields("k8sapiserver",
+				map[string]interface{}{
+					containerinsightscommon.MetricName(w.mType, containerinsightscommon.DesiredReplicas):     status.Desired,
+					containerinsightscommon.MetricName(w.mType, containerinsightscommon.AvailableReplicas):   status.Available,
+					containerinsightscommon.MetricName(w.mType, containerinsightscommon.UnavailableReplicas): status.Unavailable,
+				},
+				map[string]string{
+					containerinsightscommon.MetricType:   w.mType,
+					"Timestamp":                          timestamp,
+					w.kind:                               workload.Name,
+					containerinsightscommon.K8sNamespace: workload.Namespace,
+				})
+		}
+	}
+}
+
 func (k *K8sAPIServer) Start(acc telegraf.Accumulator) error {
-	var ctx context.Context
-	ctx, k.cancel = context.WithCancel(context.Background())
+	if err := k.validateLeaderElection(); err != nil {
+		log.Printf("E! Invalid leader election config of k8sapiserver: %v", err)
+		return err
+	}
 
 	lockNamespace := os.Getenv("K8S_NAMESPACE")
 	if lockNamespace == "" {
@@ -107,20 +229,14 @@ func (k *K8sAPIServer) Start(acc telegraf.Accumulator) error {
 		return errors.New("missing environment variable K8S_NAMESPACE")
 	}
 
-	configMapInterface := k8sclient.Get().ClientSet.CoreV1().ConfigMaps(lockNamespace)
-	if configMap, err := configMapInterface.Get(lockName, metav1.GetOptions{}); configMap == nil || err != nil {
-		log.Printf("I! Cannot get the leader config map: %v, try to create the config map...", err)
-		configMap, err = configMapInterface.Create(&v1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: lockNamespace,
-				Name:      lockName,
-			},
-		})
-		log.Printf("I! configMap: %v, err: %v", configMap, err)
+	lockType := resourcelock.LeasesResourceLock
+	if k.LockType == lockTypeConfigMaps {
+		lockType = resourcelock.ConfigMapsResourceLock
+		k.createLockConfigMap(lockNamespace)
 	}
 
 	lock, err := resourcelock.New(
-		resourcelock.ConfigMapsResourceLock,
+		lockType,
 		lockNamespace, lockName,
 		k8sclient.Get().ClientSet.CoreV1(),
 		k8sclient.Get().ClientSet.CoordinationV1(),
@@ -133,12 +249,49 @@ func (k *K8sAPIServer) Start(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	var ctx context.Context
+	ctx, k.cancel = context.WithCancel(context.Background())
+	k.done = make(chan struct{})
 	go k.startLeaderElection(ctx, lock)
 
 	return nil
 }
 
+// validateLeaderElection checks the lock type and the durations, RunOrDie panics on durations it does not accept
+func (k *K8sAPIServer) validateLeaderElection() error {
+	if k.LockType != lockTypeConfigMaps && k.LockType != lockTypeLeases {
+		return fmt.Errorf("leader_lock_type %q is not supported, it should be %q or %q", k.LockType, lockTypeConfigMaps, lockTypeLeases)
+	}
+	if k.RetryPeriod.Duration <= 0 {
+		return fmt.Errorf("leader_retry_period %v should be greater than zero", k.RetryPeriod.Duration)
+	}
+	if k.RenewDeadline.Duration <= time.Duration(leaderelection.JitterFactor*float64(k.RetryPeriod.Duration)) {
+		return fmt.Errorf("leader_renew_deadline %v should be greater than %v times leader_retry_period %v",
+			k.RenewDeadline.Duration, leaderelection.JitterFactor, k.RetryPeriod.Duration)
+	}
+	if k.LeaseDuration.Duration <= k.RenewDeadline.Duration {
+		return fmt.Errorf("leader_lease_duration %v should be greater than leader_renew_deadline %v",
+			k.LeaseDuration.Duration, k.RenewDeadline.Duration)
+	}
+	return nil
+}
+
+func (k *K8sAPIServer) createLockConfigMap(lockNamespace string) {
+	configMapInterface := k8sclient.Get().ClientSet.CoreV1().ConfigMaps(lockNamespace)
+	if configMap, err := configMapInterface.Get(lockName, metav1.GetOptions{}); configMap == nil || err != nil {
+		log.Printf("I! Cannot get the leader config map: %v, try to create the config map...", err)
+		configMap, err = configMapInterface.Create(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: lockNamespace,
+				Name:      lockName,
+			},
+		})
+		log.Printf("I! configMap: %v, err: %v", configMap, err)
+	}
+}
+
 func (k *K8sAPIServer) startLeaderElection(ctx context.Context, lock resourcelock.Interface) {
+	defer close(k.done)
 
 	for {
 		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
@@ -149,22 +302,30 @@ func (k *K8sAPIServer) startLeaderElection(ctx context.Context, lock resourceloc
 			// loop still running and another process could
 			// get elected before your background loop finished, violating
 			// the stated goal of the lease.
-			LeaseDuration: 60 * time.Second,
-			RenewDeadline: 15 * time.Second,
-			RetryPeriod:   5 * time.Second,
+			LeaseDuration: k.LeaseDuration.Duration,
+			RenewDeadline: k.RenewDeadline.Duration,
+			RetryPeriod:   k.RetryPeriod.Duration,
+			// the leader gives up the lock when the agent stops, so another agent takes over after a retry period
+			// instead of waiting for the lease to expire.
+			ReleaseOnCancel: true,
 			Callbacks: leaderelection.LeaderCallbacks{
 				OnStartedLeading: func(ctx context.Context) {
 					log.Printf("I! k8sapiserver OnStartedLeading: %s", k.NodeName)
 					// we're notified when we start
-					k.leading = true
+					setLeading(true)
 				},
 				OnStoppedLeading: func() {
 					log.Printf("I! k8sapiserver OnStoppedLeading: %s", k.NodeName)
 					// we can do cleanup here, or after the RunOrDie method returns
-					k.leading = false
-					//node and pod are only used for cluster level metrics, endpoint is used for decorator too.
+					setLeading(false)
+					//node, pod and the workloads are only used for cluster level metrics, endpoint is used for decorator too.
 					k8sclient.Get().Node.Shutdown()
 					k8sclient.Get().Pod.Shutdown()
+					k8sclient.Get().Deployment.Shutdown()
+					k8sclient.Get().DaemonSet.Shutdown()
+					k8sclient.Get().StatefulSet.Shutdown()
+					k8sclient.Get().Job.Shutdown()
+					k8sclient.Get().PVC.Shutdown()
 				},
 				OnNewLeader: func(identity string) {
 					log.Printf("I! k8sapiserver Switch New Leader: %s", identity)
@@ -181,9 +342,16 @@ func (k *K8sAPIServer) startLeaderElection(ctx context.Context, lock resourceloc
 	}
 }
 
+// Stop ends the leader election and waits for the lock to be released, it is called when the agent receives SIGTERM
 func (k *K8sAPIServer) Stop() {
-	if k.cancel != nil {
-		k.cancel()
+	if k.cancel == nil {
+		return
+	}
+	k.cancel()
+	select {
+	case <-k.done:
+	case <-time.After(k.RenewDeadline.Duration):
+		log.Printf("W! k8sapiserver timed out releasing the leader lock: %s", k.NodeName)
 	}
 }
 
@@ -7,9 +7,11 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent/internal/containerinsightscommon"
 	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -18,9 +20,14 @@ import (
 var mockClient = new(MockClient)
 
 var mockK8sClient = &k8sclient.K8sClient{
-	Pod:  mockClient,
-	Node: mockClient,
-	Ep:   mockClient,
+	Pod:         mockClient,
+	Node:        mockClient,
+	Ep:          mockClient,
+	Deployment:  mockClient,
+	DaemonSet:   mockClient,
+	StatefulSet: mockClient,
+	Job:         mockClient,
+	PVC:         mockClient,
 }
 
 func mockGet() *k8sclient.K8sClient {
@@ -31,6 +38,11 @@ type MockClient struct {
 	k8sclient.PodClient
 	k8sclient.NodeClient
 	k8sclient.EpClient
+	k8sclient.DeploymentClient
+	k8sclient.DaemonSetClient
+	k8sclient.StatefulSetClient
+	k8sclient.JobClient
+	k8sclient.PVCClient
 
 	mock.Mock
 }
@@ -41,6 +53,11 @@ func (client *MockClient) NamespaceToRunningPodNum() map[string]int {
 	return args.Get(0).(map[string]int)
 }
 
+func (client *MockClient) NamespaceToPendingPodNumByReason() map[string]map[string]int {
+	args := client.Called()
+	return args.Get(0).(map[string]map[string]int)
+}
+
 // k8sclient.NodeClient
 func (client *MockClient) ClusterFailedNodeCount() int {
 	args := client.Called()
@@ -58,6 +75,36 @@ func (client *MockClient) ServiceToPodNum() map[k8sclient.Service]int {
 	return args.Get(0).(map[k8sclient.Service]int)
 }
 
+// k8sclient.DeploymentClient
+func (client *MockClient) DeploymentToReplicaStatus() map[k8sclient.Workload]k8sclient.ReplicaStatus {
+	args := client.Called()
+	return args.Get(0).(map[k8sclient.Workload]k8sclient.ReplicaStatus)
+}
+
+// k8sclient.DaemonSetClient
+func (client *MockClient) DaemonSetToReplicaStatus() map[k8sclient.Workload]k8sclient.ReplicaStatus {
+	args := client.Called()
+	return args.Get(0).(map[k8sclient.Workload]k8sclient.ReplicaStatus)
+}
+
+// k8sclient.StatefulSetClient
+func (client *MockClient) StatefulSetToReplicaStatus() map[k8sclient.Workload]k8sclient.ReplicaStatus {
+	args := client.Called()
+	return args.Get(0).(map[k8sclient.Workload]k8sclient.ReplicaStatus)
+}
+
+// k8sclient.JobClient
+func (client *MockClient) NamespaceToJobCount() map[string]k8sclient.JobCount {
+	args := client.Called()
+	return args.Get(0).(map[string]k8sclient.JobCount)
+}
+
+// k8sclient.PVCClient
+func (client *MockClient) NamespaceToPVCNumByPhase() map[string]map[string]int {
+	args := client.Called()
+	return args.Get(0).(map[string]map[string]int)
+}
+
 func (client *MockClient) Init() {
 }
 
@@ -69,8 +116,9 @@ func TestK8sAPIServer_Gather(t *testing.T) {
 	assert.NoError(t, err)
 	plugin := &K8sAPIServer{
 		NodeName: hostName,
-		leading:  true,
 	}
+	setLeading(true)
+	defer setLeading(false)
 
 	k8sclient.Get = mockGet
 
@@ -79,6 +127,16 @@ func TestK8sAPIServer_Gather(t *testing.T) {
 	mockClient.On("ClusterNodeCount").Return(1)
 	mockClient.On("ServiceToPodNum").Return(map[k8sclient.Service]int{k8sclient.NewService("service1", "kube-system"): 1, k8sclient.NewService("service2", "kube-system"): 1})
 
+	mockClient.On("NamespaceToPendingPodNumByReason").Return(map[string]map[string]int{"default": {"Unschedulable": 3}})
+	mockClient.On("DeploymentToReplicaStatus").Return(map[k8sclient.Workload]k8sclient.ReplicaStatus{
+		k8sclient.NewWorkload("deployment1", "default"): {Desired: 3, Available: 2, Unavailable: 1}})
+	mockClient.On("DaemonSetToReplicaStatus").Return(map[k8sclient.Workload]k8sclient.ReplicaStatus{
+		k8sclient.NewWorkload("daemonset1", "kube-system"): {Desired: 2, Available: 2}})
+	mockClient.On("StatefulSetToReplicaStatus").Return(map[k8sclient.Workload]k8sclient.ReplicaStatus{
+		k8sclient.NewWorkload("statefulset1", "default"): {Desired: 1, Unavailable: 1}})
+	mockClient.On("NamespaceToJobCount").Return(map[string]k8sclient.JobCount{"default": {Active: 1, Succeeded: 4, Failed: 2}})
+	mockClient.On("NamespaceToPVCNumByPhase").Return(map[string]map[string]int{"default": {"Bound": 2}})
+
 	var acc testutil.Accumulator
 
 	err = plugin.Gather(&acc)
@@ -90,6 +148,7 @@ func TestK8sAPIServer_Gather(t *testing.T) {
 		tags: map[Service:service1 Timestamp:1557291396709 Type:ClusterService], fields: map[service_number_of_running_pods:1],
 		tags: map[Namespace:default Timestamp:1557291396709 Type:ClusterNamespace], fields: map[namespace_number_of_running_pods:2],
 	*/
+	assert.Len(t, acc.Metrics, 11)
 	for _, metric := range acc.Metrics {
 		log.Printf("measurement: %v, tags: %v, fields: %v, time: %v\n", metric.Measurement, metric.Tags, metric.Fields, metric.Time)
 		if metricType := metric.Tags[containerinsightscommon.MetricType]; metricType == containerinsightscommon.TypeCluster {
@@ -105,9 +164,78 @@ func TestK8sAPIServer_Gather(t *testing.T) {
 		} else if metricType == containerinsightscommon.TypeClusterNamespace {
 			assert.Equal(t, map[string]interface{}{"namespace_number_of_running_pods": 2}, metric.Fields)
 			assert.Equal(t, "default", metric.Tags[containerinsightscommon.K8sNamespace])
+		} else if metricType == containerinsightscommon.TypeClusterDeployment {
+			assert.Equal(t, map[string]interface{}{"deployment_desired_replicas": 3, "deployment_available_replicas": 2, "deployment_unavailable_replicas": 1}, metric.Fields)
+			assert.Equal(t, "deployment1", metric.Tags[containerinsightscommon.Deployment])
+			assert.Equal(t, "default", metric.Tags[containerinsightscommon.K8sNamespace])
+		} else if metricType == containerinsightscommon.TypeClusterDaemonSet {
+			assert.Equal(t, map[string]interface{}{"daemonset_desired_replicas": 2, "daemonset_available_replicas": 2, "daemonset_unavailable_replicas": 0}, metric.Fields)
+			assert.Equal(t, "daemonset1", metric.Tags[containerinsightscommon.DaemonSet])
+			assert.Equal(t, "kube-system", metric.Tags[containerinsightscommon.K8sNamespace])
+		} else if metricType == containerinsightscommon.TypeClusterStatefulSet {
+			assert.Equal(t, map[string]interface{}{"statefulset_desired_replicas": 1, "statefulset_available_replicas": 0, "statefulset_unavailable_replicas": 1}, metric.Fields)
+			assert.Equal(t, "statefulset1", metric.Tags[containerinsightscommon.StatefulSet])
+		} else if metricType == containerinsightscommon.TypeClusterJob {
+			assert.Equal(t, map[string]interface{}{"namespace_number_of_active_jobs": 1, "namespace_number_of_succeeded_jobs": 4, "namespace_number_of_failed_jobs": 2}, metric.Fields)
+			assert.Equal(t, "default", metric.Tags[containerinsightscommon.K8sNamespace])
+		} else if metricType == containerinsightscommon.TypeClusterPendingPod {
+			assert.Equal(t, map[string]interface{}{"namespace_number_of_pending_pods": 3}, metric.Fields)
+			assert.Equal(t, "Unschedulable", metric.Tags[containerinsightscommon.PendingReasonKey])
+		} else if metricType == containerinsightscommon.TypeClusterPVC {
+			assert.Equal(t, map[string]interface{}{"namespace_number_of_persistent_volume_claims": 2}, metric.Fields)
+			assert.Equal(t, "Bound", metric.Tags[containerinsightscommon.PVCPhaseKey])
+		} else if metricType == containerinsightscommon.TypeClusterLeaderElection {
+			assert.Equal(t, map[string]interface{}{"leader_election_is_leader": 1}, metric.Fields)
+			assert.Equal(t, hostName, metric.Tags[containerinsightscommon.NodeNameKey])
 		} else {
 			assert.Fail(t, "Unexpected metric type: "+metricType)
 		}
 	}
 
 }
+
+func TestK8sAPIServer_GatherNotLeading(t *testing.T) {
+	plugin := &K8sAPIServer{NodeName: "node1"}
+
+	var acc testutil.Accumulator
+	assert.NoError(t, plugin.Gather(&acc))
+
+	assert.Len(t, acc.Metrics, 1)
+	assert.Equal(t, containerinsightscommon.TypeClusterLeaderElection, acc.Metrics[0].Tags[containerinsightscommon.MetricType])
+	assert.Equal(t, "node1", acc.Metrics[0].Tags[containerinsightscommon.NodeNameKey])
+	assert.Equal(t, map[string]interface{}{"leader_election_is_leader": 0}, acc.Metrics[0].Fields)
+}
+
+func TestK8sAPIServer_ValidateLeaderElection(t *testing.T) {
+	newPlugin := func() *K8sAPIServer {
+		return inputs.Inputs["k8sapiserver"]().(*K8sAPIServer)
+	}
+
+	plugin := newPlugin()
+	assert.NoError(t, plugin.validateLeaderElection())
+	assert.Equal(t, lockTypeConfigMaps, plugin.LockType)
+	assert.Equal(t, 60*time.Second, plugin.LeaseDuration.Duration)
+
+	plugin = newPlugin()
+	plugin.LockType = lockTypeLeases
+	plugin.LeaseDuration.Duration = 30 * time.Second
+	plugin.RenewDeadline.Duration = 10 * time.Second
+	plugin.RetryPeriod.Duration = 2 * time.Second
+	assert.NoError(t, plugin.validateLeaderElection())
+
+	plugin = newPlugin()
+	plugin.LockType = "endpoints"
+	assert.Error(t, plugin.validateLeaderElection())
+
+	plugin = newPlugin()
+	plugin.LeaseDuration.Duration = 15 * time.Second
+	assert.Error(t, plugin.validateLeaderElection())
+
+	plugin = newPlugin()
+	plugin.RetryPeriod.Duration = 13 * time.Second
+	assert.Error(t, plugin.validateLeaderElection())
+
+	plugin = newPlugin()
+	plugin.RetryPeriod.Duration = 0
+	assert.Error(t, plugin.validateLeaderElection())
+}
@@ -0,0 +1,25 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sapiserver
+
+import (
+	"sync/atomic"
+)
+
+// leading is 1 while the agent holds the cluster leader lock. The lock is acquired by k8sapiserver, the other inputs
+// collecting cluster level data, e.g. k8sevents, share its election so the cluster has a single collector.
+var leading int32
+
+// IsLeading returns whether the agent is the cluster leader elected by k8sapiserver
+func IsLeading() bool {
+	return atomic.LoadInt32(&leading) == 1
+}
+
+func setLeading(l bool) {
+	var v int32
+	if l {
+		v = 1
+	}
+	atomic.StoreInt32(&leading, v)
+}
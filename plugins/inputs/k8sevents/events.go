@@ -0,0 +1,59 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// event is the json log event published for a Kubernetes event
+type event struct {
+	Type           string         `json:"type"`
+	Reason         string         `json:"reason"`
+	Message        string         `json:"message"`
+	Namespace      string         `json:"namespace"`
+	Name           string         `json:"name"`
+	InvolvedObject involvedObject `json:"involved_object"`
+	Source         string         `json:"source,omitempty"`
+	Host           string         `json:"host,omitempty"`
+	Count          int32          `json:"count"`
+	FirstTimestamp string         `json:"first_timestamp,omitempty"`
+	LastTimestamp  string         `json:"last_timestamp"`
+}
+
+type involvedObject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	FieldPath string `json:"field_path,omitempty"`
+}
+
+func newEvent(e *v1.Event, count int32, last time.Time) event {
+	res := event{
+		Type:      e.Type,
+		Reason:    e.Reason,
+		Message:   e.Message,
+		Namespace: e.Namespace,
+		Name:      e.Name,
+		InvolvedObject: involvedObject{
+			Kind:      e.InvolvedObject.Kind,
+			Name:      e.InvolvedObject.Name,
+			Namespace: e.InvolvedObject.Namespace,
+			FieldPath: e.InvolvedObject.FieldPath,
+		},
+		Source:        e.Source.Component,
+		Host:          e.Source.Host,
+		Count:         count,
+		LastTimestamp: last.UTC().Format(time.RFC3339),
+	}
+	if res.Source == "" {
+		res.Source = e.ReportingController
+	}
+	if !e.FirstTimestamp.IsZero() {
+		res.FirstTimestamp = e.FirstTimestamp.UTC().Format(time.RFC3339)
+	}
+	return res
+}
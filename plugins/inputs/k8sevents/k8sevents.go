@@ -0,0 +1,270 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/k8sapiserver"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	defaultLogStreamName = "kubernetes-events"
+	defaultDestination   = "cloudwatchlogs"
+	defaultQueueSize     = 1000
+
+	// The events observed shortly before the watch started are published too, they may have been missed during the
+	// handover from the previous leader
+	lookback = time.Minute
+)
+
+var (
+	isLeading       = k8sapiserver.IsLeading
+	createListWatch = createEventListWatch
+)
+
+type K8sEvents struct {
+	LogGroupName  string   `toml:"log_group_name"`
+	LogStreamName string   `toml:"log_stream_name"`
+	Destination   string   `toml:"destination"`
+	Namespaces    []string `toml:"namespaces"`
+	Reasons       []string `toml:"reasons"`
+	EventTypes    []string `toml:"event_types"`
+
+	sync.Mutex
+	src     *logs.EventSrc
+	newSrcs []logs.LogSrc
+
+	namespaces map[string]bool
+	reasons    map[string]bool
+	eventTypes map[string]bool
+
+	// the watch is only running while the agent is the cluster leader
+	stopChan  chan struct{}
+	startTime time.Time
+	// the count and the last timestamp of the published events, a relist or an update of an event which did not occur
+	// again is not published twice
+	published map[types.UID]string
+}
+
+var sampleConfig = `
+  ## The log group the Kubernetes events are published to.
+  log_group_name = "/aws/containerinsights/my-cluster/events"
+  # log_stream_name = "kubernetes-events"
+  ## Only the events of these namespaces, reasons and types (Normal or Warning) are published, all when empty.
+  # namespaces = ["default"]
+  # reasons = ["OOMKilling", "FailedScheduling", "BackOff"]
+  # event_types = ["Warning"]
+`
+
+func init() {
+	inputs.Add("k8sevents", func() telegraf.Input {
+		return &K8sEvents{
+			LogStreamName: defaultLogStreamName,
+			Destination:   defaultDestination,
+		}
+	})
+}
+
+// SampleConfig returns a sample config
+func (k *K8sEvents) SampleConfig() string {
+	return sampleConfig
+}
+
+// Description returns the description of this plugin
+func (k *K8sEvents) Description() string {
+	return "Publish the Kubernetes events to CloudWatch Logs from the cluster leader"
+}
+
+// Gather starts watching the events when the agent becomes the cluster leader, and stops when it loses the leadership
+func (k *K8sEvents) Gather(acc telegraf.Accumulator) error {
+	k.Lock()
+	defer k.Unlock()
+	leading := isLeading()
+	if leading && k.stopChan == nil {
+		k.startWatch()
+	} else if !leading && k.stopChan != nil {
+		k.stopWatch()
+	}
+	return nil
+}
+
+func (k *K8sEvents) Start(acc telegraf.Accumulator) error {
+	if k.LogGroupName == "" {
+		return fmt.Errorf("log_group_name is required by k8sevents")
+	}
+	k.namespaces = toSet(k.Namespaces)
+	k.reasons = toSet(k.Reasons)
+	k.eventTypes = toSet(k.EventTypes)
+
+	k.Lock()
+	defer k.Unlock()
+	k.src = logs.NewEventSrc(k.LogGroupName, k.LogStreamName, k.Destination, "kubernetes events", -1, defaultQueueSize)
+	k.newSrcs = append(k.newSrcs, k.src)
+	return nil
+}
+
+func (k *K8sEvents) Stop() {
+	k.Lock()
+	defer k.Unlock()
+	if k.stopChan != nil {
+		k.stopWatch()
+	}
+	if k.src != nil {
+		k.src.Stop()
+	}
+}
+
+// FindLogSrc returns the log src of the Kubernetes events to the log agent
+func (k *K8sEvents) FindLogSrc() []logs.LogSrc {
+	k.Lock()
+	defer k.Unlock()
+	srcs := k.newSrcs
+	k.newSrcs = nil
+	return srcs
+}
+
+func (k *K8sEvents) startWatch() {
+	log.Printf("I! k8sevents start watching the events as the cluster leader")
+	k.stopChan = make(chan struct{})
+	k.startTime = time.Now()
+	k.published = make(map[types.UID]string)
+
+	_, controller := cache.NewInformer(createListWatch(k8sclient.Get().ClientSet, metav1.NamespaceAll), &v1.Event{}, 0,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				k.handleEvent(obj)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				k.handleEvent(newObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				k.forgetEvent(obj)
+			},
+		})
+	go controller.Run(k.stopChan)
+}
+
+func (k *K8sEvents) stopWatch() {
+	log.Printf("I! k8sevents stop watching the events")
+	close(k.stopChan)
+	k.stopChan = nil
+}
+
+func (k *K8sEvents) handleEvent(obj interface{}) {
+	e, ok := obj.(*v1.Event)
+	if !ok {
+		log.Printf("W! k8sevents input obj %v is not Event type", obj)
+		return
+	}
+	if !k.matches(e) {
+		return
+	}
+	last := lastTimestamp(e)
+	count := eventCount(e)
+
+	k.Lock()
+	defer k.Unlock()
+	if k.stopChan == nil {
+		// the watch has been stopped
+		return
+	}
+	key := fmt.Sprintf("%d/%d", count, last.UnixNano())
+	if k.published[e.UID] == key {
+		return
+	}
+	k.published[e.UID] = key
+	if last.Before(k.startTime.Add(-lookback)) {
+		return
+	}
+
+	msg, err := json.Marshal(newEvent(e, count, last))
+	if err != nil {
+		log.Printf("E! k8sevents failed to marshal the event %s/%s: %v", e.Namespace, e.Name, err)
+		return
+	}
+	if !k.src.Publish(logs.NewEvent(string(msg), last, nil)) {
+		log.Printf("E! k8sevents queue full, dropping the event %s/%s", e.Namespace, e.Name)
+	}
+}
+
+func (k *K8sEvents) forgetEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	e, ok := obj.(*v1.Event)
+	if !ok {
+		return
+	}
+	k.Lock()
+	defer k.Unlock()
+	delete(k.published, e.UID)
+}
+
+func (k *K8sEvents) matches(e *v1.Event) bool {
+	return matchesSet(k.namespaces, e.Namespace) && matchesSet(k.reasons, e.Reason) && matchesSet(k.eventTypes, e.Type)
+}
+
+// matchesSet returns true when the set is empty, i.e. no filter is configured, or contains the value
+func matchesSet(set map[string]bool, value string) bool {
+	return len(set) == 0 || set[value]
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// lastTimestamp returns when the event was last observed, the events reported by the events.k8s.io api only set
+// the event time and the series
+func lastTimestamp(e *v1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	case !e.FirstTimestamp.IsZero():
+		return e.FirstTimestamp.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func eventCount(e *v1.Event) int32 {
+	if e.Series != nil {
+		return e.Series.Count
+	}
+	return e.Count
+}
+
+func createEventListWatch(client kubernetes.Interface, ns string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.ResourceVersion = ""
+			return client.CoreV1().Events(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Events(ns).Watch(opts)
+		},
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newEventObj(uid, namespace, reason, eventType string, count int32, last time.Time) *v1.Event {
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(uid),
+			Name:      "pod1." + uid,
+			Namespace: namespace,
+		},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "pod1", Namespace: namespace},
+		Reason:         reason,
+		Message:        "Back-off restarting failed container",
+		Type:           eventType,
+		Source:         v1.EventSource{Component: "kubelet", Host: "node1"},
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(last.Add(-time.Minute)),
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func newPlugin(t *testing.T) *K8sEvents {
+	k := &K8sEvents{
+		LogGroupName:  "/aws/containerinsights/cluster/events",
+		LogStreamName: defaultLogStreamName,
+		Destination:   defaultDestination,
+		Namespaces:    []string{"default"},
+		EventTypes:    []string{"Warning"},
+	}
+	require.NoError(t, k.Start(&testutil.Accumulator{}))
+	return k
+}
+
+// output returns the log events published by the src of the plugin
+func output(k *K8sEvents) chan logs.LogEvent {
+	events := make(chan logs.LogEvent, 10)
+	k.src.SetOutput(func(e logs.LogEvent) { events <- e })
+	return events
+}
+
+func nextEvent(t *testing.T, events chan logs.LogEvent) logs.LogEvent {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event is not published")
+	}
+	return nil
+}
+
+// published returns the events published until the end marker, which is published after them
+func published(t *testing.T, k *K8sEvents, events chan logs.LogEvent) []event {
+	require.True(t, k.src.Publish(logs.NewEvent("end", time.Now(), nil)))
+	var res []event
+	for {
+		e := nextEvent(t, events)
+		if e.Message() == "end" {
+			return res
+		}
+		var ev event
+		json.Unmarshal([]byte(e.Message()), &ev)
+		res = append(res, ev)
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	k := newPlugin(t)
+	defer k.Stop()
+	out := output(k)
+	k.stopChan = make(chan struct{})
+	k.startTime = time.Now()
+	k.published = make(map[types.UID]string)
+
+	now := time.Now().Truncate(time.Second)
+	k.handleEvent(newEventObj("1", "default", "BackOff", "Warning", 1, now))
+	// filtered out by the namespace and the type
+	k.handleEvent(newEventObj("2", "kube-system", "BackOff", "Warning", 1, now))
+	k.handleEvent(newEventObj("3", "default", "Pulled", "Normal", 1, now))
+	// observed before the watch started
+	k.handleEvent(newEventObj("4", "default", "BackOff", "Warning", 1, now.Add(-time.Hour)))
+	// the same event again, e.g. after a relist
+	k.handleEvent(newEventObj("1", "default", "BackOff", "Warning", 1, now))
+	// the event occurred again
+	k.handleEvent(newEventObj("1", "default", "BackOff", "Warning", 2, now.Add(time.Second)))
+
+	events := published(t, k, out)
+	require.Len(t, events, 2)
+	assert.Equal(t, event{
+		Type:           "Warning",
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Namespace:      "default",
+		Name:           "pod1.1",
+		InvolvedObject: involvedObject{Kind: "Pod", Name: "pod1", Namespace: "default"},
+		Source:         "kubelet",
+		Host:           "node1",
+		Count:          1,
+		FirstTimestamp: now.Add(-time.Minute).UTC().Format(time.RFC3339),
+		LastTimestamp:  now.UTC().Format(time.RFC3339),
+	}, events[0])
+	assert.Equal(t, int32(2), events[1].Count)
+
+	k.forgetEvent(newEventObj("1", "default", "BackOff", "Warning", 2, now))
+	assert.NotContains(t, k.published, types.UID("1"))
+}
+
+func TestGatherFollowsLeadership(t *testing.T) {
+	origIsLeading, origCreateListWatch, origGet := isLeading, createListWatch, k8sclient.Get
+	defer func() {
+		isLeading, createListWatch, k8sclient.Get = origIsLeading, origCreateListWatch, origGet
+	}()
+
+	leading := false
+	isLeading = func() bool { return leading }
+	fakeWatcher := watch.NewFake()
+	createListWatch = func(client kubernetes.Interface, ns string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				return &v1.EventList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}}, nil
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				return fakeWatcher, nil
+			},
+		}
+	}
+	k8sclient.Get = func() *k8sclient.K8sClient {
+		return &k8sclient.K8sClient{}
+	}
+
+	k := newPlugin(t)
+	defer k.Stop()
+	out := output(k)
+	assert.Equal(t, []logs.LogSrc{k.src}, k.FindLogSrc())
+	assert.Empty(t, k.FindLogSrc())
+
+	assert.NoError(t, k.Gather(&testutil.Accumulator{}))
+	assert.Nil(t, k.stopChan)
+
+	leading = true
+	assert.NoError(t, k.Gather(&testutil.Accumulator{}))
+	assert.NotNil(t, k.stopChan)
+
+	fakeWatcher.Add(newEventObj("1", "default", "BackOff", "Warning", 1, time.Now()))
+	assert.Contains(t, nextEvent(t, out).Message(), `"reason":"BackOff"`)
+
+	leading = false
+	assert.NoError(t, k.Gather(&testutil.Accumulator{}))
+	assert.Nil(t, k.stopChan)
+}
+
+func TestLastTimestamp(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	e := &v1.Event{EventTime: metav1.NewMicroTime(now)}
+	assert.Equal(t, now, lastTimestamp(e))
+	e.Series = &v1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(now.Add(time.Minute))}
+	assert.Equal(t, now.Add(time.Minute), lastTimestamp(e))
+	assert.Equal(t, int32(3), eventCount(e))
+}
+
+func TestStartWithoutLogGroup(t *testing.T) {
+	k := &K8sEvents{}
+	assert.Error(t, k.Start(&testutil.Accumulator{}))
+}
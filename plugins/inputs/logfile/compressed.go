@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CloudWatch Logs rejects the log events older than 14 days, see hasValidTime of the cloudwatchlogs output. The
+// compressed files last modified before are not collected, and their older log events are skipped.
+const maxCompressedLogAge = 14 * 24 * time.Hour
+
+// decompressors of the rotated log files which can be collected with collect_compressed, the archives like .tar
+// or .zip are still skipped
+var decompressors = map[string]func(io.Reader) (io.ReadCloser, error){
+	".gz": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	},
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return zstdReadCloser{d}, nil
+	},
+}
+
+// decompressorOf returns the decompressor of the compressed file, or nil if it cannot be collected
+func decompressorOf(filename string) func(io.Reader) (io.ReadCloser, error) {
+	return decompressors[filepath.Ext(filename)]
+}
+
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (r zstdReadCloser) Close() error {
+	r.Decoder.Close()
+	return nil
+}
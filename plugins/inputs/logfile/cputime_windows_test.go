@@ -0,0 +1,23 @@
+// +build windows
+
+package logfile
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and kernel CPU time used by the process
+func processCPUTime() time.Duration {
+	var creation, exit, kernel, user syscall.Filetime
+	h, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0
+	}
+	if err := syscall.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+		return 0
+	}
+	// the Filetimes of the CPU times are durations in 100ns
+	return time.Duration((int64(kernel.HighDateTime)<<32+int64(kernel.LowDateTime))*100 +
+		(int64(user.HighDateTime)<<32+int64(user.LowDateTime))*100)
+}
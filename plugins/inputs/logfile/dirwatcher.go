@@ -0,0 +1,207 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/globpath"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail/watch"
+	"github.com/influxdata/telegraf"
+	"gopkg.in/fsnotify.v1"
+)
+
+const (
+	// fileWatcherPolling matches the file_path globs every second and polls the files tailed for changes
+	fileWatcherPolling = "polling"
+	// fileWatcherInotify only matches the file_path globs again when their directories change, and watches the
+	// files tailed with inotify. The directories and files on file systems like NFS or overlay are still polled.
+	fileWatcherInotify = "inotify"
+)
+
+// the file_path globs are matched again at this interval in case an inotify event was missed, e.g. when the
+// inotify queue overflowed
+var dirRescanInterval = time.Minute
+
+// dirWatcher watches the directories of the file_path globs with inotify, so a glob is only matched again when
+// an entry of its directories is created, renamed or removed instead of every second.
+type dirWatcher struct {
+	watcher *fsnotify.Watcher
+	log     telegraf.Logger
+
+	mu       sync.Mutex
+	dirs     map[string]map[*FileConfig]bool // the file configs of each directory watched
+	configs  map[*FileConfig][]string        // the directories watched of each file config
+	polled   map[*FileConfig]bool            // the file configs whose directories cannot all be watched
+	changed  map[*FileConfig]bool
+	lastScan map[*FileConfig]time.Time
+}
+
+func newDirWatcher(log telegraf.Logger) (*dirWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &dirWatcher{
+		watcher:  watcher,
+		log:      log,
+		dirs:     make(map[string]map[*FileConfig]bool),
+		configs:  make(map[*FileConfig][]string),
+		polled:   make(map[*FileConfig]bool),
+		changed:  make(map[*FileConfig]bool),
+		lastScan: make(map[*FileConfig]time.Time),
+	}, nil
+}
+
+// run marks the file configs as changed on the events of their directories until done is closed
+func (w *dirWatcher) run(done chan struct{}) {
+	defer w.watcher.Close()
+	for {
+		select {
+		case evt, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.onEvent(evt)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			// some events may have been lost, all the globs are matched again
+			w.log.Warnf("Error watching the directories of the log files: %v", err)
+			w.mu.Lock()
+			for fileconfig := range w.configs {
+				w.changed[fileconfig] = true
+			}
+			w.mu.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (w *dirWatcher) onEvent(evt fsnotify.Event) {
+	name := filepath.Clean(evt.Name)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.dirs[name]; ok && evt.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		// the watch of a removed directory is gone, it is watched again if it is recreated
+		for fileconfig := range w.dirs[name] {
+			w.changed[fileconfig] = true
+		}
+		delete(w.dirs, name)
+		return
+	}
+	for fileconfig := range w.dirs[filepath.Dir(name)] {
+		// a write may make another file the most recently modified one, which is the only one tailed
+		// without publish_multi_logs
+		if evt.Op&(fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 ||
+			evt.Op&fsnotify.Write != 0 && !fileconfig.PublishMultiLogs {
+			w.changed[fileconfig] = true
+		}
+	}
+}
+
+// needsScan returns whether the glob of the file config has to be matched again
+func (w *dirWatcher) needsScan(fileconfig *FileConfig) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lastScan, ok := w.lastScan[fileconfig]
+	if !ok || w.polled[fileconfig] || w.changed[fileconfig] || time.Since(lastScan) >= dirRescanInterval {
+		// the events received while the glob is matched are not missed
+		w.changed[fileconfig] = false
+		return true
+	}
+	return false
+}
+
+// changedConfig marks the file config as changed, e.g. when one of its tailers stopped
+func (w *dirWatcher) changedConfig(fileconfig *FileConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.changed[fileconfig] = true
+}
+
+// watchDirs watches the current directories of the glob of the file config before it is matched, the glob keeps
+// being matched every second if one of its directories cannot be watched.
+func (w *dirWatcher) watchDirs(fileconfig *FileConfig) {
+	var dirs []string
+	g, polledErr := globpath.Compile(fileconfig.FilePath)
+	if polledErr == nil {
+		dirs = g.Dirs()
+	}
+	for _, dir := range dirs {
+		if !watch.SupportsInotify(dir) {
+			polledErr = fmt.Errorf("the changes of %v are not reported by inotify", dir)
+			break
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastScan[fileconfig] = time.Now()
+	previous := w.configs[fileconfig]
+	w.configs[fileconfig] = nil
+	if polledErr == nil {
+		for _, dir := range dirs {
+			if polledErr = w.add(fileconfig, dir); polledErr != nil {
+				break
+			}
+		}
+	}
+	if polledErr != nil {
+		if !w.polled[fileconfig] {
+			w.log.Infof("Matching %v every second instead of watching its directories: %v", fileconfig.FilePath, polledErr)
+		}
+		// the directories do not need to be watched while the glob is matched every second
+		previous = append(previous, w.configs[fileconfig]...)
+		w.configs[fileconfig] = nil
+	}
+	w.polled[fileconfig] = polledErr != nil
+	w.release(fileconfig, previous)
+}
+
+func (w *dirWatcher) add(fileconfig *FileConfig, dir string) error {
+	if _, ok := w.dirs[dir]; !ok {
+		if err := w.watcher.Add(dir); err != nil {
+			return fmt.Errorf("unable to watch %v: %v", dir, err)
+		}
+		w.dirs[dir] = make(map[*FileConfig]bool)
+	}
+	w.dirs[dir][fileconfig] = true
+	w.configs[fileconfig] = append(w.configs[fileconfig], dir)
+	return nil
+}
+
+// release stops watching the directories previously watched for the file config which it does not need anymore,
+// the directories of no file config are not watched anymore
+func (w *dirWatcher) release(fileconfig *FileConfig, previous []string) {
+	for _, dir := range previous {
+		if containsString(w.configs[fileconfig], dir) {
+			continue
+		}
+		configs, ok := w.dirs[dir]
+		if !ok {
+			// the directory was removed
+			continue
+		}
+		delete(configs, fileconfig)
+		if len(configs) == 0 {
+			delete(w.dirs, dir)
+			w.watcher.Remove(dir)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
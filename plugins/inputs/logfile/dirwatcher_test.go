@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	if !watch.SupportsInotify(dir) {
+		t.Skipf("the changes of %v are not reported by inotify", dir)
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.log"), []byte("line 1\n"), 0644))
+
+	w, err := newDirWatcher(TestLogger{t})
+	require.NoError(t, err)
+	done := make(chan struct{})
+	defer close(done)
+	go w.run(done)
+
+	multi := &FileConfig{FilePath: filepath.Join(dir, "*.log"), PublishMultiLogs: true}
+	single := &FileConfig{FilePath: filepath.Join(dir, "*.log")}
+	missing := &FileConfig{FilePath: filepath.Join(dir, "missing", "*.log")}
+	for _, fileconfig := range []*FileConfig{multi, single, missing} {
+		assert.True(t, w.needsScan(fileconfig), "the glob %v was never matched", fileconfig.FilePath)
+		w.watchDirs(fileconfig)
+	}
+	assert.False(t, w.needsScan(multi))
+	assert.False(t, w.needsScan(single))
+	// the directory which does not exist cannot be watched, its glob is matched every second
+	assert.True(t, w.needsScan(missing))
+
+	// a write only matters when the most recently modified file is tailed
+	f, err := os.OpenFile(filepath.Join(dir, "a.log"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("line 2\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Eventually(t, func() bool { return w.needsScan(single) }, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, w.needsScan(multi))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.log"), []byte("line 1\n"), 0644))
+	assert.Eventually(t, func() bool { return w.needsScan(multi) }, 5*time.Second, 10*time.Millisecond)
+
+	// the directory is watched once it is created
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "missing"), 0755))
+	w.watchDirs(missing)
+	assert.False(t, w.needsScan(missing))
+	assert.Equal(t, []string{filepath.Join(dir, "missing")}, w.configs[missing])
+
+	// the directories of no file config are not watched anymore
+	multi.FilePath = filepath.Join(dir, "missing", "*.log")
+	w.watchDirs(multi)
+	single.FilePath = filepath.Join(dir, "missing", "*.log")
+	w.watchDirs(single)
+	assert.NotContains(t, w.dirs, dir)
+	assert.Len(t, w.dirs[filepath.Join(dir, "missing")], 3)
+}
@@ -0,0 +1,122 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	internalaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/ec2tagger"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	// the waits before the retrievals of the EC2 Instance Tags, the log events stop waiting for them after the last one
+	ec2TagsRetryIntervals = ec2tagger.BackoffSleepArray
+
+	newEC2Metadata = func() ec2Metadata {
+		return ec2metadata.New((&internalaws.CredentialConfig{}).Credentials())
+	}
+	newEC2 = func(region string) ec2iface.EC2API {
+		return ec2.New((&internalaws.CredentialConfig{Region: region}).Credentials())
+	}
+)
+
+type ec2Metadata interface {
+	Available() bool
+	GetInstanceIdentityDocument() (ec2metadata.EC2InstanceIdentityDocument, error)
+}
+
+// ec2Tags are the EC2 Instance Tags and Metadata referenced by the {ec2_tag:<key>} placeholders of the log group
+// and stream names. The log events routed with them wait until they are retrieved, or until the retries are exhausted.
+type ec2Tags struct {
+	tags      map[string]string // written until retrieved is closed
+	retrieved chan struct{}     // closed once the tags are retrieved or the retries are exhausted
+	done      <-chan struct{}
+}
+
+// startEC2Tags retrieves the EC2 Metadata, and the EC2 Instance Tags of the keys in the background until done is
+// closed. It fails when the agent is not running on an EC2 instance.
+func startEC2Tags(keys []string, done <-chan struct{}, log telegraf.Logger) (*ec2Tags, error) {
+	md := newEC2Metadata()
+	if !md.Available() {
+		return nil, errors.New("the {ec2_tag:<key>} placeholders of the log group and stream names require an EC2 instance")
+	}
+	doc, err := md.GetInstanceIdentityDocument()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the EC2 Metadata of the {ec2_tag:<key>} placeholders: %v", err)
+	}
+
+	t := &ec2Tags{
+		tags: map[string]string{
+			ec2tagger.MdKeyInstanceId:   doc.InstanceID,
+			ec2tagger.MdKeyImageId:      doc.ImageID,
+			ec2tagger.MdKeyInstanceType: doc.InstanceType,
+		},
+		retrieved: make(chan struct{}),
+		done:      done,
+	}
+	var tagKeys []string
+	for _, key := range keys {
+		if _, ok := t.tags[key]; !ok {
+			tagKeys = append(tagKeys, key)
+		}
+	}
+	if len(tagKeys) == 0 {
+		close(t.retrieved)
+		return t, nil
+	}
+
+	go t.retrieve(newEC2(doc.Region), ec2tagger.TagFilters(doc.InstanceID, tagKeys), ec2TagsRetryIntervals, log)
+	return t, nil
+}
+
+// retrieve describes the EC2 Instance Tags after each of the retry intervals until it succeeds. The placeholders of
+// the tags are replaced by their default once the retries are exhausted, rather than holding the log events.
+func (t *ec2Tags) retrieve(client ec2iface.EC2API, filters []*ec2.Filter, retryIntervals []time.Duration, log telegraf.Logger) {
+	defer close(t.retrieved)
+	var err error
+	for _, wait := range retryIntervals {
+		timer := time.NewTimer(wait)
+		select {
+		case <-t.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		var tags map[string]string
+		if tags, err = ec2tagger.DescribeTags(client, filters); err != nil {
+			log.Warnf("Unable to retrieve the EC2 Instance Tags of the log group and stream names, it is retried: %v", err)
+			continue
+		}
+		for k, v := range tags {
+			t.tags[k] = v
+		}
+		log.Infof("Retrieved the EC2 Instance Tags of the log group and stream names")
+		return
+	}
+	log.Errorf("Unable to retrieve the EC2 Instance Tags of the log group and stream names after %d attempts, their placeholders are replaced by their default: %v", len(retryIntervals), err)
+}
+
+// get returns the EC2 Instance Tag or Metadata once they are retrieved, it returns false when the key is not found,
+// the retrieval failed or the plugin stops first
+func (t *ec2Tags) get(key string) (string, bool) {
+	select {
+	case <-t.retrieved:
+	case <-t.done:
+		select {
+		case <-t.retrieved:
+		default:
+			return "", false
+		}
+	}
+	v, ok := t.tags[key]
+	return v, ok
+}
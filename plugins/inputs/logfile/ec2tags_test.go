@@ -0,0 +1,154 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/ec2tagger"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEC2Metadata struct {
+	available bool
+}
+
+func (m *mockEC2Metadata) Available() bool {
+	return m.available
+}
+
+func (m *mockEC2Metadata) GetInstanceIdentityDocument() (ec2metadata.EC2InstanceIdentityDocument, error) {
+	return ec2metadata.EC2InstanceIdentityDocument{InstanceID: "i-123", ImageID: "ami-123", InstanceType: "m5.large", Region: "us-east-1"}, nil
+}
+
+// mockEC2Client fails the first DescribeTags calls, it blocks them until release is closed. The tags are returned in
+// two pages.
+type mockEC2Client struct {
+	ec2iface.EC2API
+	failures int
+	release  chan struct{}
+	inputs   chan *ec2.DescribeTagsInput
+}
+
+func (m *mockEC2Client) DescribeTags(input *ec2.DescribeTagsInput) (*ec2.DescribeTagsOutput, error) {
+	<-m.release
+	if input.NextToken != nil {
+		return &ec2.DescribeTagsOutput{Tags: []*ec2.TagDescription{
+			{Key: aws.String(ec2tagger.EC2InstanceTagKeyASG), Value: aws.String("asg-1")},
+		}}, nil
+	}
+	m.inputs <- input
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("throttled")
+	}
+	return &ec2.DescribeTagsOutput{
+		NextToken: aws.String("page-2"),
+		Tags: []*ec2.TagDescription{
+			{Key: aws.String("Name"), Value: aws.String("web")},
+		},
+	}, nil
+}
+
+// mockEC2 replaces the EC2 clients, it returns the function restoring them
+func mockEC2(t *testing.T, available bool, client *mockEC2Client) func() {
+	md, ec2Client, intervals := newEC2Metadata, newEC2, ec2TagsRetryIntervals
+	newEC2Metadata = func() ec2Metadata { return &mockEC2Metadata{available: available} }
+	newEC2 = func(region string) ec2iface.EC2API {
+		assert.Equal(t, "us-east-1", region)
+		return client
+	}
+	ec2TagsRetryIntervals = []time.Duration{0, 10 * time.Millisecond}
+	return func() {
+		newEC2Metadata, newEC2, ec2TagsRetryIntervals = md, ec2Client, intervals
+	}
+}
+
+func TestEC2Tags_NotEC2(t *testing.T) {
+	defer mockEC2(t, false, nil)()
+	_, err := startEC2Tags([]string{"Name"}, make(chan struct{}), testutil.Logger{})
+	assert.Error(t, err)
+}
+
+func TestEC2Tags_Metadata(t *testing.T) {
+	defer mockEC2(t, true, nil)()
+	tags, err := startEC2Tags([]string{ec2tagger.MdKeyInstanceId, ec2tagger.MdKeyInstanceType}, make(chan struct{}), testutil.Logger{})
+	require.NoError(t, err)
+
+	v, ok := tags.get(ec2tagger.MdKeyInstanceId)
+	assert.True(t, ok)
+	assert.Equal(t, "i-123", v)
+	v, _ = tags.get(ec2tagger.MdKeyInstanceType)
+	assert.Equal(t, "m5.large", v)
+}
+
+func TestEC2Tags_InstanceTags(t *testing.T) {
+	client := &mockEC2Client{failures: 1, release: make(chan struct{}), inputs: make(chan *ec2.DescribeTagsInput, 2)}
+	defer mockEC2(t, true, client)()
+	tags, err := startEC2Tags([]string{"Name", ec2tagger.CWDimensionASG, ec2tagger.MdKeyImageId}, make(chan struct{}), testutil.Logger{})
+	require.NoError(t, err)
+
+	// the tags are not returned until they are retrieved
+	got := make(chan string)
+	go func() {
+		v, _ := tags.get("Name")
+		got <- v
+	}()
+	select {
+	case v := <-got:
+		t.Fatalf("the tag %v was returned before it was retrieved", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the retrieval is retried until it succeeds
+	close(client.release)
+	assert.Equal(t, "web", <-got)
+	input := <-client.inputs
+	assert.Equal(t, []string{"i-123"}, aws.StringValueSlice(input.Filters[1].Values))
+	assert.Equal(t, []string{"Name", ec2tagger.EC2InstanceTagKeyASG}, aws.StringValueSlice(input.Filters[2].Values))
+	assert.Len(t, client.inputs, 1)
+
+	v, _ := tags.get(ec2tagger.CWDimensionASG)
+	assert.Equal(t, "asg-1", v)
+	v, _ = tags.get(ec2tagger.MdKeyImageId)
+	assert.Equal(t, "ami-123", v)
+	_, ok := tags.get("Missing")
+	assert.False(t, ok)
+}
+
+func TestEC2Tags_Stopped(t *testing.T) {
+	client := &mockEC2Client{release: make(chan struct{}), inputs: make(chan *ec2.DescribeTagsInput, 1)}
+	defer mockEC2(t, true, client)()
+	done := make(chan struct{})
+	tags, err := startEC2Tags([]string{"Name"}, done, testutil.Logger{})
+	require.NoError(t, err)
+
+	close(done)
+	_, ok := tags.get("Name")
+	assert.False(t, ok)
+	close(client.release)
+}
+
+func TestEC2Tags_RetriesExhausted(t *testing.T) {
+	client := &mockEC2Client{failures: 2, release: make(chan struct{}), inputs: make(chan *ec2.DescribeTagsInput, 2)}
+	defer mockEC2(t, true, client)()
+	close(client.release)
+	tags, err := startEC2Tags([]string{"Name"}, make(chan struct{}), testutil.Logger{})
+	require.NoError(t, err)
+
+	// the log events stop waiting for the tags, their placeholders are replaced by their default
+	_, ok := tags.get("Name")
+	assert.False(t, ok)
+	assert.Len(t, client.inputs, 2)
+	v, _ := tags.get(ec2tagger.MdKeyInstanceId)
+	assert.Equal(t, "i-123", v)
+}
@@ -12,6 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/logprocessor"
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
+	"github.com/aws/amazon-cloudwatch-agent/logs/parser"
 	"golang.org/x/net/html/charset"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/ianaindex"
@@ -36,6 +40,9 @@ type FileConfig struct {
 	LogGroupName string `toml:"log_group_name"`
 	//log stream name
 	LogStreamName string `toml:"log_stream_name"`
+	//The regex matched against the file name, its groups are referenced by the {file_path:<group>} placeholders
+	//of the log group and stream names
+	FilePathRegex string `toml:"file_path_regex"`
 
 	//The regex of the timestampFromLogLine presents in the log entry
 	TimestampRegex string `toml:"timestamp_regex"`
@@ -60,6 +67,8 @@ type FileConfig struct {
 	FromBeginning bool `toml:"from_beginning"`
 	//Indicate whether it is a named pipe.
 	Pipe bool `toml:"pipe"`
+	//Indicate whether to collect, once, the rotated .gz, .bz2 and .zst files matching the file path instead of skipping them.
+	CollectCompressed bool `toml:"collect_compressed"`
 
 	//Indicate logType for scroll
 	LogType string `toml:"log_type"`
@@ -75,6 +84,15 @@ type FileConfig struct {
 
 	//Indicate retention in days for log group
 	RetentionInDays int `toml:"retention_in_days"`
+
+	//The parser of the log events into fields, e.g. to find their timestamp or publish them as JSON
+	Parser parser.Config `toml:"parser"`
+
+	//The processing chain applied on the log events in order before they are published
+	Processors []logprocessor.Config `toml:"processors"`
+
+	//The metrics derived from the log events after the processing chain
+	MetricExtractors []MetricExtractorConfig `toml:"metric_extractors"`
 	//Time *time.Location Go type timezone info.
 	TimezoneLoc *time.Location
 	//Regexp go type timestampFromLogLine regex
@@ -83,8 +101,18 @@ type FileConfig struct {
 	MultiLineStartPatternP *regexp.Regexp
 	//Regexp go type blacklist regex
 	BlacklistRegexP *regexp.Regexp
+	//Regexp go type file path regex
+	FilePathRegexP *regexp.Regexp
 	//Decoder object
 	Enc encoding.Encoding
+	//The log processors created from the processors config
+	LogProcessors []logs.LogProcessor
+	//The metric extractors created from the metric_extractors config
+	metricExtractors []*metricExtractor
+	//The log parser created from the parser config
+	logParser *parser.Parser
+	//The keys of the {ec2_tag:<key>} placeholders of the log group and stream names
+	ec2TagKeys []string
 }
 
 //Initialize some variables in the FileConfig object based on the rest info fetched from the configuration file.
@@ -133,6 +161,26 @@ func (config *FileConfig) init() error {
 		}
 	}
 
+	if config.FilePathRegex != "" {
+		if config.FilePathRegexP, err = regexp.Compile(config.FilePathRegex); err != nil {
+			return fmt.Errorf("file_path_regex has issue, regexp: Compile( %v ): %v", config.FilePathRegex, err.Error())
+		}
+	}
+	groupTemplate, err := newNameTemplate(config.LogGroupName, config.FilePathRegexP, nametemplate.InvalidLogGroupNameChars)
+	if err != nil {
+		return fmt.Errorf("log_group_name has issue: %v", err)
+	}
+	streamTemplate, err := newNameTemplate(config.LogStreamName, config.FilePathRegexP, nametemplate.InvalidLogStreamNameChars)
+	if err != nil {
+		return fmt.Errorf("log_stream_name has issue: %v", err)
+	}
+	config.ec2TagKeys = append(groupTemplate.Keys(placeholderEC2Tag), streamTemplate.Keys(placeholderEC2Tag)...)
+
+	//The compressed files are collected once by their fingerprint, which a named pipe does not have.
+	if config.Pipe && config.CollectCompressed {
+		return fmt.Errorf("collect_compressed is not supported with pipe")
+	}
+
 	if config.MaxEventSize == 0 {
 		config.MaxEventSize = defaultMaxEventSize
 	}
@@ -144,9 +192,43 @@ func (config *FileConfig) init() error {
 		config.RetentionInDays = -1
 	}
 
+	if config.Parser.Type != "" {
+		if config.logParser, err = parser.New(config.Parser, config.TimezoneLoc); err != nil {
+			return fmt.Errorf("parser has issue: %v", err)
+		}
+	}
+
+	if len(config.Processors) > 0 {
+		if config.LogProcessors, err = logprocessor.New(config.Processors); err != nil {
+			return fmt.Errorf("processors has issue: %v", err)
+		}
+	}
+
+	for i, c := range config.MetricExtractors {
+		e, err := newMetricExtractor(c)
+		if err != nil {
+			return fmt.Errorf("metric extractor %d (%v) has issue: %v", i, c.MetricName, err)
+		}
+		config.metricExtractors = append(config.metricExtractors, e)
+	}
+
 	return nil
 }
 
+//Validate checks the FileConfig the same way as the plugin does when it starts, the FileConfig is not changed.
+func (config FileConfig) Validate() error {
+	return config.init()
+}
+
+//The processing chain of the log events, the metric extractors run last to see the published log events.
+func (config *FileConfig) logProcessors() []logs.LogProcessor {
+	processors := append([]logs.LogProcessor{}, config.LogProcessors...)
+	for _, e := range config.metricExtractors {
+		processors = append(processors, e)
+	}
+	return processors
+}
+
 //The default log group name calculation logic if the log group name is not specified.
 //It will use the part before the last dot in the file path, e.g.
 // file path: "/tmp/TestLogFile.log.2017-07-11-14" -> log group name: "/tmp/TestLogFile.log"
@@ -180,17 +262,7 @@ func (config *FileConfig) timestampFromLogLine(logValue string) time.Time {
 			log.Printf("E! Error parsing timestampFromLogLine: %s", err)
 			return time.Time{}
 		}
-		if timestamp.Year() == 0 {
-			now := time.Now()
-			timestamp = timestamp.AddDate(now.Year(), 0, 0)
-			// If now is very early January and we are pushing logs from very late
-			// December, there will be a very large number of hours different
-			// between the dates. 30 * 24 hours will be sufficient.
-			if timestamp.Sub(now) > 30*24*time.Hour {
-				timestamp = timestamp.AddDate(-1, 0, 0)
-			}
-		}
-		return timestamp
+		return parser.WithCurrentYear(timestamp)
 	}
 	return time.Time{}
 }
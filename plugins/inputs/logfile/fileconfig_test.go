@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/logs/logprocessor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +67,55 @@ func TestFileConfigInitFailureCase(t *testing.T) {
 	err = fileConfig.init()
 	assert.Error(t, err)
 	assert.Equal(t, "multi_line_start_pattern has issue, regexp: Compile( (\\d{2} \\w{3} \\d{4} \\d{2}:\\d{2}:\\d{2}+) ): error parsing regexp: invalid nested repetition operator: `{2}+`", err.Error())
+
+	fileConfig = &FileConfig{
+		FilePath:          "/tmp/logfile*",
+		LogGroupName:      "logfile",
+		Pipe:              true,
+		CollectCompressed: true,
+	}
+	err = fileConfig.init()
+	assert.EqualError(t, err, "collect_compressed is not supported with pipe")
+}
+
+func TestFileConfigInitProcessors(t *testing.T) {
+	fileConfig := &FileConfig{
+		FilePath: "/tmp/logfile.log",
+		Processors: []logprocessor.Config{
+			{Type: logprocessor.TypeDrop, Pattern: "DEBUG"},
+			{Type: logprocessor.TypeMask, Preset: logprocessor.PresetAWSAccessKey},
+		},
+	}
+	require.NoError(t, fileConfig.init())
+	assert.Equal(t, 2, len(fileConfig.LogProcessors))
+
+	fileConfig = &FileConfig{
+		FilePath:   "/tmp/logfile.log",
+		Processors: []logprocessor.Config{{Type: logprocessor.TypeDrop, Pattern: "("}},
+	}
+	err := fileConfig.init()
+	assert.Error(t, err)
+	assert.Equal(t, "processors has issue: processor 0 (drop) has issue: pattern regex has issue, regexp: Compile( ( ): error parsing regexp: missing closing ): `(`", err.Error())
+}
+
+func TestFileConfigInitMetricExtractors(t *testing.T) {
+	fileConfig := &FileConfig{
+		FilePath:         "/tmp/logfile.log",
+		Processors:       []logprocessor.Config{{Type: logprocessor.TypeDrop, Pattern: "DEBUG"}},
+		MetricExtractors: []MetricExtractorConfig{{MetricName: "errors", Pattern: "ERROR"}},
+	}
+	require.NoError(t, fileConfig.init())
+	processors := fileConfig.logProcessors()
+	assert.Equal(t, 2, len(processors))
+	assert.Equal(t, fileConfig.metricExtractors[0], processors[1])
+
+	fileConfig = &FileConfig{
+		FilePath:         "/tmp/logfile.log",
+		MetricExtractors: []MetricExtractorConfig{{MetricName: "errors", MetricType: "summary"}},
+	}
+	err := fileConfig.init()
+	assert.Error(t, err)
+	assert.Equal(t, "metric extractor 0 (errors) has issue: metric_type summary is not supported", err.Error())
 }
 
 func TestLogGroupName(t *testing.T) {
@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
+)
+
+// the state files of the fingerprinted files are named after the device and inode of the file instead of its name,
+// so the offset of a file follows it when it is renamed by the log rotation
+const fingerprintStateFilePrefix = "fingerprint_"
+
+// the last line of the state of a compressed file which was completely uploaded
+const fileStateComplete = "complete"
+
+// fileState is the content of a state file: the offset uploaded, the name of the file when it was tailed and,
+// since the state files are keyed by fingerprint, the fingerprint of the file. The state of a compressed file,
+// whose offset is in its decompressed content, also records when it was completely uploaded.
+type fileState struct {
+	offset      int64
+	filename    string
+	fingerprint *tail.Fingerprint
+	complete    bool
+}
+
+func (s fileState) content() []byte {
+	content := strconv.FormatInt(s.offset, 10) + "\n" + s.filename
+	if s.fingerprint != nil || s.complete {
+		content += "\n"
+		if s.fingerprint != nil {
+			content += s.fingerprint.String()
+		}
+	}
+	if s.complete {
+		content += "\n" + fileStateComplete
+	}
+	return []byte(content)
+}
+
+func readFileState(stateFilePath string) (fileState, error) {
+	var s fileState
+	byteArray, err := ioutil.ReadFile(stateFilePath)
+	if err != nil {
+		return s, err
+	}
+
+	lines := strings.Split(string(byteArray), "\n")
+	if s.offset, err = strconv.ParseInt(lines[0], 10, 64); err != nil {
+		return s, fmt.Errorf("invalid offset value %v: %v", lines[0], err)
+	}
+	if len(lines) >= 2 {
+		s.filename = lines[1]
+	}
+	if len(lines) >= 3 && lines[2] != "" {
+		if s.fingerprint, err = tail.ParseFingerprint(lines[2]); err != nil {
+			return s, err
+		}
+	}
+	s.complete = len(lines) >= 4 && lines[3] == fileStateComplete
+	return s, nil
+}
+
+func writeFileState(stateFilePath string, s fileState) error {
+	return ioutil.WriteFile(stateFilePath, s.content(), stateFileMode)
+}
+
+// stateMatchesFile returns whether the state was saved for the content of the named file
+func stateMatchesFile(s fileState, filename string) bool {
+	if s.fingerprint == nil {
+		return false
+	}
+	matches, err := s.fingerprint.MatchesFile(filename)
+	return err == nil && matches
+}
+
+func fingerprintStateFileName(fingerprint *tail.Fingerprint) string {
+	return fmt.Sprintf("%s%d_%d", fingerprintStateFilePrefix, fingerprint.Dev, fingerprint.Ino)
+}
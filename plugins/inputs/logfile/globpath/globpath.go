@@ -76,6 +76,42 @@ func (g *GlobPath) Match() map[string]os.FileInfo {
 	return walkFilePath(g.root, g.g)
 }
 
+// Dirs returns the directories whose entries may be matched by the glob, a file created, renamed or
+// removed in one of them may change the files matched. The static root directory of the glob is
+// returned even if it does not exist yet.
+func (g *GlobPath) Dirs() []string {
+	if !g.hasMeta {
+		return []string{filepath.Dir(g.path)}
+	}
+	root := findRootDir(g.path)
+	dirs := []string{root}
+	if g.hasSuperMeta {
+		// the files are matched at any depth under the root directory
+		filepath.Walk(root, func(path string, info os.FileInfo, _ error) error {
+			if info != nil && info.IsDir() && path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		return dirs
+	}
+	// e.g. /var/log/*/app/*.log -> /var/log, /var/log/* and /var/log/*/app
+	prefix := root
+	for _, item := range strings.Split(strings.TrimPrefix(filepath.Dir(g.path), root), sepStr) {
+		if item == "" {
+			continue
+		}
+		prefix = filepath.Join(prefix, item)
+		matches, _ := filepath.Glob(prefix)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+	return dirs
+}
+
 // walk the filepath from the given root and return a list of files that match
 // the given glob.
 func walkFilePath(root string, g glob.Glob) map[string]os.FileInfo {
@@ -68,6 +68,26 @@ func TestFindNestedTextFile(t *testing.T) {
 	assert.Len(t, matches, 1)
 }
 
+func TestDirs(t *testing.T) {
+	dir := getTestdataDir()
+	tests := []struct {
+		input  string
+		output []string
+	}{
+		{dir + "/log1.log", []string{dir}},
+		{dir + "/*.log", []string{dir}},
+		{dir + "/*/nested2/*.txt", []string{dir, dir + "/nested1", dir + "/nested1/nested2"}},
+		{dir + "/**.txt", []string{dir, dir + "/nested1", dir + "/nested1/nested2"}},
+		{dir + "/dir_doesnt_exist/*.log", []string{dir + "/dir_doesnt_exist"}},
+	}
+
+	for _, test := range tests {
+		g, err := Compile(test.input)
+		require.NoError(t, err)
+		assert.Equal(t, test.output, g.Dirs(), test.input)
+	}
+}
+
 func getTestdataDir() string {
 	_, filename, _, _ := runtime.Caller(1)
 	return strings.Replace(filename, "globpath_test.go", "testdata", 1)
@@ -10,12 +10,12 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent/internal/logscommon"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/globpath"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
 	"github.com/influxdata/telegraf"
@@ -29,10 +29,17 @@ type LogFile struct {
 	FileStateFolder string `toml:"file_state_folder"`
 	//destination
 	Destination string `toml:"destination"`
+	//tags added to the metrics extracted from the log events
+	MetricTags map[string]string `toml:"metric_tags"`
+	//how the new files and the changes of the files tailed are detected, "polling" (default) or "inotify"
+	FileWatcher string `toml:"file_watcher"`
 
 	Log telegraf.Logger `toml:"-"`
 
 	configs           map[*FileConfig]map[string]*tailerSrc
+	collectedFiles    map[string]bool // fingerprints of the compressed files collected
+	dirWatcher        *dirWatcher     // only set with the inotify file watcher
+	ec2Tags           *ec2Tags        // only set when the log group or stream names have {ec2_tag:<key>} placeholders
 	done              chan struct{}
 	removeTailerSrcCh chan *tailerSrc
 	started           bool
@@ -41,6 +48,7 @@ type LogFile struct {
 func NewLogFile() *LogFile {
 	return &LogFile{
 		configs:           make(map[*FileConfig]map[string]*tailerSrc),
+		collectedFiles:    make(map[string]bool),
 		done:              make(chan struct{}),
 		removeTailerSrcCh: make(chan *tailerSrc, 100),
 	}
@@ -63,6 +71,15 @@ const sampleConfig = `
   ## folder path where state of how much of a file has been transferred is stored
   file_state_folder = "/tmp/logfile/state"
 
+  ## How the new files and the changes of the files tailed are detected, "polling" (default) or "inotify".
+  ## With inotify the file paths are only matched again when their directories change, the directories and
+  ## files on file systems like NFS or overlay are still polled.
+  # file_watcher = "polling"
+
+  ## tags added to the metrics extracted from the log events
+  # [inputs.logs.metric_tags]
+  #   metricPath = "metrics"
+
   [[inputs.logs.file_config]]
       file_path = "/tmp/logfile.log*"
       ## Regular expression for log files to ignore
@@ -71,6 +88,9 @@ const sampleConfig = `
       publish_multi_logs = false
       log_group_name = "logfile.log"
       log_stream_name = "<log_stream_name>"
+      ## Regular expression matched against the file name, its groups are referenced
+      ## by the {file_path:<group>} placeholders of the log group and stream names
+      # file_path_regex = "/tmp/(?P<app>[^/.]+)"
       publish_multi_logs = false
       timestamp_regex = "^(\\d{2} \\w{3} \\d{4} \\d{2}:\\d{2}:\\d{2}).*$"
       timestamp_layout = "02 Jan 2006 15:04:05"
@@ -80,11 +100,57 @@ const sampleConfig = `
       from_beginning = false
       ## Whether file is a named pipe
       pipe = false
+      ## Collect once the rotated log files compressed with gzip, bzip2 or zstd (.gz, .bz2, .zst)
+      # collect_compressed = false
       destination = "cloudwatchlogs"
       ## Max size of each log event, defaults to 262144 (256KB)
       max_event_size = 262144
       ## Suffix to be added to truncated logline to indicate its truncation, defaults to "[Truncated...]"
       truncate_suffix = "[Truncated...]"
+      ## Parse the log events into fields with the "json", "logfmt", "regex", "syslog_rfc3164",
+      ## "syslog_rfc5424" or "clf" parser, the fields are used by the metric extractors and name templates
+      # [inputs.logs.file_config.parser]
+      #   type = "regex"
+      #   ## The named groups of the pattern are the fields of the regex parser
+      #   pattern = "^(?P<time>\\S+ \\S+) (?P<level>\\w+) (?P<message>.*)$"
+      #   ## The field and layout of the log event timestamp, timestamp_regex is used when it is not found
+      #   timestamp_field = "time"
+      #   timestamp_layout = "2006-01-02 15:04:05"
+      #   ## Publish the log events as they are read ("raw", default) or their fields as JSON ("json")
+      #   output_format = "json"
+      ## Processing chain applied in order on each log event before it is published
+      [[inputs.logs.file_config.processors]]
+        ## Drop the log events matching the pattern
+        type = "drop"
+        pattern = "DEBUG"
+      [[inputs.logs.file_config.processors]]
+        ## Replace the content matching the pattern, or one of the presets:
+        ## "credit_card", "aws_access_key" or "aws_secret_key"
+        type = "mask"
+        preset = "credit_card"
+        replacement = "****"
+      [[inputs.logs.file_config.processors]]
+        ## Add static fields, non JSON log events are wrapped into {"message": ...}
+        type = "add_fields"
+        [inputs.logs.file_config.processors.fields]
+          env = "prod"
+      [[inputs.logs.file_config.processors]]
+        ## Rename and remove keys of JSON log events
+        type = "json"
+        remove_keys = ["password"]
+        [inputs.logs.file_config.processors.rename_keys]
+          msg = "message"
+      [[inputs.logs.file_config.metric_extractors]]
+        ## Derive a metric from the log events, metric_type is "counter" (default), "gauge" or "timing"
+        metric_name = "http_request_latency"
+        metric_type = "timing"
+        ## Only the log events matching the pattern are used, value and dimensions reference
+        ## its named groups, or JSON paths of the log event like "$.request.status"
+        pattern = "status=(?P<status>\\d+) latency=(?P<latency>\\d+)ms"
+        value = "latency"
+        unit = "Milliseconds"
+        [inputs.logs.file_config.metric_extractors.dimensions]
+          Status = "status"
 
 `
 
@@ -97,6 +163,14 @@ func (t *LogFile) Description() string {
 }
 
 func (t *LogFile) Gather(acc telegraf.Accumulator) error {
+	if !t.started {
+		return nil
+	}
+	for i := range t.FileConfig {
+		for _, e := range t.FileConfig[i].metricExtractors {
+			e.collect(acc, t.MetricTags)
+		}
+	}
 	return nil
 }
 
@@ -107,6 +181,18 @@ func (t *LogFile) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("failed to create state file directory %s: %v", t.FileStateFolder, err)
 	}
 
+	switch t.FileWatcher {
+	case "", fileWatcherPolling:
+	case fileWatcherInotify:
+		if t.dirWatcher, err = newDirWatcher(t.Log); err != nil {
+			t.Log.Warnf("Unable to watch the directories of the log files with inotify, matching the file paths every second: %v", err)
+		} else {
+			go t.dirWatcher.run(t.done)
+		}
+	default:
+		return fmt.Errorf("invalid file_watcher %v, it must be %v or %v", t.FileWatcher, fileWatcherPolling, fileWatcherInotify)
+	}
+
 	// Clean state file regularly
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
@@ -123,10 +209,17 @@ func (t *LogFile) Start(acc telegraf.Accumulator) error {
 	}()
 
 	// Initialize all the file configs
+	var ec2TagKeys []string
 	for i := range t.FileConfig {
 		if err := t.FileConfig[i].init(); err != nil {
 			return err
 		}
+		ec2TagKeys = append(ec2TagKeys, t.FileConfig[i].ec2TagKeys...)
+	}
+	if len(ec2TagKeys) > 0 {
+		if t.ec2Tags, err = startEC2Tags(ec2TagKeys, t.done, t.Log); err != nil {
+			return err
+		}
 	}
 
 	t.started = true
@@ -155,6 +248,14 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 	for i := range t.FileConfig {
 		fileconfig := &t.FileConfig[i]
 
+		if t.dirWatcher != nil && !t.dirWatcher.needsScan(fileconfig) {
+			// No file was created, renamed or removed in the directories of the file config since the last match
+			continue
+		}
+		if t.dirWatcher != nil {
+			// The directories are watched before the glob is matched so no new file is missed
+			t.dirWatcher.watchDirs(fileconfig)
+		}
 		targetFiles, err := t.getTargetFiles(fileconfig)
 		if err != nil {
 			t.Log.Errorf("Failed to find target files for file config %v, with error: %v", fileconfig.FilePath, err)
@@ -169,17 +270,40 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 
 			if _, ok := dests[filename]; ok {
 				continue
-			} else if fileconfig.AutoRemoval { // This logic means auto_removal does not work with publish_multi_logs
+			}
+
+			// Only the compressed files which can be collected are returned by getTargetFiles
+			decompress := decompressorOf(filename)
+
+			var fingerprint *tail.Fingerprint
+			if !fileconfig.Pipe {
+				if fingerprint, err = tail.FingerprintFile(filename); err != nil {
+					if decompress != nil {
+						// A compressed file is collected once, which is tracked by its fingerprint
+						t.Log.Errorf("Unable to fingerprint compressed file %v, it is not collected: %v", filename, err)
+						continue
+					}
+					t.Log.Debugf("Unable to fingerprint file %v, its state is saved by file name: %v", filename, err)
+				} else if t.isTailing(fingerprint) {
+					// The file was renamed while it is tailed, it is drained under its previous name first
+					continue
+				}
+			}
+			if decompress != nil && t.isCollected(filename, fingerprint) {
+				continue
+			}
+
+			if fileconfig.AutoRemoval && decompress == nil { // This logic means auto_removal does not work with publish_multi_logs
 				for _, dst := range dests {
 					dst.tailer.StopAtEOF() // Stop all other tailers in favor of the newly found file
 				}
 			}
 
 			var seekFile *tail.SeekInfo
-			offset, err := t.restoreState(filename)
+			offset, err := t.restoreState(filename, fingerprint)
 			if err == nil { // Missing state file would be an error too
 				seekFile = &tail.SeekInfo{Whence: io.SeekStart, Offset: offset}
-			} else if !fileconfig.Pipe && !fileconfig.FromBeginning {
+			} else if !fileconfig.Pipe && !fileconfig.FromBeginning && decompress == nil {
 				seekFile = &tail.SeekInfo{Whence: io.SeekEnd, Offset: 0}
 			}
 
@@ -191,13 +315,14 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 			tailer, err := tail.TailFile(filename,
 				tail.Config{
 					ReOpen:      false,
-					Follow:      true,
+					Follow:      decompress == nil, // A compressed file is read to its end once
 					Location:    seekFile,
 					MustExist:   true,
 					Pipe:        fileconfig.Pipe,
-					Poll:        true,
+					Poll:        t.dirWatcher == nil,
 					MaxLineSize: fileconfig.MaxEventSize,
 					IsUTF16:     isutf16,
+					Decompress:  decompress,
 				})
 
 			if err != nil {
@@ -223,17 +348,32 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				}
 			}
 
+			groupTemplate, err := newNameTemplate(groupName, fileconfig.FilePathRegexP, nametemplate.InvalidLogGroupNameChars)
+			if err != nil {
+				t.Log.Errorf("Invalid log group name %v for file %v: %v", groupName, filename, err)
+				tailer.Stop()
+				continue
+			}
+			streamTemplate, err := newNameTemplate(streamName, fileconfig.FilePathRegexP, nametemplate.InvalidLogStreamNameChars)
+			if err != nil {
+				t.Log.Errorf("Invalid log stream name %v for file %v: %v", streamName, filename, err)
+				tailer.Stop()
+				continue
+			}
+			groupTemplate = withFilePath(groupTemplate, filename, fileconfig.FilePathRegexP)
+			streamTemplate = withFilePath(streamTemplate, filename, fileconfig.FilePathRegexP)
+
 			destination := fileconfig.Destination
 			if destination == "" {
 				destination = t.Destination
 			}
 
 			src := NewTailerSrc(
-				groupName, streamName,
+				groupTemplate.String(), streamTemplate.String(),
 				t.Destination,
-				t.getStateFilePath(filename),
+				t.getStateFilePath(filename, fingerprint),
 				tailer,
-				fileconfig.AutoRemoval,
+				fileconfig.AutoRemoval && decompress == nil,
 				mlCheck,
 				fileconfig.timestampFromLogLine,
 				fileconfig.Enc,
@@ -241,6 +381,14 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				fileconfig.TruncateSuffix,
 				fileconfig.RetentionInDays,
 			)
+			src.SetProcessors(fileconfig.logProcessors())
+			if fileconfig.logParser != nil {
+				src.SetParser(fileconfig.logParser)
+			}
+			if decompress != nil {
+				src.SetCompressed()
+				t.collectedFiles[fingerprint.String()] = true
+			}
 
 			src.AddCleanUpFn(func(ts *tailerSrc) func() {
 				return func() {
@@ -252,7 +400,11 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				}
 			}(src))
 
-			srcs = append(srcs, src)
+			if groupTemplate.IsStatic() && streamTemplate.IsStatic() {
+				srcs = append(srcs, src)
+			} else {
+				srcs = append(srcs, &routedTailerSrc{tailerSrc: src, groupTemplate: groupTemplate, streamTemplate: streamTemplate, ec2Tags: t.ec2Tags})
+			}
 
 			dests[filename] = src
 		}
@@ -279,7 +431,7 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 			continue
 		}
 
-		if isCompressedFile(matchedFileName) {
+		if isCompressedFile(matchedFileName) && (!fileconfig.CollectCompressed || decompressorOf(matchedFileName) == nil) {
 			continue
 		}
 
@@ -295,6 +447,13 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 		if blacklistP != nil && blacklistP.MatchString(fileBaseName) {
 			continue
 		}
+		// The compressed files are collected individually, if their log events are not too old for CloudWatch Logs
+		if isCompressedFile(matchedFileName) {
+			if time.Since(matchedFileInfo.ModTime()) < maxCompressedLogAge {
+				targetFileList = append(targetFileList, matchedFileName)
+			}
+			continue
+		}
 		if !fileconfig.PublishMultiLogs {
 			if targetFileName == "" || matchedFileInfo.ModTime().After(targetModTime) {
 				targetFileName = matchedFileName
@@ -313,46 +472,118 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 }
 
 //The plugin will look at the state folder, and restore the offset of the file seeked if such state exists.
-func (t *LogFile) restoreState(filename string) (int64, error) {
-	filePath := t.getStateFilePath(filename)
+//The state of a fingerprinted file is keyed by its fingerprint, so the offset follows the file when it is renamed
+//by the log rotation, and a new file replacing a rotated file is read from its beginning.
+func (t *LogFile) restoreState(filename string, fingerprint *tail.Fingerprint) (int64, error) {
+	filePath := t.getStateFilePath(filename, fingerprint)
 
 	if _, err := os.Stat(filePath); err != nil {
 		t.Log.Debugf("The state file %s for %s does not exist: %v", filePath, filename, err)
+		if fingerprint != nil {
+			return t.restoreStateWithoutFingerprint(filename, fingerprint, err)
+		}
 		return 0, err
 	}
 
-	byteArray, err := ioutil.ReadFile(filePath)
+	state, err := readFileState(filePath)
 	if err != nil {
 		t.Log.Warnf("Issue encountered when reading offset from file %s: %v", filename, err)
 		return 0, err
 	}
 
-	offset, err := strconv.ParseInt(strings.Split(string(byteArray), "\n")[0], 10, 64)
-	if err != nil {
-		t.Log.Warnf("Issue encountered when parsing offset value %v: %v", byteArray, err)
-		return 0, err
+	if fingerprint != nil && !stateMatchesFile(state, filename) {
+		// The inode was reused by a new file, or the file was truncated while it was not tailed
+		t.Log.Infof("File %s was replaced or truncated since its offset %v was saved, reading from the beginning", filename, state.offset)
+		return 0, nil
 	}
 
-	t.Log.Infof("Reading from offset %v in %s", offset, filename)
+	t.Log.Infof("Reading from offset %v in %s", state.offset, filename)
 
-	return offset, nil
+	return state.offset, nil
 }
 
-func (t *LogFile) getStateFilePath(filename string) string {
+// restoreStateWithoutFingerprint restores the offset of a fingerprinted file which has no state keyed by its fingerprint.
+func (t *LogFile) restoreStateWithoutFingerprint(filename string, fingerprint *tail.Fingerprint, notExistErr error) (int64, error) {
+	// The state saved by file name before the state files were keyed by fingerprint is migrated once
+	legacyFilePath := t.getStateFilePath(filename, nil)
+	if state, err := readFileState(legacyFilePath); err == nil {
+		state.fingerprint = fingerprint
+		if err := writeFileState(t.getStateFilePath(filename, fingerprint), state); err != nil {
+			t.Log.Warnf("Issue encountered when migrating the state file %s of %s: %v", legacyFilePath, filename, err)
+		} else if err := os.Remove(legacyFilePath); err != nil {
+			t.Log.Warnf("Issue encountered when removing the migrated state file %s of %s: %v", legacyFilePath, filename, err)
+		}
+		t.Log.Infof("Reading from offset %v in %s", state.offset, filename)
+		return state.offset, nil
+	}
+
+	if t.hasStateOfRotatedFile(filename, fingerprint) {
+		t.Log.Infof("File %s replaces a rotated file, reading from the beginning", filename)
+		return 0, nil
+	}
+	return 0, notExistErr
+}
+
+// hasStateOfRotatedFile returns whether a state was saved for another file of the same name, i.e. the file
+// replaces a file renamed, or deleted, by the log rotation.
+func (t *LogFile) hasStateOfRotatedFile(filename string, fingerprint *tail.Fingerprint) bool {
+	files, err := filepath.Glob(filepath.Join(t.FileStateFolder, fingerprintStateFilePrefix+"*"))
+	if err != nil {
+		return false
+	}
+	for _, file := range files {
+		state, err := readFileState(file)
+		if err == nil && state.filename == filename && !fingerprint.SameFile(state.fingerprint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *LogFile) getStateFilePath(filename string, fingerprint *tail.Fingerprint) string {
 	if t.FileStateFolder == "" {
 		return ""
 	}
 
+	if fingerprint != nil {
+		return filepath.Join(t.FileStateFolder, fingerprintStateFileName(fingerprint))
+	}
 	return filepath.Join(t.FileStateFolder, escapeFilePath(filename))
 }
 
+// isCollected returns whether the compressed file was already collected, by this run or a previous one.
+func (t *LogFile) isCollected(filename string, fingerprint *tail.Fingerprint) bool {
+	if t.collectedFiles[fingerprint.String()] {
+		return true
+	}
+	state, err := readFileState(t.getStateFilePath(filename, fingerprint))
+	if err != nil || !state.complete || !stateMatchesFile(state, filename) {
+		return false
+	}
+	t.collectedFiles[fingerprint.String()] = true
+	return true
+}
+
+// isTailing returns whether the file is tailed by a tailer src, under its current name or a previous one.
+func (t *LogFile) isTailing(fingerprint *tail.Fingerprint) bool {
+	for _, dests := range t.configs {
+		for _, ts := range dests {
+			if fingerprint.SameFile(ts.tailer.Fingerprint()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (t *LogFile) cleanupStateFolder() {
 	files, err := filepath.Glob(t.FileStateFolder + string(filepath.Separator) + "*")
 	if err != nil {
 		t.Log.Errorf("Error happens in cleanup state folder %s: %v", t.FileStateFolder, err)
 	}
 	for _, file := range files {
-		if info, err := os.Stat(file); err != nil || info.IsDir() {
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
 			t.Log.Debugf("File %v does not exist or is a dirctory: %v, %v", file, err, info)
 			continue
 		}
@@ -367,7 +598,19 @@ func (t *LogFile) cleanupStateFolder() {
 			continue
 		}
 		contentArray := strings.Split(string(byteArray), "\n")
-		if len(contentArray) >= 2 {
+		if len(contentArray) >= 4 && contentArray[3] == fileStateComplete {
+			if time.Since(info.ModTime()) < maxCompressedLogAge {
+				// the compressed file may have been renamed, it is not collected again while its log events are accepted
+				continue
+			}
+		} else if len(contentArray) >= 3 && contentArray[2] != "" {
+			if saved, err := tail.ParseFingerprint(contentArray[2]); err == nil {
+				if current, err := tail.FingerprintFile(contentArray[1]); err == nil && saved.SameFile(current) {
+					// the original source file still exists under the same name
+					continue
+				}
+			}
+		} else if len(contentArray) >= 2 {
 			if _, err = os.Stat(contentArray[1]); err == nil {
 				// the original source file still exists
 				continue
@@ -384,10 +627,14 @@ func (t *LogFile) cleanUpStoppedTailerSrc() {
 	for {
 		select {
 		case rts := <-t.removeTailerSrcCh:
-			for _, dsts := range t.configs {
+			for fileconfig, dsts := range t.configs {
 				for n, ts := range dsts {
 					if ts == rts {
 						delete(dsts, n)
+						if t.dirWatcher != nil {
+							// The file may be tailed again, e.g. when its tailer stopped on an error
+							t.dirWatcher.changedConfig(fileconfig)
+						}
 					}
 				}
 			}
@@ -4,6 +4,8 @@
 package logfile
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -15,6 +17,9 @@ import (
 	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail/watch"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/encoding/simplifiedchinese"
@@ -27,7 +32,7 @@ const (
 )
 
 type TestLogger struct {
-	t *testing.T
+	t testing.TB
 }
 
 func (tl TestLogger) Errorf(format string, args ...interface{}) {
@@ -208,11 +213,211 @@ func TestRestoreState(t *testing.T) {
 	tt := NewLogFile()
 	tt.Log = TestLogger{t}
 	tt.FileStateFolder = tmpfolder
-	roffset, err := tt.restoreState(logFilePath)
+	roffset, err := tt.restoreState(logFilePath, nil)
 	assert.Equal(t, offset, roffset, fmt.Sprintf("The actual offset is %d, different from the expected offset %d.", roffset, offset))
 	tt.Stop()
 }
 
+func TestLogsCompressedFiles(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	logDir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(logDir)
+	stateDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateDir)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, err = w.Write([]byte("gz line 1\ngz line 2\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zst := enc.EncodeAll([]byte("zst line 1\n"), nil)
+	bz2 := []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xd7\xd6\x7d\x1e\x00\x00\x05\x59\x80\x00\x10\x40\x00\x30\x00\x12\x25\x00\x10\x20\x00\x21\x2a\x18\x27\xea\x10\x03\x08\x8a\x3c\x48\x94\x22\x48\x89\xa2\xee\x48\xa7\x0a\x12\x1a\xfa\xcf\xa3\xc0")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, "app.log"), []byte("live line\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, "app.log.1.gz"), gz.Bytes(), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, "app.log.2.zst"), zst, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, "app.log.3.bz2"), bz2, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, "app.log.4.tar"), []byte("archive"), 0644))
+	// The log events of a file last modified 15 days ago would be rejected by CloudWatch Logs
+	oldFile := filepath.Join(logDir, "app.log.5.gz")
+	require.NoError(t, ioutil.WriteFile(oldFile, gz.Bytes(), 0644))
+	old := time.Now().Add(-15 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(oldFile, old, old))
+
+	newLogFile := func(collectCompressed bool) *LogFile {
+		tt := NewLogFile()
+		tt.Log = TestLogger{t}
+		tt.FileStateFolder = stateDir
+		tt.FileConfig = []FileConfig{{FilePath: filepath.Join(logDir, "app.log*"), FromBeginning: true, CollectCompressed: collectCompressed}}
+		require.NoError(t, tt.FileConfig[0].init())
+		tt.started = true
+		return tt
+	}
+	stopAll := func(lsrcs []logs.LogSrc) {
+		for _, lsrc := range lsrcs {
+			lsrc.Stop()
+		}
+	}
+
+	tt := newLogFile(false)
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1, "The compressed files should be skipped without collect_compressed")
+	assert.Equal(t, filepath.Join(logDir, "app.log"), lsrcs[0].Description())
+	stopAll(lsrcs)
+	tt.Stop()
+
+	tt = newLogFile(true)
+	lsrcs = tt.FindLogSrc()
+	require.Len(t, lsrcs, 4)
+	collected := map[string][]string{}
+	for _, lsrc := range lsrcs {
+		if lsrc.Description() == filepath.Join(logDir, "app.log") {
+			continue
+		}
+		evts := make(chan logs.LogEvent)
+		lsrc.SetOutput(func(e logs.LogEvent) {
+			evts <- e
+		})
+		// The compressed file is read to its end, then the src stops
+		for e := range evts {
+			if e == nil {
+				break
+			}
+			collected[filepath.Base(lsrc.Description())] = append(collected[filepath.Base(lsrc.Description())], e.Message())
+			e.Done()
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"app.log.1.gz":  {"gz line 1", "gz line 2"},
+		"app.log.2.zst": {"zst line 1"},
+		"app.log.3.bz2": {"bz2 line 1", "bz2 line 2"},
+	}, collected)
+
+	// The state of a compressed file records when it is completely uploaded
+	fingerprint, err := tail.FingerprintFile(filepath.Join(logDir, "app.log.1.gz"))
+	require.NoError(t, err)
+	stateFilePath := tt.getStateFilePath(filepath.Join(logDir, "app.log.1.gz"), fingerprint)
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(100 * time.Millisecond) {
+		if state, err := readFileState(stateFilePath); err == nil && state.complete {
+			break
+		}
+	}
+	state, err := readFileState(stateFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, fileState{offset: int64(len("gz line 1\ngz line 2\n")), filename: filepath.Join(logDir, "app.log.1.gz"), fingerprint: fingerprint, complete: true}, state)
+	stopAll(lsrcs)
+
+	// The compressed files are collected once
+	assert.Empty(t, tt.FindLogSrc())
+	tt.Stop()
+
+	// Including after a restart, once they are completely uploaded
+	tt = newLogFile(true)
+	lsrcs = tt.FindLogSrc()
+	descriptions := make([]string, len(lsrcs))
+	for i, lsrc := range lsrcs {
+		descriptions[i] = filepath.Base(lsrc.Description())
+	}
+	assert.Contains(t, descriptions, "app.log")
+	assert.NotContains(t, descriptions, "app.log.1.gz")
+	stopAll(lsrcs)
+	tt.Stop()
+}
+
+func TestRestoreStateAfterRotation(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateDir)
+	logDir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(logDir)
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = stateDir
+	defer tt.Stop()
+
+	logFilePath := filepath.Join(logDir, "app.log")
+	require.NoError(t, ioutil.WriteFile(logFilePath, []byte("line 1\nline 2\n"), 0644))
+	fingerprint, err := tail.FingerprintFile(logFilePath)
+	require.NoError(t, err)
+	require.NoError(t, writeFileState(tt.getStateFilePath(logFilePath, fingerprint), fileState{offset: 7, filename: logFilePath, fingerprint: fingerprint}))
+
+	offset, err := tt.restoreState(logFilePath, fingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), offset)
+
+	// Rename based rotation: the offset follows the renamed file, and the new file is read from its beginning
+	rotatedFilePath := logFilePath + ".1"
+	require.NoError(t, os.Rename(logFilePath, rotatedFilePath))
+	require.NoError(t, ioutil.WriteFile(logFilePath, []byte("line 3\n"), 0644))
+
+	newFingerprint, err := tail.FingerprintFile(logFilePath)
+	require.NoError(t, err)
+	offset, err = tt.restoreState(logFilePath, newFingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	rotatedFingerprint, err := tail.FingerprintFile(rotatedFilePath)
+	require.NoError(t, err)
+	offset, err = tt.restoreState(rotatedFilePath, rotatedFingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), offset)
+
+	// Copy and truncate based rotation while the file is not tailed: the file is read from its beginning
+	require.NoError(t, writeFileState(tt.getStateFilePath(logFilePath, newFingerprint), fileState{offset: 7, filename: logFilePath, fingerprint: newFingerprint}))
+	require.NoError(t, os.Truncate(logFilePath, 0))
+	require.NoError(t, ioutil.WriteFile(logFilePath, []byte("line 4\nline 5\n"), 0644))
+	truncatedFingerprint, err := tail.FingerprintFile(logFilePath)
+	require.NoError(t, err)
+	offset, err = tt.restoreState(logFilePath, truncatedFingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	// A file never tailed has no state
+	otherFilePath := filepath.Join(logDir, "other.log")
+	require.NoError(t, ioutil.WriteFile(otherFilePath, []byte("line 1\n"), 0644))
+	otherFingerprint, err := tail.FingerprintFile(otherFilePath)
+	require.NoError(t, err)
+	_, err = tt.restoreState(otherFilePath, otherFingerprint)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRestoreStateMigratesStateByFileName(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateDir)
+	tmpfile, err := createTempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("line 1\nline 2\n")
+	require.NoError(t, err)
+
+	legacyStateFilePath := filepath.Join(stateDir, escapeFilePath(tmpfile.Name()))
+	require.NoError(t, ioutil.WriteFile(legacyStateFilePath, []byte("7\n"+tmpfile.Name()), 0644))
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = stateDir
+	defer tt.Stop()
+
+	fingerprint, err := tail.FingerprintFile(tmpfile.Name())
+	require.NoError(t, err)
+	offset, err := tt.restoreState(tmpfile.Name(), fingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), offset)
+
+	_, err = os.Stat(legacyStateFilePath)
+	assert.True(t, os.IsNotExist(err), "The state saved by file name should be removed once migrated")
+	state, err := readFileState(tt.getStateFilePath(tmpfile.Name(), fingerprint))
+	require.NoError(t, err)
+	assert.Equal(t, fileState{offset: 7, filename: tmpfile.Name(), fingerprint: fingerprint}, state)
+}
+
 func TestMultipleFilesForSameConfig(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	tmpfile1, err := createTempFile("", "tmp1_")
@@ -708,11 +913,12 @@ func TestLogsFileWithInvalidOffset(t *testing.T) {
 // TestLogsFileRecreate verifies that if a LogSrc matching a LogConfig is detected,
 // We only receive log lines beginning at the offset specified in the corresponding state-file.
 // And if the file happens to get deleted and recreated we expect to receive log lines beginning
-// at that same offset in the state file.
+// at the start of the new file, since the offset in the state file is the one of the deleted file.
 func TestLogsFileRecreate(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	logEntryString := "xxxxxxxxxxContentAfterOffset"
 	expectedContent := "ContentAfterOffset"
+	recreatedLogEntryString := "yyyyyyyyyyContentOfRecreatedFile"
 
 	tmpfile, err := createTempFile("", "")
 	defer os.Remove(tmpfile.Name())
@@ -762,7 +968,7 @@ func TestLogsFileRecreate(t *testing.T) {
 		tmpfile, err = os.OpenFile(tmpfile.Name(), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
 		require.NoError(t, err)
 
-		_, err = tmpfile.WriteString(logEntryString + "\n")
+		_, err = tmpfile.WriteString(recreatedLogEntryString + "\n")
 		require.NoError(t, err)
 
 	}()
@@ -792,8 +998,8 @@ func TestLogsFileRecreate(t *testing.T) {
 	})
 
 	e = <-evts
-	if e.Message() != expectedContent {
-		t.Errorf("Wrong log found after file replacement: \n% x\nExpecting:\n% x\n", e.Message(), expectedContent)
+	if e.Message() != recreatedLogEntryString {
+		t.Errorf("Wrong log found after file replacement: \n% x\nExpecting:\n% x\n", e.Message(), recreatedLogEntryString)
 	}
 
 	lsrc.Stop()
@@ -1053,6 +1259,79 @@ func TestLogFileMultiLogsReadingWithBlacklist(t *testing.T) {
 	tt.Stop()
 }
 
+func TestLogsFileWatcherInotify(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	logDir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(logDir)
+	if !watch.SupportsInotify(logDir) {
+		t.Skipf("the changes of %v are not reported by inotify", logDir)
+	}
+	stateDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateDir)
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = stateDir
+	tt.FileWatcher = fileWatcherInotify
+	tt.FileConfig = []FileConfig{{FilePath: filepath.Join(logDir, "*.log"), FromBeginning: true, PublishMultiLogs: true}}
+	require.NoError(t, tt.Start(nil))
+	defer tt.Stop()
+	require.NotNil(t, tt.dirWatcher)
+
+	assert.Empty(t, tt.FindLogSrc())
+	// The glob is not matched again until its directory changes
+	assert.False(t, tt.dirWatcher.needsScan(&tt.FileConfig[0]))
+
+	filename := filepath.Join(logDir, "app.log")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("line 1\n"), 0644))
+	var lsrcs []logs.LogSrc
+	for start := time.Now(); len(lsrcs) == 0 && time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		lsrcs = tt.FindLogSrc()
+	}
+	require.Len(t, lsrcs, 1)
+	assert.Equal(t, filename, lsrcs[0].Description())
+	assert.False(t, tt.configs[&tt.FileConfig[0]][filename].tailer.Poll, "the file should be watched with inotify")
+
+	evts := make(chan logs.LogEvent)
+	lsrcs[0].SetOutput(func(e logs.LogEvent) {
+		if e != nil {
+			evts <- e
+		}
+	})
+	expectMessage := func(expected string) {
+		select {
+		case e := <-evts:
+			assert.Equal(t, expected, e.Message())
+			e.Done()
+		case <-time.After(5 * time.Second):
+			t.Fatalf("log event %v was not received", expected)
+		}
+	}
+	expectMessage("line 1")
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("line 2\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	expectMessage("line 2")
+
+	lsrcs[0].Stop()
+}
+
+func TestLogsInvalidFileWatcher(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateDir)
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = stateDir
+	tt.FileWatcher = "fanotify"
+	assert.EqualError(t, tt.Start(nil), "invalid file_watcher fanotify, it must be polling or inotify")
+}
+
 func TestGenerateLogGroupName(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	fileName := "C:\\tmp\\soak Test\\tmp0.log"
@@ -1090,3 +1369,106 @@ func TestCheckForDuplicateRetentionSettingsPanics(t *testing.T) {
 	}
 	assert.Panics(t, func() { tt.checkForDuplicateRetentionSettings() }, "Did not panic after finding duplicate log group")
 }
+
+// BenchmarkFindLogSrc compares the CPU used by each FindLogSrc, called every second by the log agent, once the
+// files matched are tailed: their glob is matched again with the polling file watcher, not with the inotify one.
+func BenchmarkFindLogSrc(b *testing.B) {
+	for _, fileWatcher := range []string{fileWatcherPolling, fileWatcherInotify} {
+		b.Run(fileWatcher, func(b *testing.B) {
+			logDir, err := ioutil.TempDir("", "logs")
+			require.NoError(b, err)
+			defer os.RemoveAll(logDir)
+			stateDir, err := ioutil.TempDir("", "state")
+			require.NoError(b, err)
+			defer os.RemoveAll(stateDir)
+			for i := 0; i < 1000; i++ {
+				require.NoError(b, ioutil.WriteFile(filepath.Join(logDir, fmt.Sprintf("app%d.log", i)), []byte("line\n"), 0644))
+			}
+
+			tt := NewLogFile()
+			tt.Log = TestLogger{b}
+			tt.FileStateFolder = stateDir
+			tt.FileWatcher = fileWatcher
+			tt.FileConfig = []FileConfig{{FilePath: filepath.Join(logDir, "*.log"), PublishMultiLogs: true}}
+			require.NoError(b, tt.Start(nil))
+			defer tt.Stop()
+			lsrcs := tt.FindLogSrc()
+			require.Len(b, lsrcs, 1000)
+			defer func() {
+				for _, lsrc := range lsrcs {
+					lsrc.Stop()
+				}
+			}()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tt.FindLogSrc()
+			}
+		})
+	}
+}
+
+// BenchmarkTailChangingFiles compares the CPU used to tail files which keep changing with each file watcher: the
+// polling one checks every tailed file every 250ms, the inotify one waits for their changes. Each op appends a line
+// to every file and waits until a log event of each is published, cpu-ns/op is the CPU used by the whole process.
+func BenchmarkTailChangingFiles(b *testing.B) {
+	const files = 100
+	for _, fileWatcher := range []string{fileWatcherPolling, fileWatcherInotify} {
+		b.Run(fileWatcher, func(b *testing.B) {
+			logDir, err := ioutil.TempDir("", "logs")
+			require.NoError(b, err)
+			defer os.RemoveAll(logDir)
+			stateDir, err := ioutil.TempDir("", "state")
+			require.NoError(b, err)
+			defer os.RemoveAll(stateDir)
+			var logFiles []*os.File
+			for i := 0; i < files; i++ {
+				f, err := os.Create(filepath.Join(logDir, fmt.Sprintf("app%d.log", i)))
+				require.NoError(b, err)
+				defer f.Close()
+				logFiles = append(logFiles, f)
+			}
+
+			tt := NewLogFile()
+			tt.Log = TestLogger{b}
+			tt.FileStateFolder = stateDir
+			tt.FileWatcher = fileWatcher
+			tt.FileConfig = []FileConfig{{FilePath: filepath.Join(logDir, "*.log"), PublishMultiLogs: true, FromBeginning: true}}
+			require.NoError(b, tt.Start(nil))
+			defer tt.Stop()
+			lsrcs := tt.FindLogSrc()
+			require.Len(b, lsrcs, files)
+			published := make(chan struct{}, files)
+			for _, lsrc := range lsrcs {
+				lsrc.SetOutput(func(e logs.LogEvent) {
+					if e != nil {
+						e.Done()
+						published <- struct{}{}
+					}
+				})
+				defer lsrc.Stop()
+			}
+
+			// a log event is only published once the next line is read, or after 5s, so each line appended
+			// publishes the previous one
+			for _, f := range logFiles {
+				_, err := f.WriteString("line\n")
+				require.NoError(b, err)
+			}
+
+			b.ResetTimer()
+			cpu := processCPUTime()
+			for i := 0; i < b.N; i++ {
+				for _, f := range logFiles {
+					_, err := f.WriteString("line\n")
+					require.NoError(b, err)
+				}
+				for j := 0; j < files; j++ {
+					<-published
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(processCPUTime()-cpu)/float64(b.N), "cpu-ns/op")
+		})
+	}
+}
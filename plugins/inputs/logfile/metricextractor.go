@@ -0,0 +1,211 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs/parser"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	MetricTypeCounter = "counter"
+	MetricTypeGauge   = "gauge"
+	MetricTypeTiming  = "timing"
+
+	// the field name the cloudwatch output translates into the measurement name alone
+	extractedMetricField = "value"
+)
+
+// MetricExtractorConfig derives a metric from the log events of a file config
+type MetricExtractorConfig struct {
+	MetricName string `toml:"metric_name"`
+	// counter (default), gauge or timing
+	MetricType string `toml:"metric_type"`
+	// Only the log events matching the regex are used if set, its named groups can be referenced by value and dimensions
+	Pattern string `toml:"pattern"`
+	// The capture group name or JSON path of the metric value, counters count 1 per log event when it is not set
+	Value string `toml:"value"`
+	// The unit of the metric, e.g. "Milliseconds"
+	Unit string `toml:"unit"`
+	// Dimension name to capture group name or JSON path, the dimensions not found in a log event are omitted
+	Dimensions map[string]string `toml:"dimensions"`
+}
+
+// metricExtractor is a log processor which leaves the log events unchanged and aggregates the metric
+// extracted from them until it is collected
+type metricExtractor struct {
+	config  MetricExtractorConfig
+	pattern *regexp.Regexp
+	groups  map[string]int
+	dimKeys []string
+
+	mu         sync.Mutex
+	aggregates map[string]*extractedMetric
+}
+
+type extractedMetric struct {
+	tags  map[string]string
+	value float64
+	dist  distribution.Distribution
+}
+
+func newMetricExtractor(config MetricExtractorConfig) (*metricExtractor, error) {
+	if config.MetricName == "" {
+		return nil, fmt.Errorf("metric_name is required")
+	}
+	switch config.MetricType {
+	case "":
+		config.MetricType = MetricTypeCounter
+	case MetricTypeCounter, MetricTypeGauge, MetricTypeTiming:
+	default:
+		return nil, fmt.Errorf("metric_type %v is not supported", config.MetricType)
+	}
+	if config.MetricType != MetricTypeCounter && config.Value == "" {
+		return nil, fmt.Errorf("value is required for metric_type %v", config.MetricType)
+	}
+
+	e := &metricExtractor{
+		config:     config,
+		aggregates: make(map[string]*extractedMetric),
+	}
+	if config.Pattern != "" {
+		var err error
+		if e.pattern, err = regexp.Compile(config.Pattern); err != nil {
+			return nil, fmt.Errorf("pattern has issue, regexp: Compile( %v ): %v", config.Pattern, err)
+		}
+		e.groups = make(map[string]int)
+		for i, name := range e.pattern.SubexpNames() {
+			if name != "" {
+				e.groups[name] = i
+			}
+		}
+	}
+
+	refs := []string{config.Value}
+	for k, ref := range config.Dimensions {
+		e.dimKeys = append(e.dimKeys, k)
+		refs = append(refs, ref)
+	}
+	sort.Strings(e.dimKeys)
+	for _, ref := range refs {
+		// the references starting with the JSON path prefix are JSON paths into the log event, e.g.
+		// "$.request.latency", the others are names of the pattern capture groups
+		if ref == "" || strings.HasPrefix(ref, parser.JSONPathPrefix) {
+			continue
+		}
+		if _, ok := e.groups[ref]; !ok {
+			return nil, fmt.Errorf("%v is neither a JSON path nor a named group of the pattern", ref)
+		}
+	}
+	return e, nil
+}
+
+func (e *metricExtractor) Process(msg string) (string, bool) {
+	return e.process(msg, nil)
+}
+
+// ProcessFields extracts the metric from the fields of the parsed log event when its message is not JSON
+func (e *metricExtractor) ProcessFields(msg string, fields map[string]interface{}) (string, bool) {
+	return e.process(msg, fields)
+}
+
+func (e *metricExtractor) process(msg string, fields map[string]interface{}) (string, bool) {
+	var groups []string
+	if e.pattern != nil {
+		if groups = e.pattern.FindStringSubmatch(msg); groups == nil {
+			return msg, true
+		}
+	}
+
+	doc := parser.NewDocument(msg, fields)
+	lookup := func(ref string) (string, bool) {
+		if !strings.HasPrefix(ref, parser.JSONPathPrefix) {
+			i := e.groups[ref]
+			return groups[i], groups[i] != ""
+		}
+		return doc.Lookup(ref)
+	}
+
+	value := float64(1)
+	if e.config.Value != "" {
+		s, ok := lookup(e.config.Value)
+		if !ok {
+			return msg, true
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			log.Printf("D! [logfile] Cannot parse the value %v of metric %v: %v", s, e.config.MetricName, err)
+			return msg, true
+		}
+		value = v
+	}
+
+	tags := make(map[string]string, len(e.dimKeys))
+	var key bytes.Buffer
+	for _, k := range e.dimKeys {
+		if v, ok := lookup(e.config.Dimensions[k]); ok {
+			tags[k] = v
+			key.WriteString(k + "=" + v + ",")
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	m, ok := e.aggregates[key.String()]
+	if !ok {
+		m = &extractedMetric{tags: tags}
+		if e.config.MetricType == MetricTypeTiming {
+			m.dist = regular.NewRegularDistribution()
+		}
+		e.aggregates[key.String()] = m
+	}
+	switch e.config.MetricType {
+	case MetricTypeCounter:
+		m.value += value
+	case MetricTypeGauge:
+		m.value = value
+	case MetricTypeTiming:
+		m.dist.AddEntryWithUnit(value, 1, e.config.Unit)
+	}
+	return msg, true
+}
+
+// collect adds the metrics aggregated since the last collection to the accumulator
+func (e *metricExtractor) collect(acc telegraf.Accumulator, extraTags map[string]string) {
+	e.mu.Lock()
+	aggregates := e.aggregates
+	e.aggregates = make(map[string]*extractedMetric)
+	e.mu.Unlock()
+
+	for _, m := range aggregates {
+		tags := make(map[string]string, len(m.tags)+len(extraTags))
+		for k, v := range extraTags {
+			tags[k] = v
+		}
+		for k, v := range m.tags {
+			tags[k] = v
+		}
+		switch e.config.MetricType {
+		case MetricTypeCounter:
+			acc.AddCounter(e.config.MetricName, map[string]interface{}{extractedMetricField: m.value}, tags)
+		case MetricTypeGauge:
+			acc.AddGauge(e.config.MetricName, map[string]interface{}{extractedMetricField: m.value}, tags)
+		case MetricTypeTiming:
+			if m.dist.SampleCount() > 0 {
+				acc.AddFields(e.config.MetricName, map[string]interface{}{extractedMetricField: m.dist}, tags)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricExtractor_Counter(t *testing.T) {
+	e, err := newMetricExtractor(MetricExtractorConfig{
+		MetricName: "errors",
+		Pattern:    `level=ERROR component=(?P<component>\w+)`,
+		Dimensions: map[string]string{"Component": "component"},
+	})
+	require.NoError(t, err)
+
+	for _, msg := range []string{
+		"level=ERROR component=db timeout",
+		"level=INFO component=db connected",
+		"level=ERROR component=api bad request",
+		"level=ERROR component=db timeout",
+	} {
+		res, keep := e.Process(msg)
+		assert.True(t, keep)
+		assert.Equal(t, msg, res)
+	}
+
+	acc := &testutil.Accumulator{}
+	e.collect(acc, map[string]string{"metricPath": "metrics"})
+	assert.Equal(t, 2, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "errors", map[string]interface{}{"value": float64(2)}, map[string]string{"Component": "db", "metricPath": "metrics"})
+	acc.AssertContainsTaggedFields(t, "errors", map[string]interface{}{"value": float64(1)}, map[string]string{"Component": "api", "metricPath": "metrics"})
+	for _, m := range acc.Metrics {
+		assert.Equal(t, telegraf.Counter, m.Type)
+	}
+
+	// the aggregation is reset after each collection
+	acc.ClearMetrics()
+	e.collect(acc, nil)
+	assert.Equal(t, 0, len(acc.Metrics))
+}
+
+func TestMetricExtractor_GaugeFromJSON(t *testing.T) {
+	e, err := newMetricExtractor(MetricExtractorConfig{
+		MetricName: "queue_depth",
+		MetricType: MetricTypeGauge,
+		Value:      "$.queue.depth",
+		Dimensions: map[string]string{"Queue": "$.queue.name", "Missing": "$.missing"},
+	})
+	require.NoError(t, err)
+
+	e.Process(`{"queue": {"name": "jobs", "depth": 5}}`)
+	e.Process(`{"queue": {"name": "jobs", "depth": 3}}`)
+	e.Process(`{"queue": {"name": "jobs", "depth": "not a number"}}`)
+	e.Process(`not json`)
+
+	acc := &testutil.Accumulator{}
+	e.collect(acc, nil)
+	assert.Equal(t, 1, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "queue_depth", map[string]interface{}{"value": float64(3)}, map[string]string{"Queue": "jobs"})
+	assert.Equal(t, telegraf.Gauge, acc.Metrics[0].Type)
+}
+
+func TestMetricExtractor_GaugeFromFields(t *testing.T) {
+	e, err := newMetricExtractor(MetricExtractorConfig{
+		MetricName: "latency",
+		MetricType: MetricTypeGauge,
+		Value:      "$.duration",
+		Dimensions: map[string]string{"Path": "$.path"},
+	})
+	require.NoError(t, err)
+
+	// the fields of the log events parsed from logfmt, which are published as they were read
+	e.ProcessFields("path=/ duration=12", map[string]interface{}{"path": "/", "duration": "12"})
+	e.ProcessFields("not parsed", nil)
+
+	acc := &testutil.Accumulator{}
+	e.collect(acc, nil)
+	assert.Equal(t, 1, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "latency", map[string]interface{}{"value": float64(12)}, map[string]string{"Path": "/"})
+}
+
+func TestMetricExtractor_Timing(t *testing.T) {
+	e, err := newMetricExtractor(MetricExtractorConfig{
+		MetricName: "latency",
+		MetricType: MetricTypeTiming,
+		Pattern:    `latency=(?P<latency>\d+)ms`,
+		Value:      "latency",
+		Unit:       "Milliseconds",
+	})
+	require.NoError(t, err)
+
+	e.Process("GET / latency=10ms")
+	e.Process("GET / latency=30ms")
+	e.Process("GET / no latency")
+
+	acc := &testutil.Accumulator{}
+	e.collect(acc, nil)
+	require.Equal(t, 1, len(acc.Metrics))
+	dist, ok := acc.Metrics[0].Fields["value"].(distribution.Distribution)
+	require.True(t, ok)
+	assert.Equal(t, float64(2), dist.SampleCount())
+	assert.Equal(t, float64(40), dist.Sum())
+	assert.Equal(t, float64(10), dist.Minimum())
+	assert.Equal(t, float64(30), dist.Maximum())
+	assert.Equal(t, "Milliseconds", dist.Unit())
+}
+
+func TestNewMetricExtractor_Invalid(t *testing.T) {
+	testCases := map[string]struct {
+		config MetricExtractorConfig
+		err    string
+	}{
+		"NoName": {
+			config: MetricExtractorConfig{},
+			err:    "metric_name is required",
+		},
+		"BadType": {
+			config: MetricExtractorConfig{MetricName: "m", MetricType: "summary"},
+			err:    "metric_type summary is not supported",
+		},
+		"NoValue": {
+			config: MetricExtractorConfig{MetricName: "m", MetricType: MetricTypeGauge},
+			err:    "value is required for metric_type gauge",
+		},
+		"UnknownGroup": {
+			config: MetricExtractorConfig{MetricName: "m", Pattern: `(?P<a>\d+)`, Value: "b"},
+			err:    "b is neither a JSON path nor a named group of the pattern",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newMetricExtractor(testCase.config)
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
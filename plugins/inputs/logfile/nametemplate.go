@@ -0,0 +1,93 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
+)
+
+const (
+	// {file_path:app} is the named, or numbered, group of the file_path_regex matched against the file name
+	placeholderFilePath = "file_path"
+	// {ec2_tag:Name} is the EC2 Instance Tag, or EC2 Metadata field, retrieved by the plugin when it starts
+	placeholderEC2Tag = "ec2_tag"
+)
+
+// newNameTemplate returns the template of the log group or stream name, its placeholders are resolved from the file
+// path, the EC2 Instance Tags and the content of the log events
+func newNameTemplate(name string, filePathRegex *regexp.Regexp, invalid *regexp.Regexp) (*nametemplate.Template, error) {
+	t, err := nametemplate.New(name, invalid, placeholderFilePath, placeholderEC2Tag, nametemplate.KindJSON)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range t.Keys(placeholderFilePath) {
+		if filePathRegex == nil {
+			return nil, fmt.Errorf("the placeholder {%v:%v} requires the file_path_regex", placeholderFilePath, key)
+		}
+		if !hasGroup(filePathRegex, key) {
+			return nil, fmt.Errorf("%v is not a group of the file_path_regex", key)
+		}
+	}
+	return t, nil
+}
+
+func hasGroup(r *regexp.Regexp, key string) bool {
+	i := groupIndex(r, key)
+	return i >= 0 && i <= r.NumSubexp()
+}
+
+// groupIndex returns the index of the named or numbered group of the regex, or -1
+func groupIndex(r *regexp.Regexp, key string) int {
+	if i, err := strconv.Atoi(key); err == nil {
+		return i
+	}
+	for i, name := range r.SubexpNames() {
+		if name != "" && name == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// withFilePath returns the template whose file_path placeholders are resolved from the file name
+func withFilePath(t *nametemplate.Template, filename string, filePathRegex *regexp.Regexp) *nametemplate.Template {
+	var groups []string
+	if filePathRegex != nil {
+		groups = filePathRegex.FindStringSubmatch(filename)
+	}
+	return t.ResolveKind(placeholderFilePath, func(_, key string) (string, bool) {
+		if groups == nil {
+			return "", false
+		}
+		return groups[groupIndex(filePathRegex, key)], true
+	})
+}
+
+// routedTailerSrc is a tailerSrc whose log group or stream name depends on the EC2 Instance Tags or the log events
+type routedTailerSrc struct {
+	*tailerSrc
+	groupTemplate, streamTemplate *nametemplate.Template
+	ec2Tags                       *ec2Tags
+}
+
+// Route waits until the EC2 Instance Tags are retrieved, the placeholders of the log events resolve to their
+// default for the fallback log group and stream
+func (ts *routedTailerSrc) Route(e logs.LogEvent) (string, string) {
+	eventResolver := nametemplate.EventResolver(e)
+	r := func(kind, key string) (string, bool) {
+		if kind == placeholderEC2Tag {
+			if ts.ec2Tags == nil {
+				return "", false
+			}
+			return ts.ec2Tags.get(key)
+		}
+		return eventResolver(kind, key)
+	}
+	return ts.groupTemplate.Resolve(r), ts.streamTemplate.Resolve(r)
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/ec2tagger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameTemplate_FilePath(t *testing.T) {
+	filePathRegex := regexp.MustCompile(`/var/log/(?P<app>[^/]+)/(\w+)`)
+	tmpl, err := newNameTemplate("/apps/{file_path:app}/{file_path:2}", filePathRegex, nametemplate.InvalidLogGroupNameChars)
+	require.NoError(t, err)
+	assert.False(t, tmpl.IsStatic())
+
+	resolved := withFilePath(tmpl, "/var/log/checkout/server.log", filePathRegex)
+	assert.True(t, resolved.IsStatic())
+	assert.Equal(t, "/apps/checkout/server", resolved.String())
+
+	// the file name does not match the regex
+	resolved = withFilePath(tmpl, "/tmp/server.log", filePathRegex)
+	assert.Equal(t, "/apps/unknown/unknown", resolved.String())
+
+	_, err = newNameTemplate("/apps/{file_path:app}", nil, nametemplate.InvalidLogGroupNameChars)
+	assert.Error(t, err)
+	_, err = newNameTemplate("/apps/{file_path:service}", filePathRegex, nametemplate.InvalidLogGroupNameChars)
+	assert.Error(t, err)
+	_, err = newNameTemplate("/apps/{file_path:3}", filePathRegex, nametemplate.InvalidLogGroupNameChars)
+	assert.Error(t, err)
+	_, err = newNameTemplate("/apps/{hostname:app}", filePathRegex, nametemplate.InvalidLogGroupNameChars)
+	assert.Error(t, err)
+}
+
+func TestNameTemplate_Static(t *testing.T) {
+	tmpl, err := newNameTemplate("/aws/{instance_id}/group", nil, nametemplate.InvalidLogGroupNameChars)
+	require.NoError(t, err)
+	assert.True(t, tmpl.IsStatic())
+	assert.Equal(t, "/aws/{instance_id}/group", tmpl.String())
+}
+
+func TestRoutedTailerSrc_Route(t *testing.T) {
+	group, err := newNameTemplate("/apps/{json:$.app}", nil, nametemplate.InvalidLogGroupNameChars)
+	require.NoError(t, err)
+	stream, err := newNameTemplate("{json:$.host}", nil, nametemplate.InvalidLogStreamNameChars)
+	require.NoError(t, err)
+	src := &routedTailerSrc{tailerSrc: &tailerSrc{}, groupTemplate: group, streamTemplate: stream}
+
+	g, s := src.Route(&LogEvent{msg: `{"app":"my app","host":"ip-10-0-0-1"}`})
+	assert.Equal(t, "/apps/my_app", g)
+	assert.Equal(t, "ip-10-0-0-1", s)
+
+	// the fields of the parsed log events are used when they are not published as JSON
+	g, s = src.Route(&LogEvent{msg: "app=api host=ip-10-0-0-2", fields: map[string]interface{}{"app": "api", "host": "ip-10-0-0-2"}})
+	assert.Equal(t, "/apps/api", g)
+	assert.Equal(t, "ip-10-0-0-2", s)
+
+	// the fallback log group and stream use the defaults of the placeholders
+	g, s = src.Route(nil)
+	assert.Equal(t, "/apps/unknown", g)
+	assert.Equal(t, "unknown", s)
+}
+
+func TestRoutedTailerSrc_RouteEC2Tag(t *testing.T) {
+	group, err := newNameTemplate("{ec2_tag:Name|untagged}_{ec2_tag:Other}", nil, nametemplate.InvalidLogGroupNameChars)
+	require.NoError(t, err)
+	stream, err := newNameTemplate("{ec2_tag:InstanceId}", nil, nametemplate.InvalidLogStreamNameChars)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Other"}, group.Keys(placeholderEC2Tag))
+
+	tags := &ec2Tags{tags: map[string]string{"Name": "web server", ec2tagger.MdKeyInstanceId: "i-123"}, retrieved: make(chan struct{})}
+	close(tags.retrieved)
+	src := &routedTailerSrc{tailerSrc: &tailerSrc{}, groupTemplate: group, streamTemplate: stream, ec2Tags: tags}
+	g, s := src.Route(nil)
+	assert.Equal(t, "web_server_unknown", g)
+	assert.Equal(t, "i-123", s)
+
+	src.ec2Tags = nil
+	g, _ = src.Route(nil)
+	assert.Equal(t, "untagged_unknown", g)
+}
@@ -0,0 +1,22 @@
+// +build linux darwin freebsd netbsd openbsd
+
+package tail
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileID returns the device and inode of the file
+func fileID(f *os.File) (uint64, uint64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || stat == nil {
+		return 0, 0, fmt.Errorf("no device and inode for file %s", f.Name())
+	}
+	return uint64(stat.Dev), uint64(stat.Ino), nil
+}
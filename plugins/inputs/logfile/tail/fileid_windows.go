@@ -0,0 +1,17 @@
+// +build windows
+
+package tail
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the volume serial number and the file index of the file
+func fileID(f *os.File) (uint64, uint64, error) {
+	var d syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(f.Fd()), &d); err != nil {
+		return 0, 0, err
+	}
+	return uint64(d.VolumeSerialNumber), uint64(d.FileIndexHigh)<<32 | uint64(d.FileIndexLow), nil
+}
@@ -0,0 +1,109 @@
+package tail
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FingerprintSize is the number of bytes at the head of a file hashed into its Fingerprint.
+const FingerprintSize = 1024
+
+// Fingerprint identifies a file independently of its name: the device and inode of the file (the volume
+// serial number and file index on Windows), and a hash of its first bytes which detects the inodes reused
+// by new files and the files truncated and rewritten in place.
+type Fingerprint struct {
+	Dev, Ino uint64
+	// Size is the number of bytes hashed, it is less than FingerprintSize while the file is smaller
+	Size int64
+	Hash string
+}
+
+// NewFingerprint returns the fingerprint of the opened file, without moving its read offset.
+func NewFingerprint(f *os.File) (*Fingerprint, error) {
+	dev, ino, err := fileID(f)
+	if err != nil {
+		return nil, err
+	}
+	size, hash, err := hashHead(f, FingerprintSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Fingerprint{Dev: dev, Ino: ino, Size: size, Hash: hash}, nil
+}
+
+// FingerprintFile returns the fingerprint of the named file.
+func FingerprintFile(filename string) (*Fingerprint, error) {
+	f, err := OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewFingerprint(f)
+}
+
+// SameFile returns whether both fingerprints have the same device and inode.
+func (fp *Fingerprint) SameFile(other *Fingerprint) bool {
+	return other != nil && fp.Dev == other.Dev && fp.Ino == other.Ino
+}
+
+// MatchesFile returns whether the named file is still the file of the fingerprint: it has the same device
+// and inode, and it starts with the bytes hashed into the fingerprint, so it was at most appended to.
+func (fp *Fingerprint) MatchesFile(filename string) (bool, error) {
+	f, err := OpenFile(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	dev, ino, err := fileID(f)
+	if err != nil {
+		return false, err
+	}
+	if dev != fp.Dev || ino != fp.Ino {
+		return false, nil
+	}
+	size, hash, err := hashHead(f, fp.Size)
+	if err != nil {
+		return false, err
+	}
+	return size == fp.Size && hash == fp.Hash, nil
+}
+
+// String returns the fingerprint as saved in the state files, e.g. 2049:1835041:1024:<sha1 hex>
+func (fp *Fingerprint) String() string {
+	return fmt.Sprintf("%d:%d:%d:%s", fp.Dev, fp.Ino, fp.Size, fp.Hash)
+}
+
+// ParseFingerprint parses the fingerprint returned by Fingerprint.String.
+func ParseFingerprint(s string) (*Fingerprint, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid fingerprint %q", s)
+	}
+	fp := &Fingerprint{Hash: parts[3]}
+	var err error
+	if fp.Dev, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid device of fingerprint %q: %v", s, err)
+	}
+	if fp.Ino, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid inode of fingerprint %q: %v", s, err)
+	}
+	if fp.Size, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid size of fingerprint %q: %v", s, err)
+	}
+	return fp, nil
+}
+
+// hashHead returns the number of bytes read, up to n, at the head of the file and their hash
+func hashHead(f *os.File, n int64) (int64, string, error) {
+	h := sha1.New()
+	size, err := io.Copy(h, io.NewSectionReader(f, 0, n))
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
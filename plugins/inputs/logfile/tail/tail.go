@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"sync"
 	"time"
@@ -65,6 +66,9 @@ type Config struct {
 
 	// Special handling for utf16
 	IsUTF16 bool
+
+	// Decompress the content of a compressed file, which can only be read, and seeked, from its beginning
+	Decompress func(io.Reader) (io.ReadCloser, error)
 }
 
 type Tail struct {
@@ -72,15 +76,17 @@ type Tail struct {
 	Lines    chan *Line
 	Config
 
-	file   *os.File
-	reader *bufio.Reader
+	file         *os.File
+	decompressor io.ReadCloser
+	reader       *bufio.Reader
 
 	watcher watch.FileWatcher
 	changes *watch.FileChanges
 
-	curOffset int64
-	tomb.Tomb // provides: Done, Kill, Dying
-	dropCnt   int
+	curOffset   int64
+	fingerprint *Fingerprint
+	tomb.Tomb   // provides: Done, Kill, Dying
+	dropCnt     int
 
 	lk sync.Mutex
 }
@@ -105,6 +111,10 @@ func TailFile(filename string, config Config) (*Tail, error) {
 		t.Logger = models.NewLogger("inputs", "tail", "")
 	}
 
+	if !t.Poll && !watch.SupportsInotify(filename) {
+		t.Logger.Debugf("The changes of %s are not reported by inotify, polling it instead", filename)
+		t.Poll = true
+	}
 	if t.Poll {
 		t.watcher = watch.NewPollingFileWatcher(filename)
 	} else {
@@ -174,17 +184,43 @@ func (tail *Tail) close() {
 }
 
 func (tail *Tail) closeFile() {
+	tail.lk.Lock()
+	defer tail.lk.Unlock()
+	if tail.decompressor != nil {
+		tail.decompressor.Close()
+		tail.decompressor = nil
+	}
 	if tail.file != nil {
 		tail.file.Close()
 		tail.file = nil
 	}
 }
 
+// Fingerprint returns the fingerprint of the file tailed. It is refreshed while the file is smaller than
+// FingerprintSize and after the file is truncated, the last fingerprint is returned once the tail is stopped.
+// It returns nil if the fingerprint is not available, e.g. for a named pipe.
+func (tail *Tail) Fingerprint() *Fingerprint {
+	tail.lk.Lock()
+	defer tail.lk.Unlock()
+	if tail.Pipe || tail.file == nil {
+		return tail.fingerprint
+	}
+	if tail.fingerprint == nil || tail.fingerprint.Size < FingerprintSize {
+		if fp, err := NewFingerprint(tail.file); err == nil {
+			tail.fingerprint = fp
+		}
+	}
+	return tail.fingerprint
+}
+
 func (tail *Tail) reopen() error {
 	tail.closeFile()
 	for {
-		var err error
-		tail.file, err = OpenFile(tail.Filename)
+		file, err := OpenFile(tail.Filename)
+		tail.lk.Lock()
+		tail.file = file
+		tail.fingerprint = nil
+		tail.lk.Unlock()
 		tail.curOffset = 0
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -317,7 +353,10 @@ func (tail *Tail) tailFileSync() {
 		}
 	}
 	// openReader should be invoked before seekTo
-	tail.openReader()
+	if err := tail.openReader(); err != nil {
+		tail.Killf("Error reading %s: %s", tail.Filename, err)
+		return
+	}
 
 	// Seek to requested location on first open of the file.
 	if tail.Location != nil {
@@ -425,6 +464,13 @@ func (tail *Tail) watchChanges() error {
 		return err
 	}
 	tail.changes, err = tail.watcher.ChangeEvents(&tail.Tomb, pos)
+	if err != nil && !tail.Poll {
+		// e.g. the fs.inotify.max_user_watches limit is reached
+		tail.Logger.Warnf("Unable to watch %s with inotify, polling it instead: %v", tail.Filename, err)
+		tail.Poll = true
+		tail.watcher = watch.NewPollingFileWatcher(tail.Filename)
+		tail.changes, err = tail.watcher.ChangeEvents(&tail.Tomb, pos)
+	}
 	return err
 }
 
@@ -447,8 +493,7 @@ func (tail *Tail) waitForChanges() error {
 				return err
 			}
 			tail.Logger.Debugf("Successfully reopened %s", tail.Filename)
-			tail.openReader()
-			return nil
+			return tail.openReader()
 		} else {
 			tail.Logger.Warnf("Stopping tail as file no longer exists: %s", tail.Filename)
 			return ErrDeletedNotReOpen
@@ -460,23 +505,32 @@ func (tail *Tail) waitForChanges() error {
 			return err
 		}
 		tail.Logger.Debugf("Successfully reopened truncated %s", tail.Filename)
-		tail.openReader()
-		return nil
+		return tail.openReader()
 	case <-tail.Dying():
 		return ErrStop
 	}
 	panic("unreachable")
 }
 
-func (tail *Tail) openReader() {
+func (tail *Tail) openReader() error {
 	tail.lk.Lock()
+	defer tail.lk.Unlock()
+	var r io.Reader = tail.file
+	if tail.Decompress != nil {
+		decompressor, err := tail.Decompress(tail.file)
+		if err != nil {
+			return fmt.Errorf("unable to decompress %s: %v", tail.Filename, err)
+		}
+		tail.decompressor = decompressor
+		r = decompressor
+	}
 	if tail.MaxLineSize > 0 {
 		// add 2 to account for newline characters
-		tail.reader = bufio.NewReaderSize(tail.file, tail.MaxLineSize+2)
+		tail.reader = bufio.NewReaderSize(r, tail.MaxLineSize+2)
 	} else {
-		tail.reader = bufio.NewReader(tail.file)
+		tail.reader = bufio.NewReader(r)
 	}
-	tail.lk.Unlock()
+	return nil
 }
 
 func (tail *Tail) seekEnd() error {
@@ -484,6 +538,9 @@ func (tail *Tail) seekEnd() error {
 }
 
 func (tail *Tail) seekTo(pos SeekInfo) error {
+	if tail.Decompress != nil {
+		return tail.skipDecompressed(pos)
+	}
 	_, err := tail.file.Seek(pos.Offset, pos.Whence)
 	if err != nil {
 		return fmt.Errorf("Seek error on %s: %s", tail.Filename, err)
@@ -494,6 +551,20 @@ func (tail *Tail) seekTo(pos SeekInfo) error {
 	return err
 }
 
+// skipDecompressed skips the decompressed content up to the offset, from the beginning of the compressed file
+func (tail *Tail) skipDecompressed(pos SeekInfo) error {
+	if pos.Whence != io.SeekStart || pos.Offset < tail.curOffset {
+		return fmt.Errorf("Seek error on %s: a compressed file can only be read from its beginning", tail.Filename)
+	}
+	n, err := io.CopyN(ioutil.Discard, tail.reader, pos.Offset-tail.curOffset)
+	tail.curOffset += n
+	if err == io.EOF {
+		// the file was completely read
+		return nil
+	}
+	return err
+}
+
 // sendLine sends the line(s) to Lines channel, splitting longer lines
 // if necessary. Return false if rate limit is reached.
 func (tail *Tail) sendLine(line string, offset int64) bool {
@@ -1,13 +1,19 @@
 package tail
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail/watch"
 )
 
 type testLogger struct {
@@ -90,6 +96,328 @@ func TestDroppedLinesWhenStopAtEOFLogging(t *testing.T) {
 	verifyTailerExited(t, tail)
 }
 
+func TestFingerprintRename(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+	writeFile(t, name, "line 1\nline 2\n")
+
+	fp, err := FingerprintFile(name)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %v: %v", name, err)
+	}
+	if fp.Size != int64(len("line 1\nline 2\n")) {
+		t.Errorf("wrong fingerprint size %v of a file smaller than %v", fp.Size, FingerprintSize)
+	}
+
+	// Rename based rotation, the renamed file is appended by the application before it reopens its log file
+	rotated := name + ".1"
+	if err := os.Rename(name, rotated); err != nil {
+		t.Fatalf("failed to rename %v: %v", name, err)
+	}
+	appendFile(t, rotated, "line 3\n")
+	writeFile(t, name, "line 1\nline 2\n")
+
+	assertMatches(t, fp, rotated, true)
+	// the new file has the same content, but it is another file
+	assertMatches(t, fp, name, false)
+
+	renamed, err := FingerprintFile(rotated)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %v: %v", rotated, err)
+	}
+	if !fp.SameFile(renamed) {
+		t.Errorf("renamed file %v should have the same device and inode %v as %v", rotated, renamed, fp)
+	}
+	parsed, err := ParseFingerprint(renamed.String())
+	if err != nil || *parsed != *renamed {
+		t.Errorf("fingerprint %v was parsed as %v: %v", renamed, parsed, err)
+	}
+}
+
+func TestFingerprintCopyTruncate(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+	writeFile(t, name, "line 1\nline 2\n")
+
+	fp, err := FingerprintFile(name)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %v: %v", name, err)
+	}
+
+	// Copy and truncate based rotation, the file keeps its inode but its content is replaced
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("failed to read %v: %v", name, err)
+	}
+	writeFile(t, name+".1", string(content))
+	if err := os.Truncate(name, 0); err != nil {
+		t.Fatalf("failed to truncate %v: %v", name, err)
+	}
+	assertMatches(t, fp, name, false)
+	assertMatches(t, fp, name+".1", false)
+
+	// The truncated file is rewritten with more content than the fingerprinted one
+	appendFile(t, name, "line 3\nline 4\nline 5\n")
+	assertMatches(t, fp, name, false)
+
+	truncated, err := FingerprintFile(name)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %v: %v", name, err)
+	}
+	if !fp.SameFile(truncated) {
+		t.Errorf("truncated file %v should have the same device and inode %v as %v", name, truncated, fp)
+	}
+}
+
+func TestFingerprintAppend(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+	writeFile(t, name, "line 1\n")
+
+	fp, err := FingerprintFile(name)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %v: %v", name, err)
+	}
+	appendFile(t, name, strings.Repeat("x", 2*FingerprintSize)+"\n")
+	assertMatches(t, fp, name, true)
+
+	grown, err := FingerprintFile(name)
+	if err != nil {
+		t.Fatalf("failed to fingerprint %v: %v", name, err)
+	}
+	if grown.Size != FingerprintSize {
+		t.Errorf("wrong fingerprint size %v, expecting %v", grown.Size, FingerprintSize)
+	}
+	assertMatches(t, grown, name, true)
+}
+
+func TestTailDrainsRenamedFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+	writeFile(t, name, "line 1\n")
+
+	tail, err := TailFile(name, Config{
+		Logger:    &testLogger{},
+		Follow:    true,
+		MustExist: true,
+		Poll:      true,
+	})
+	if err != nil {
+		t.Fatalf("failed to tail file %v: %v", name, err)
+	}
+	defer tail.Stop()
+	expectLines(t, tail, "line 1")
+	fp := tail.Fingerprint()
+
+	// The lines written before and after the rename are drained to EOF before the tail stops
+	appendFile(t, name, "line 2\n")
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatalf("failed to rename %v: %v", name, err)
+	}
+	appendFile(t, name+".1", "line 3\n")
+	writeFile(t, name, "new line 1\n")
+	expectLines(t, tail, "line 2", "line 3")
+
+	select {
+	case line, ok := <-tail.Lines:
+		if ok {
+			t.Errorf("unexpected line %v after the renamed file was drained", line.Text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("tail of renamed file %v did not stop", name)
+	}
+	// the last fingerprint is kept once the tail is stopped
+	assertMatches(t, tail.Fingerprint(), name+".1", true)
+	if !fp.SameFile(tail.Fingerprint()) {
+		t.Errorf("fingerprint %v of the renamed file changed to %v", fp, tail.Fingerprint())
+	}
+}
+
+func TestTailInotify(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	if !watch.SupportsInotify(dir) {
+		t.Skipf("the changes of %v are not reported by inotify", dir)
+	}
+	name := filepath.Join(dir, "app.log")
+	writeFile(t, name, "line 1\n")
+
+	tail, err := TailFile(name, Config{
+		Logger:    &testLogger{},
+		Follow:    true,
+		MustExist: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to tail file %v: %v", name, err)
+	}
+	defer tail.Stop()
+	expectLines(t, tail, "line 1")
+
+	appendFile(t, name, "line 2\n")
+	expectLines(t, tail, "line 2")
+
+	// A change of the attributes of the file is not a deletion
+	if err := os.Chmod(name, 0600); err != nil {
+		t.Fatalf("failed to chmod %v: %v", name, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	appendFile(t, name, "line 3\n")
+	expectLines(t, tail, "line 3")
+
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatalf("failed to rename %v: %v", name, err)
+	}
+	select {
+	case line, ok := <-tail.Lines:
+		if ok {
+			t.Errorf("unexpected line %v after the file was renamed", line.Text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("tail of renamed file %v did not stop", name)
+	}
+	if tail.Poll {
+		t.Errorf("file %v was polled instead of watched with inotify", name)
+	}
+}
+
+func TestTailFingerprintAfterTruncate(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+	writeFile(t, name, "line 1\nline 2\n")
+
+	tail, err := TailFile(name, Config{
+		Logger:    &testLogger{},
+		Follow:    true,
+		MustExist: true,
+		Poll:      true,
+	})
+	if err != nil {
+		t.Fatalf("failed to tail file %v: %v", name, err)
+	}
+	defer tail.Stop()
+	expectLines(t, tail, "line 1", "line 2")
+	before := tail.Fingerprint()
+
+	// the polling watcher detects the truncation from the size of its previous poll
+	time.Sleep(2 * watch.POLL_DURATION)
+	if err := os.Truncate(name, 0); err != nil {
+		t.Fatalf("failed to truncate %v: %v", name, err)
+	}
+	appendFile(t, name, "new 1\n")
+	expectLines(t, tail, "new 1")
+
+	after := tail.Fingerprint()
+	if !before.SameFile(after) || before.Hash == after.Hash {
+		t.Errorf("fingerprint %v of the truncated file should have the same inode but another hash than %v", after, before)
+	}
+	assertMatches(t, after, name, true)
+}
+
+func TestTailCompressedFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log.1.gz")
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte("line 1\nline 2\nline 3\n"))
+	w.Close()
+	writeFile(t, name, buf.String())
+
+	decompress := func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+	tail, err := TailFile(name, Config{
+		Logger:     &testLogger{},
+		MustExist:  true,
+		Poll:       true,
+		Location:   &SeekInfo{Offset: int64(len("line 1\n")), Whence: io.SeekStart},
+		Decompress: decompress,
+	})
+	if err != nil {
+		t.Fatalf("failed to tail file %v: %v", name, err)
+	}
+	defer tail.Stop()
+
+	// The offsets are in the decompressed content, and the tail stops at the end of the compressed file
+	expectLines(t, tail, "line 2", "line 3")
+	select {
+	case line, ok := <-tail.Lines:
+		if ok {
+			t.Errorf("unexpected line %v after the end of the compressed file", line.Text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("tail of compressed file %v did not stop", name)
+	}
+	if tail.curOffset != int64(len("line 1\nline 2\nline 3\n")) {
+		t.Errorf("wrong offset %v at the end of the compressed file", tail.curOffset)
+	}
+
+	// An invalid compressed file stops the tail with an error
+	writeFile(t, name, "not compressed\n")
+	tail, err = TailFile(name, Config{Logger: &testLogger{}, MustExist: true, Poll: true, Decompress: decompress})
+	if err != nil {
+		t.Fatalf("failed to tail file %v: %v", name, err)
+	}
+	<-tail.Dead()
+	if tail.Err() == nil {
+		t.Errorf("tail of an invalid compressed file should fail")
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tailrotation")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, name, content string) {
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %v: %v", name, err)
+	}
+}
+
+func appendFile(t *testing.T, name, content string) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open %v: %v", name, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("failed to append to %v: %v", name, err)
+	}
+}
+
+func assertMatches(t *testing.T, fp *Fingerprint, name string, expected bool) {
+	matches, err := fp.MatchesFile(name)
+	if err != nil {
+		t.Fatalf("failed to match fingerprint %v with %v: %v", fp, name, err)
+	}
+	if matches != expected {
+		t.Errorf("fingerprint %v matches %v: %v, expecting %v", fp, name, matches, expected)
+	}
+}
+
+func expectLines(t *testing.T, tail *Tail, expected ...string) {
+	for _, text := range expected {
+		select {
+		case line, ok := <-tail.Lines:
+			if !ok {
+				t.Fatalf("tail stopped before line '%v'", text)
+			}
+			if line.Text != text {
+				t.Errorf("wrong line from tail found: '%v', expecting '%v'", line.Text, text)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("line '%v' not tailed", text)
+		}
+	}
+}
+
 func setup(t *testing.T) (*os.File, *Tail, *testLogger) {
 	tmpfile, err := ioutil.TempFile("", "example")
 	if err != nil {
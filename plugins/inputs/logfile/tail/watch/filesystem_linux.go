@@ -0,0 +1,43 @@
+// +build linux
+
+package watch
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+// the magic numbers of statfs(2) of the filesystems whose changes made by other hosts, or the lower layers
+// of an overlay, are not reported by inotify
+const (
+	nfsSuperMagic    = 0x6969
+	smbSuperMagic    = 0x517b
+	cifsMagicNumber  = 0xff534d42
+	smb2MagicNumber  = 0xfe534d42
+	overlayfsMagic   = 0x794c7630
+	fuseSuperMagic   = 0x65735546 // including GlusterFS
+	v9fsMagic        = 0x01021997
+	cephSuperMagic   = 0x00c36400
+	afsSuperMagic    = 0x5346414f
+	codaSuperMagic   = 0x73757245
+	gfs2Magic        = 0x01161970
+	lustreSuperMagic = 0x0bd00bd0
+)
+
+// SupportsInotify returns whether the changes of the file, or of the entries of the directory, are reported
+// by inotify. The file systems like NFS or overlay have to be polled.
+func SupportsInotify(name string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(name, &st); err != nil {
+		// the file does not exist yet, its directory is watched for its creation
+		if err := syscall.Statfs(filepath.Dir(name), &st); err != nil {
+			return false
+		}
+	}
+	switch uint32(st.Type) {
+	case nfsSuperMagic, smbSuperMagic, cifsMagicNumber, smb2MagicNumber, overlayfsMagic, fuseSuperMagic,
+		v9fsMagic, cephSuperMagic, afsSuperMagic, codaSuperMagic, gfs2Magic, lustreSuperMagic:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,9 @@
+// +build !linux
+
+package watch
+
+// SupportsInotify returns whether the changes of the file, or of the entries of the directory, are reported
+// by inotify. The files are only watched with inotify on Linux, they are polled on the other platforms.
+func SupportsInotify(name string) bool {
+	return false
+}
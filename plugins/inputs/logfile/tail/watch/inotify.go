@@ -65,7 +65,11 @@ func (fw *InotifyFileWatcher) BlockUntilExists(t *tomb.Tomb) error {
 }
 
 func (fw *InotifyFileWatcher) ChangeEvents(t *tomb.Tomb, pos int64) (*FileChanges, error) {
-	err := Watch(fw.Filename)
+	origFi, err := os.Stat(fw.Filename)
+	if err != nil {
+		return nil, err
+	}
+	err = Watch(fw.Filename)
 	if err != nil {
 		return nil, err
 	}
@@ -97,10 +101,14 @@ func (fw *InotifyFileWatcher) ChangeEvents(t *tomb.Tomb, pos int64) (*FileChange
 			switch {
 			//With an open fd, unlink(fd) - inotify returns IN_ATTRIB (==fsnotify.Chmod)
 			case evt.Op&fsnotify.Chmod == fsnotify.Chmod:
-				if _, err := os.Stat(fw.Filename); err != nil {
-					if !os.IsNotExist(err) {
-						return
-					}
+				fi, err := os.Stat(fw.Filename)
+				if err == nil && os.SameFile(origFi, fi) {
+					// Only the attributes changed, e.g. touch or chmod
+					continue
+				}
+				if err != nil && !os.IsNotExist(err) {
+					log.Printf("E! [logfile] Failed to stat file %v: %v", fw.Filename, err)
+					continue
 				}
 				fallthrough
 
@@ -121,6 +129,7 @@ func (fw *InotifyFileWatcher) ChangeEvents(t *tomb.Tomb, pos int64) (*FileChange
 						return
 					}
 					log.Printf("E! [logfile] Failed to stat file %v: %v", fw.Filename, err)
+					continue
 				}
 				fw.Size = fi.Size()
 
@@ -4,6 +4,7 @@
 package watch
 
 import (
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
@@ -22,6 +23,8 @@ type InotifyTracker struct {
 	watch     chan *watchInfo
 	remove    chan *watchInfo
 	error     chan error
+	// err is the error creating the watcher, e.g. when the fs.inotify.max_user_instances limit is reached
+	err error
 }
 
 type watchInfo struct {
@@ -49,9 +52,16 @@ var (
 			remove:    make(chan *watchInfo),
 			error:     make(chan error),
 		}
+		shared.watcher, shared.err = fsnotify.NewWatcher()
+		if shared.err != nil {
+			log.Printf("E! [logfile] Failed to create Watcher: %v", shared.err)
+			return
+		}
 		go shared.run()
 	}
 
+	errNoWatcher = errors.New("inotify watcher is not available")
+
 	logger = log.New(os.Stderr, "", log.LstdFlags)
 )
 
@@ -74,6 +84,9 @@ func WatchCreate(fname string) error {
 func watch(winfo *watchInfo) error {
 	// start running the shared InotifyTracker if not already running
 	once.Do(goRun)
+	if shared.err != nil {
+		return errNoWatcher
+	}
 
 	winfo.fname = filepath.Clean(winfo.fname)
 	shared.watch <- winfo
@@ -98,6 +111,9 @@ func RemoveWatchCreate(fname string) error {
 func remove(winfo *watchInfo) error {
 	// start running the shared InotifyTracker if not already running
 	once.Do(goRun)
+	if shared.err != nil {
+		return nil
+	}
 
 	winfo.fname = filepath.Clean(winfo.fname)
 	shared.mux.Lock()
@@ -212,12 +228,6 @@ func (shared *InotifyTracker) sendEvent(event fsnotify.Event) {
 // run starts the goroutine in which the shared struct reads events from its
 // Watcher's Event channel and sends the events to the appropriate Tail.
 func (shared *InotifyTracker) run() {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Printf("E! [logfile] Failed to create Watcher")
-	}
-	shared.watcher = watcher
-
 	for {
 		select {
 		case winfo := <-shared.watch:
@@ -5,14 +5,13 @@ package logfile
 
 import (
 	"bytes"
-	"io/ioutil"
 	"log"
 	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/parser"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
 	"golang.org/x/text/encoding"
 )
@@ -40,6 +39,7 @@ func (fo *fileOffset) SetOffset(o int64) {
 type LogEvent struct {
 	msg    string
 	t      time.Time
+	fields map[string]interface{}
 	offset fileOffset
 	src    *tailerSrc
 }
@@ -52,6 +52,11 @@ func (le LogEvent) Time() time.Time {
 	return le.t
 }
 
+// Fields returns the fields of the log event parsed by the parser of its file config, nil without parser
+func (le LogEvent) Fields() map[string]interface{} {
+	return le.fields
+}
+
 func (le LogEvent) Done() {
 	le.src.Done(le.offset)
 }
@@ -67,10 +72,14 @@ type tailerSrc struct {
 	maxEventSize    int
 	truncateSuffix  string
 	retentionInDays int
+	processors      []logs.LogProcessor
+	parser          *parser.Parser
+	compressed      bool
 
 	outputFn        func(logs.LogEvent)
 	isMLStart       func(string) bool
 	offsetCh        chan fileOffset
+	eofCh           chan int64
 	done            chan struct{}
 	startTailerOnce sync.Once
 	cleanUpFns      []func()
@@ -102,6 +111,7 @@ func NewTailerSrc(
 		retentionInDays: retentionInDays,
 
 		offsetCh: make(chan fileOffset, 2000),
+		eofCh:    make(chan int64, 1),
 		done:     make(chan struct{}),
 	}
 	go ts.runSaveState()
@@ -135,6 +145,27 @@ func (ts tailerSrc) Destination() string {
 func (ts tailerSrc) Retention() int {
 	return ts.retentionInDays
 }
+
+func (ts *tailerSrc) Processors() []logs.LogProcessor {
+	return ts.processors
+}
+
+// SetProcessors sets the processing chain applied by the log agent on the log events of this src
+func (ts *tailerSrc) SetProcessors(processors []logs.LogProcessor) {
+	ts.processors = processors
+}
+
+// SetParser sets the parser of the log events, they are parsed before they are published
+func (ts *tailerSrc) SetParser(p *parser.Parser) {
+	ts.parser = p
+}
+
+// SetCompressed marks the src as tailing a compressed file, which is read once: its log events older than
+// CloudWatch Logs accepts are skipped, and its state records when it is completely uploaded.
+func (ts *tailerSrc) SetCompressed() {
+	ts.compressed = true
+}
+
 func (ts tailerSrc) Done(offset fileOffset) {
 	// ts.offsetCh will only be blocked when the runSaveState func has exited,
 	// which only happens when the original file has been removed, thus making
@@ -169,14 +200,10 @@ func (ts *tailerSrc) runTail() {
 		case line, ok := <-ts.tailer.Lines:
 			if !ok {
 				if msgBuf.Len() > 0 {
-					msg := msgBuf.String()
-					e := &LogEvent{
-						msg:    msg,
-						t:      ts.timestampFn(msg),
-						offset: *fo,
-						src:    ts,
-					}
-					ts.outputFn(e)
+					ts.publish(ts.newLogEvent(msgBuf.String(), *fo))
+				}
+				if ts.compressed {
+					ts.eofCh <- fo.offset
 				}
 				return
 			}
@@ -220,14 +247,7 @@ func (ts *tailerSrc) runTail() {
 			}
 
 			if msgBuf.Len() > 0 {
-				msg := msgBuf.String()
-				e := &LogEvent{
-					msg:    msg,
-					t:      ts.timestampFn(msg),
-					offset: *fo,
-					src:    ts,
-				}
-				ts.outputFn(e)
+				ts.publish(ts.newLogEvent(msgBuf.String(), *fo))
 			}
 
 			msgBuf.Reset()
@@ -243,14 +263,7 @@ func (ts *tailerSrc) runTail() {
 				continue
 			}
 
-			msg := msgBuf.String()
-			e := &LogEvent{
-				msg:    msg,
-				t:      ts.timestampFn(msg),
-				offset: *fo,
-				src:    ts,
-			}
-			ts.outputFn(e)
+			ts.publish(ts.newLogEvent(msgBuf.String(), *fo))
 			msgBuf.Reset()
 			cnt = 0
 		case <-ts.done:
@@ -259,6 +272,32 @@ func (ts *tailerSrc) runTail() {
 	}
 }
 
+// newLogEvent creates the log event of the message read up to the offset, its timestamp is taken from the fields
+// parsed by the parser if any, or found by the timestamp_regex
+func (ts *tailerSrc) newLogEvent(msg string, offset fileOffset) *LogEvent {
+	e := &LogEvent{msg: msg, offset: offset, src: ts}
+	if ts.parser != nil {
+		e.fields, e.t, e.msg = ts.parser.Parse(msg)
+		if len(e.msg) > ts.maxEventSize {
+			// the log event reserialized as JSON is published as it was read rather than truncated
+			e.msg = msg
+		}
+	}
+	if e.t.IsZero() {
+		e.t = ts.timestampFn(msg)
+	}
+	return e
+}
+
+func (ts *tailerSrc) publish(e *LogEvent) {
+	if ts.compressed && !e.t.IsZero() && time.Since(e.t) > maxCompressedLogAge {
+		// the log event would be rejected, it is skipped as if it was uploaded
+		e.Done()
+		return
+	}
+	ts.outputFn(e)
+}
+
 func (ts *tailerSrc) cleanUp() {
 	if ts.autoRemoval {
 		if err := os.Remove(ts.tailer.Filename); err != nil {
@@ -278,24 +317,28 @@ func (ts *tailerSrc) runSaveState() {
 	defer t.Stop()
 
 	var offset, lastSavedOffset fileOffset
+	var lastSavedComplete bool
+	eof := int64(-1) // the offset of the end of a compressed file, once it is read
 	for {
 		select {
 		case o := <-ts.offsetCh:
 			if o.seq > offset.seq || (o.seq == offset.seq && o.offset > offset.offset) {
 				offset = o
 			}
+		case eof = <-ts.eofCh:
 		case <-t.C:
-			if offset == lastSavedOffset {
+			complete := eof >= 0 && offset.offset >= eof
+			if offset == lastSavedOffset && complete == lastSavedComplete {
 				continue
 			}
-			err := ts.saveState(offset.offset)
+			err := ts.saveState(offset.offset, complete)
 			if err != nil {
 				log.Printf("E! [logfile] Error happened when saving file state %s to file state folder %s: %v", ts.tailer.Filename, ts.stateFilePath, err)
 				continue
 			}
-			lastSavedOffset = offset
+			lastSavedOffset, lastSavedComplete = offset, complete
 		case <-ts.done:
-			err := ts.saveState(offset.offset)
+			err := ts.saveState(offset.offset, eof >= 0 && offset.offset >= eof)
 			if err != nil {
 				log.Printf("E! [logfile] Error happened during final file state saving of logfile %s to file state folder %s, duplicate log maybe sent at next start: %v", ts.tailer.Filename, ts.stateFilePath, err)
 			}
@@ -304,11 +347,15 @@ func (ts *tailerSrc) runSaveState() {
 	}
 }
 
-func (ts *tailerSrc) saveState(offset int64) error {
-	if ts.stateFilePath == "" || offset == 0 {
+func (ts *tailerSrc) saveState(offset int64, complete bool) error {
+	if ts.stateFilePath == "" || (offset == 0 && !complete) {
 		return nil
 	}
 
-	content := []byte(strconv.FormatInt(offset, 10) + "\n" + ts.tailer.Filename)
-	return ioutil.WriteFile(ts.stateFilePath, content, stateFileMode)
+	return writeFileState(ts.stateFilePath, fileState{
+		offset:      offset,
+		filename:    ts.tailer.Filename,
+		fingerprint: ts.tailer.Fingerprint(),
+		complete:    complete,
+	})
 }
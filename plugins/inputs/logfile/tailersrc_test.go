@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/parser"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
 )
 
@@ -296,6 +297,43 @@ func TestOffsetDoneCallBack(t *testing.T) {
 	}
 }
 
+func TestTailerSrcParser(t *testing.T) {
+	p, err := parser.New(parser.Config{Type: parser.Logfmt, TimestampField: "ts", OutputFormat: parser.OutputJSON}, time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to create the parser: %v", err)
+	}
+	ts := &tailerSrc{timestampFn: parseRFC3339Timestamp, maxEventSize: 64}
+	ts.SetParser(p)
+
+	e := ts.newLogEvent(`ts=2020-10-11T22:14:15Z level=info msg="started"`, fileOffset{})
+	if expected := `{"level":"info","msg":"started","ts":"2020-10-11T22:14:15Z"}`; e.Message() != expected {
+		t.Errorf("The log event should be published as JSON: %v != %v", e.Message(), expected)
+	}
+	if e.Fields()["level"] != "info" {
+		t.Errorf("Unexpected fields of the log event: %v", e.Fields())
+	}
+	if expected := time.Date(2020, 10, 11, 22, 14, 15, 0, time.UTC); !e.Time().Equal(expected) {
+		t.Errorf("The timestamp should be taken from the ts field: %v != %v", e.Time(), expected)
+	}
+
+	// the timestamp_regex is used when the log event has no timestamp field
+	msg := "2020-10-11T22:14:15Z not logfmt"
+	e = ts.newLogEvent(msg, fileOffset{})
+	if e.Message() != msg || e.Fields() != nil {
+		t.Errorf("The log event which cannot be parsed should be published as it was read: %v %v", e.Message(), e.Fields())
+	}
+	if expected := parseRFC3339Timestamp(msg); !e.Time().Equal(expected) {
+		t.Errorf("The timestamp should be found by the timestamp_regex: %v != %v", e.Time(), expected)
+	}
+
+	// the log event is not reserialized beyond the max event size
+	msg = "msg=" + strings.Repeat("a", 58)
+	e = ts.newLogEvent(msg, fileOffset{})
+	if e.Message() != msg || e.Fields()["msg"] != strings.Repeat("a", 58) {
+		t.Errorf("The log event larger than the max event size as JSON should be published as it was read: %v", e.Message())
+	}
+}
+
 func parseRFC3339Timestamp(line string) time.Time {
 	// Use RFC3339 for testing `2006-01-02T15:04:05Z07:00`
 	re := regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[Z+\-]\d{2}:\d{2}`)
@@ -0,0 +1,315 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"encoding/base64"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/influxdata/telegraf"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+const (
+	// the field name the cloudwatch output translates into the measurement name alone
+	defaultFieldName = "value"
+
+	// the state of the cumulative series which are not received anymore is dropped after the expiry
+	cumulativeExpiry      = 10 * time.Minute
+	cumulativeCleanPeriod = time.Minute
+)
+
+// The UCUM units used by the OpenTelemetry semantic conventions and their CloudWatch units
+var cloudWatchUnits = map[string]string{
+	"s":     "Seconds",
+	"ms":    "Milliseconds",
+	"us":    "Microseconds",
+	"By":    "Bytes",
+	"KiBy":  "Kilobytes",
+	"MiBy":  "Megabytes",
+	"GiBy":  "Gigabytes",
+	"bit":   "Bits",
+	"By/s":  "Bytes/Second",
+	"bit/s": "Bits/Second",
+	"%":     "Percent",
+	"1":     "None",
+}
+
+// converter maps the OTLP metrics onto telegraf metrics, the cumulative sums and histograms are converted to the
+// deltas between their data points, like the other CloudWatch agent inputs report them
+type converter struct {
+	mu        sync.Mutex
+	series    map[string]*cumulativeState
+	lastClean time.Time
+	now       func() time.Time
+}
+
+type cumulativeState struct {
+	startTime uint64
+	count     float64
+	values    map[float64]float64
+	lastSeen  time.Time
+}
+
+func newConverter() *converter {
+	return &converter{
+		series: make(map[string]*cumulativeState),
+		now:    time.Now,
+	}
+}
+
+// convert adds the metrics of the request to the accumulator and returns the number of data points which are not
+// supported
+func (c *converter) convert(req *collectorpb.ExportMetricsServiceRequest, acc telegraf.Accumulator) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cleanExpired()
+
+	var rejected int64
+	for _, rm := range req.ResourceMetrics {
+		var resourceTags map[string]string
+		if rm.Resource != nil {
+			resourceTags = attributesToTags(rm.Resource.Attributes, nil)
+		}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				switch data := m.Data.(type) {
+				case *metricspb.Metric_Gauge:
+					c.convertNumbers(m, data.Gauge.DataPoints, false, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED, resourceTags, acc)
+				case *metricspb.Metric_Sum:
+					c.convertNumbers(m, data.Sum.DataPoints, data.Sum.IsMonotonic, data.Sum.AggregationTemporality, resourceTags, acc)
+				case *metricspb.Metric_Histogram:
+					for _, dp := range data.Histogram.DataPoints {
+						tags := attributesToTags(dp.Attributes, resourceTags)
+						c.addDistribution(m, explicitBucketValues(dp), float64(dp.Count), dp.StartTimeUnixNano, dp.TimeUnixNano, data.Histogram.AggregationTemporality, tags, acc)
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					for _, dp := range data.ExponentialHistogram.DataPoints {
+						tags := attributesToTags(dp.Attributes, resourceTags)
+						c.addDistribution(m, exponentialBucketValues(dp), float64(dp.Count), dp.StartTimeUnixNano, dp.TimeUnixNano, data.ExponentialHistogram.AggregationTemporality, tags, acc)
+					}
+				default:
+					log.Printf("D! [inputs.otlp] The type of metric %v is not supported", m.Name)
+					rejected++
+				}
+			}
+		}
+	}
+	return rejected
+}
+
+func (c *converter) convertNumbers(m *metricspb.Metric, dps []*metricspb.NumberDataPoint, monotonic bool,
+	temporality metricspb.AggregationTemporality, resourceTags map[string]string, acc telegraf.Accumulator) {
+	for _, dp := range dps {
+		var value float64
+		switch v := dp.Value.(type) {
+		case *metricspb.NumberDataPoint_AsDouble:
+			value = v.AsDouble
+		case *metricspb.NumberDataPoint_AsInt:
+			value = float64(v.AsInt)
+		default:
+			continue
+		}
+		tags := attributesToTags(dp.Attributes, resourceTags)
+		fields := map[string]interface{}{defaultFieldName: value}
+		ts := timestamp(dp.TimeUnixNano)
+
+		// the non monotonic sums are reported like gauges, as the current value is what matters
+		if !monotonic {
+			acc.AddGauge(m.Name, fields, tags, ts)
+			continue
+		}
+		if temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+			delta, ok := c.delta(seriesKey(m.Name, tags), dp.StartTimeUnixNano, value, map[float64]float64{0: value})
+			if !ok {
+				continue
+			}
+			fields[defaultFieldName] = delta[0]
+		}
+		acc.AddCounter(m.Name, fields, tags, ts)
+	}
+}
+
+func (c *converter) addDistribution(m *metricspb.Metric, values map[float64]float64, count float64, startTime, timeUnixNano uint64,
+	temporality metricspb.AggregationTemporality, tags map[string]string, acc telegraf.Accumulator) {
+	if temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		var ok bool
+		if values, ok = c.delta(seriesKey(m.Name, tags), startTime, count, values); !ok {
+			return
+		}
+	}
+
+	dist := distribution.NewDistribution()
+	unit := cloudWatchUnits[m.Unit]
+	for value, weight := range values {
+		if weight > 0 {
+			dist.AddEntryWithUnit(value, weight, unit)
+		}
+	}
+	if dist.SampleCount() == 0 {
+		return
+	}
+	acc.AddFields(m.Name, map[string]interface{}{defaultFieldName: dist}, tags, timestamp(timeUnixNano))
+}
+
+// delta returns the difference between the values of the cumulative data point and the previous one of its series.
+// The first data point of a series only initializes it, and the values of a data point following a reset of the
+// series are returned as they are.
+func (c *converter) delta(key string, startTime uint64, count float64, values map[float64]float64) (map[float64]float64, bool) {
+	prev, ok := c.series[key]
+	c.series[key] = &cumulativeState{startTime: startTime, count: count, values: values, lastSeen: c.now()}
+	if !ok {
+		return nil, false
+	}
+	if startTime != prev.startTime || count < prev.count {
+		return values, true
+	}
+
+	delta := make(map[float64]float64, len(values))
+	for k, v := range values {
+		if d := v - prev.values[k]; d > 0 {
+			delta[k] = d
+		}
+	}
+	return delta, true
+}
+
+func (c *converter) cleanExpired() {
+	now := c.now()
+	if now.Sub(c.lastClean) < cumulativeCleanPeriod {
+		return
+	}
+	c.lastClean = now
+	for k, s := range c.series {
+		if now.Sub(s.lastSeen) > cumulativeExpiry {
+			delete(c.series, k)
+		}
+	}
+}
+
+// explicitBucketValues returns the middle of each bucket with the count of the bucket, the unbounded buckets use
+// their finite bound, or the minimum and maximum of the data point when they are set
+func explicitBucketValues(dp *metricspb.HistogramDataPoint) map[float64]float64 {
+	values := make(map[float64]float64)
+	bounds := dp.ExplicitBounds
+	if len(bounds) == 0 || len(dp.BucketCounts) != len(bounds)+1 {
+		// a single bucket covering all the values
+		if dp.Count > 0 && dp.Sum != nil {
+			values[*dp.Sum/float64(dp.Count)] = float64(dp.Count)
+		}
+		return values
+	}
+	for i, count := range dp.BucketCounts {
+		if count == 0 {
+			continue
+		}
+		var value float64
+		switch {
+		case i == 0:
+			value = bounds[0]
+			if dp.Min != nil {
+				value = (*dp.Min + bounds[0]) / 2
+			} else if bounds[0] > 0 {
+				value = bounds[0] / 2
+			}
+		case i == len(bounds):
+			value = bounds[i-1]
+			if dp.Max != nil {
+				value = (bounds[i-1] + *dp.Max) / 2
+			}
+		default:
+			value = (bounds[i-1] + bounds[i]) / 2
+		}
+		values[value] += float64(count)
+	}
+	return values
+}
+
+// exponentialBucketValues returns the middle of each positive bucket, whose bounds are base^index and
+// base^(index+1) with base = 2^(2^-scale), with the count of the bucket. The negative buckets are skipped since
+// the distributions only hold positive values.
+func exponentialBucketValues(dp *metricspb.ExponentialHistogramDataPoint) map[float64]float64 {
+	values := make(map[float64]float64)
+	if dp.ZeroCount > 0 {
+		values[0] = float64(dp.ZeroCount)
+	}
+	if dp.Negative != nil && len(dp.Negative.BucketCounts) > 0 {
+		log.Printf("D! [inputs.otlp] The negative buckets of the exponential histogram are skipped")
+	}
+	if dp.Positive == nil {
+		return values
+	}
+	base := math.Pow(2, math.Pow(2, -float64(dp.Scale)))
+	for i, count := range dp.Positive.BucketCounts {
+		if count == 0 {
+			continue
+		}
+		index := float64(dp.Positive.Offset) + float64(i)
+		lower := math.Pow(base, index)
+		upper := math.Pow(base, index+1)
+		values[(lower+upper)/2] += float64(count)
+	}
+	return values
+}
+
+// attributesToTags returns the tags made of the attributes on top of the base tags, the attributes which have
+// no value or a non scalar one are skipped
+func attributesToTags(attributes []*commonpb.KeyValue, base map[string]string) map[string]string {
+	tags := make(map[string]string, len(base)+len(attributes))
+	for k, v := range base {
+		tags[k] = v
+	}
+	for _, kv := range attributes {
+		if kv.Value == nil || kv.Key == "" {
+			continue
+		}
+		var value string
+		switch v := kv.Value.Value.(type) {
+		case *commonpb.AnyValue_StringValue:
+			value = v.StringValue
+		case *commonpb.AnyValue_BoolValue:
+			value = strconv.FormatBool(v.BoolValue)
+		case *commonpb.AnyValue_IntValue:
+			value = strconv.FormatInt(v.IntValue, 10)
+		case *commonpb.AnyValue_DoubleValue:
+			value = strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+		case *commonpb.AnyValue_BytesValue:
+			value = base64.StdEncoding.EncodeToString(v.BytesValue)
+		}
+		if value != "" {
+			tags[kv.Key] = value
+		}
+	}
+	return tags
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, k := range keys {
+		sb.WriteString("," + k + "=" + tags[k])
+	}
+	return sb.String()
+}
+
+func timestamp(unixNano uint64) time.Time {
+	if unixNano == 0 {
+		return time.Now()
+	}
+	return time.Unix(0, int64(unixNano))
+}
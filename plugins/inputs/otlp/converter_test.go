@@ -0,0 +1,180 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+const testTime = uint64(1600000000000000000)
+
+func stringAttribute(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func buildRequest(metrics ...*metricspb.Metric) *collectorpb.ExportMetricsServiceRequest {
+	return &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				stringAttribute("service.name", "checkout"),
+				{Key: "host.cpus", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 4}}},
+			}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+		}},
+	}
+}
+
+func sumMetric(value float64, startTime uint64, temporality metricspb.AggregationTemporality) *metricspb.Metric {
+	return &metricspb.Metric{
+		Name: "requests",
+		Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			IsMonotonic:            true,
+			AggregationTemporality: temporality,
+			DataPoints: []*metricspb.NumberDataPoint{{
+				Attributes:        []*commonpb.KeyValue{stringAttribute("route", "/cart")},
+				StartTimeUnixNano: startTime,
+				TimeUnixNano:      testTime,
+				Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+			}},
+		}},
+	}
+}
+
+func TestConverter_Gauge(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	c := newConverter()
+	rejected := c.convert(buildRequest(&metricspb.Metric{
+		Name: "queue_size",
+		Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+			TimeUnixNano: testTime,
+			Value:        &metricspb.NumberDataPoint_AsInt{AsInt: 12},
+		}}}},
+	}), acc)
+
+	assert.Equal(t, int64(0), rejected)
+	require.Equal(t, 1, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "queue_size", map[string]interface{}{"value": float64(12)},
+		map[string]string{"service.name": "checkout", "host.cpus": "4"})
+	assert.Equal(t, telegraf.Gauge, acc.Metrics[0].Type)
+	assert.Equal(t, time.Unix(0, int64(testTime)), acc.Metrics[0].Time)
+}
+
+func TestConverter_CumulativeSum(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	c := newConverter()
+	tags := map[string]string{"service.name": "checkout", "host.cpus": "4", "route": "/cart"}
+
+	// the first data point only initializes the series
+	c.convert(buildRequest(sumMetric(10, 1, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE)), acc)
+	assert.Equal(t, 0, len(acc.Metrics))
+
+	c.convert(buildRequest(sumMetric(25, 1, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE)), acc)
+	require.Equal(t, 1, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "requests", map[string]interface{}{"value": float64(15)}, tags)
+	assert.Equal(t, telegraf.Counter, acc.Metrics[0].Type)
+
+	// the series is reset when its start time changes
+	acc.ClearMetrics()
+	c.convert(buildRequest(sumMetric(3, 2, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE)), acc)
+	acc.AssertContainsTaggedFields(t, "requests", map[string]interface{}{"value": float64(3)}, tags)
+
+	acc.ClearMetrics()
+	c.convert(buildRequest(sumMetric(7, 2, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA)), acc)
+	acc.AssertContainsTaggedFields(t, "requests", map[string]interface{}{"value": float64(7)}, tags)
+}
+
+func TestConverter_Histogram(t *testing.T) {
+	distribution.NewDistribution = regular.NewRegularDistribution
+	acc := &testutil.Accumulator{}
+	c := newConverter()
+	max := float64(30)
+	c.convert(buildRequest(&metricspb.Metric{
+		Name: "latency",
+		Unit: "ms",
+		Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+			DataPoints: []*metricspb.HistogramDataPoint{{
+				TimeUnixNano:   testTime,
+				Count:          6,
+				BucketCounts:   []uint64{1, 3, 0, 2},
+				ExplicitBounds: []float64{2, 4, 10},
+				Max:            &max,
+			}},
+		}},
+	}), acc)
+
+	require.Equal(t, 1, len(acc.Metrics))
+	dist := acc.Metrics[0].Fields["value"].(distribution.Distribution)
+	assert.Equal(t, float64(6), dist.SampleCount())
+	assert.Equal(t, float64(1), dist.Minimum())
+	assert.Equal(t, float64(20), dist.Maximum())
+	// 1*1 + 3*3 + 2*20
+	assert.Equal(t, float64(50), dist.Sum())
+	assert.Equal(t, "Milliseconds", dist.Unit())
+}
+
+func TestConverter_ExponentialHistogram(t *testing.T) {
+	distribution.NewDistribution = regular.NewRegularDistribution
+	acc := &testutil.Accumulator{}
+	c := newConverter()
+	metric := func(counts []uint64, count uint64) *metricspb.Metric {
+		return &metricspb.Metric{
+			Name: "size",
+			Data: &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: &metricspb.ExponentialHistogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricspb.ExponentialHistogramDataPoint{{
+					StartTimeUnixNano: 1,
+					TimeUnixNano:      testTime,
+					Count:             count,
+					Scale:             0,
+					ZeroCount:         1,
+					Positive:          &metricspb.ExponentialHistogramDataPoint_Buckets{Offset: 1, BucketCounts: counts},
+					Negative:          &metricspb.ExponentialHistogramDataPoint_Buckets{BucketCounts: []uint64{4}},
+				}},
+			}},
+		}
+	}
+	c.convert(buildRequest(metric([]uint64{1, 1}, 3)), acc)
+	assert.Equal(t, 0, len(acc.Metrics))
+
+	c.convert(buildRequest(metric([]uint64{3, 1}, 5)), acc)
+	require.Equal(t, 1, len(acc.Metrics))
+	dist := acc.Metrics[0].Fields["value"].(distribution.Distribution)
+	// only the bucket (2, 4] has new values
+	assert.Equal(t, float64(2), dist.SampleCount())
+	assert.Equal(t, float64(3), dist.Minimum())
+	assert.Equal(t, float64(3), dist.Maximum())
+}
+
+func TestConverter_Unsupported(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	rejected := newConverter().convert(buildRequest(&metricspb.Metric{Name: "summary"}), acc)
+	assert.Equal(t, int64(1), rejected)
+	assert.Equal(t, 0, len(acc.Metrics))
+}
+
+func TestConverter_Expiry(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	c := newConverter()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.convert(buildRequest(sumMetric(10, 1, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE)), acc)
+	assert.Equal(t, 1, len(c.series))
+
+	now = now.Add(cumulativeExpiry + time.Second)
+	c.convert(buildRequest(), acc)
+	assert.Equal(t, 0, len(c.series))
+}
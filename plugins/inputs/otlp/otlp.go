@@ -0,0 +1,213 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // register the gzip compressor used by the OTLP exporters
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	metricsPath         = "/v1/metrics"
+	protobufContentType = "application/x-protobuf"
+	jsonContentType     = "application/json"
+	maxRequestSize      = 4 * 1024 * 1024
+	httpShutdownTimeout = 5 * time.Second
+)
+
+type OTLP struct {
+	// Address & Port of the OTLP/gRPC server, e.g. "127.0.0.1:4317", the server is disabled if empty
+	GRPCServiceAddress string `toml:"grpc_service_address"`
+	// Address & Port of the OTLP/HTTP server, e.g. "127.0.0.1:4318", the server is disabled if empty
+	HTTPServiceAddress string `toml:"http_service_address"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	acc          telegraf.Accumulator
+	converter    *converter
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+	httpServer   *http.Server
+	httpListener net.Listener
+	wg           sync.WaitGroup
+}
+
+const sampleConfig = `
+  ## Address and port of the OTLP/gRPC server, the server is disabled if empty
+  grpc_service_address = "127.0.0.1:4317"
+  ## Address and port of the OTLP/HTTP server, the server is disabled if empty
+  http_service_address = "127.0.0.1:4318"
+`
+
+func (o *OTLP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OTLP) Description() string {
+	return "Receive metrics from the OpenTelemetry SDKs and collectors with OTLP/gRPC and OTLP/HTTP"
+}
+
+func (o *OTLP) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (o *OTLP) Start(acc telegraf.Accumulator) error {
+	if o.GRPCServiceAddress == "" && o.HTTPServiceAddress == "" {
+		return fmt.Errorf("at least one of grpc_service_address and http_service_address is required")
+	}
+	o.acc = acc
+	o.converter = newConverter()
+
+	if o.GRPCServiceAddress != "" {
+		listener, err := net.Listen("tcp", o.GRPCServiceAddress)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %v: %v", o.GRPCServiceAddress, err)
+		}
+		o.grpcListener = listener
+		o.grpcServer = grpc.NewServer(grpc.MaxRecvMsgSize(maxRequestSize))
+		collectorpb.RegisterMetricsServiceServer(o.grpcServer, &metricsServiceServer{otlp: o})
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+			if err := o.grpcServer.Serve(listener); err != nil {
+				o.Log.Errorf("OTLP/gRPC server stopped: %v", err)
+			}
+		}()
+		o.Log.Infof("Listening for OTLP/gRPC on %v", listener.Addr())
+	}
+
+	if o.HTTPServiceAddress != "" {
+		listener, err := net.Listen("tcp", o.HTTPServiceAddress)
+		if err != nil {
+			o.Stop()
+			return fmt.Errorf("failed to listen on %v: %v", o.HTTPServiceAddress, err)
+		}
+		o.httpListener = listener
+		mux := http.NewServeMux()
+		mux.HandleFunc(metricsPath, o.handleHTTP)
+		o.httpServer = &http.Server{Handler: mux}
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+			if err := o.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				o.Log.Errorf("OTLP/HTTP server stopped: %v", err)
+			}
+		}()
+		o.Log.Infof("Listening for OTLP/HTTP on %v", listener.Addr())
+	}
+	return nil
+}
+
+func (o *OTLP) Stop() {
+	if o.grpcServer != nil {
+		o.grpcServer.Stop()
+	}
+	if o.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := o.httpServer.Shutdown(ctx); err != nil {
+			o.Log.Warnf("Failed to shut down the OTLP/HTTP server: %v", err)
+		}
+	}
+	o.wg.Wait()
+}
+
+// export handles the metrics of both the gRPC and HTTP servers
+func (o *OTLP) export(req *collectorpb.ExportMetricsServiceRequest) *collectorpb.ExportMetricsServiceResponse {
+	resp := &collectorpb.ExportMetricsServiceResponse{}
+	if rejected := o.converter.convert(req, o.acc); rejected > 0 {
+		resp.PartialSuccess = &collectorpb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       "only the gauge, sum, histogram and exponential histogram metrics are supported",
+		}
+	}
+	return resp
+}
+
+// handleHTTP decodes the request with its content type, the binary protobuf or JSON encodings of OTLP/HTTP, and
+// encodes the response the same way
+func (o *OTLP) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var unmarshal func([]byte, proto.Message) error
+	var marshal func(proto.Message) ([]byte, error)
+	switch contentType {
+	case protobufContentType:
+		unmarshal, marshal = proto.Unmarshal, proto.Marshal
+	case jsonContentType:
+		unmarshal, marshal = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal, protojson.Marshal
+	default:
+		http.Error(w, "only the "+protobufContentType+" and "+jsonContentType+" content types are supported", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(payload) > maxRequestSize {
+		http.Error(w, "the request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	req := &collectorpb.ExportMetricsServiceRequest{}
+	if err := unmarshal(payload, req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	out, err := marshal(o.export(req))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
+}
+
+// metricsServiceServer is the OTLP/gRPC MetricsService
+type metricsServiceServer struct {
+	collectorpb.UnimplementedMetricsServiceServer
+	otlp *OTLP
+}
+
+func (s *metricsServiceServer) Export(_ context.Context, req *collectorpb.ExportMetricsServiceRequest) (*collectorpb.ExportMetricsServiceResponse, error) {
+	return s.otlp.export(req), nil
+}
+
+func init() {
+	inputs.Add("otlp", func() telegraf.Input {
+		return &OTLP{}
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func gaugeRequest() *collectorpb.ExportMetricsServiceRequest {
+	return buildRequest(&metricspb.Metric{
+		Name: "temperature",
+		Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+			TimeUnixNano: testTime,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 21.5},
+		}}}},
+	})
+}
+
+func TestOTLP_NoServer(t *testing.T) {
+	o := &OTLP{Log: testutil.Logger{}}
+	assert.Error(t, o.Start(&testutil.Accumulator{}))
+}
+
+func TestOTLP_HTTP(t *testing.T) {
+	o := &OTLP{HTTPServiceAddress: "127.0.0.1:0", Log: testutil.Logger{}}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, o.Start(acc))
+	defer o.Stop()
+
+	url := "http://" + o.httpListener.Addr().String() + metricsPath
+
+	payload, err := proto.Marshal(gaugeRequest())
+	require.NoError(t, err)
+	resp, err := http.Post(url, protobufContentType, bytes.NewReader(payload))
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, protobufContentType, resp.Header.Get("Content-Type"))
+	assert.NoError(t, proto.Unmarshal(body, &collectorpb.ExportMetricsServiceResponse{}))
+
+	acc.AssertContainsTaggedFields(t, "temperature", map[string]interface{}{"value": 21.5},
+		map[string]string{"service.name": "checkout", "host.cpus": "4"})
+
+	resp, err = http.Post(url, "text/plain", bytes.NewReader(payload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestOTLP_HTTPJSON(t *testing.T) {
+	o := &OTLP{HTTPServiceAddress: "127.0.0.1:0", Log: testutil.Logger{}}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, o.Start(acc))
+	defer o.Stop()
+
+	url := "http://" + o.httpListener.Addr().String() + metricsPath
+
+	// the 64 bit integers are encoded as strings and the enums may be their names or numbers
+	payload := `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+		"scopeMetrics":[{"metrics":[{"name":"requests","sum":{"isMonotonic":true,"aggregationTemporality":1,
+		"dataPoints":[{"timeUnixNano":"1600000000000000000","asInt":"3","attributes":[{"key":"code","value":{"intValue":"200"}}]}]}},
+		{"name":"latency","unknownField":1,"summary":{"dataPoints":[{"count":"1"}]}}]}]}]}`
+	resp, err := http.Post(url, "application/json; charset=utf-8", bytes.NewReader([]byte(payload)))
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, jsonContentType, resp.Header.Get("Content-Type"))
+	res := &collectorpb.ExportMetricsServiceResponse{}
+	require.NoError(t, protojson.Unmarshal(body, res))
+	assert.Equal(t, int64(1), res.PartialSuccess.RejectedDataPoints)
+
+	acc.AssertContainsTaggedFields(t, "requests", map[string]interface{}{"value": float64(3)},
+		map[string]string{"service.name": "checkout", "code": "200"})
+
+	resp, err = http.Post(url, jsonContentType, bytes.NewReader([]byte(`{"resourceMetrics":{}}`)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestOTLP_GRPC(t *testing.T) {
+	o := &OTLP{GRPCServiceAddress: "127.0.0.1:0", Log: testutil.Logger{}}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, o.Start(acc))
+	defer o.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, o.grpcListener.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := collectorpb.NewMetricsServiceClient(conn).Export(ctx, gaugeRequest())
+	require.NoError(t, err)
+	assert.Nil(t, resp.PartialSuccess)
+	acc.AssertContainsTaggedFields(t, "temperature", map[string]interface{}{"value": 21.5},
+		map[string]string{"service.name": "checkout", "host.cpus": "4"})
+}
@@ -6,10 +6,21 @@ package prometheus_scraper
 import (
 	"log"
 	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/seh1"
+)
+
+const (
+	HistogramOutputValuesCounts = "values_counts"
+	HistogramOutputDistribution = "distribution"
 )
 
 type Calculator struct {
 	deltaCalculator *DeltaCalculator
+	// creates the distribution the histogram buckets are merged into
+	newDistribution func() distribution.Distribution
 }
 
 func appendValidValue(pmb PrometheusMetricBatch, pm *PrometheusMetric) PrometheusMetricBatch {
@@ -25,6 +36,8 @@ func (c *Calculator) Calculate(pmb PrometheusMetricBatch) (result PrometheusMetr
 	var gauges PrometheusMetricBatch
 	var counters PrometheusMetricBatch
 	var summaries PrometheusMetricBatch
+	var histograms PrometheusMetricBatch
+	var buckets PrometheusMetricBatch
 
 	for _, pm := range pmb {
 		if pm.isGauge() {
@@ -43,17 +56,39 @@ func (c *Calculator) Calculate(pmb PrometheusMetricBatch) (result PrometheusMetr
 			} else {
 				summaries = appendValidValue(summaries, pm)
 			}
+		} else if pm.isHistogram() {
+			// calculate the delta for <basename>_count, <basename>_sum and every <basename>_bucket metrics,
+			// the buckets are then merged into a single distribution per histogram
+			if strings.HasSuffix(pm.metricName, histogramBucketSuffix) {
+				if calculatedMetric := c.deltaCalculator.calculate(pm); calculatedMetric != nil {
+					buckets = append(buckets, calculatedMetric)
+				}
+			} else if strings.HasSuffix(pm.metricName, histogramSummaryCountSuffix) ||
+				strings.HasSuffix(pm.metricName, histogramSummarySumSuffix) {
+				if calculatedMetric := c.deltaCalculator.calculate(pm); calculatedMetric != nil {
+					histograms = append(histograms, calculatedMetric)
+				}
+			}
 		}
 	}
 
 	result = append(result, gauges...)
 	result = append(result, counters...)
 	result = append(result, summaries...)
+	result = append(result, histograms...)
+	result = append(result, c.mergeHistogramBuckets(buckets)...)
 	return
 }
 
-func NewCalculator() *Calculator {
-	return &Calculator{
+// NewCalculator creates a calculator merging histogram buckets into the distribution
+// given by histogramOutput, which is HistogramOutputValuesCounts or HistogramOutputDistribution.
+func NewCalculator(histogramOutput string) *Calculator {
+	c := &Calculator{
 		deltaCalculator: NewDeltaCalculator(),
+		newDistribution: regular.NewRegularDistribution,
+	}
+	if histogramOutput == HistogramOutputDistribution {
+		c.newDistribution = seh1.NewSEH1Distribution
 	}
+	return c
 }
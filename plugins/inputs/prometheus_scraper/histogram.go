@@ -0,0 +1,113 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus_scraper
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+)
+
+type histogramBucket struct {
+	upperBound float64
+	// the delta of the cumulative count of the bucket
+	count float64
+}
+
+// the buckets of a single histogram, identified by its base name and the tags without "le"
+type histogramBuckets struct {
+	metricName string
+	tags       map[string]string
+	timeInMS   int64
+	buckets    []histogramBucket
+}
+
+// Merge the delta of the <basename>_bucket metrics into one distribution per histogram.
+// Each bucket contributes the difference between its cumulative count and the one of the previous bucket,
+// using the middle of the bucket as the value. The +Inf bucket uses the largest finite upper bound. The values of the
+// buckets with negative bounds are dropped as the distributions only hold positive values.
+func (c *Calculator) mergeHistogramBuckets(pmb PrometheusMetricBatch) (result PrometheusMetricBatch) {
+	histogramMap := make(map[string]*histogramBuckets)
+	var keys []string
+	for _, pm := range pmb {
+		le, ok := pm.tags[labels.BucketLabel]
+		if !ok {
+			log.Printf("D! Drop histogram bucket without %v label: %v", labels.BucketLabel, pm.metricName)
+			continue
+		}
+		upperBound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			log.Printf("D! Drop histogram bucket with invalid %v label %q: %v", labels.BucketLabel, le, pm.metricName)
+			continue
+		}
+
+		tags := make(map[string]string, len(pm.tags))
+		for k, v := range pm.tags {
+			if k != labels.BucketLabel {
+				tags[k] = v
+			}
+		}
+		hb := &histogramBuckets{
+			metricName: strings.TrimSuffix(pm.metricName, histogramBucketSuffix),
+			tags:       tags,
+			timeInMS:   pm.timeInMS,
+		}
+		key := getUniqMetricKey(&PrometheusMetric{tags: hb.tags, metricName: hb.metricName})
+		if existing, ok := histogramMap[key]; ok {
+			hb = existing
+		} else {
+			histogramMap[key] = hb
+			keys = append(keys, key)
+		}
+		hb.buckets = append(hb.buckets, histogramBucket{upperBound: upperBound, count: pm.metricValue})
+	}
+
+	for _, key := range keys {
+		hb := histogramMap[key]
+		dist := c.newDistribution()
+		sort.Slice(hb.buckets, func(i, j int) bool { return hb.buckets[i].upperBound < hb.buckets[j].upperBound })
+
+		// the first bucket is [0, le] like histogram_quantile does, or only its upper bound when it is not positive
+		lowerBound, preCount := 0.0, 0.0
+		if len(hb.buckets) > 0 && hb.buckets[0].upperBound < 0 {
+			lowerBound = hb.buckets[0].upperBound
+		}
+		for _, b := range hb.buckets {
+			// buckets are reset independently, so the difference could be negative for a single scrape
+			count := math.Max(b.count-preCount, 0)
+			preCount = b.count
+
+			value := lowerBound
+			if !math.IsInf(b.upperBound, 1) {
+				value = (lowerBound + b.upperBound) / 2
+				lowerBound = b.upperBound
+			}
+			if count == 0 {
+				continue
+			}
+			if value < 0 {
+				log.Printf("D! Drop %v negative values of histogram %v", count, hb.metricName)
+				continue
+			}
+			dist.AddEntry(value, count)
+		}
+
+		if dist.SampleCount() == 0 {
+			continue
+		}
+		result = append(result, &PrometheusMetric{
+			tags:               hb.tags,
+			metricName:         hb.metricName,
+			metricType:         textparse.MetricTypeHistogram,
+			timeInMS:           hb.timeInMS,
+			metricDistribution: dist,
+		})
+	}
+	return
+}
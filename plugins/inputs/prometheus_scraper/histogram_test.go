@@ -0,0 +1,121 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus_scraper
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildHistogramBatch(timeInMS int64, sum, count float64, buckets map[string]float64) PrometheusMetricBatch {
+	pmb := PrometheusMetricBatch{
+		{tags: map[string]string{"job": "test"}, metricName: "latency_sum", metricValue: sum, metricType: "histogram", timeInMS: timeInMS},
+		{tags: map[string]string{"job": "test"}, metricName: "latency_count", metricValue: count, metricType: "histogram", timeInMS: timeInMS},
+	}
+	for le, v := range buckets {
+		pmb = append(pmb, &PrometheusMetric{
+			tags:        map[string]string{"job": "test", "le": le},
+			metricName:  "latency_bucket",
+			metricValue: v,
+			metricType:  "histogram",
+			timeInMS:    timeInMS,
+		})
+	}
+	return pmb
+}
+
+func TestCalculator_Histogram(t *testing.T) {
+	c := NewCalculator(HistogramOutputValuesCounts)
+
+	// the first scrape only initializes the deltas
+	result := c.Calculate(buildHistogramBatch(1000, 10, 5, map[string]float64{"1": 1, "2": 3, "4": 4, "+Inf": 5}))
+	assert.Equal(t, 0, len(result))
+
+	result = c.Calculate(buildHistogramBatch(2000, 30, 12, map[string]float64{"1": 3, "2": 6, "4": 9, "+Inf": 12}))
+	assert.Equal(t, 3, len(result))
+
+	fields := map[string]interface{}{}
+	for _, pm := range result {
+		assert.Equal(t, map[string]string{"job": "test"}, pm.tags)
+		if pm.metricDistribution != nil {
+			fields[pm.metricName] = pm.metricDistribution
+		} else {
+			fields[pm.metricName] = pm.metricValue
+		}
+	}
+	assert.Equal(t, float64(20), fields["latency_sum"])
+	assert.Equal(t, float64(7), fields["latency_count"])
+
+	dist := fields["latency"].(distribution.Distribution)
+	assert.Equal(t, float64(7), dist.SampleCount())
+	assert.Equal(t, 0.5, dist.Minimum())
+	assert.Equal(t, float64(4), dist.Maximum())
+	// 2*0.5 + 1*1.5 + 2*3 + 2*4
+	assert.Equal(t, 16.5, dist.Sum())
+}
+
+func TestCalculator_HistogramWithNegativeBounds(t *testing.T) {
+	c := NewCalculator(HistogramOutputValuesCounts)
+
+	c.Calculate(buildHistogramBatch(1000, 0, 0, map[string]float64{"-10": 0, "-5": 0, "0": 0, "5": 0, "+Inf": 0}))
+	result := c.Calculate(buildHistogramBatch(2000, 0, 10, map[string]float64{"-10": 1, "-5": 3, "0": 6, "5": 9, "+Inf": 10}))
+
+	var dist distribution.Distribution
+	for _, pm := range result {
+		if pm.metricDistribution != nil {
+			dist = pm.metricDistribution
+		}
+	}
+	// the buckets below 0 are dropped, (0, 5] is 2.5 and +Inf is 5
+	if assert.NotNil(t, dist) {
+		assert.Equal(t, float64(4), dist.SampleCount())
+		assert.Equal(t, 2.5, dist.Minimum())
+		assert.Equal(t, float64(5), dist.Maximum())
+		assert.Equal(t, 12.5, dist.Sum())
+	}
+
+	// the bucket across 0 is valued at its middle
+	c = NewCalculator(HistogramOutputValuesCounts)
+	c.Calculate(buildHistogramBatch(1000, 0, 0, map[string]float64{"-4": 0, "4": 0, "+Inf": 0}))
+	result = c.Calculate(buildHistogramBatch(2000, 0, 4, map[string]float64{"-4": 1, "4": 3, "+Inf": 4}))
+	dist = nil
+	for _, pm := range result {
+		if pm.metricDistribution != nil {
+			dist = pm.metricDistribution
+		}
+	}
+	if assert.NotNil(t, dist) {
+		assert.Equal(t, float64(3), dist.SampleCount())
+		assert.Equal(t, float64(0), dist.Minimum())
+		assert.Equal(t, float64(4), dist.Maximum())
+	}
+}
+
+func TestCalculator_HistogramWithoutNewSamples(t *testing.T) {
+	c := NewCalculator(HistogramOutputDistribution)
+	buckets := map[string]float64{"0.5": 1, "+Inf": 2}
+
+	c.Calculate(buildHistogramBatch(1000, 1, 2, buckets))
+	result := c.Calculate(buildHistogramBatch(2000, 1, 2, buckets))
+	// only _sum and _count are reported when the buckets did not change
+	assert.Equal(t, 2, len(result))
+	for _, pm := range result {
+		assert.Nil(t, pm.metricDistribution)
+	}
+}
+
+func TestMergePrometheusMetrics_Distribution(t *testing.T) {
+	c := NewCalculator(HistogramOutputDistribution)
+	c.Calculate(buildHistogramBatch(1000, 0, 0, map[string]float64{"1": 0, "+Inf": 0}))
+	result := c.Calculate(buildHistogramBatch(2000, 2, 4, map[string]float64{"1": 4, "+Inf": 4}))
+
+	mms := mergeMetrics(result)
+	assert.Equal(t, 1, len(mms))
+	dist, ok := mms[0].fields["latency"].(distribution.Distribution)
+	assert.True(t, ok)
+	assert.Equal(t, float64(4), dist.SampleCount())
+	assert.Equal(t, float64(2), mms[0].fields["latency_sum"])
+}
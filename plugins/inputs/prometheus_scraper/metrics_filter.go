@@ -5,25 +5,40 @@ package prometheus_scraper
 
 import (
 	"log"
+
+	"github.com/prometheus/prometheus/pkg/textparse"
 )
 
 const (
 	MaxDropMetricsLogged = 1000
+
+	UntypedMetricsDrop    = "drop"
+	UntypedMetricsGauge   = "gauge"
+	UntypedMetricsCounter = "counter"
 )
 
 type MetricsFilter struct {
 	maxDropMetricsLogged int
 	droppedMetrics       map[string]string
 	hitMaxLimit          bool
+	// the metric type given to untyped metrics, they are dropped when it is empty
+	untypedMetricType string
 }
 
 // Filter out and Log the unsupported metric types
 func (mf *MetricsFilter) Filter(pmb PrometheusMetricBatch) (result PrometheusMetricBatch) {
 	for _, pm := range pmb {
-		if !pm.isGauge() && !pm.isCounter() && !pm.isSummary() {
+		if pm.isUntyped() && mf.untypedMetricType != "" {
+			pm.metricType = mf.untypedMetricType
+			if pm.tags != nil {
+				pm.tags[prometheusMetricTypeKey] = pm.metricType
+			}
+		}
+
+		if !pm.isGauge() && !pm.isCounter() && !pm.isSummary() && !pm.isHistogram() {
 			if mf.droppedMetrics == nil {
 				mf.droppedMetrics = make(map[string]string, mf.maxDropMetricsLogged)
-				log.Println("I! Drop Prometheus metrics with unsupported types. Only Gauge, Counter and Summary are supported.")
+				log.Println("I! Drop Prometheus metrics with unsupported types. Only Gauge, Counter, Summary and Histogram are supported, untyped metrics are dropped unless untyped_metrics is set.")
 				log.Printf("I! Please enable CWAgent debug mode to view the first %d dropped metrics \n", mf.maxDropMetricsLogged)
 			}
 
@@ -47,6 +62,14 @@ func (mf *MetricsFilter) Filter(pmb PrometheusMetricBatch) (result PrometheusMet
 	return
 }
 
-func NewMetricsFilter() *MetricsFilter {
-	return &MetricsFilter{maxDropMetricsLogged: MaxDropMetricsLogged}
+// NewMetricsFilter creates a filter treating untyped metrics as untypedMetrics, which is
+// one of UntypedMetricsGauge, UntypedMetricsCounter or UntypedMetricsDrop.
+func NewMetricsFilter(untypedMetrics string) *MetricsFilter {
+	mf := &MetricsFilter{maxDropMetricsLogged: MaxDropMetricsLogged}
+	if untypedMetrics == UntypedMetricsGauge {
+		mf.untypedMetricType = textparse.MetricTypeGauge
+	} else if untypedMetrics == UntypedMetricsCounter {
+		mf.untypedMetricType = textparse.MetricTypeCounter
+	}
+	return mf
 }
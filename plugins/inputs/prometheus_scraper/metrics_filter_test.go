@@ -21,7 +21,7 @@ func buildTestData(pass int, drop int) (result PrometheusMetricBatch) {
 	for i := 0; i < drop; i++ {
 		pm := &PrometheusMetric{
 			metricName: fmt.Sprintf("dropped_id_%d", i),
-			metricType: "histogram",
+			metricType: "info",
 		}
 		result = append(result, pm)
 	}
@@ -80,6 +80,28 @@ func TestMetricsFilterFilter_ExceedMaxDroppedMetrics(t *testing.T) {
 }
 
 func TestMetricsFilterFilter_MetricsFilter(t *testing.T) {
-	mf := NewMetricsFilter()
+	mf := NewMetricsFilter("")
 	assert.Equal(t, MaxDropMetricsLogged, mf.maxDropMetricsLogged)
 }
+
+func TestMetricsFilterFilter_UntypedMetrics(t *testing.T) {
+	batch := func() PrometheusMetricBatch {
+		return PrometheusMetricBatch{
+			{metricName: "untyped_metric", metricType: "unknown", tags: map[string]string{prometheusMetricTypeKey: "unknown"}},
+			{metricName: "histogram_metric_bucket", metricType: "histogram", tags: map[string]string{prometheusMetricTypeKey: "histogram"}},
+		}
+	}
+
+	result := NewMetricsFilter(UntypedMetricsDrop).Filter(batch())
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "histogram_metric_bucket", result[0].metricName)
+
+	result = NewMetricsFilter(UntypedMetricsGauge).Filter(batch())
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "gauge", result[0].metricType)
+	assert.Equal(t, "gauge", result[0].tags[prometheusMetricTypeKey])
+
+	result = NewMetricsFilter(UntypedMetricsCounter).Filter(batch())
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "counter", result[0].metricType)
+}
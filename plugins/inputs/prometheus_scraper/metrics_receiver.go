@@ -8,6 +8,7 @@ import (
 	"log"
 	"math"
 
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/pkg/value"
@@ -27,6 +28,8 @@ type PrometheusMetric struct {
 	metricValue             float64
 	metricType              string
 	timeInMS                int64 // Unix time in milli-seconds
+	// the merged buckets of a histogram, metricValue is not used when it is set
+	metricDistribution distribution.Distribution
 }
 
 func (pm *PrometheusMetric) isValueValid() bool {
@@ -4,9 +4,11 @@
 package prometheus_scraper
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/aws/amazon-cloudwatch-agent/internal/ecsservicediscovery"
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sservicediscovery"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -15,6 +17,9 @@ type PrometheusScraper struct {
 	PrometheusConfigPath string                                      `toml:"prometheus_config_path"`
 	ClusterName          string                                      `toml:"cluster_name"`
 	ECSSDConfig          *ecsservicediscovery.ServiceDiscoveryConfig `toml:"ecs_service_discovery"`
+	K8sSDConfig          *k8sservicediscovery.ServiceDiscoveryConfig `toml:"kubernetes_service_discovery"`
+	HistogramOutput      string                                      `toml:"histogram_output"`
+	UntypedMetrics       string                                      `toml:"untyped_metrics"`
 	mbCh                 chan PrometheusMetricBatch
 	shutDownChan         chan interface{}
 	wg                   sync.WaitGroup
@@ -24,6 +29,11 @@ const sampleConfig = `
   [[inputs.prometheus_scraper]]
     cluster_name = "EC2-EC2-Justin-Testing"
     prometheus_config_path = "/opt/aws/amazon-cloudwatch-agent/etc/prometheus.yaml"
+    ## "values_counts" (default) keeps the middle of every histogram bucket as an exact value,
+    ## "distribution" merges the buckets into an exponential histogram
+    histogram_output = "values_counts"
+    ## "drop" (default), "gauge" or "counter"
+    untyped_metrics = "drop"
     [inputs.prometheus_scraper.ecs_service_discovery]
       sd_cluster_region = "us-east-2"
       sd_frequency = "15s"
@@ -43,6 +53,11 @@ const sampleConfig = `
       [[inputs.prometheus_scraper.ecs_service_discovery.task_definition_list]]
         sd_metrics_ports = "9902"
         sd_task_definition_name = "task_def_2"
+    ## Kubernetes pods discovery based on the prometheus.io/scrape, prometheus.io/port and prometheus.io/path annotations
+    # [inputs.prometheus_scraper.kubernetes_service_discovery]
+    #   sd_frequency = "1m"
+    #   sd_result_file = "/opt/aws/amazon-cloudwatch-agent/etc/k8s_sd_targets.yaml"
+    #   sd_job_name = "kubernetes-pods"
     [inputs.prometheus_scraper.tags]
       metricPath = "logs"
 `
@@ -60,12 +75,23 @@ func (p *PrometheusScraper) Gather(_ telegraf.Accumulator) error {
 }
 
 func (p *PrometheusScraper) Start(accIn telegraf.Accumulator) error {
+	switch p.HistogramOutput {
+	case "", HistogramOutputValuesCounts, HistogramOutputDistribution:
+	default:
+		return fmt.Errorf("invalid histogram_output %q, it must be %q or %q", p.HistogramOutput, HistogramOutputValuesCounts, HistogramOutputDistribution)
+	}
+	switch p.UntypedMetrics {
+	case "", UntypedMetricsDrop, UntypedMetricsGauge, UntypedMetricsCounter:
+	default:
+		return fmt.Errorf("invalid untyped_metrics %q, it must be %q, %q or %q", p.UntypedMetrics, UntypedMetricsDrop, UntypedMetricsGauge, UntypedMetricsCounter)
+	}
+
 	mth := NewMetricsTypeHandler()
 	receiver := &metricsReceiver{pmbCh: p.mbCh}
 	handler := &metricsHandler{mbCh: p.mbCh,
 		acc:         accIn,
-		calculator:  NewCalculator(),
-		filter:      NewMetricsFilter(),
+		calculator:  NewCalculator(p.HistogramOutput),
+		filter:      NewMetricsFilter(p.UntypedMetrics),
 		clusterName: p.ClusterName,
 		mtHandler:   mth,
 	}
@@ -76,6 +102,12 @@ func (p *PrometheusScraper) Start(accIn telegraf.Accumulator) error {
 	p.wg.Add(1)
 	go ecsservicediscovery.StartECSServiceDiscovery(ecssd, p.shutDownChan, &p.wg)
 
+	k8ssd := &k8sservicediscovery.ServiceDiscovery{Config: p.K8sSDConfig}
+
+	// start Kubernetes Service Discovery
+	p.wg.Add(1)
+	go k8sservicediscovery.StartK8sServiceDiscovery(k8ssd, p.shutDownChan, &p.wg)
+
 	// start metric collecting
 	p.wg.Add(1)
 	go Start(p.PrometheusConfigPath, receiver, p.shutDownChan, &p.wg, mth)
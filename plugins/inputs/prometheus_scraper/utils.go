@@ -55,6 +55,10 @@ func mergePrometheusMetrics(mm *metricMaterial, pm *PrometheusMetric) *metricMat
 		mm = &metricMaterial{tags: pm.tags, fields: map[string]interface{}{}, timeInMS: pm.timeInMS}
 	}
 
-	mm.fields[pm.metricName] = pm.metricValue
+	if pm.metricDistribution != nil {
+		mm.fields[pm.metricName] = pm.metricDistribution
+	} else {
+		mm.fields[pm.metricName] = pm.metricValue
+	}
 	return mm
 }
@@ -0,0 +1,176 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	eventPrefix        = "_e{"
+	serviceCheckPrefix = "_sc|"
+
+	eventType        = "event"
+	serviceCheckType = "service_check"
+)
+
+var serviceCheckStatuses = map[string]string{
+	"0": "ok",
+	"1": "warning",
+	"2": "critical",
+	"3": "unknown",
+}
+
+// A DogStatsD event, form is _e{<title length>,<text length>}:<title>|<text>|d:<timestamp>|h:<hostname>|p:<priority>|t:<alert type>|#<tags>
+// see https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/#events
+type event struct {
+	Type           string            `json:"type"`
+	Title          string            `json:"title"`
+	Text           string            `json:"text"`
+	Timestamp      int64             `json:"timestamp,omitempty"`
+	Hostname       string            `json:"hostname,omitempty"`
+	AggregationKey string            `json:"aggregation_key,omitempty"`
+	Priority       string            `json:"priority,omitempty"`
+	SourceTypeName string            `json:"source_type_name,omitempty"`
+	AlertType      string            `json:"alert_type,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// A DogStatsD service check, form is _sc|<name>|<status>|d:<timestamp>|h:<hostname>|#<tags>|m:<message>
+// see https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/#service-checks
+type serviceCheck struct {
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Hostname  string            `json:"hostname,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// parseEventLine parses the given DogStatsD event line and publishes it to the events log src
+func (s *Statsd) parseEventLine(line string) error {
+	header := strings.SplitN(line[len(eventPrefix):], "}:", 2)
+	if len(header) != 2 {
+		return eventError("missing '}:'", line)
+	}
+	lengths := strings.Split(header[0], ",")
+	if len(lengths) != 2 {
+		return eventError("invalid lengths", line)
+	}
+	titleLen, err := strconv.Atoi(lengths[0])
+	if err != nil {
+		return eventError("invalid title length", line)
+	}
+	textLen, err := strconv.Atoi(lengths[1])
+	if err != nil {
+		return eventError("invalid text length", line)
+	}
+	rest := header[1]
+	if titleLen < 0 || textLen < 0 || len(rest) < titleLen+1+textLen || rest[titleLen] != '|' {
+		return eventError("title or text length mismatch", line)
+	}
+
+	e := event{
+		Type:  eventType,
+		Title: rest[:titleLen],
+		Text:  strings.Replace(rest[titleLen+1:titleLen+1+textLen], "\\n", "\n", -1),
+	}
+	for _, segment := range strings.Split(rest[titleLen+1+textLen:], "|") {
+		switch {
+		case segment == "":
+		case strings.HasPrefix(segment, "d:"):
+			if e.Timestamp, err = strconv.ParseInt(segment[2:], 10, 64); err != nil {
+				return eventError("invalid timestamp", line)
+			}
+		case strings.HasPrefix(segment, "h:"):
+			e.Hostname = segment[2:]
+		case strings.HasPrefix(segment, "k:"):
+			e.AggregationKey = segment[2:]
+		case strings.HasPrefix(segment, "p:"):
+			e.Priority = segment[2:]
+		case strings.HasPrefix(segment, "s:"):
+			e.SourceTypeName = segment[2:]
+		case strings.HasPrefix(segment, "t:"):
+			e.AlertType = segment[2:]
+		case segment[0] == '#':
+			e.Tags = make(map[string]string)
+			parseDataDogTags(segment[1:], e.Tags)
+		default:
+			log.Printf("D! Ignoring the unknown field %s of statsd event: %s\n", segment, line)
+		}
+	}
+	return s.publishEvent(e, e.Timestamp)
+}
+
+// parseServiceCheckLine parses the given DogStatsD service check line and publishes it to the events log src
+func (s *Statsd) parseServiceCheckLine(line string) error {
+	rest := line[len(serviceCheckPrefix):]
+	sc := serviceCheck{Type: serviceCheckType}
+	// the message is the last field and may contain pipes
+	if i := strings.Index(rest, "|m:"); i >= 0 {
+		sc.Message = strings.Replace(rest[i+3:], "\\n", "\n", -1)
+		rest = rest[:i]
+	}
+	segments := strings.Split(rest, "|")
+	if len(segments) < 2 || segments[0] == "" {
+		return eventError("missing name or status", line)
+	}
+	sc.Name = segments[0]
+	status, ok := serviceCheckStatuses[segments[1]]
+	if !ok {
+		return eventError("invalid status", line)
+	}
+	sc.Status = status
+
+	var err error
+	for _, segment := range segments[2:] {
+		switch {
+		case segment == "":
+		case strings.HasPrefix(segment, "d:"):
+			if sc.Timestamp, err = strconv.ParseInt(segment[2:], 10, 64); err != nil {
+				return eventError("invalid timestamp", line)
+			}
+		case strings.HasPrefix(segment, "h:"):
+			sc.Hostname = segment[2:]
+		case segment[0] == '#':
+			sc.Tags = make(map[string]string)
+			parseDataDogTags(segment[1:], sc.Tags)
+		default:
+			log.Printf("D! Ignoring the unknown field %s of statsd service check: %s\n", segment, line)
+		}
+	}
+	return s.publishEvent(sc, sc.Timestamp)
+}
+
+func (s *Statsd) publishEvent(v interface{}, timestamp int64) error {
+	if s.eventSrc == nil {
+		log.Printf("D! Dropping the statsd event or service check as events_log_group_name is not configured\n")
+		return nil
+	}
+	msg, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	if timestamp > 0 {
+		t = time.Unix(timestamp, 0)
+	}
+	if !s.eventSrc.Publish(logs.NewEvent(string(msg), t, nil)) {
+		log.Printf("E! Error: statsd events queue full, dropping the event or service check\n")
+	}
+	return nil
+}
+
+func eventError(reason, line string) error {
+	log.Printf("E! Error: %s, Unable to parse statsd event or service check: %s\n", reason, line)
+	return errors.New("Error Parsing statsd event or service check")
+}
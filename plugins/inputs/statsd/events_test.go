@@ -0,0 +1,110 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStatsdWithEvents returns the plugin and the events it publishes
+func newTestStatsdWithEvents() (*Statsd, chan logs.LogEvent) {
+	s := NewTestStatsd()
+	s.eventSrc = logs.NewEventSrc("statsd-events", "stream", "cloudwatchlogs", "statsd events and service checks", -1, 10)
+	events := make(chan logs.LogEvent, 10)
+	s.eventSrc.SetOutput(func(e logs.LogEvent) { events <- e })
+	return s, events
+}
+
+func nextEvent(t *testing.T, events chan logs.LogEvent) (logs.LogEvent, map[string]interface{}) {
+	select {
+	case e := <-events:
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(e.Message()), &fields))
+		return e, fields
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event was published")
+	}
+	return nil, nil
+}
+
+func TestParse_Event(t *testing.T) {
+	s, events := newTestStatsdWithEvents()
+	require.NoError(t, s.parseStatsdLine("_e{10,17}:Deployment|version 2\\nrolled|d:1600000000|h:web-1|p:low|t:warning|k:deploy|s:ci|#env:prod,canary"))
+
+	e, fields := nextEvent(t, events)
+	assert.Equal(t, time.Unix(1600000000, 0), e.Time())
+	assert.Equal(t, map[string]interface{}{
+		"type":             "event",
+		"title":            "Deployment",
+		"text":             "version 2\nrolled",
+		"timestamp":        float64(1600000000),
+		"hostname":         "web-1",
+		"priority":         "low",
+		"alert_type":       "warning",
+		"aggregation_key":  "deploy",
+		"source_type_name": "ci",
+		"tags":             map[string]interface{}{"env": "prod", "canary": "<empty>"},
+	}, fields)
+
+	// the title and text may contain pipes
+	require.NoError(t, s.parseStatsdLine("_e{3,3}:a|b|c|d"))
+	_, fields = nextEvent(t, events)
+	assert.Equal(t, "a|b", fields["title"])
+	assert.Equal(t, "c|d", fields["text"])
+}
+
+func TestParse_InvalidEvents(t *testing.T) {
+	s, events := newTestStatsdWithEvents()
+	invalidLines := []string{
+		"_e{5,4}title|text",
+		"_e{5}:title|text",
+		"_e{x,4}:title|text",
+		"_e{5,10}:title|text",
+		"_e{4,4}:title|text",
+		"_e{5,4}:title|text|d:yesterday",
+	}
+	for _, line := range invalidLines {
+		assert.Error(t, s.parseStatsdLine(line), line)
+	}
+	// the next event published is the first valid one
+	require.NoError(t, s.parseStatsdLine("_e{5,4}:valid|text"))
+	_, fields := nextEvent(t, events)
+	assert.Equal(t, "valid", fields["title"])
+}
+
+func TestParse_ServiceCheck(t *testing.T) {
+	s, events := newTestStatsdWithEvents()
+	require.NoError(t, s.parseStatsdLine("_sc|db.connection|2|d:1600000000|h:web-1|#env:prod|m:timeout | retrying"))
+
+	e, fields := nextEvent(t, events)
+	assert.Equal(t, time.Unix(1600000000, 0), e.Time())
+	assert.Equal(t, map[string]interface{}{
+		"type":      "service_check",
+		"name":      "db.connection",
+		"status":    "critical",
+		"timestamp": float64(1600000000),
+		"hostname":  "web-1",
+		"message":   "timeout | retrying",
+		"tags":      map[string]interface{}{"env": "prod"},
+	}, fields)
+
+	assert.Error(t, s.parseStatsdLine("_sc|db.connection|5"))
+	assert.Error(t, s.parseStatsdLine("_sc|db.connection"))
+	require.NoError(t, s.parseStatsdLine("_sc|valid|0"))
+	_, fields = nextEvent(t, events)
+	assert.Equal(t, "valid", fields["name"])
+}
+
+func TestParse_EventWithoutLogGroup(t *testing.T) {
+	s := NewTestStatsd()
+	assert.NoError(t, s.parseStatsdLine("_e{5,4}:title|text"))
+	assert.NoError(t, s.parseStatsdLine("_sc|check|0"))
+	assert.Equal(t, 0, len(s.FindLogSrc()))
+}
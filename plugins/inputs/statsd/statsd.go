@@ -4,12 +4,15 @@
 package statsd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/statsd/graphite"
 	"log"
 	"net"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -31,6 +34,12 @@ const (
 
 	defaultSeparator           = "_"
 	defaultAllowPendingMessage = 10000
+	defaultMaxTCPConnections   = 250
+
+	protocolUDP      = "udp"
+	protocolTCP      = "tcp"
+	protocolUnixgram = "unixgram"
+	protocolUnix     = "unix"
 )
 
 var dropwarn = "E! Error: statsd message queue full. " +
@@ -38,8 +47,18 @@ var dropwarn = "E! Error: statsd message queue full. " +
 	"You may want to increase allowed_pending_messages in the config\n"
 
 type Statsd struct {
-	// Address & Port to serve from
+	// Protocol of the listener, one of "udp", "tcp", "unixgram" and "unix"
+	Protocol string
+	// Address & Port to serve from, or the path of the socket for the unix protocols
 	ServiceAddress string
+	// Maximum number of concurrent connections of the tcp and unix listeners
+	MaxTCPConnections int
+
+	// Log group, log stream and destination of the DogStatsD events and
+	// service checks, they are dropped if the log group is empty
+	EventsLogGroupName  string
+	EventsLogStreamName string
+	EventsDestination   string
 
 	// Number of messages allowed to queue up in between calls to Gather. If this
 	// fills up, packets will get dropped until the next Gather interval is ran.
@@ -82,7 +101,12 @@ type Statsd struct {
 	// bucket -> influx templates
 	Templates []string
 
-	listener *net.UDPConn
+	packetListener net.PacketConn
+	streamListener net.Listener
+	conns          map[net.Conn]struct{}
+
+	eventSrc     *logs.EventSrc
+	newEventSrcs []logs.LogSrc
 
 	graphiteParser *graphite.GraphiteParser
 }
@@ -131,9 +155,22 @@ func (_ *Statsd) Description() string {
 }
 
 const sampleConfig = `
-  ## Address and port to host UDP listener on
+  ## Protocol of the listener, one of "udp", "tcp", "unixgram" and "unix"
+  protocol = "udp"
+
+  ## Address and port to host the listener on, or the path of the socket
+  ## for the unix protocols
   service_address = ":8125"
 
+  ## Maximum number of concurrent tcp and unix connections
+  max_tcp_connections = 250
+
+  ## Log group and log stream of the DogStatsD events and service checks,
+  ## they are dropped if the log group is empty
+  # events_log_group_name = "statsd-events"
+  # events_log_stream_name = "{instance_id}"
+  # events_destination = "cloudwatchlogs"
+
   ## The following configuration options control when telegraf clears it's cache
   ## of previous values. If set to false, then telegraf will only clear it's
   ## cache when the daemon is restarted.
@@ -159,7 +196,7 @@ const sampleConfig = `
   #     "cpu.* measurement*"
   # ]
 
-  ## Number of UDP messages allowed to queue up, once filled,
+  ## Number of messages allowed to queue up, once filled,
   ## the statsd server will start dropping packets
   allowed_pending_messages = 10000
 
@@ -221,57 +258,172 @@ func (s *Statsd) Start(_ telegraf.Accumulator) error {
 	s.counters = make(map[string]cachedcounter)
 	s.sets = make(map[string]cachedset)
 	s.timings = make(map[string]cachedtimings)
+	s.conns = make(map[net.Conn]struct{})
 
 	if s.MetricSeparator == "" {
 		s.MetricSeparator = defaultSeparator
 	}
+	if s.Protocol == "" {
+		s.Protocol = protocolUDP
+	}
+	if s.MaxTCPConnections <= 0 {
+		s.MaxTCPConnections = defaultMaxTCPConnections
+	}
+
+	if s.EventsLogGroupName != "" {
+		s.eventSrc = logs.NewEventSrc(s.EventsLogGroupName, s.EventsLogStreamName, s.EventsDestination,
+			"statsd events and service checks", -1, s.AllowedPendingMessages)
+		s.newEventSrcs = append(s.newEventSrcs, s.eventSrc)
+	}
 
-	s.wg.Add(2)
-	// Start the UDP listener
-	go s.udpListen()
+	var err error
+	switch s.Protocol {
+	case protocolUDP, protocolUnixgram:
+		if s.Protocol == protocolUnixgram {
+			os.Remove(s.ServiceAddress)
+		}
+		if s.packetListener, err = net.ListenPacket(s.Protocol, s.ServiceAddress); err != nil {
+			return fmt.Errorf("statsd failed to listen on %s %s: %v", s.Protocol, s.ServiceAddress, err)
+		}
+		log.Printf("I! Statsd %s listener listening on: %s\n", s.Protocol, s.packetListener.LocalAddr().String())
+		s.wg.Add(1)
+		go s.packetListen()
+	case protocolTCP, protocolUnix:
+		if s.Protocol == protocolUnix {
+			os.Remove(s.ServiceAddress)
+		}
+		if s.streamListener, err = net.Listen(s.Protocol, s.ServiceAddress); err != nil {
+			return fmt.Errorf("statsd failed to listen on %s %s: %v", s.Protocol, s.ServiceAddress, err)
+		}
+		log.Printf("I! Statsd %s listener listening on: %s\n", s.Protocol, s.streamListener.Addr().String())
+		s.wg.Add(1)
+		go s.streamListen()
+	default:
+		return fmt.Errorf("statsd protocol %s is not supported", s.Protocol)
+	}
+
+	s.wg.Add(1)
 	// Start the line parser
 	go s.parser()
 	log.Printf("I! Started the statsd service on %s\n", s.ServiceAddress)
 	return nil
 }
 
-// udpListen starts listening for udp packets on the configured port.
-func (s *Statsd) udpListen() error {
+// packetListen reads the udp or unixgram packets of the listener.
+func (s *Statsd) packetListen() {
 	defer s.wg.Done()
-	var err error
-	address, _ := net.ResolveUDPAddr("udp", s.ServiceAddress)
-	s.listener, err = net.ListenUDP("udp", address)
-	if err != nil {
-		log.Fatalf("ERROR: ListenUDP - %s", err)
-	}
-	log.Println("I! Statsd listener listening on: ", s.listener.LocalAddr().String())
-
 	buf := make([]byte, UDP_MAX_PACKET_SIZE)
 	for {
-		select {
-		case <-s.done:
-			return nil
-		default:
-			n, _, err := s.listener.ReadFromUDP(buf)
-			if err != nil && !strings.Contains(err.Error(), "closed network") {
+		n, _, err := s.packetListener.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			if !strings.Contains(err.Error(), "closed network") {
 				log.Printf("E! Error READ: %s\n", err.Error())
 				continue
 			}
-			bufCopy := make([]byte, n)
-			copy(bufCopy, buf[:n])
+			return
+		}
+		bufCopy := make([]byte, n)
+		copy(bufCopy, buf[:n])
+		s.enqueue(bufCopy)
+	}
+}
 
+// streamListen accepts the tcp or unix connections of the listener, up to
+// MaxTCPConnections at a time.
+func (s *Statsd) streamListen() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.streamListener.Accept()
+		if err != nil {
 			select {
-			case s.in <- bufCopy:
+			case <-s.done:
+				return
 			default:
-				s.drops++
-				if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
-					log.Printf(dropwarn, s.drops)
-				}
 			}
+			log.Printf("E! Error accepting the statsd connection: %s\n", err.Error())
+			if strings.Contains(err.Error(), "closed network") {
+				return
+			}
+			continue
 		}
+
+		s.Lock()
+		if len(s.conns) >= s.MaxTCPConnections {
+			s.Unlock()
+			log.Printf("E! Error: maximum number of statsd connections %d reached, closing the connection from %s\n",
+				s.MaxTCPConnections, conn.RemoteAddr().String())
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
 	}
 }
 
+// handleConn reads the newline delimited lines of the connection until it is
+// closed.
+func (s *Statsd) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.Lock()
+		delete(s.conns, conn)
+		s.Unlock()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), UDP_MAX_PACKET_SIZE)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		lineCopy := make([]byte, len(line))
+		copy(lineCopy, line)
+		s.enqueue(lineCopy)
+	}
+	if err := scanner.Err(); err != nil {
+		select {
+		case <-s.done:
+		default:
+			log.Printf("E! Error reading the statsd connection from %s: %s\n", conn.RemoteAddr().String(), err.Error())
+		}
+	}
+}
+
+// enqueue sends the packet to the parser, the packet is dropped if the queue
+// is full.
+func (s *Statsd) enqueue(packet []byte) {
+	select {
+	case s.in <- packet:
+	default:
+		s.Lock()
+		s.drops++
+		if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
+			log.Printf(dropwarn, s.drops)
+		}
+		s.Unlock()
+	}
+}
+
+// FindLogSrc returns the log src of the DogStatsD events and service checks to
+// the log agent
+func (s *Statsd) FindLogSrc() []logs.LogSrc {
+	s.Lock()
+	defer s.Unlock()
+	srcs := s.newEventSrcs
+	s.newEventSrcs = nil
+	return srcs
+}
+
 // parser monitors the s.in channel, if there is a packet ready, it parses the
 // packet into statsd strings and then calls parseStatsdLine, which parses a
 // single statsd metric into a struct.
@@ -297,6 +449,12 @@ func (s *Statsd) parser() error {
 // parseStatsdLine will parse the given statsd line, validating it as it goes.
 // If the line is valid, it will be cached for the next call to Gather()
 func (s *Statsd) parseStatsdLine(line string) error {
+	if strings.HasPrefix(line, eventPrefix) {
+		return s.parseEventLine(line)
+	}
+	if strings.HasPrefix(line, serviceCheckPrefix) {
+		return s.parseServiceCheckLine(line)
+	}
 
 	lineTags := make(map[string]string)
 	if s.ParseDataDogTags {
@@ -310,24 +468,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		for _, segment := range pipesplit {
 			if len(segment) > 0 && segment[0] == '#' {
 				// we have ourselves a tag; they are comma separated
-				tagstr := segment[1:]
-				tags := strings.Split(tagstr, ",")
-				for _, tag := range tags {
-					ts := strings.SplitN(tag, ":", 2)
-					var k, v string
-					switch len(ts) {
-					case 1:
-						// just a tag
-						k = ts[0]
-						v = "<empty>" //cloudwatch does not allow empty string
-					case 2:
-						k = ts[0]
-						v = ts[1]
-					}
-					if k != "" {
-						lineTags[k] = v
-					}
-				}
+				parseDataDogTags(segment[1:], lineTags)
 			} else {
 				recombinedSegments = append(recombinedSegments, segment)
 			}
@@ -454,6 +595,26 @@ func (s *Statsd) parseStatsdLine(line string) error {
 	return nil
 }
 
+// parseDataDogTags parses the comma separated datadog tags, e.g. "country:china,sometagwithnovalue"
+func parseDataDogTags(tagstr string, tags map[string]string) {
+	for _, tag := range strings.Split(tagstr, ",") {
+		ts := strings.SplitN(tag, ":", 2)
+		var k, v string
+		switch len(ts) {
+		case 1:
+			// just a tag
+			k = ts[0]
+			v = "<empty>" //cloudwatch does not allow empty string
+		case 2:
+			k = ts[0]
+			v = ts[1]
+		}
+		if k != "" {
+			tags[k] = v
+		}
+	}
+}
+
 // parseName parses the given bucket name with the list of bucket maps in the
 // config file. If there is a match, it will parse the name of the metric and
 // map of tags.
@@ -602,16 +763,34 @@ func (s *Statsd) aggregate(m metric) {
 func (s *Statsd) Stop() {
 	log.Println("D! Stopping the statsd service")
 	close(s.done)
-	s.listener.Close()
+	if s.packetListener != nil {
+		s.packetListener.Close()
+		if s.Protocol == protocolUnixgram {
+			os.Remove(s.ServiceAddress)
+		}
+	}
+	if s.streamListener != nil {
+		s.streamListener.Close()
+	}
+	s.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.Unlock()
 	s.wg.Wait()
 	close(s.in)
+	if s.eventSrc != nil {
+		s.eventSrc.Stop()
+	}
 	log.Println("D! Stopped the statsd service")
 }
 
 func init() {
 	inputs.Add("statsd", func() telegraf.Input {
 		return &Statsd{
+			Protocol:               protocolUDP,
 			ServiceAddress:         ":8125",
+			MaxTCPConnections:      defaultMaxTCPConnections,
 			MetricSeparator:        "_",
 			AllowedPendingMessages: defaultAllowPendingMessage,
 			DeleteCounters:         true,
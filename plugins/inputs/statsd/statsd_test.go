@@ -8,11 +8,18 @@ import (
 	"fmt"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/seh1"
+	"io"
+	"io/ioutil"
 	"math"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func NewTestStatsd() *Statsd {
@@ -1481,3 +1488,77 @@ func test_validate_gauge(
 func init() {
 	distribution.NewDistribution = seh1.NewSEH1Distribution
 }
+
+func waitForCounter(t *testing.T, s *Statsd, name string, value int64) {
+	for i := 0; i < 100; i++ {
+		s.Lock()
+		err := test_validate_counter(name, value, s.counters, "value")
+		s.Unlock()
+		if err == nil {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("the counter %s was not aggregated to %d", name, value)
+}
+
+func TestStart_UnsupportedProtocol(t *testing.T) {
+	s := &Statsd{Protocol: "sctp", ServiceAddress: "127.0.0.1:0"}
+	assert.Error(t, s.Start(&testutil.Accumulator{}))
+}
+
+func TestListen_TCP(t *testing.T) {
+	s := &Statsd{Protocol: protocolTCP, ServiceAddress: "127.0.0.1:0", AllowedPendingMessages: 100, MaxTCPConnections: 1}
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.streamListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("requests:1|c\nrequests:2|c\n"))
+	require.NoError(t, err)
+	waitForCounter(t, s, "requests", 3)
+
+	// the connections above the limit are closed
+	extra, err := net.Dial("tcp", s.streamListener.Addr().String())
+	require.NoError(t, err)
+	defer extra.Close()
+	extra.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = extra.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestListen_Unix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "statsd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, protocol := range []string{protocolUnix, protocolUnixgram} {
+		path := filepath.Join(dir, protocol+".sock")
+		s := &Statsd{Protocol: protocol, ServiceAddress: path, AllowedPendingMessages: 100}
+		require.NoError(t, s.Start(&testutil.Accumulator{}))
+
+		conn, err := net.Dial(protocol, path)
+		require.NoError(t, err)
+		_, err = conn.Write([]byte("requests:4|c\n"))
+		require.NoError(t, err)
+		waitForCounter(t, s, "requests", 4)
+		conn.Close()
+
+		s.Stop()
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err), protocol)
+	}
+}
+
+func TestFindLogSrc(t *testing.T) {
+	s := &Statsd{ServiceAddress: "127.0.0.1:0", EventsLogGroupName: "statsd-events", EventsDestination: "cloudwatchlogs"}
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	defer s.Stop()
+
+	srcs := s.FindLogSrc()
+	require.Equal(t, 1, len(srcs))
+	assert.Equal(t, "statsd-events", srcs[0].Group())
+	assert.Equal(t, "cloudwatchlogs", srcs[0].Destination())
+	assert.Equal(t, 0, len(s.FindLogSrc()))
+}
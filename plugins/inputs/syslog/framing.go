@@ -0,0 +1,69 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// readFrame returns the next message of the TCP stream, RFC6587. The framing is detected per message: a message
+// starting with a digit is framed by octet counting, "<length> <message>", the others are terminated by a newline.
+func readFrame(r *bufio.Reader) (string, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	if b[0] >= '1' && b[0] <= '9' {
+		return readOctetCounted(r)
+	}
+	return readLine(r)
+}
+
+func readOctetCounted(r *bufio.Reader) (string, error) {
+	length := 0
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if c == ' ' {
+			break
+		}
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("invalid octet count, unexpected %q", c)
+		}
+		length = length*10 + int(c-'0')
+		if length > maxMessageSize {
+			return "", fmt.Errorf("the message exceeds %d bytes", maxMessageSize)
+		}
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return "", err
+	}
+	return string(msg), nil
+}
+
+// readLine returns the message up to the next newline, or the end of the stream
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxMessageSize+len("\r\n") {
+			return "", fmt.Errorf("the message exceeds %d bytes", maxMessageSize)
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && len(line) > 0:
+			return string(line), nil
+		case err != nil:
+			return "", err
+		}
+		return string(line), nil
+	}
+}
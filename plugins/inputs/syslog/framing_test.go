@@ -0,0 +1,47 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFrame(t *testing.T) {
+	stream := "11 <13>1 a\nb c" + "<13>Oct 11 22:14:15 host app: line\r\n" + "5 <13>1" + "<13>last without newline"
+	r := bufio.NewReaderSize(strings.NewReader(stream), 16)
+	var msgs []string
+	for {
+		msg, err := readFrame(r)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		msgs = append(msgs, msg)
+	}
+	assert.Equal(t, []string{
+		"<13>1 a\nb c",
+		"<13>Oct 11 22:14:15 host app: line\r\n",
+		"<13>1",
+		"<13>last without newline",
+	}, msgs)
+}
+
+func TestReadFrame_Invalid(t *testing.T) {
+	for _, stream := range []string{
+		"12a <13>1 message",
+		"999999999 <13>1 message",
+		"20 <13>1 truncated",
+		"<13>" + strings.Repeat("a", maxMessageSize) + "\n",
+	} {
+		_, err := readFrame(bufio.NewReader(strings.NewReader(stream)))
+		assert.Error(t, err, "reading %.20q should fail", stream)
+		assert.NotEqual(t, io.EOF, err)
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tlsint "github.com/aws/amazon-cloudwatch-agent/internal/tls"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
+	"github.com/aws/amazon-cloudwatch-agent/logs/parser"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const (
+	FormatRFC3164 = "rfc3164"
+	FormatRFC5424 = "rfc5424"
+
+	defaultDestination   = "cloudwatchlogs"
+	defaultLogStreamName = "{json:$.hostname}"
+	// the maximum size of a CloudWatch Logs event, which is its message plus 26 bytes
+	maxMessageSize = 256*1024 - 26
+	// the maximum size of a UDP datagram
+	maxDatagramSize = 64 * 1024
+)
+
+// <PRI>VERSION, the messages which do not start with it are parsed as RFC3164 when the format is not set
+var rfc5424Prefix = regexp.MustCompile(`^<\d{1,3}>\d{1,2} `)
+
+type Syslog struct {
+	// The protocol and address of the listener, e.g. "udp://:514" or "tcp://:6514"
+	ServiceAddress string `toml:"service_address"`
+	// The format of the messages, "rfc3164" or "rfc5424", it is detected per message when not set
+	Format string `toml:"format"`
+	// The log group and stream names, their {json:$.hostname}... placeholders are resolved per message
+	LogGroupName  string `toml:"log_group_name"`
+	LogStreamName string `toml:"log_stream_name"`
+	Destination   string `toml:"destination"`
+	// Indicate retention in days for the log groups
+	RetentionInDays int `toml:"retention_in_days"`
+	// Publish the messages as they are received ("raw", default) or their fields as JSON ("json")
+	OutputFormat string `toml:"output_format"`
+	// The timezone of the RFC3164 timestamps, "UTC" or the local one by default
+	Timezone string `toml:"timezone"`
+	// The TLS server config of the tcp listener
+	tlsint.ServerConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	rfc3164        *parser.Parser
+	rfc5424        *parser.Parser
+	groupTemplate  *nametemplate.Template
+	streamTemplate *nametemplate.Template
+	tlsConfig      *tls.Config
+	src            *syslogSrc
+	listener       net.Listener
+	packetConn     net.PacketConn
+
+	mu      sync.Mutex
+	newSrcs []logs.LogSrc
+	conns   map[net.Conn]bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+const sampleConfig = `
+  ## The protocol and address of the listener: "udp://:514", "tcp://:514", or "tcp://:6514" with TLS.
+  ## The TCP messages are framed by octet counting or terminated by a newline, detected per message.
+  service_address = "udp://:514"
+
+  ## The format of the messages, "rfc3164" or "rfc5424", it is detected per message when not set
+  # format = "rfc5424"
+
+  ## The log group and stream of the messages, the placeholders {json:$.hostname}, {json:$.appname},
+  ## {json:$.procid}, {json:$.msgid}, {json:$.facility} and {json:$.severity} are resolved from the
+  ## fields of each message, with an optional default like {json:$.appname|system}.
+  ## The log stream name defaults to "{json:$.hostname}".
+  log_group_name = "/syslog/{json:$.appname|system}"
+  # log_stream_name = "{json:$.hostname}"
+  destination = "cloudwatchlogs"
+  # retention_in_days = 30
+
+  ## Publish the messages as they are received ("raw", default) or their fields as JSON ("json")
+  # output_format = "raw"
+
+  ## The timezone of the RFC3164 timestamps, "UTC" or the local one by default
+  # timezone = "UTC"
+
+  ## Terminate TLS on the tcp listener
+  # tls_cert = "/etc/ssl/syslog.pem"
+  # tls_key = "/etc/ssl/syslog.key"
+  ## Only accept the clients whose certificate is signed by these CAs
+  # tls_allowed_cacerts = ["/etc/ssl/clients-ca.pem"]
+`
+
+func (s *Syslog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Syslog) Description() string {
+	return "Receive syslog messages over UDP, TCP or TLS and publish them to CloudWatch Logs"
+}
+
+func (s *Syslog) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *Syslog) Start(acc telegraf.Accumulator) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	s.src = newSyslogSrc(s.ServiceAddress, s.Destination, s.groupTemplate, s.streamTemplate, s.RetentionInDays)
+	s.done = make(chan struct{})
+	s.conns = make(map[net.Conn]bool)
+	var addr net.Addr
+	var err error
+	network, address := parseServiceAddress(s.ServiceAddress)
+	if isUDP(network) {
+		if s.packetConn, err = net.ListenPacket(network, address); err != nil {
+			return fmt.Errorf("failed to listen on %v: %v", s.ServiceAddress, err)
+		}
+		addr = s.packetConn.LocalAddr()
+		s.wg.Add(1)
+		go s.serveUDP()
+	} else {
+		if s.listener, err = net.Listen(network, address); err != nil {
+			return fmt.Errorf("failed to listen on %v: %v", s.ServiceAddress, err)
+		}
+		addr = s.listener.Addr()
+		if s.tlsConfig != nil {
+			s.listener = tls.NewListener(s.listener, s.tlsConfig)
+			network += "+tls"
+		}
+		s.wg.Add(1)
+		go s.serveTCP()
+	}
+
+	s.mu.Lock()
+	s.newSrcs = []logs.LogSrc{s.src}
+	s.mu.Unlock()
+	s.Log.Infof("Listening for syslog messages on %v://%v", network, addr)
+	return nil
+}
+
+// Validate checks the config the same way as the plugin does when it starts, without listening
+func (s *Syslog) Validate() error {
+	return s.init()
+}
+
+// init sets the defaults of the config and creates its parsers, name templates and TLS config
+func (s *Syslog) init() error {
+	network, _ := parseServiceAddress(s.ServiceAddress)
+	if !isUDP(network) && !isTCP(network) {
+		return fmt.Errorf("invalid service_address %v, the protocol must be udp or tcp", s.ServiceAddress)
+	}
+	switch s.Format {
+	case "", FormatRFC3164, FormatRFC5424:
+	default:
+		return fmt.Errorf("invalid format %v, it must be %v or %v", s.Format, FormatRFC3164, FormatRFC5424)
+	}
+	if s.LogGroupName == "" {
+		return fmt.Errorf("log_group_name is required")
+	}
+	if s.LogStreamName == "" {
+		s.LogStreamName = defaultLogStreamName
+	}
+	if s.Destination == "" {
+		s.Destination = defaultDestination
+	}
+	if s.RetentionInDays == 0 {
+		s.RetentionInDays = -1
+	}
+	location := time.Local
+	if s.Timezone == time.UTC.String() {
+		location = time.UTC
+	}
+
+	var err error
+	if s.rfc3164, err = parser.New(parser.Config{Type: parser.SyslogRFC3164, OutputFormat: s.OutputFormat}, location); err != nil {
+		return err
+	}
+	if s.rfc5424, err = parser.New(parser.Config{Type: parser.SyslogRFC5424, OutputFormat: s.OutputFormat}, location); err != nil {
+		return err
+	}
+	if s.groupTemplate, err = nametemplate.New(s.LogGroupName, nametemplate.InvalidLogGroupNameChars, nametemplate.KindJSON); err != nil {
+		return fmt.Errorf("log_group_name has issue: %v", err)
+	}
+	if s.streamTemplate, err = nametemplate.New(s.LogStreamName, nametemplate.InvalidLogStreamNameChars, nametemplate.KindJSON); err != nil {
+		return fmt.Errorf("log_stream_name has issue: %v", err)
+	}
+	if s.tlsConfig, err = s.ServerConfig.TLSConfig(); err != nil {
+		return err
+	}
+	if s.tlsConfig != nil && isUDP(network) {
+		return fmt.Errorf("TLS is not supported over %v", network)
+	}
+	return nil
+}
+
+// Stop closes the listener and the connections. The syslog src is stopped by the log agent after the output
+// plugin is stopped instead of here, so the messages already received are published.
+func (s *Syslog) Stop() {
+	if s.done == nil {
+		return
+	}
+	s.mu.Lock()
+	close(s.done)
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.packetConn != nil {
+		s.packetConn.Close()
+	}
+	s.wg.Wait()
+}
+
+// FindLogSrc returns the syslog src once after the plugin is started
+func (s *Syslog) FindLogSrc() []logs.LogSrc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	srcs := s.newSrcs
+	s.newSrcs = nil
+	return srcs
+}
+
+func (s *Syslog) serveUDP() {
+	defer s.wg.Done()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := s.packetConn.ReadFrom(buf)
+		if err != nil {
+			if !s.stopped() {
+				s.Log.Errorf("Stopped receiving syslog messages on %v: %v", s.ServiceAddress, err)
+			}
+			return
+		}
+		s.handle(string(buf[:n]))
+	}
+}
+
+func (s *Syslog) serveTCP() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.stopped() {
+				return
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				s.Log.Warnf("Failed to accept a syslog connection on %v: %v", s.ServiceAddress, err)
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			s.Log.Errorf("Stopped accepting syslog connections on %v: %v", s.ServiceAddress, err)
+			return
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return
+		}
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// track records the connection so it is closed by Stop, it returns false if the plugin is stopped
+func (s *Syslog) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped() {
+		return false
+	}
+	s.conns[conn] = true
+	return true
+}
+
+func (s *Syslog) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readFrame(r)
+		if err != nil {
+			if err != io.EOF && !s.stopped() {
+				s.Log.Warnf("Closing the syslog connection from %v: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		s.handle(msg)
+	}
+}
+
+// handle parses the message and publishes it, the messages which cannot be parsed are published as they are
+// received without fields
+func (s *Syslog) handle(msg string) {
+	msg = strings.TrimRight(msg, "\r\n\x00")
+	if msg == "" {
+		return
+	}
+	if len(msg) > maxMessageSize {
+		msg = msg[:maxMessageSize]
+	}
+	p := s.rfc3164
+	if s.Format == FormatRFC5424 || s.Format == "" && rfc5424Prefix.MatchString(msg) {
+		p = s.rfc5424
+	}
+	fields, t, published := p.Parse(msg)
+	if t.IsZero() {
+		// the timestamp of the message cannot be parsed
+		t = time.Now()
+	}
+	if len(published) > maxMessageSize {
+		// the message reserialized as JSON is published as it was received rather than truncated
+		published = msg
+	}
+	// the message is dropped if the src or the listener is stopped first
+	s.src.PublishWait(logs.NewEvent(published, t, fields), s.done)
+}
+
+func (s *Syslog) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseServiceAddress splits the service address into its network and address, e.g. "udp://:514"
+func parseServiceAddress(serviceAddress string) (string, string) {
+	parts := strings.SplitN(serviceAddress, "://", 2)
+	if len(parts) != 2 {
+		return "", serviceAddress
+	}
+	return strings.ToLower(parts[0]), parts[1]
+}
+
+func isUDP(network string) bool {
+	return network == "udp" || network == "udp4" || network == "udp6"
+}
+
+func isTCP(network string) bool {
+	return network == "tcp" || network == "tcp4" || network == "tcp6"
+}
+
+func init() {
+	inputs.Add("syslog", func() telegraf.Input {
+		return &Syslog{}
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tlsint "github.com/aws/amazon-cloudwatch-agent/internal/tls"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type routedEvent struct {
+	group, stream string
+	e             logs.LogEvent
+}
+
+// startSyslog starts the plugin and returns the log events it publishes with their routed log group and stream
+func startSyslog(t *testing.T, s *Syslog) chan routedEvent {
+	s.Log = testutil.Logger{}
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	srcs := s.FindLogSrc()
+	require.Len(t, srcs, 1)
+	assert.Empty(t, s.FindLogSrc(), "the src is only returned once")
+	src := srcs[0].(logs.RoutedLogSrc)
+
+	events := make(chan routedEvent, 10)
+	src.SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			close(events)
+			return
+		}
+		group, stream := src.Route(e)
+		events <- routedEvent{group, stream, e}
+	})
+	return events
+}
+
+func nextEvent(t *testing.T, events chan routedEvent) routedEvent {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the syslog message")
+	}
+	return routedEvent{}
+}
+
+func TestSyslogUDP(t *testing.T) {
+	s := &Syslog{ServiceAddress: "udp://127.0.0.1:0", LogGroupName: "/syslog/{json:$.appname|system}", Timezone: "UTC"}
+	events := startSyslog(t, s)
+	defer s.Stop()
+	assert.Equal(t, "/syslog/{json:$.appname}", s.src.Group())
+	assert.Equal(t, "{json:$.hostname}", s.src.Stream())
+	assert.Equal(t, -1, s.src.Retention())
+	assert.Equal(t, "cloudwatchlogs", s.src.Destination())
+
+	conn, err := net.Dial("udp", s.packetConn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	msg := `<165>1 2003-10-11T22:14:15.003Z web-1 nginx 1234 ID47 [meta env="prod"] request served`
+	_, err = conn.Write([]byte(msg + "\n"))
+	require.NoError(t, err)
+	e := nextEvent(t, events)
+	assert.Equal(t, "/syslog/nginx", e.group)
+	assert.Equal(t, "web-1", e.stream)
+	assert.Equal(t, msg, e.e.Message())
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), e.e.Time().UTC())
+	assert.Equal(t, "ID47", e.e.(logs.StructuredLogEvent).Fields()["msgid"])
+
+	_, err = conn.Write([]byte("<34>Oct 11 22:14:15 db:1 su: 'su root' failed"))
+	require.NoError(t, err)
+	e = nextEvent(t, events)
+	assert.Equal(t, "/syslog/su", e.group)
+	assert.Equal(t, "db_1", e.stream, "the characters not allowed in the log stream names are replaced")
+	assert.Equal(t, time.October, e.e.Time().Month())
+	assert.Equal(t, 22, e.e.Time().Hour())
+
+	// the messages which cannot be parsed are published with the time they are received
+	before := time.Now()
+	_, err = conn.Write([]byte("not syslog"))
+	require.NoError(t, err)
+	e = nextEvent(t, events)
+	assert.Equal(t, "/syslog/system", e.group)
+	assert.Equal(t, "unknown", e.stream)
+	assert.Equal(t, "not syslog", e.e.Message())
+	assert.False(t, e.e.Time().Before(before))
+}
+
+func TestSyslogTCP(t *testing.T) {
+	s := &Syslog{ServiceAddress: "tcp://127.0.0.1:0", Format: FormatRFC5424, LogGroupName: "syslog",
+		LogStreamName: "{json:$.hostname}/{json:$.severity}", OutputFormat: "json"}
+	events := startSyslog(t, s)
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// octet counting and newline framing on the same connection
+	first := "<13>1 2020-10-11T22:14:15Z host-a app - - - multi\nline"
+	second := "<11>1 2020-10-11T22:14:16Z host-b app - - - second"
+	_, err = fmt.Fprintf(conn, "%d %s%s\n", len(first), first, second)
+	require.NoError(t, err)
+
+	e := nextEvent(t, events)
+	assert.Equal(t, "host-a/5", e.stream)
+	assert.Equal(t, `{"appname":"app","facility":1,"hostname":"host-a","message":"multi\nline","priority":13,"severity":5,"timestamp":"2020-10-11T22:14:15Z","version":1}`, e.e.Message())
+	e = nextEvent(t, events)
+	assert.Equal(t, "host-b/3", e.stream)
+	assert.Equal(t, "syslog", e.group)
+
+	// the src stops publishing once it is stopped by the log agent
+	s.Stop()
+	s.src.Stop()
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestSyslogTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cert, key := writeCertificate(t, dir)
+
+	s := &Syslog{ServiceAddress: "tcp://127.0.0.1:0", LogGroupName: "syslog",
+		ServerConfig: tlsint.ServerConfig{TLSCert: cert, TLSKey: key, TLSAllowedCACerts: []string{cert}}}
+	events := startSyslog(t, s)
+	defer s.Stop()
+
+	pair, err := tls.LoadX509KeyPair(cert, key)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(readFile(t, cert))
+	conn, err := tls.Dial("tcp", s.listener.Addr().String(), &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{pair}, ServerName: "localhost"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	msg := "<13>1 2020-10-11T22:14:15Z secure app - - - over tls"
+	_, err = fmt.Fprintf(conn, "%d %s", len(msg), msg)
+	require.NoError(t, err)
+	e := nextEvent(t, events)
+	assert.Equal(t, msg, e.e.Message())
+	assert.Equal(t, "secure", e.stream)
+
+	// the clients without certificate are rejected
+	conn, err = tls.Dial("tcp", s.listener.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	if err == nil {
+		defer conn.Close()
+		_, err = fmt.Fprintf(conn, "%d %s", len(msg), msg)
+		if err == nil {
+			_, err = conn.Read(make([]byte, 1))
+		}
+	}
+	assert.Error(t, err)
+}
+
+func TestSyslogInvalidConfig(t *testing.T) {
+	for _, s := range []*Syslog{
+		{ServiceAddress: "udp://127.0.0.1:0"},
+		{ServiceAddress: "udp://127.0.0.1:0", LogGroupName: "group", Format: "rfc3339"},
+		{ServiceAddress: "udp://127.0.0.1:0", LogGroupName: "{json:$.hostname}", LogStreamName: "{message:hostname}"},
+		{ServiceAddress: "udp://127.0.0.1:0", LogGroupName: "group", OutputFormat: "xml"},
+		{ServiceAddress: "udp://127.0.0.1:0", LogGroupName: "group", ServerConfig: tlsint.ServerConfig{TLSCert: "cert.pem", TLSKey: "key.pem"}},
+		{ServiceAddress: "unix:///tmp/syslog.sock", LogGroupName: "group"},
+		{ServiceAddress: ":514", LogGroupName: "group"},
+	} {
+		s.Log = testutil.Logger{}
+		assert.Error(t, s.Start(&testutil.Accumulator{}), "%+v should be invalid", s)
+	}
+}
+
+// writeCertificate writes a self-signed certificate of localhost, which is also its CA, and returns the paths of
+// the certificate and its key
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert := filepath.Join(dir, "cert.pem")
+	require.NoError(t, ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return cert, keyFile
+}
+
+func readFile(t *testing.T, filename string) []byte {
+	content, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	return content
+}
@@ -0,0 +1,32 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
+)
+
+// the messages received are buffered while they are published
+const eventBufferSize = 1000
+
+// syslogSrc is the log src of the messages received by the listener, they are routed to the log group and stream
+// resolved from their fields
+type syslogSrc struct {
+	*logs.EventSrc
+	groupTemplate, streamTemplate *nametemplate.Template
+}
+
+func newSyslogSrc(description, destination string, group, stream *nametemplate.Template, retentionInDays int) *syslogSrc {
+	return &syslogSrc{
+		EventSrc:       logs.NewEventSrc(group.String(), stream.String(), destination, description, retentionInDays, eventBufferSize),
+		groupTemplate:  group,
+		streamTemplate: stream,
+	}
+}
+
+func (s *syslogSrc) Route(e logs.LogEvent) (string, string) {
+	r := nametemplate.EventResolver(e)
+	return s.groupTemplate.Resolve(r), s.streamTemplate.Resolve(r)
+}
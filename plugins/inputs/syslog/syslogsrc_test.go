@@ -0,0 +1,36 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/nametemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSrc_Route(t *testing.T) {
+	group, err := nametemplate.New("/syslog/{json:$.appname|system}/{json:$.severity}-{json:$.procid}", nametemplate.InvalidLogGroupNameChars, nametemplate.KindJSON)
+	require.NoError(t, err)
+	stream, err := nametemplate.New("{json:$.hostname|}static", nametemplate.InvalidLogStreamNameChars, nametemplate.KindJSON)
+	require.NoError(t, err)
+	src := newSyslogSrc("udp://:514", "cloudwatchlogs", group, stream, -1)
+	assert.Equal(t, "/syslog/{json:$.appname}/{json:$.severity}-{json:$.procid}", src.Group())
+
+	fields := map[string]interface{}{"appname": "my app", "severity": json.Number("3"), "procid": "12", "hostname": "a:b"}
+	g, s := src.Route(logs.NewEvent("<11>1 - a:b my app 12 - - msg", time.Now(), fields))
+	assert.Equal(t, "/syslog/my_app/3-12", g)
+	assert.Equal(t, "a_bstatic", s)
+
+	// the messages which cannot be parsed, and the fallback log group and stream, use the defaults
+	g, s = src.Route(logs.NewEvent("not syslog", time.Now(), nil))
+	assert.Equal(t, "/syslog/system/unknown-unknown", g)
+	assert.Equal(t, "static", s)
+	g, _ = src.Route(nil)
+	assert.Equal(t, "/syslog/system/unknown-unknown", g)
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/health"
 	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
 	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
 
@@ -38,6 +39,7 @@ const (
 	maxConcurrentPublisher         = 10 // the number of CloudWatch clients send request concurrently
 	pushIntervalInSec              = 60 // 60 sec
 	highResolutionTagKey           = "aws:StorageResolution"
+	namespaceTagKey                = "aws:Namespace"
 	defaultRetryCount              = 5 // this is the retry count, the total attempts would be retry count + 1 at most.
 	backoffRetryBase               = 200
 )
@@ -45,6 +47,9 @@ const (
 const (
 	opPutLogEvents  = "PutLogEvents"
 	opPutMetricData = "PutMetricData"
+
+	// queueName is the name of the output's queue reported by the health endpoint
+	queueName = "cloudwatch"
 )
 
 type CloudWatch struct {
@@ -63,6 +68,12 @@ type CloudWatch struct {
 	RollupDimensions   [][]string               `toml:"rollup_dimensions"`
 	Namespace          string                   `toml:"namespace"` // CloudWatch Metrics Namespace
 
+	// The limits of unique dimension sets seen within the window, 0 means no limit
+	MaxDimensionSetsPerMetric    int               `toml:"max_dimension_sets_per_metric"`
+	MaxDimensionSetsPerNamespace int               `toml:"max_dimension_sets_per_namespace"`
+	DimensionSetsWindow          internal.Duration `toml:"dimension_sets_window"`
+	DimensionSetsOverflowAction  string            `toml:"dimension_sets_overflow_action"` // drop (default) or collapse
+
 	Log telegraf.Logger `toml:"-"`
 
 	svc                    cloudwatchiface.CloudWatchAPI
@@ -70,12 +81,13 @@ type CloudWatch struct {
 	aggregatorShutdownChan chan struct{}
 	aggregatorWaitGroup    sync.WaitGroup
 	metricChan             chan telegraf.Metric
-	datumBatchChan         chan []*cloudwatch.MetricDatum
+	datumBatchChan         chan *cloudwatch.PutMetricDataInput
 	datumBatchFullChan     chan bool
-	metricDatumBatch       *MetricDatumBatch
+	metricDatumBatches     map[string]*MetricDatumBatch // namespace -> batch
 	shutdownChan           chan struct{}
 	pushTicker             *time.Ticker
 	metricDecorations      *MetricDecorations
+	dimensionSetGuard      *dimensionSetGuard
 	retries                int
 	publisher              *publisher.Publisher
 	retryer                *retryer.LogThrottleRetryer
@@ -105,6 +117,13 @@ var sampleConfig = `
 
   ## RollupDimensions
   # RollupDimensions = [["host"],["host", "ImageId"],[]]
+
+  ## Limits of the unique dimension sets per metric name and per namespace seen within the window,
+  ## the datums of the new dimension sets over the limits are dropped or collapsed into the __overflow__ dimension value
+  # max_dimension_sets_per_metric = 1000
+  # max_dimension_sets_per_namespace = 10000
+  # dimension_sets_window = "1h"
+  # dimension_sets_overflow_action = "drop"
 `
 
 func (c *CloudWatch) SampleConfig() string {
@@ -124,6 +143,11 @@ func (c *CloudWatch) Connect() error {
 		return err
 	}
 
+	if c.dimensionSetGuard, err = newDimensionSetGuard(c.MaxDimensionSetsPerMetric, c.MaxDimensionSetsPerNamespace,
+		c.DimensionSetsWindow.Duration, c.DimensionSetsOverflowAction); err != nil {
+		return err
+	}
+
 	credentialConfig := &internalaws.CredentialConfig{
 		Region:    c.Region,
 		AccessKey: c.AccessKey,
@@ -152,12 +176,13 @@ func (c *CloudWatch) Connect() error {
 	c.svc = svc
 	c.retryer = logThrottleRetryer
 	c.startRoutines()
+	health.Status.SetQueue(queueName, c.queueDepth)
 	return nil
 }
 
 func (c *CloudWatch) startRoutines() {
 	c.metricChan = make(chan telegraf.Metric, metricChanBufferSize)
-	c.datumBatchChan = make(chan []*cloudwatch.MetricDatum, datumBatchChanBufferSize)
+	c.datumBatchChan = make(chan *cloudwatch.PutMetricDataInput, datumBatchChanBufferSize)
 	c.datumBatchFullChan = make(chan bool, 1)
 	c.shutdownChan = make(chan struct{})
 	c.aggregatorShutdownChan = make(chan struct{})
@@ -172,12 +197,16 @@ func (c *CloudWatch) startRoutines() {
 		c.MaxValuesPerDatum = defaultMaxValuesPerDatum
 	}
 	setNewDistributionFunc(c.MaxValuesPerDatum)
-	perRequestConstSize := overallConstPerRequestSize + len(c.Namespace) + namespaceOverheads
-	c.metricDatumBatch = newMetricDatumBatch(c.MaxDatumsPerCall, perRequestConstSize)
+	c.metricDatumBatches = map[string]*MetricDatumBatch{}
 	go c.pushMetricDatum()
 	go c.publish()
 }
 
+// queueDepth returns the number of the metrics and the batches of datums waiting to be published
+func (c *CloudWatch) queueDepth() int {
+	return len(c.metricChan) + len(c.datumBatchChan)
+}
+
 func (c *CloudWatch) Close() error {
 	log.Println("D! Stopping the CloudWatch output plugin")
 	close(c.aggregatorShutdownChan)
@@ -194,6 +223,7 @@ func (c *CloudWatch) Close() error {
 		log.Printf("D! CloudWatch Close, metricChan length = %v, datumBatchChan length = %v.", metricChanLen, datumBatchChanLen)
 	}
 	close(c.shutdownChan)
+	health.Status.RemoveQueue(queueName)
 	c.publisher.Close()
 	c.retryer.Stop()
 	log.Println("D! Stopped the CloudWatch output plugin")
@@ -216,22 +246,35 @@ func (c *CloudWatch) pushMetricDatum() {
 	for {
 		select {
 		case point := <-c.metricChan:
+			namespace := c.getNamespace(point)
+			batch := c.getMetricDatumBatch(namespace)
 			datums := c.BuildMetricDatum(point)
-			numberOfPartitions := len(datums)
-			for i := 0; i < numberOfPartitions; i++ {
-				c.metricDatumBatch.Partition = append(c.metricDatumBatch.Partition, datums[i])
-				c.metricDatumBatch.Size += payload(datums[i])
-				if c.metricDatumBatch.isFull() {
+			now := time.Now()
+			for _, datum := range datums {
+				if c.dimensionSetGuard != nil && !c.dimensionSetGuard.admit(namespace, datum, now) {
+					continue
+				}
+				batch.Partition = append(batch.Partition, datum)
+				batch.Size += payload(datum)
+				if batch.isFull() {
 					// if batch is full
-					c.datumBatchChan <- c.metricDatumBatch.Partition
-					c.metricDatumBatch.clear()
+					c.datumBatchChan <- batch.request()
+					batch.clear()
 				}
 			}
 		case <-ticker.C:
-			if c.timeToPublish(c.metricDatumBatch) {
-				// if the time to publish comes
-				c.datumBatchChan <- c.metricDatumBatch.Partition
-				c.metricDatumBatch.clear()
+			if c.dimensionSetGuard != nil {
+				c.dimensionSetGuard.expire(time.Now())
+			}
+			for namespace, batch := range c.metricDatumBatches {
+				if c.timeToPublish(batch) {
+					// if the time to publish comes
+					c.datumBatchChan <- batch.request()
+					batch.clear()
+				}
+				if len(batch.Partition) == 0 && namespace != c.Namespace {
+					delete(c.metricDatumBatches, namespace)
+				}
 			}
 		case <-c.shutdownChan:
 			return
@@ -240,6 +283,7 @@ func (c *CloudWatch) pushMetricDatum() {
 }
 
 type MetricDatumBatch struct {
+	Namespace           string
 	MaxDatumsPerCall    int
 	Partition           []*cloudwatch.MetricDatum
 	BeginTime           time.Time
@@ -257,6 +301,13 @@ func newMetricDatumBatch(maxDatumsPerCall, perRequestConstSize int) *MetricDatum
 	}
 }
 
+func (b *MetricDatumBatch) request() *cloudwatch.PutMetricDataInput {
+	return &cloudwatch.PutMetricDataInput{
+		MetricData: b.Partition,
+		Namespace:  aws.String(b.Namespace),
+	}
+}
+
 func (b *MetricDatumBatch) clear() {
 	b.Partition = make([]*cloudwatch.MetricDatum, 0, b.MaxDatumsPerCall)
 	b.BeginTime = time.Now()
@@ -267,6 +318,31 @@ func (b *MetricDatumBatch) isFull() bool {
 	return len(b.Partition) >= b.MaxDatumsPerCall || b.Size >= bottomLinePayloadSizeToPublish
 }
 
+// getNamespace returns the namespace of the metric, the namespace of the output is overridden by the aws:Namespace
+// tag, e.g. for the metrics extracted from the embedded metric format documents
+func (c *CloudWatch) getNamespace(point telegraf.Metric) string {
+	namespace, ok := point.GetTag(namespaceTagKey)
+	if !ok {
+		return c.Namespace
+	}
+	point.RemoveTag(namespaceTagKey)
+	if namespace == "" {
+		return c.Namespace
+	}
+	return namespace
+}
+
+func (c *CloudWatch) getMetricDatumBatch(namespace string) *MetricDatumBatch {
+	batch, ok := c.metricDatumBatches[namespace]
+	if !ok {
+		perRequestConstSize := overallConstPerRequestSize + len(namespace) + namespaceOverheads
+		batch = newMetricDatumBatch(c.MaxDatumsPerCall, perRequestConstSize)
+		batch.Namespace = namespace
+		c.metricDatumBatches[namespace] = batch
+	}
+	return batch
+}
+
 func (c *CloudWatch) timeToPublish(b *MetricDatumBatch) bool {
 	return len(b.Partition) > 0 && time.Now().Sub(b.BeginTime) >= c.ForceFlushInterval.Duration
 }
@@ -337,11 +413,7 @@ func (c *CloudWatch) backoffSleep() {
 }
 
 func (c *CloudWatch) WriteToCloudWatch(req interface{}) {
-	datums := req.([]*cloudwatch.MetricDatum)
-	params := &cloudwatch.PutMetricDataInput{
-		MetricData: datums,
-		Namespace:  aws.String(c.Namespace),
-	}
+	params := req.(*cloudwatch.PutMetricDataInput)
 	var err error
 	for i := 0; i < defaultRetryCount; i++ {
 		_, err = c.svc.PutMetricData(params)
@@ -367,6 +439,7 @@ func (c *CloudWatch) WriteToCloudWatch(req interface{}) {
 			}
 		} else {
 			c.retries = 0
+			health.Status.RecordSuccess(opPutMetricData)
 		}
 		break
 	}
@@ -383,16 +456,59 @@ func (c *CloudWatch) decorateMetricName(category string, name string) (decorated
 		if name == "value" {
 			decoratedName = category
 		} else {
-			separator := "_"
-			if runtime.GOOS == "windows" {
-				separator = " "
-			}
-			decoratedName = strings.Join([]string{category, name}, separator)
+			decoratedName = strings.Join([]string{category, name}, metricNameSeparator())
 		}
 	}
 	return
 }
 
+func metricNameSeparator() string {
+	if runtime.GOOS == "windows" {
+		return " "
+	}
+	return "_"
+}
+
+// decorateMetricStatistics returns the statistics of an aggregated metric, the ones of its metric_decoration take
+// precedence over the ones of its tags
+func (c *CloudWatch) decorateMetricStatistics(category string, name string, tagStatistics *statistics) *statistics {
+	if c.metricDecorations != nil {
+		if stats := c.metricDecorations.getStatistics(category, name); stats != nil {
+			return stats
+		}
+	}
+	if tagStatistics != nil {
+		return tagStatistics
+	}
+	return defaultStatistics
+}
+
+// getTagStatistics removes the statistics tags from the point and returns the statistics they choose
+func getTagStatistics(point telegraf.Metric) *statistics {
+	mode, hasMode := point.GetTag(statisticsTagKey)
+	percentilesTag, hasPercentiles := point.GetTag(percentilesTagKey)
+	if !hasMode && !hasPercentiles {
+		return nil
+	}
+	point.RemoveTag(statisticsTagKey)
+	point.RemoveTag(percentilesTagKey)
+
+	percentiles, err := parsePercentiles(percentilesTag)
+	if err != nil {
+		log.Printf("W! cloudwatch: invalid %s tag %s of the metric %s: %v", percentilesTagKey, percentilesTag, point.Name(), err)
+		return nil
+	}
+	if mode == "" && len(percentiles) > 0 {
+		mode = StatisticsPercentiles
+	}
+	stats, err := newStatistics(mode, percentiles)
+	if err != nil {
+		log.Printf("W! cloudwatch: invalid statistics tags of the metric %s: %v", point.Name(), err)
+		return nil
+	}
+	return stats
+}
+
 func (c *CloudWatch) decorateMetricUnit(category string, name string) (decoratedUnit string) {
 	if c.metricDecorations != nil {
 		decoratedUnit = c.metricDecorations.getUnit(category, name)
@@ -410,6 +526,7 @@ func (c *CloudWatch) BuildMetricDatum(point telegraf.Metric) []*cloudwatch.Metri
 		isHighResolution = true
 		point.RemoveTag(highResolutionTagKey)
 	}
+	tagStatistics := getTagStatistics(point)
 
 	rawDimensions := BuildDimensions(point.Tags())
 	dimensionsList := c.ProcessRollup(rawDimensions)
@@ -419,6 +536,7 @@ func (c *CloudWatch) BuildMetricDatum(point telegraf.Metric) []*cloudwatch.Metri
 	for k, v := range point.Fields() {
 		var unit string
 		var value float64
+		var dist distribution.Distribution
 		var distList []distribution.Distribution
 
 		switch t := v.(type) {
@@ -459,7 +577,7 @@ func (c *CloudWatch) BuildMetricDatum(point telegraf.Metric) []*cloudwatch.Metri
 				// the distribution does not have a value
 				continue
 			}
-			distList = resize(t, c.MaxValuesPerDatum)
+			dist = t
 			unit = t.Unit()
 		default:
 			// Skip unsupported type.
@@ -470,9 +588,51 @@ func (c *CloudWatch) BuildMetricDatum(point telegraf.Metric) []*cloudwatch.Metri
 		if unit == "" {
 			unit = c.decorateMetricUnit(point.Name(), k)
 		}
+		stats := defaultStatistics
+		if dist != nil {
+			stats = c.decorateMetricStatistics(point.Name(), k, tagStatistics)
+			if stats.mode == StatisticsDistribution {
+				distList = resize(dist, c.MaxValuesPerDatum)
+			}
+		}
 
 		for _, dimensions := range dimensionsList {
-			if len(distList) == 0 {
+			if dist != nil && stats.mode == StatisticsStatisticSet {
+				datum := &cloudwatch.MetricDatum{
+					MetricName: metricName,
+					Dimensions: dimensions,
+					Timestamp:  aws.Time(point.Time()),
+					StatisticValues: &cloudwatch.StatisticSet{
+						Maximum:     aws.Float64(dist.Maximum()),
+						Minimum:     aws.Float64(dist.Minimum()),
+						SampleCount: aws.Float64(dist.SampleCount()),
+						Sum:         aws.Float64(dist.Sum()),
+					},
+				}
+				if unit != "" {
+					datum.SetUnit(unit)
+				}
+				if isHighResolution {
+					datum.SetStorageResolution(1)
+				}
+				datums = append(datums, datum)
+			} else if dist != nil && stats.mode == StatisticsPercentiles {
+				for _, p := range stats.percentiles {
+					datum := &cloudwatch.MetricDatum{
+						MetricName: aws.String(*metricName + metricNameSeparator() + percentileSuffix(p)),
+						Dimensions: dimensions,
+						Timestamp:  aws.Time(point.Time()),
+						Value:      aws.Float64(percentile(dist, p)),
+					}
+					if unit != "" {
+						datum.SetUnit(unit)
+					}
+					if isHighResolution {
+						datum.SetStorageResolution(1)
+					}
+					datums = append(datums, datum)
+				}
+			} else if len(distList) == 0 {
 				datum := &cloudwatch.MetricDatum{
 					MetricName: metricName,
 					Dimensions: dimensions,
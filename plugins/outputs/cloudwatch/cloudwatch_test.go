@@ -410,6 +410,35 @@ func TestWriteError(t *testing.T) {
 
 }
 
+func TestWrite_NamespaceOverride(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	res := cloudwatch.PutMetricDataOutput{}
+	svc.On("PutMetricData", mock.Anything).Return(
+		&res,
+		nil)
+	cloudWatchOutput := newCloudWatchClient(svc)
+	cloudWatchOutput.Namespace = "CWAgent"
+	cloudWatchOutput.publisher, _ = publisher.NewPublisher(publisher.NewNonBlockingFifoQueue(10), 10, 2*time.Second, cloudWatchOutput.WriteToCloudWatch)
+
+	ti := time.Now()
+	m1, _ := metric.New("cpu", map[string]string{"host": "example.org"}, map[string]interface{}{"value": 1}, ti)
+	m2, _ := metric.New("Latency", map[string]string{"Service": "checkout", namespaceTagKey: "MyApp"}, map[string]interface{}{"value": 2}, ti)
+	cloudWatchOutput.Write([]telegraf.Metric{m1, m2})
+	time.Sleep(time.Second + 2*cloudWatchOutput.ForceFlushInterval.Duration)
+	cloudWatchOutput.Close()
+
+	datums := map[string][]*cloudwatch.MetricDatum{}
+	for _, call := range svc.Calls {
+		input := call.Arguments.Get(0).(*cloudwatch.PutMetricDataInput)
+		datums[*input.Namespace] = append(datums[*input.Namespace], input.MetricData...)
+	}
+	require.Len(t, datums["CWAgent"], 1)
+	assert.Equal(t, "cpu", *datums["CWAgent"][0].MetricName)
+	require.Len(t, datums["MyApp"], 1)
+	assert.Equal(t, "Latency", *datums["MyApp"][0].MetricName)
+	assert.Equal(t, []*cloudwatch.Dimension{{Name: aws.String("Service"), Value: aws.String("checkout")}}, datums["MyApp"][0].Dimensions)
+}
+
 func TestMetricConfigsRead(t *testing.T) {
 	contents := `[[outputs.cloudwatch.metric_decoration]]
                      category = "cpu"
@@ -521,7 +550,7 @@ func TestBackoffRetries(t *testing.T) {
 
 func TestCloudWatch_metricDatumBatchFull(t *testing.T) {
 	c := &CloudWatch{
-		datumBatchChan:     make(chan []*cloudwatch.MetricDatum, datumBatchChanBufferSize),
+		datumBatchChan:     make(chan *cloudwatch.PutMetricDataInput, datumBatchChanBufferSize),
 		datumBatchFullChan: make(chan bool, 1),
 	}
 
@@ -532,7 +561,7 @@ func TestCloudWatch_metricDatumBatchFull(t *testing.T) {
 	}
 
 	for i := 0; i < datumBatchChanBufferSize; i++ {
-		c.datumBatchChan <- []*cloudwatch.MetricDatum{}
+		c.datumBatchChan <- &cloudwatch.PutMetricDataInput{}
 	}
 
 	select {
@@ -553,7 +582,7 @@ func TestCloudWatch_metricDatumBatchFull(t *testing.T) {
 
 func TestBuildMetricDatums_SkipEmptyTags(t *testing.T) {
 	c := &CloudWatch{
-		datumBatchChan:     make(chan []*cloudwatch.MetricDatum, 0),
+		datumBatchChan:     make(chan *cloudwatch.PutMetricDataInput, 0),
 		datumBatchFullChan: make(chan bool, 1),
 	}
 	input := testutil.MustMetric(
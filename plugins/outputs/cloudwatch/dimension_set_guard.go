@@ -0,0 +1,166 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	// OverflowActionDrop drops the datums of the dimension sets over the limits
+	OverflowActionDrop = "drop"
+	// OverflowActionCollapse publishes the datums of the dimension sets over the limits with the overflow dimension value
+	OverflowActionCollapse = "collapse"
+
+	overflowDimensionValue     = "__overflow__"
+	defaultDimensionSetsWindow = time.Hour
+	// the dimension sets not seen within the window are forgotten at most once per expiry interval
+	dimensionSetsExpiryInterval = time.Minute
+)
+
+// dimensionSetGuard caps the number of unique dimension sets published per metric name and per namespace. A
+// dimension set is tracked until it is not seen for the window, so the caps apply to the recently active series.
+type dimensionSetGuard struct {
+	maxPerMetric    int
+	maxPerNamespace int
+	window          time.Duration
+	collapse        bool
+
+	namespaces map[string]*namespaceDimensionSets
+	warned     map[string]time.Time // namespace and metric name -> time of the last overflow warning
+	lastExpiry time.Time
+	overflowed selfstat.Stat
+}
+
+type namespaceDimensionSets struct {
+	count   int
+	metrics map[string]map[string]time.Time // metric name -> dimension set key -> time it was last seen
+}
+
+func newDimensionSetGuard(maxPerMetric, maxPerNamespace int, window time.Duration, action string) (*dimensionSetGuard, error) {
+	if maxPerMetric < 0 || maxPerNamespace < 0 {
+		return nil, fmt.Errorf("the dimension sets limits cannot be negative")
+	}
+	if action != "" && action != OverflowActionDrop && action != OverflowActionCollapse {
+		return nil, fmt.Errorf("unknown dimension sets overflow action %s", action)
+	}
+	if maxPerMetric == 0 && maxPerNamespace == 0 {
+		// no limit
+		return nil, nil
+	}
+	if window <= 0 {
+		window = defaultDimensionSetsWindow
+	}
+	return &dimensionSetGuard{
+		maxPerMetric:    maxPerMetric,
+		maxPerNamespace: maxPerNamespace,
+		window:          window,
+		collapse:        action == OverflowActionCollapse,
+		namespaces:      map[string]*namespaceDimensionSets{},
+		warned:          map[string]time.Time{},
+		lastExpiry:      time.Now(),
+		overflowed:      selfstat.Register("cloudwatch", "dimension_sets_overflow", map[string]string{}),
+	}, nil
+}
+
+// admit returns whether the datum is published, the dimensions of a collapsed datum are replaced by the overflow ones
+func (g *dimensionSetGuard) admit(namespace string, datum *cloudwatch.MetricDatum, now time.Time) bool {
+	ns, ok := g.namespaces[namespace]
+	if !ok {
+		ns = &namespaceDimensionSets{metrics: map[string]map[string]time.Time{}}
+		g.namespaces[namespace] = ns
+	}
+	metricName := aws.StringValue(datum.MetricName)
+	dimensionSets, ok := ns.metrics[metricName]
+	if !ok {
+		dimensionSets = map[string]time.Time{}
+		ns.metrics[metricName] = dimensionSets
+	}
+
+	key := dimensionSetKey(datum.Dimensions)
+	if _, ok := dimensionSets[key]; ok {
+		dimensionSets[key] = now
+		return true
+	}
+	if (g.maxPerMetric == 0 || len(dimensionSets) < g.maxPerMetric) &&
+		(g.maxPerNamespace == 0 || ns.count < g.maxPerNamespace) {
+		dimensionSets[key] = now
+		ns.count++
+		return true
+	}
+
+	g.overflowed.Incr(1)
+	g.warn(namespace, metricName, now)
+	if !g.collapse {
+		return false
+	}
+	// the dimensions may be shared with the other datums of the point
+	dimensions := make([]*cloudwatch.Dimension, len(datum.Dimensions))
+	for i, d := range datum.Dimensions {
+		dimensions[i] = &cloudwatch.Dimension{Name: d.Name, Value: aws.String(overflowDimensionValue)}
+	}
+	datum.Dimensions = dimensions
+	return true
+}
+
+func (g *dimensionSetGuard) warn(namespace, metricName string, now time.Time) {
+	key := namespace + "/" + metricName
+	if last, ok := g.warned[key]; ok && now.Sub(last) < g.window {
+		return
+	}
+	g.warned[key] = now
+	action := "dropped"
+	if g.collapse {
+		action = "collapsed into the " + overflowDimensionValue + " dimension value"
+	}
+	log.Printf("W! cloudwatch: the metric %s in the namespace %s exceeds the limit of unique dimension sets "+
+		"(per metric: %d, per namespace: %d), the new dimension sets are %s.",
+		metricName, namespace, g.maxPerMetric, g.maxPerNamespace, action)
+}
+
+// expire forgets the dimension sets which have not been seen within the window
+func (g *dimensionSetGuard) expire(now time.Time) {
+	if now.Sub(g.lastExpiry) < dimensionSetsExpiryInterval {
+		return
+	}
+	g.lastExpiry = now
+	for namespace, ns := range g.namespaces {
+		for metricName, dimensionSets := range ns.metrics {
+			for key, lastSeen := range dimensionSets {
+				if now.Sub(lastSeen) >= g.window {
+					delete(dimensionSets, key)
+					ns.count--
+				}
+			}
+			if len(dimensionSets) == 0 {
+				delete(ns.metrics, metricName)
+			}
+		}
+		if len(ns.metrics) == 0 {
+			delete(g.namespaces, namespace)
+		}
+	}
+	for key, last := range g.warned {
+		if now.Sub(last) >= g.window {
+			delete(g.warned, key)
+		}
+	}
+}
+
+func dimensionSetKey(dimensions []*cloudwatch.Dimension) string {
+	pairs := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		pairs[i] = aws.StringValue(d.Name) + "=" + aws.StringValue(d.Value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,109 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDatum(name string, dimensions ...string) *cloudwatch.MetricDatum {
+	datum := &cloudwatch.MetricDatum{MetricName: aws.String(name)}
+	for i := 0; i+1 < len(dimensions); i += 2 {
+		datum.Dimensions = append(datum.Dimensions, &cloudwatch.Dimension{
+			Name:  aws.String(dimensions[i]),
+			Value: aws.String(dimensions[i+1]),
+		})
+	}
+	return datum
+}
+
+func TestNewDimensionSetGuard(t *testing.T) {
+	guard, err := newDimensionSetGuard(0, 0, 0, "")
+	assert.NoError(t, err)
+	assert.Nil(t, guard)
+
+	guard, err = newDimensionSetGuard(10, 0, 0, OverflowActionCollapse)
+	require.NoError(t, err)
+	assert.Equal(t, defaultDimensionSetsWindow, guard.window)
+	assert.True(t, guard.collapse)
+
+	_, err = newDimensionSetGuard(10, 0, 0, "sample")
+	assert.Error(t, err)
+	_, err = newDimensionSetGuard(-1, 0, 0, "")
+	assert.Error(t, err)
+}
+
+func TestDimensionSetGuard_PerMetric(t *testing.T) {
+	guard, err := newDimensionSetGuard(2, 0, time.Hour, OverflowActionDrop)
+	require.NoError(t, err)
+	now := time.Now()
+
+	assert.True(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "1"), now))
+	assert.True(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "2"), now))
+	assert.False(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "3"), now))
+	// the known dimension sets are still published
+	assert.True(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "1"), now))
+	// the limit is per metric name and per namespace
+	assert.True(t, guard.admit("CWAgent", newDatum("errors", "RequestId", "3"), now))
+	assert.True(t, guard.admit("MyApp", newDatum("latency", "RequestId", "3"), now))
+	// the order of the dimensions does not matter
+	assert.True(t, guard.admit("MyApp", newDatum("requests", "a", "1", "b", "2"), now))
+	assert.True(t, guard.admit("MyApp", newDatum("requests", "b", "2", "a", "1"), now))
+	assert.Len(t, guard.namespaces["MyApp"].metrics["requests"], 1)
+}
+
+func TestDimensionSetGuard_PerNamespace(t *testing.T) {
+	guard, err := newDimensionSetGuard(0, 3, time.Hour, "")
+	require.NoError(t, err)
+	now := time.Now()
+
+	assert.True(t, guard.admit("CWAgent", newDatum("cpu", "host", "a"), now))
+	assert.True(t, guard.admit("CWAgent", newDatum("mem", "host", "a"), now))
+	assert.True(t, guard.admit("CWAgent", newDatum("disk", "host", "a"), now))
+	assert.False(t, guard.admit("CWAgent", newDatum("net", "host", "a"), now))
+	assert.False(t, guard.admit("CWAgent", newDatum("cpu", "host", "b"), now))
+	assert.True(t, guard.admit("MyApp", newDatum("net", "host", "a"), now))
+}
+
+func TestDimensionSetGuard_Collapse(t *testing.T) {
+	guard, err := newDimensionSetGuard(1, 0, time.Hour, OverflowActionCollapse)
+	require.NoError(t, err)
+	now := time.Now()
+
+	assert.True(t, guard.admit("CWAgent", newDatum("latency", "Service", "checkout", "RequestId", "1"), now))
+	overflow := newDatum("latency", "Service", "checkout", "RequestId", "2")
+	dimensions := overflow.Dimensions
+	assert.True(t, guard.admit("CWAgent", overflow, now))
+	assert.Equal(t, []*cloudwatch.Dimension{
+		{Name: aws.String("Service"), Value: aws.String(overflowDimensionValue)},
+		{Name: aws.String("RequestId"), Value: aws.String(overflowDimensionValue)},
+	}, overflow.Dimensions)
+	// the dimensions shared with the other datums of the point are not modified
+	assert.Equal(t, "2", *dimensions[1].Value)
+}
+
+func TestDimensionSetGuard_Expire(t *testing.T) {
+	guard, err := newDimensionSetGuard(1, 1, 10*time.Minute, OverflowActionDrop)
+	require.NoError(t, err)
+	start := time.Now()
+
+	assert.True(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "1"), start))
+	assert.False(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "2"), start))
+
+	// the dimension set is still within the window
+	guard.expire(start.Add(9 * time.Minute))
+	assert.False(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "2"), start.Add(9*time.Minute)))
+
+	guard.expire(start.Add(11 * time.Minute))
+	assert.Empty(t, guard.namespaces)
+	assert.Empty(t, guard.warned)
+	assert.True(t, guard.admit("CWAgent", newDatum("latency", "RequestId", "2"), start.Add(11*time.Minute)))
+	assert.Equal(t, 1, guard.namespaces["CWAgent"].count)
+}
@@ -14,6 +14,9 @@ type MetricDecorationConfig struct {
 	Metric   string `toml:"name"`
 	Rename   string `toml:"rename"`
 	Unit     string `toml:"unit"`
+	// Statistics chooses how an aggregated metric is published: distribution (default), statistic_set or percentiles
+	Statistics  string    `toml:"statistics"`
+	Percentiles []float64 `toml:"percentiles"`
 }
 
 var supportedUnits = []string{"Seconds", "Microseconds", "Milliseconds", "Bytes", "Kilobytes", "Megabytes",
@@ -26,6 +29,7 @@ func NewMetricDecorations(metricConfigs []MetricDecorationConfig) (*MetricDecora
 	result := &MetricDecorations{
 		decorationNames: make(map[string]map[string]string),
 		decorationUnits: make(map[string]map[string]string),
+		decorationStats: make(map[string]map[string]*statistics),
 	}
 
 	for k, v := range defaultUnits {
@@ -45,6 +49,10 @@ func NewMetricDecorations(metricConfigs []MetricDecorationConfig) (*MetricDecora
 		if err != nil {
 			return result, err
 		}
+		err = result.addStatistics(metricConfig.Category, metricConfig.Metric, metricConfig.Statistics, metricConfig.Percentiles)
+		if err != nil {
+			return result, err
+		}
 	}
 	return result, nil
 }
@@ -52,6 +60,7 @@ func NewMetricDecorations(metricConfigs []MetricDecorationConfig) (*MetricDecora
 type MetricDecorations struct {
 	decorationNames map[string]map[string]string
 	decorationUnits map[string]map[string]string
+	decorationStats map[string]map[string]*statistics
 }
 
 func (m *MetricDecorations) getUnit(category string, metric string) string {
@@ -61,6 +70,13 @@ func (m *MetricDecorations) getUnit(category string, metric string) string {
 	return ""
 }
 
+func (m *MetricDecorations) getStatistics(category string, metric string) *statistics {
+	if val, ok := m.decorationStats[category]; ok {
+		return val[metric]
+	}
+	return nil
+}
+
 func (m *MetricDecorations) getRename(category string, metric string) string {
 	if val, ok := m.decorationNames[category]; ok {
 		return val[metric]
@@ -109,3 +125,24 @@ func (m *MetricDecorations) addDecorations(category string, name string, rename
 	}
 	return nil
 }
+
+func (m *MetricDecorations) addStatistics(category string, name string, mode string, percentiles []float64) error {
+	if category == "" || name == "" || (mode == "" && len(percentiles) == 0) {
+		return nil
+	}
+	if mode == "" {
+		// the percentiles alone choose the percentiles statistics
+		mode = StatisticsPercentiles
+	}
+	stats, err := newStatistics(mode, percentiles)
+	if err != nil {
+		return fmt.Errorf("invalid statistics of %s %s: %v", category, name, err)
+	}
+	val, ok := m.decorationStats[category]
+	if !ok {
+		val = make(map[string]*statistics)
+		m.decorationStats[category] = val
+	}
+	val[name] = stats
+	return nil
+}
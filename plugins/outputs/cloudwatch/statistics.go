@@ -0,0 +1,105 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+)
+
+const (
+	// StatisticsDistribution publishes the values and counts of the aggregated metrics with their statistic set
+	StatisticsDistribution = "distribution"
+	// StatisticsStatisticSet only publishes the minimum, maximum, sum and sample count of the aggregated metrics
+	StatisticsStatisticSet = "statistic_set"
+	// StatisticsPercentiles publishes a metric per percentile of the aggregated metrics, e.g. Latency_p99
+	StatisticsPercentiles = "percentiles"
+
+	// the tags set on the inputs to choose the statistics of their aggregated metrics, the metric_decoration
+	// statistics of a metric take precedence over them
+	statisticsTagKey  = "aws:Statistics"
+	percentilesTagKey = "aws:Percentiles"
+)
+
+var defaultPercentiles = []float64{50, 90, 99}
+
+type statistics struct {
+	mode        string
+	percentiles []float64
+}
+
+var defaultStatistics = &statistics{mode: StatisticsDistribution}
+
+func newStatistics(mode string, percentiles []float64) (*statistics, error) {
+	switch mode {
+	case "", StatisticsDistribution:
+		return defaultStatistics, nil
+	case StatisticsStatisticSet:
+		return &statistics{mode: mode}, nil
+	case StatisticsPercentiles:
+		if len(percentiles) == 0 {
+			percentiles = defaultPercentiles
+		}
+		for _, p := range percentiles {
+			if p <= 0 || p > 100 {
+				return nil, fmt.Errorf("percentile %v is not in (0, 100]", p)
+			}
+		}
+		return &statistics{mode: mode, percentiles: percentiles}, nil
+	default:
+		return nil, fmt.Errorf("unknown statistics %s", mode)
+	}
+}
+
+// parsePercentiles parses the comma separated percentiles of the aws:Percentiles tag, e.g. "50,90,99.9" or "p50,p99"
+func parsePercentiles(s string) ([]float64, error) {
+	var percentiles []float64
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "p")
+		if p == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, err
+		}
+		percentiles = append(percentiles, value)
+	}
+	return percentiles, nil
+}
+
+// percentileSuffix returns the suffix of the metric name of a percentile, e.g. p99 or p99.9
+func percentileSuffix(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// percentile returns the smallest value of the distribution whose cumulative count covers the percentile
+func percentile(dist distribution.Distribution, p float64) float64 {
+	values, counts := dist.ValuesAndCounts()
+	indexes := make([]int, len(values))
+	var total float64
+	for i := range values {
+		indexes[i] = i
+		total += counts[i]
+	}
+	sort.Slice(indexes, func(i, j int) bool { return values[indexes[i]] < values[indexes[j]] })
+
+	target := total * p / 100
+	value := dist.Maximum()
+	var cumulative float64
+	for _, i := range indexes {
+		cumulative += counts[i]
+		if cumulative >= target {
+			value = values[i]
+			break
+		}
+	}
+	// the values of a SEH1 distribution are the bucket values, keep the percentile within the actual values
+	return math.Min(math.Max(value, dist.Minimum()), dist.Maximum())
+}
@@ -0,0 +1,121 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatistics(t *testing.T) {
+	stats, err := newStatistics("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatisticsDistribution, stats.mode)
+
+	stats, err = newStatistics(StatisticsPercentiles, nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPercentiles, stats.percentiles)
+
+	stats, err = newStatistics(StatisticsPercentiles, []float64{99.9})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{99.9}, stats.percentiles)
+
+	_, err = newStatistics(StatisticsPercentiles, []float64{0})
+	assert.Error(t, err)
+	_, err = newStatistics(StatisticsPercentiles, []float64{101})
+	assert.Error(t, err)
+	_, err = newStatistics("average", nil)
+	assert.Error(t, err)
+}
+
+func TestParsePercentiles(t *testing.T) {
+	percentiles, err := parsePercentiles("50, p90,99.9")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{50, 90, 99.9}, percentiles)
+
+	percentiles, err = parsePercentiles("")
+	require.NoError(t, err)
+	assert.Empty(t, percentiles)
+
+	_, err = parsePercentiles("p50,high")
+	assert.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	distribution.NewDistribution = regular.NewRegularDistribution
+	dist := distribution.NewDistribution()
+	for i := 1; i <= 100; i++ {
+		dist.AddEntry(float64(i), 1)
+	}
+	assert.Equal(t, float64(50), percentile(dist, 50))
+	assert.Equal(t, float64(99), percentile(dist, 99))
+	assert.Equal(t, float64(100), percentile(dist, 100))
+	assert.Equal(t, "p99", percentileSuffix(99))
+	assert.Equal(t, "p99.9", percentileSuffix(99.9))
+
+	weighted := distribution.NewDistribution()
+	weighted.AddEntry(1, 90)
+	weighted.AddEntry(1000, 10)
+	assert.Equal(t, float64(1), percentile(weighted, 90))
+	assert.Equal(t, float64(1000), percentile(weighted, 91))
+}
+
+func TestBuildMetricDatums_Statistics(t *testing.T) {
+	distribution.NewDistribution = regular.NewRegularDistribution
+	newPoint := func(tags map[string]string) telegraf.Metric {
+		dist := distribution.NewDistribution()
+		for i := 1; i <= 100; i++ {
+			dist.AddEntryWithUnit(float64(i), 1, "Milliseconds")
+		}
+		return testutil.MustMetric("latency", tags, map[string]interface{}{"value": dist}, time.Unix(0, 0))
+	}
+	decorations, err := NewMetricDecorations([]MetricDecorationConfig{
+		{Category: "latency", Metric: "value", Statistics: StatisticsStatisticSet},
+	})
+	require.NoError(t, err)
+	c := &CloudWatch{MaxValuesPerDatum: 150}
+
+	// the statistics tags
+	datums := c.BuildMetricDatum(newPoint(map[string]string{"service": "checkout", statisticsTagKey: StatisticsPercentiles, percentilesTagKey: "50,99"}))
+	require.Len(t, datums, 2)
+	assert.Equal(t, "latency_p50", *datums[0].MetricName)
+	assert.Equal(t, float64(50), *datums[0].Value)
+	assert.Equal(t, "latency_p99", *datums[1].MetricName)
+	assert.Equal(t, float64(99), *datums[1].Value)
+	assert.Equal(t, "Milliseconds", *datums[1].Unit)
+	assert.Nil(t, datums[1].Values)
+	assert.Equal(t, []*cloudwatch.Dimension{{Name: aws.String("service"), Value: aws.String("checkout")}}, datums[1].Dimensions)
+
+	// the distribution by default
+	datums = c.BuildMetricDatum(newPoint(map[string]string{}))
+	require.Len(t, datums, 1)
+	assert.NotEmpty(t, datums[0].Values)
+	assert.NotNil(t, datums[0].StatisticValues)
+	distributionDatum := datums[0]
+
+	// the metric_decoration takes precedence over the tags
+	c.metricDecorations = decorations
+	datums = c.BuildMetricDatum(newPoint(map[string]string{statisticsTagKey: StatisticsPercentiles}))
+	require.Len(t, datums, 1)
+	assert.Equal(t, "latency", *datums[0].MetricName)
+	assert.Nil(t, datums[0].Values)
+	assert.Nil(t, datums[0].Value)
+	assert.Equal(t, &cloudwatch.StatisticSet{
+		Maximum:     aws.Float64(100),
+		Minimum:     aws.Float64(1),
+		SampleCount: aws.Float64(100),
+		Sum:         aws.Float64(5050),
+	}, datums[0].StatisticValues)
+	assert.Empty(t, datums[0].Dimensions)
+	assert.Less(t, payload(datums[0]), payload(distributionDatum))
+}
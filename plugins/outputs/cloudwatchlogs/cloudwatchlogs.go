@@ -4,8 +4,14 @@
 package cloudwatchlogs
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +22,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/internal"
 	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
@@ -38,6 +45,8 @@ const (
 	metricRetryTimeout = 2 * time.Minute
 
 	attributesInFields = "attributesInFields"
+
+	spoolTargetFileName = "target.json"
 )
 
 type CloudWatchLogs struct {
@@ -59,16 +68,122 @@ type CloudWatchLogs struct {
 
 	ForceFlushInterval internal.Duration `toml:"force_flush_interval"` // unit is second
 
+	// Directory of the on-disk spool, events are buffered in memory only when it is empty
+	SpoolDir string `toml:"spool_dir"`
+	// Max size of the spool of each log group/stream
+	SpoolMaxSizeMB int `toml:"spool_max_size_mb"`
+
 	Log telegraf.Logger `toml:"-"`
 
-	cwDests map[Target]*cwDest
+	// the destinations are created by the log sources routing their events as well as the agent
+	cwDestsLock sync.Mutex
+	cwDests     map[Target]*cwDest
 }
 
 func (c *CloudWatchLogs) Connect() error {
+	if c.SpoolDir != "" {
+		c.replaySpools()
+	}
 	return nil
 }
 
+// replaySpools creates the destinations of the spools left with unsent events by the previous run,
+// so the events are sent even if their log source is not found again.
+func (c *CloudWatchLogs) replaySpools() {
+	files, err := ioutil.ReadDir(c.SpoolDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.Log.Errorf("Unable to read spool directory %v: %v", c.SpoolDir, err)
+		}
+		return
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		dir := filepath.Join(c.SpoolDir, f.Name())
+		content, err := ioutil.ReadFile(filepath.Join(dir, spoolTargetFileName))
+		if err != nil {
+			c.Log.Warnf("Ignoring spool %v without target information: %v", dir, err)
+			continue
+		}
+		var st spoolTarget
+		if err := json.Unmarshal(content, &st); err != nil {
+			c.Log.Warnf("Ignoring spool %v with invalid target information: %v", dir, err)
+			continue
+		}
+		c.replaySpool(dir, st)
+	}
+}
+
+// replaySpool creates the destination of the spool if it has a backlog. The log agent may already be creating the
+// destinations of its log sources, so the spool is opened and its destination created under cwDestsLock.
+func (c *CloudWatchLogs) replaySpool(dir string, st spoolTarget) {
+	c.cwDestsLock.Lock()
+	defer c.cwDestsLock.Unlock()
+	if cwd, ok := c.cwDests[st.Target]; ok {
+		// the destination was created first with the spool of its target
+		cwd.refs++
+		if st.EMF {
+			cwd.switchToEMF()
+		}
+		return
+	}
+	sp, err := openSpool(dir, int64(c.SpoolMaxSizeMB)*1024*1024, c.Log)
+	if err != nil {
+		c.Log.Errorf("Unable to open spool %v: %v", dir, err)
+		return
+	}
+	if !sp.hasBacklog() {
+		sp.close()
+		if err := os.RemoveAll(dir); err != nil {
+			c.Log.Warnf("Unable to remove empty spool %v: %v", dir, err)
+		}
+		return
+	}
+	cwd := c.newDest(st.Target, sp)
+	cwd.refs++ // kept until the agent stops, like the destinations of the metrics
+	if st.EMF {
+		cwd.switchToEMF()
+	}
+	c.Log.Infof("Replaying spooled log events for %v/%v", st.Group, st.Stream)
+}
+
+// spoolTarget is stored along with a spool to find its destination on restart.
+type spoolTarget struct {
+	Target
+	EMF bool
+}
+
+func (c *CloudWatchLogs) spoolDir(t Target) string {
+	h := sha1.Sum([]byte(fmt.Sprintf("%s\x00%s\x00%d", t.Group, t.Stream, t.Retention)))
+	return filepath.Join(c.SpoolDir, hex.EncodeToString(h[:]))
+}
+
+func (c *CloudWatchLogs) openSpool(t Target) *spool {
+	dir := c.spoolDir(t)
+	s, err := openSpool(dir, int64(c.SpoolMaxSizeMB)*1024*1024, c.Log)
+	if err != nil {
+		c.Log.Errorf("Unable to open spool for %v/%v, log events will be buffered in memory only: %v", t.Group, t.Stream, err)
+		return nil
+	}
+	c.saveSpoolTarget(dir, spoolTarget{Target: t})
+	return s
+}
+
+func (c *CloudWatchLogs) saveSpoolTarget(dir string, st spoolTarget) {
+	content, err := json.Marshal(st)
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(dir, spoolTargetFileName), content, spoolFileMode)
+	}
+	if err != nil {
+		c.Log.Errorf("Unable to save spool target for %v/%v: %v", st.Group, st.Stream, err)
+	}
+}
+
 func (c *CloudWatchLogs) Close() error {
+	c.cwDestsLock.Lock()
+	defer c.cwDestsLock.Unlock()
 	for _, d := range c.cwDests {
 		d.Stop()
 	}
@@ -102,10 +217,40 @@ func (c *CloudWatchLogs) CreateDest(group, stream string, retention int) logs.Lo
 }
 
 func (c *CloudWatchLogs) getDest(t Target) *cwDest {
+	c.cwDestsLock.Lock()
+	defer c.cwDestsLock.Unlock()
 	if cwd, ok := c.cwDests[t]; ok {
+		cwd.refs++
 		return cwd
 	}
 
+	var s *spool
+	if c.SpoolDir != "" {
+		s = c.openSpool(t)
+	}
+	cwd := c.newDest(t, s)
+	cwd.refs++
+	return cwd
+}
+
+// ReleaseDest stops the destination once it is released by all the log sources which created it,
+// the destinations of the metrics are never released.
+func (c *CloudWatchLogs) ReleaseDest(d logs.LogDest) {
+	cwd, ok := d.(*cwDest)
+	if !ok {
+		return
+	}
+	c.cwDestsLock.Lock()
+	defer c.cwDestsLock.Unlock()
+	cwd.refs--
+	if cwd.refs > 0 || cwd.stopped {
+		return
+	}
+	delete(c.cwDests, cwd.Target)
+	cwd.Stop()
+}
+
+func (c *CloudWatchLogs) newDest(t Target, s *spool) *cwDest {
 	credentialConfig := &configaws.CredentialConfig{
 		Region:    c.Region,
 		AccessKey: c.AccessKey,
@@ -127,8 +272,12 @@ func (c *CloudWatchLogs) getDest(t Target) *cwDest {
 	client.Handlers.Build.PushBackNamed(handlers.NewRequestCompressionHandler([]string{"PutLogEvents"}))
 	client.Handlers.Build.PushBackNamed(handlers.NewCustomHeaderHandler("User-Agent", agentinfo.UserAgent()))
 
-	pusher := NewPusher(t, client, c.ForceFlushInterval.Duration, maxRetryTimeout, c.Log)
-	cwd := &cwDest{pusher: pusher, retryer: logThrottleRetryer}
+	pusher := newSpooledPusher(t, client, c.ForceFlushInterval.Duration, maxRetryTimeout, c.Log, s)
+	cwd := &cwDest{pusher: pusher, retryer: logThrottleRetryer, onSwitchToEMF: func() {
+		if s != nil {
+			c.saveSpoolTarget(c.spoolDir(t), spoolTarget{Target: t, EMF: true})
+		}
+	}}
 	c.cwDests[t] = cwd
 	return cwd
 }
@@ -173,6 +322,39 @@ func (c *CloudWatchLogs) getTargetFromMetric(m telegraf.Metric) (Target, error)
 	return Target{logGroup, logStream, -1}, nil
 }
 
+// EMF representation of a distribution, values are sorted so the same distribution always produces the same log
+type emfDistributionValue struct {
+	Values []float64
+	Counts []float64
+	Max    float64
+	Min    float64
+	Count  float64
+	Sum    float64
+}
+
+func getEMFDistributionValue(d distribution.Distribution) emfDistributionValue {
+	values, counts := d.ValuesAndCounts()
+	indexes := make([]int, len(values))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool { return values[indexes[i]] < values[indexes[j]] })
+
+	v := emfDistributionValue{
+		Values: make([]float64, 0, len(values)),
+		Counts: make([]float64, 0, len(counts)),
+		Max:    d.Maximum(),
+		Min:    d.Minimum(),
+		Count:  d.SampleCount(),
+		Sum:    d.Sum(),
+	}
+	for _, i := range indexes {
+		v.Values = append(v.Values, values[i])
+		v.Counts = append(v.Counts, counts[i])
+	}
+	return v
+}
+
 func (c *CloudWatchLogs) getLogEventFromMetric(metric telegraf.Metric) *structuredLogEvent {
 	var message string
 	if metric.HasField(LogEntryField) {
@@ -227,6 +409,8 @@ func (c *CloudWatchLogs) getLogEventFromMetric(metric telegraf.Metric) *structur
 				value = t
 			case time.Time:
 				value = float64(t.Unix())
+			case distribution.Distribution:
+				value = getEMFDistributionValue(t)
 
 			default:
 				c.Log.Errorf("Detected unexpected fields (%s,%v) when encoding structured log event, value type %T is not supported", k, v, v)
@@ -266,9 +450,11 @@ func (e *structuredLogEvent) Done() {}
 type cwDest struct {
 	*pusher
 	sync.Mutex
-	isEMF   bool
-	stopped bool
-	retryer *retryer.LogThrottleRetryer
+	isEMF         bool
+	stopped       bool
+	refs          int // the number of times it was created, guarded by cwDestsLock
+	retryer       *retryer.LogThrottleRetryer
+	onSwitchToEMF func()
 }
 
 func (cd *cwDest) Publish(events []logs.LogEvent) error {
@@ -311,6 +497,9 @@ func (cd *cwDest) switchToEMF() {
 		if ok {
 			cwl.Handlers.Build.PushBackNamed(handlers.NewCustomHeaderHandler("x-amzn-logs-format", "json/emf"))
 		}
+		if cd.onSwitchToEMF != nil {
+			cd.onSwitchToEMF()
+		}
 	}
 }
 
@@ -352,6 +541,12 @@ var sampleConfig = `
 
   # The log stream name.
   log_stream_name = "<log_stream_name>"
+
+  ## Directory of the on-disk spool which keeps log events until they are accepted
+  ## by CloudWatch Logs, log events are only buffered in memory if not set.
+  #spool_dir = "/opt/aws/amazon-cloudwatch-agent/logs/spool"
+  ## Max size of the spool of each log group/stream, defaults to 100MB
+  #spool_max_size_mb = 100
 `
 
 // SampleConfig returns the default configuration of the Output
@@ -5,8 +5,14 @@ package cloudwatchlogs
 
 import (
 	"testing"
+	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateDest(t *testing.T) {
@@ -44,3 +50,39 @@ func TestCreateDest(t *testing.T) {
 		t.Errorf("Empty create dest should return dest to default group and stream, %v/%v found", d.pusher.Group, d.pusher.Stream)
 	}
 }
+
+func TestReleaseDest(t *testing.T) {
+	c := outputs.Outputs["cloudwatchlogs"]().(*CloudWatchLogs)
+	c.Log = testutil.Logger{}
+
+	d1 := c.CreateDest("GROUP", "STREAM", -1).(*cwDest)
+	d2 := c.CreateDest("GROUP", "STREAM", -1).(*cwDest)
+	require.True(t, d1 == d2)
+
+	// the destination is stopped once released by all the log sources which created it
+	c.ReleaseDest(d1)
+	assert.False(t, d1.stopped)
+	c.ReleaseDest(d2)
+	assert.True(t, d1.stopped)
+
+	d3 := c.CreateDest("GROUP", "STREAM", -1).(*cwDest)
+	assert.False(t, d1 == d3)
+	assert.NoError(t, c.Close())
+	// releasing a destination stopped by Close is a no-op
+	c.ReleaseDest(d3)
+}
+
+func TestGetLogEventFromMetric_Distribution(t *testing.T) {
+	c := outputs.Outputs["cloudwatchlogs"]().(*CloudWatchLogs)
+
+	dist := regular.NewRegularDistribution()
+	dist.AddEntry(3, 1)
+	dist.AddEntry(0.5, 2)
+	dist.AddEntry(1.5, 1)
+	m, err := metric.New("prometheus_scraper", map[string]string{"job": "test"}, map[string]interface{}{"latency": dist}, time.Unix(1, 0))
+	require.NoError(t, err)
+
+	e := c.getLogEventFromMetric(m)
+	require.NotNil(t, e)
+	assert.Equal(t, `{"job":"test","latency":{"Values":[0.5,1.5,3],"Counts":[2,1,1],"Max":3,"Min":0.5,"Count":4,"Sum":5.5}}`, e.msg)
+}
@@ -7,8 +7,10 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent/health"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/profiler"
 	"github.com/aws/aws-sdk-go/aws"
@@ -34,12 +36,16 @@ type CloudWatchLogsService interface {
 }
 
 type pusher struct {
+	// queued is the number of events in memory waiting to be sent, it is first for its atomic access
+	queued int64
+
 	Target
 	Service       CloudWatchLogsService
 	FlushTimeout  time.Duration
 	RetryDuration time.Duration
 	Log           telegraf.Logger
 
+	spool               *spool
 	events              []*cloudwatchlogs.InputLogEvent
 	minT, maxT          *time.Time
 	doneCallbacks       []func()
@@ -58,12 +64,19 @@ type pusher struct {
 }
 
 func NewPusher(target Target, service CloudWatchLogsService, flushTimeout time.Duration, retryDuration time.Duration, logger telegraf.Logger) *pusher {
+	return newSpooledPusher(target, service, flushTimeout, retryDuration, logger, nil)
+}
+
+// newSpooledPusher creates a pusher which writes the events to the given spool before sending them,
+// the events left in the spool from a previous run are sent first. A nil spool keeps events in memory only.
+func newSpooledPusher(target Target, service CloudWatchLogsService, flushTimeout time.Duration, retryDuration time.Duration, logger telegraf.Logger, s *spool) *pusher {
 	p := &pusher{
 		Target:          target,
 		Service:         service,
 		FlushTimeout:    flushTimeout,
 		RetryDuration:   retryDuration,
 		Log:             logger,
+		spool:           s,
 		events:          make([]*cloudwatchlogs.InputLogEvent, 0, 10),
 		eventsCh:        make(chan logs.LogEvent, 100),
 		flushTimer:      time.NewTimer(flushTimeout),
@@ -71,6 +84,7 @@ func NewPusher(target Target, service CloudWatchLogsService, flushTimeout time.D
 		startNonBlockCh: make(chan struct{}),
 	}
 	p.putRetentionPolicy()
+	health.Status.SetQueue(p.queueName(), p.queueDepth)
 	go p.start()
 	return p
 }
@@ -80,6 +94,10 @@ func (p *pusher) AddEvent(e logs.LogEvent) {
 		p.Log.Errorf("The log entry in (%v/%v) with timestamp (%v) comparing to the current time (%v) is out of accepted time range. Discard the log entry.", p.Group, p.Stream, e.Time(), time.Now())
 		return
 	}
+	if p.spoolEvent(e) {
+		return
+	}
+	atomic.AddInt64(&p.queued, 1)
 	p.eventsCh <- e
 }
 
@@ -88,6 +106,9 @@ func (p *pusher) AddEventNonBlocking(e logs.LogEvent) {
 		p.Log.Errorf("The log entry in (%v/%v) with timestamp (%v) comparing to the current time (%v) is out of accepted time range. Discard the log entry.", p.Group, p.Stream, e.Time(), time.Now())
 		return
 	}
+	if p.spoolEvent(e) {
+		return
+	}
 
 	p.initNonBlockingChOnce.Do(func() {
 		p.nonBlockingEventsCh = make(chan logs.LogEvent, reqEventsLimit*2)
@@ -95,17 +116,32 @@ func (p *pusher) AddEventNonBlocking(e logs.LogEvent) {
 	})
 
 	// Drain the channel until new event can be added
+	atomic.AddInt64(&p.queued, 1)
 	for {
 		select {
 		case p.nonBlockingEventsCh <- e:
 			return
 		default:
 			<-p.nonBlockingEventsCh
+			atomic.AddInt64(&p.queued, -1)
 			p.addStats("emfMetricDrop", 1)
 		}
 	}
 }
 
+// spoolEvent writes the event to the spool if there is one, the event is done once it is on disk.
+// It returns false when the event has to be buffered in memory instead.
+func (p *pusher) spoolEvent(e logs.LogEvent) bool {
+	if p.spool == nil {
+		return false
+	}
+	if err := p.spool.write(e); err != nil {
+		p.Log.Errorf("Unable to spool log event for %v/%v, buffering it in memory instead: %v", p.Group, p.Stream, err)
+		return false
+	}
+	return true
+}
+
 func hasValidTime(e logs.LogEvent) bool {
 	//http://docs.aws.amazon.com/goto/SdkForGoV1/logs-2014-03-28/PutLogEvents
 	//* None of the log events in the batch can be more than 2 hours in the future.
@@ -121,9 +157,19 @@ func hasValidTime(e logs.LogEvent) bool {
 }
 
 func (p *pusher) Stop() {
+	health.Status.RemoveQueue(p.queueName())
 	close(p.stop)
 }
 
+func (p *pusher) queueName() string {
+	return "cloudwatchlogs/" + p.Group + "/" + p.Stream
+}
+
+// queueDepth returns the number of the events waiting in memory to be sent, the events in the spool are not included
+func (p *pusher) queueDepth() int {
+	return int(atomic.LoadInt64(&p.queued))
+}
+
 func (p *pusher) start() {
 	ec := make(chan logs.LogEvent)
 
@@ -142,6 +188,24 @@ func (p *pusher) start() {
 		}
 	}()
 
+	// Feed events from the spool, including the ones left over from the previous run
+	if p.spool != nil {
+		go func() {
+			for {
+				e, ok := p.spool.next(p.stop)
+				if !ok {
+					return
+				}
+				atomic.AddInt64(&p.queued, 1)
+				select {
+				case ec <- e:
+				case <-p.stop:
+					return
+				}
+			}
+		}()
+	}
+
 	for {
 		select {
 		case e := <-ec:
@@ -187,12 +251,16 @@ func (p *pusher) start() {
 			if len(p.events) > 0 {
 				p.send()
 			}
+			if p.spool != nil {
+				p.spool.close()
+			}
 			return
 		}
 	}
 }
 
 func (p *pusher) reset() {
+	atomic.AddInt64(&p.queued, -int64(len(p.events)))
 	for i := 0; i < len(p.events); i++ {
 		p.events[i] = nil
 	}
@@ -247,6 +315,7 @@ func (p *pusher) send() {
 				done()
 			}
 
+			health.Status.RecordSuccess("PutLogEvents")
 			p.Log.Debugf("Pusher published %v log events to group: %v stream: %v with size %v KB in %v.", len(p.events), p.Group, p.Stream, p.bufferredSize/1024, time.Since(startTime))
 			p.addStats("rawSize", float64(p.bufferredSize))
 
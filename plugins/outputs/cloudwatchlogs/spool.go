@@ -0,0 +1,550 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	spoolSegmentSuffix  = ".seg"
+	spoolAckFileName    = "ack"
+	spoolFileMode       = 0644
+	spoolRecordHdrSize  = 8 // 4 bytes payload length + 4 bytes crc32 of the payload
+	spoolTimestampSize  = 8
+	spoolSegmentSize    = 4 * 1024 * 1024
+	spoolSyncInterval   = 100 * time.Millisecond
+	defaultSpoolMaxSize = 100 * 1024 * 1024
+)
+
+var errSpoolClosed = errors.New("spool is closed")
+
+// spoolPos is the position of a record in the spool, the offset is relative to the segment.
+type spoolPos struct {
+	seg, off int64
+}
+
+func (p spoolPos) after(o spoolPos) bool {
+	return p.seg > o.seg || (p.seg == o.seg && p.off > o.off)
+}
+
+type spoolSegment struct {
+	id      int64
+	size    int64
+	records int
+}
+
+// spool is an on-disk write-ahead log for the events of a single log group/stream.
+// Events are appended to size limited segment files, handed out to the pusher in order,
+// and the segments are removed once every event in them has been acknowledged.
+// The acknowledged position is persisted so that unacknowledged events are replayed on restart.
+type spool struct {
+	dir     string
+	maxSize int64
+	Log     telegraf.Logger
+
+	mu          sync.Mutex
+	segments    []*spoolSegment
+	totalSize   int64
+	w           *os.File
+	r           *os.File
+	rSeg        int64
+	readPos     spoolPos
+	ackPos      spoolPos
+	savedAckPos spoolPos
+	dirty       bool
+	pendingDone []func()
+	dropped     int
+	closed      bool
+
+	notify chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// spooledEvent is an event read back from the spool, Done acknowledges its position.
+type spooledEvent struct {
+	msg string
+	t   time.Time
+	end spoolPos
+	s   *spool
+}
+
+func (e *spooledEvent) Message() string {
+	return e.msg
+}
+
+func (e *spooledEvent) Time() time.Time {
+	return e.t
+}
+
+func (e *spooledEvent) Done() {
+	e.s.ack(e.end)
+}
+
+func openSpool(dir string, maxSize int64, logger telegraf.Logger) (*spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory %s: %v", dir, err)
+	}
+	if maxSize <= 0 {
+		maxSize = defaultSpoolMaxSize
+	}
+
+	s := &spool{
+		dir:     dir,
+		maxSize: maxSize,
+		Log:     logger,
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	ids, err := s.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	s.ackPos = s.loadAck()
+	for _, id := range ids {
+		if id < s.ackPos.seg {
+			s.removeSegmentFile(id)
+			continue
+		}
+		seg, err := s.scanSegment(id)
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, seg)
+		s.totalSize += seg.size
+	}
+	if len(s.segments) == 0 || s.segments[0].id != s.ackPos.seg || s.ackPos.off > s.segments[0].size {
+		// The acknowledged segment is gone, replay from the oldest one still on disk.
+		s.ackPos = spoolPos{}
+		if len(s.segments) > 0 {
+			s.ackPos.seg = s.segments[0].id
+		}
+	}
+	s.savedAckPos = s.ackPos
+	s.readPos = s.ackPos
+
+	// Always start a new segment for writing, the last one may end with a partial record.
+	var next int64 = 1
+	if len(s.segments) > 0 {
+		next = s.segments[len(s.segments)-1].id + 1
+	}
+	if err := s.newWriteSegment(next); err != nil {
+		return nil, err
+	}
+	if len(s.segments) == 1 {
+		s.readPos = spoolPos{seg: next}
+		s.ackPos = s.readPos
+	}
+
+	s.wg.Add(1)
+	go s.runSync()
+	return s, nil
+}
+
+// hasBacklog returns true if the spool holds events that have not been acknowledged yet.
+func (s *spool) hasBacklog() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, seg := range s.segments {
+		if (seg.id > s.ackPos.seg && seg.size > 0) || (seg.id == s.ackPos.seg && seg.size > s.ackPos.off) {
+			return true
+		}
+	}
+	return false
+}
+
+// write appends the event to the spool. The event is marked as done once it has been synced to disk.
+func (s *spool) write(e logs.LogEvent) error {
+	msg := e.Message()
+	if len(msg) > msgSizeLimit {
+		msg = msg[:msgSizeLimit-len(truncatedSuffix)] + truncatedSuffix
+	}
+	buf := make([]byte, spoolRecordHdrSize+spoolTimestampSize+len(msg))
+	var ts int64
+	if !e.Time().IsZero() {
+		ts = e.Time().UnixNano()
+	}
+	payload := buf[spoolRecordHdrSize:]
+	binary.BigEndian.PutUint64(payload, uint64(ts))
+	copy(payload[spoolTimestampSize:], msg)
+	binary.BigEndian.PutUint32(buf, uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(payload))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errSpoolClosed
+	}
+
+	seg := s.segments[len(s.segments)-1]
+	if seg.size >= spoolSegmentSize {
+		if err := s.newWriteSegment(seg.id + 1); err != nil {
+			return err
+		}
+		seg = s.segments[len(s.segments)-1]
+	}
+
+	if _, err := s.w.Write(buf); err != nil {
+		// Drop the partial record so the segment stays readable
+		s.w.Truncate(seg.size)
+		s.w.Seek(seg.size, io.SeekStart)
+		return err
+	}
+	seg.size += int64(len(buf))
+	seg.records++
+	s.totalSize += int64(len(buf))
+	s.dirty = true
+	s.pendingDone = append(s.pendingDone, e.Done)
+	s.enforceMaxSize()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next blocks until an event is available in the spool, it returns false when stop is closed.
+func (s *spool) next(stop <-chan struct{}) (*spooledEvent, bool) {
+	for {
+		s.mu.Lock()
+		e, err := s.readRecord()
+		s.mu.Unlock()
+		if err != nil {
+			s.Log.Errorf("Failed to read from spool %v: %v", s.dir, err)
+		}
+		if e != nil {
+			return e, true
+		}
+
+		select {
+		case <-s.notify:
+		case <-stop:
+			return nil, false
+		case <-time.After(spoolSyncInterval):
+		}
+	}
+}
+
+// readRecord reads the record at the read position, moving on to the next segment
+// when the end of a segment that is no longer written to is reached.
+func (s *spool) readRecord() (*spooledEvent, error) {
+	for {
+		if s.closed {
+			return nil, nil
+		}
+		seg := s.segment(s.readPos.seg)
+		if seg == nil {
+			return nil, nil
+		}
+		last := seg == s.segments[len(s.segments)-1]
+		if s.readPos.off >= seg.size {
+			if last {
+				return nil, nil
+			}
+			s.readPos = spoolPos{seg: s.nextSegmentID(seg.id)}
+			continue
+		}
+
+		if s.r == nil || s.rSeg != seg.id {
+			if s.r != nil {
+				s.r.Close()
+				s.r = nil
+			}
+			f, err := os.Open(s.segmentPath(seg.id))
+			if err != nil {
+				s.readPos = spoolPos{seg: s.nextSegmentID(seg.id)}
+				return nil, err
+			}
+			s.r = f
+			s.rSeg = seg.id
+		}
+
+		payload, err := readSpoolRecord(s.r, s.readPos.off)
+		if err != nil {
+			if last {
+				return nil, err
+			}
+			s.Log.Warnf("Skipping the rest of corrupted spool segment %v: %v", s.segmentPath(seg.id), err)
+			s.readPos = spoolPos{seg: s.nextSegmentID(seg.id)}
+			continue
+		}
+		s.readPos.off += int64(spoolRecordHdrSize + len(payload))
+
+		e := &spooledEvent{
+			msg: string(payload[spoolTimestampSize:]),
+			end: s.readPos,
+			s:   s,
+		}
+		if ts := int64(binary.BigEndian.Uint64(payload)); ts != 0 {
+			e.t = time.Unix(0, ts)
+		}
+		return e, nil
+	}
+}
+
+func readSpoolRecord(r io.ReaderAt, off int64) ([]byte, error) {
+	var hdr [spoolRecordHdrSize]byte
+	if _, err := r.ReadAt(hdr[:], off); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size < spoolTimestampSize || size > spoolTimestampSize+msgSizeLimit {
+		return nil, fmt.Errorf("invalid record size %v at offset %v", size, off)
+	}
+	payload := make([]byte, size)
+	if _, err := r.ReadAt(payload, off+spoolRecordHdrSize); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(hdr[4:]) {
+		return nil, fmt.Errorf("checksum mismatch for record at offset %v", off)
+	}
+	return payload, nil
+}
+
+func (s *spool) ack(p spoolPos) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.after(s.ackPos) {
+		s.ackPos = p
+	}
+}
+
+// close syncs the spool and persists the acknowledged position, the unacknowledged
+// events are kept on disk to be replayed by the next spool opened on the same directory.
+func (s *spool) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+	s.sync()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w != nil {
+		s.w.Close()
+	}
+	if s.r != nil {
+		s.r.Close()
+	}
+}
+
+func (s *spool) runSync() {
+	defer s.wg.Done()
+	t := time.NewTicker(spoolSyncInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			s.sync()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// sync flushes the written events to disk before marking them as done, then persists
+// the acknowledged position and removes the segments that have been fully acknowledged.
+func (s *spool) sync() {
+	s.mu.Lock()
+	var dones []func()
+	if s.dirty {
+		if err := s.w.Sync(); err != nil {
+			s.Log.Errorf("Failed to sync spool %v: %v", s.dir, err)
+		} else {
+			dones = s.pendingDone
+			s.pendingDone = nil
+			s.dirty = false
+		}
+	}
+	ackPos := s.ackPos
+	dropped := s.dropped
+	s.dropped = 0
+	s.mu.Unlock()
+
+	for _, done := range dones {
+		done()
+	}
+	if dropped > 0 {
+		s.Log.Warnf("Spool %v exceeded its size limit of %v bytes, %v oldest log events are dropped", s.dir, s.maxSize, dropped)
+	}
+
+	if ackPos == s.savedAckPos {
+		return
+	}
+	if err := s.saveAck(ackPos); err != nil {
+		s.Log.Errorf("Failed to save acknowledged position for spool %v: %v", s.dir, err)
+		return
+	}
+	s.savedAckPos = ackPos
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.segments) > 1 && s.segments[0].id < ackPos.seg {
+		s.dropOldestSegment()
+	}
+}
+
+// enforceMaxSize drops the oldest segments until the spool fits in its size limit.
+// The segment being written to is never dropped.
+func (s *spool) enforceMaxSize() {
+	for s.totalSize > s.maxSize && len(s.segments) > 1 {
+		oldest := s.segments[0]
+		if oldest.id >= s.ackPos.seg {
+			s.dropped += oldest.records
+		}
+		s.dropOldestSegment()
+	}
+}
+
+func (s *spool) dropOldestSegment() {
+	oldest := s.segments[0]
+	s.segments = s.segments[1:]
+	s.totalSize -= oldest.size
+	if s.r != nil && s.rSeg == oldest.id {
+		s.r.Close()
+		s.r = nil
+	}
+	start := spoolPos{seg: s.segments[0].id}
+	if start.after(s.readPos) {
+		s.readPos = start
+	}
+	if start.after(s.ackPos) {
+		s.ackPos = start
+	}
+	s.removeSegmentFile(oldest.id)
+}
+
+func (s *spool) newWriteSegment(id int64) error {
+	f, err := os.OpenFile(s.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, spoolFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to create spool segment: %v", err)
+	}
+	if s.w != nil {
+		if err := s.w.Sync(); err != nil {
+			s.Log.Errorf("Failed to sync spool segment before rotation in %v: %v", s.dir, err)
+		}
+		s.w.Close()
+	}
+	s.w = f
+	s.segments = append(s.segments, &spoolSegment{id: id})
+	return nil
+}
+
+// scanSegment validates the records of an existing segment, the size of the segment
+// is limited to the last complete record.
+func (s *spool) scanSegment(id int64) (*spoolSegment, error) {
+	f, err := os.Open(s.segmentPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool segment: %v", err)
+	}
+	defer f.Close()
+
+	seg := &spoolSegment{id: id}
+	for {
+		payload, err := readSpoolRecord(f, seg.size)
+		if err != nil {
+			if err != io.EOF {
+				s.Log.Warnf("Spool segment %v is truncated at offset %v: %v", s.segmentPath(id), seg.size, err)
+			}
+			return seg, nil
+		}
+		seg.size += int64(spoolRecordHdrSize + len(payload))
+		seg.records++
+	}
+}
+
+func (s *spool) segment(id int64) *spoolSegment {
+	for _, seg := range s.segments {
+		if seg.id == id {
+			return seg
+		}
+	}
+	return nil
+}
+
+func (s *spool) nextSegmentID(id int64) int64 {
+	for _, seg := range s.segments {
+		if seg.id > id {
+			return seg.id
+		}
+	}
+	return id
+}
+
+func (s *spool) listSegments() ([]int64, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spool directory %s: %v", s.dir, err)
+	}
+	var ids []int64
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, spoolSegmentSuffix) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(name, spoolSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (s *spool) segmentPath(id int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, spoolSegmentSuffix))
+}
+
+func (s *spool) removeSegmentFile(id int64) {
+	if err := os.Remove(s.segmentPath(id)); err != nil && !os.IsNotExist(err) {
+		s.Log.Errorf("Failed to remove spool segment %v: %v", s.segmentPath(id), err)
+	}
+}
+
+func (s *spool) loadAck() spoolPos {
+	var p spoolPos
+	content, err := ioutil.ReadFile(filepath.Join(s.dir, spoolAckFileName))
+	if err != nil {
+		return p
+	}
+	if _, err := fmt.Sscanf(string(content), "%d %d", &p.seg, &p.off); err != nil {
+		s.Log.Warnf("Ignoring invalid acknowledged position in spool %v: %v", s.dir, err)
+		return spoolPos{}
+	}
+	return p
+}
+
+func (s *spool) saveAck(p spoolPos) error {
+	path := filepath.Join(s.dir, spoolAckFileName)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(fmt.Sprintf("%d %d\n", p.seg, p.off)), spoolFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
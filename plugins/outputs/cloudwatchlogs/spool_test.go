@@ -0,0 +1,225 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/influxdata/telegraf/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var spoolTestLogger = models.NewLogger("cloudwatchlogs", "test", "")
+
+func newTestSpoolDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	return dir
+}
+
+func readSpoolEvents(t *testing.T, s *spool, n int) []*spooledEvent {
+	var events []*spooledEvent
+	stop := make(chan struct{})
+	timer := time.AfterFunc(2*time.Second, func() { close(stop) })
+	defer timer.Stop()
+	for len(events) < n {
+		e, ok := s.next(stop)
+		if !ok {
+			t.Fatalf("Only %v of %v events were read from the spool", len(events), n)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestSpoolWriteAndRead(t *testing.T) {
+	dir := newTestSpoolDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	defer s.close()
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	require.NoError(t, s.write(evtMock{"MSG1", now, wg.Done}))
+	require.NoError(t, s.write(evtMock{"MSG2", time.Time{}, wg.Done}))
+
+	events := readSpoolEvents(t, s, 2)
+	assert.Equal(t, "MSG1", events[0].Message())
+	assert.Equal(t, now.UnixNano(), events[0].Time().UnixNano())
+	assert.Equal(t, "MSG2", events[1].Message())
+	assert.True(t, events[1].Time().IsZero())
+
+	// Written events are marked as done once they are synced to disk
+	wg.Wait()
+}
+
+func TestSpoolReplayUnacknowledgedEvents(t *testing.T) {
+	dir := newTestSpoolDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.write(evtMock{fmt.Sprintf("MSG%v", i), time.Now(), nil}))
+	}
+	events := readSpoolEvents(t, s, 5)
+	events[1].Done()
+	s.close()
+
+	s, err = openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	assert.True(t, s.hasBacklog())
+	events = readSpoolEvents(t, s, 3)
+	for i, e := range events {
+		assert.Equal(t, fmt.Sprintf("MSG%v", i+2), e.Message())
+	}
+	events[2].Done()
+	s.close()
+
+	s, err = openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	defer s.close()
+	assert.False(t, s.hasBacklog())
+}
+
+func TestSpoolIgnoresTruncatedRecord(t *testing.T) {
+	dir := newTestSpoolDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	require.NoError(t, s.write(evtMock{"MSG1", time.Now(), nil}))
+	require.NoError(t, s.write(evtMock{"MSG2", time.Now(), nil}))
+	seg := s.segments[len(s.segments)-1]
+	s.close()
+
+	// Simulate a crash in the middle of writing the last record
+	require.NoError(t, os.Truncate(s.segmentPath(seg.id), seg.size-2))
+
+	s, err = openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	defer s.close()
+	events := readSpoolEvents(t, s, 1)
+	assert.Equal(t, "MSG1", events[0].Message())
+
+	require.NoError(t, s.write(evtMock{"MSG3", time.Now(), nil}))
+	events = readSpoolEvents(t, s, 1)
+	assert.Equal(t, "MSG3", events[0].Message())
+}
+
+func TestSpoolDropsOldestSegmentsOverMaxSize(t *testing.T) {
+	dir := newTestSpoolDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := openSpool(dir, 2*spoolSegmentSize, spoolTestLogger)
+	require.NoError(t, err)
+	defer s.close()
+
+	msg := string(make([]byte, 64*1024))
+	n := 3 * spoolSegmentSize / len(msg)
+	for i := 0; i < n; i++ {
+		require.NoError(t, s.write(evtMock{msg, time.Now(), nil}))
+	}
+
+	assert.True(t, s.totalSize <= s.maxSize, "spool size %v exceeds max size %v", s.totalSize, s.maxSize)
+	assert.True(t, s.readPos.seg > 1, "oldest segment should have been dropped")
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, len(s.segments), len(files))
+}
+
+func TestPusherWithSpool(t *testing.T) {
+	dir := newTestSpoolDir(t)
+	defer os.RemoveAll(dir)
+
+	var s svcMock
+	var mu sync.Mutex
+	var sent []string
+	fail := true
+	s.ple = func(in *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			return nil, fmt.Errorf("connection refused")
+		}
+		for _, e := range in.LogEvents {
+			sent = append(sent, *e.Message)
+		}
+		return &cloudwatchlogs.PutLogEventsOutput{}, nil
+	}
+
+	// Events dropped on the non aws error are kept in the spool
+	sp, err := openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	p := newSpooledPusher(Target{"G", "S", -1}, &s, 10*time.Millisecond, maxRetryTimeout, spoolTestLogger, sp)
+	done := make(chan struct{})
+	p.AddEvent(evtMock{"MSG", time.Now(), func() { close(done) }})
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Spooled event should be marked as done once it is on disk")
+	}
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	sp, err = openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	assert.True(t, sp.hasBacklog())
+	p = newSpooledPusher(Target{"G", "S", -1}, &s, 10*time.Millisecond, maxRetryTimeout, spoolTestLogger, sp)
+	time.Sleep(200 * time.Millisecond)
+	p.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, []string{"MSG"}, sent)
+	mu.Unlock()
+
+	sp, err = openSpool(dir, 0, spoolTestLogger)
+	require.NoError(t, err)
+	defer sp.close()
+	assert.False(t, sp.hasBacklog())
+}
+
+func TestReplaySpoolsWhileCreatingDests(t *testing.T) {
+	dir := newTestSpoolDir(t)
+	defer os.RemoveAll(dir)
+
+	c := &CloudWatchLogs{SpoolDir: dir, Log: spoolTestLogger, cwDests: make(map[Target]*cwDest)}
+	target := Target{"G", "S", -1}
+	sp, err := openSpool(c.spoolDir(target), 0, spoolTestLogger)
+	require.NoError(t, err)
+	c.saveSpoolTarget(c.spoolDir(target), spoolTarget{Target: target})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	require.NoError(t, sp.write(evtMock{"MSG", time.Now(), wg.Done}))
+	wg.Wait()
+	sp.close()
+
+	// the log agent creates the destination of the spool while it is replayed
+	created := make(chan *cwDest)
+	go func() { created <- c.CreateDest("G", "S", -1).(*cwDest) }()
+	require.NoError(t, c.Connect())
+	cwd := <-created
+
+	c.cwDestsLock.Lock()
+	assert.Len(t, c.cwDests, 1)
+	assert.True(t, c.cwDests[target] == cwd)
+	assert.Equal(t, 2, cwd.refs)
+	c.cwDestsLock.Unlock()
+	assert.NoError(t, c.Close())
+}
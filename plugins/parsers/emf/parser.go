@@ -16,6 +16,9 @@ import (
 	"github.com/influxdata/telegraf/metric"
 )
 
+// the length of the prefix of a line quoted in the errors
+const maxQuotedLength = 128
+
 type EMFParser struct {
 	MetricName  string
 	DefaultTags map[string]string
@@ -56,7 +59,7 @@ func (v *EMFParser) ParseLine(line string) (telegraf.Metric, error) {
 	metadata := new(emfMetadata)
 	err := json.Unmarshal([]byte(line), metadata)
 	if err != nil {
-		return nil, fmt.Errorf("cannot serialize %s to json: %v", line, err)
+		return nil, fmt.Errorf("cannot serialize %s to json: %v", abbreviate(line), err)
 	}
 	var logGroupName, logStreamName string
 	if metadata.AWSMetadata != nil {
@@ -69,7 +72,7 @@ func (v *EMFParser) ParseLine(line string) (telegraf.Metric, error) {
 		logStreamName = metadata.LogStreamName
 	}
 	if logGroupName == "" {
-		return nil, fmt.Errorf("log group name is required to send as structured log: %s", line)
+		return nil, fmt.Errorf("log group name is required to send as structured log: %s", abbreviate(line))
 	}
 
 	fields := map[string]interface{}{"value": line}
@@ -87,6 +90,14 @@ func (v *EMFParser) ParseLine(line string) (telegraf.Metric, error) {
 	return metric, nil
 }
 
+// abbreviate returns the prefix of the line quoted in the errors, the documents are up to 256KB
+func abbreviate(line string) string {
+	if len(line) <= maxQuotedLength {
+		return line
+	}
+	return line[:maxQuotedLength] + "..."
+}
+
 func (v *EMFParser) SetDefaultTags(tags map[string]string) {
 	v.DefaultTags = tags
 }
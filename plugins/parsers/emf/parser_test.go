@@ -5,6 +5,7 @@ package emf
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -188,3 +189,12 @@ func TestParseInvalidValues_InvalidJson(t *testing.T) {
 	assert.Equal(t, nil, metric)
 	assert.Equal(t, fmt.Sprintf("cannot serialize %s to json: invalid character ':' after top-level value", expectedValueEntry), err.Error())
 }
+
+func TestParseInvalidValues_LongLineIsAbbreviated(t *testing.T) {
+	parser := EMFParser{
+		MetricName: "emf_test",
+	}
+	line := `{"message":"` + strings.Repeat("a", 1024) + `"}`
+	_, err := parser.ParseLine(line)
+	assert.Equal(t, fmt.Sprintf("log group name is required to send as structured log: %s...", line[:maxQuotedLength]), err.Error())
+}
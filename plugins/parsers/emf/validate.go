@@ -0,0 +1,179 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// The limits of the embedded metric format, see
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+const (
+	maxNamespaceLength      = 255
+	maxMetricNameLength     = 255
+	maxMetricsPerDirective  = 100
+	maxDimensionsPerSet     = 30
+	maxValuesPerMetric      = 100
+	maxDimensionValueLength = 1024
+)
+
+var standardUnits = map[string]bool{
+	cloudwatch.StandardUnitSeconds:         true,
+	cloudwatch.StandardUnitMicroseconds:    true,
+	cloudwatch.StandardUnitMilliseconds:    true,
+	cloudwatch.StandardUnitBytes:           true,
+	cloudwatch.StandardUnitKilobytes:       true,
+	cloudwatch.StandardUnitMegabytes:       true,
+	cloudwatch.StandardUnitGigabytes:       true,
+	cloudwatch.StandardUnitTerabytes:       true,
+	cloudwatch.StandardUnitBits:            true,
+	cloudwatch.StandardUnitKilobits:        true,
+	cloudwatch.StandardUnitMegabits:        true,
+	cloudwatch.StandardUnitGigabits:        true,
+	cloudwatch.StandardUnitTerabits:        true,
+	cloudwatch.StandardUnitPercent:         true,
+	cloudwatch.StandardUnitCount:           true,
+	cloudwatch.StandardUnitBytesSecond:     true,
+	cloudwatch.StandardUnitKilobytesSecond: true,
+	cloudwatch.StandardUnitMegabytesSecond: true,
+	cloudwatch.StandardUnitGigabytesSecond: true,
+	cloudwatch.StandardUnitTerabytesSecond: true,
+	cloudwatch.StandardUnitBitsSecond:      true,
+	cloudwatch.StandardUnitKilobitsSecond:  true,
+	cloudwatch.StandardUnitMegabitsSecond:  true,
+	cloudwatch.StandardUnitGigabitsSecond:  true,
+	cloudwatch.StandardUnitTerabitsSecond:  true,
+	cloudwatch.StandardUnitCountSecond:     true,
+	cloudwatch.StandardUnitNone:            true,
+}
+
+type metricDefinition struct {
+	Name              *string     `json:"Name"`
+	Unit              *string     `json:"Unit"`
+	StorageResolution interface{} `json:"StorageResolution"`
+}
+
+type metricDirective struct {
+	Namespace  *string             `json:"Namespace"`
+	Dimensions [][]string          `json:"Dimensions"`
+	Metrics    []*metricDefinition `json:"Metrics"`
+}
+
+type awsMetricsMetadata struct {
+	Timestamp         interface{}        `json:"Timestamp"`
+	CloudWatchMetrics []*metricDirective `json:"CloudWatchMetrics"`
+}
+
+// Validate checks the metric directives of an embedded metric format document against the specification. The
+// documents without metric directives, e.g. the v0 documents or the structured logs of Container Insights, are valid.
+// The log group name is checked by ParseLine.
+func Validate(line string) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		// the document is not echoed as it is up to 256KB and the errors are returned to the clients of emf_http
+		return fmt.Errorf("cannot serialize the document to json: %v", err)
+	}
+	rawMetadata, ok := doc["_aws"]
+	if !ok {
+		return nil
+	}
+	metadata := new(awsMetricsMetadata)
+	if err := json.Unmarshal(rawMetadata, metadata); err != nil {
+		return fmt.Errorf("_aws is not a valid metadata object: %v", err)
+	}
+	if metadata.CloudWatchMetrics == nil {
+		return nil
+	}
+	if _, ok := metadata.Timestamp.(float64); !ok {
+		return fmt.Errorf("_aws.Timestamp is required and must be a number of milliseconds since epoch")
+	}
+
+	for i, directive := range metadata.CloudWatchMetrics {
+		if err := validateDirective(directive, doc); err != nil {
+			return fmt.Errorf("_aws.CloudWatchMetrics[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateDirective(directive *metricDirective, doc map[string]json.RawMessage) error {
+	if directive == nil {
+		return fmt.Errorf("the metric directive must be an object")
+	}
+	if directive.Namespace == nil || *directive.Namespace == "" {
+		return fmt.Errorf("Namespace is required")
+	}
+	if len(*directive.Namespace) > maxNamespaceLength {
+		return fmt.Errorf("Namespace must not be longer than %d characters", maxNamespaceLength)
+	}
+	if directive.Dimensions == nil {
+		return fmt.Errorf("Dimensions is required")
+	}
+	for i, set := range directive.Dimensions {
+		if len(set) > maxDimensionsPerSet {
+			return fmt.Errorf("Dimensions[%d] has %d dimensions, the limit is %d", i, len(set), maxDimensionsPerSet)
+		}
+		for _, key := range set {
+			var value string
+			if err := json.Unmarshal(doc[key], &value); err != nil {
+				return fmt.Errorf("the dimension %s of Dimensions[%d] must reference a string member", key, i)
+			}
+			if len(value) == 0 || len(value) > maxDimensionValueLength {
+				return fmt.Errorf("the value of the dimension %s must have 1 to %d characters", key, maxDimensionValueLength)
+			}
+		}
+	}
+	if directive.Metrics == nil {
+		return fmt.Errorf("Metrics is required")
+	}
+	if len(directive.Metrics) > maxMetricsPerDirective {
+		return fmt.Errorf("Metrics has %d metrics, the limit is %d", len(directive.Metrics), maxMetricsPerDirective)
+	}
+	for i, m := range directive.Metrics {
+		if err := validateMetric(m, doc); err != nil {
+			return fmt.Errorf("Metrics[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateMetric(m *metricDefinition, doc map[string]json.RawMessage) error {
+	if m == nil {
+		return fmt.Errorf("the metric definition must be an object")
+	}
+	if m.Name == nil || *m.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if len(*m.Name) > maxMetricNameLength {
+		return fmt.Errorf("Name must not be longer than %d characters", maxMetricNameLength)
+	}
+	if m.Unit != nil && !standardUnits[*m.Unit] {
+		return fmt.Errorf("Unit %s is not a CloudWatch unit", *m.Unit)
+	}
+	if m.StorageResolution != nil {
+		if r, ok := m.StorageResolution.(float64); !ok || (r != 1 && r != 60) {
+			return fmt.Errorf("StorageResolution must be 1 or 60")
+		}
+	}
+
+	raw, ok := doc[*m.Name]
+	if !ok {
+		return fmt.Errorf("the metric %s has no value member", *m.Name)
+	}
+	var value float64
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return nil
+	}
+	var values []float64
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return fmt.Errorf("the value of the metric %s must be a number or an array of numbers", *m.Name)
+	}
+	if len(values) > maxValuesPerMetric {
+		return fmt.Errorf("the metric %s has %d values, the limit is %d", *m.Name, len(values), maxValuesPerMetric)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func emfDocument(directive string, members string) string {
+	return fmt.Sprintf(`{"_aws":{"Timestamp":1566164466581,"LogGroupName":"lg","CloudWatchMetrics":[%s]}%s}`, directive, members)
+}
+
+func TestValidate(t *testing.T) {
+	tooManyDimensions := make([]string, 31)
+	for i := range tooManyDimensions {
+		tooManyDimensions[i] = `"Service"`
+	}
+	tests := []struct {
+		name string
+		line string
+		err  string
+	}{
+		{"valid", emfDocument(`{"Namespace":"ns","Dimensions":[["Service"]],"Metrics":[{"Name":"Latency","Unit":"Milliseconds","StorageResolution":1}]}`, `,"Service":"checkout","Latency":[1,2.5]`), ""},
+		{"no metric directives", `{"_aws":{"LogGroupName":"lg"},"message":"hello"}`, ""},
+		{"v0", `{"log_group_name":"lg","message":"hello"}`, ""},
+		{"not json", `{"_aws":`, "cannot serialize"},
+		{"no timestamp", `{"_aws":{"CloudWatchMetrics":[]}}`, "_aws.Timestamp is required"},
+		{"no namespace", emfDocument(`{"Dimensions":[],"Metrics":[]}`, ``), "_aws.CloudWatchMetrics[0]: Namespace is required"},
+		{"long namespace", emfDocument(`{"Namespace":"`+strings.Repeat("n", 256)+`","Dimensions":[],"Metrics":[]}`, ``), "Namespace must not be longer than 255 characters"},
+		{"no dimensions", emfDocument(`{"Namespace":"ns","Metrics":[]}`, ``), "Dimensions is required"},
+		{"too many dimensions", emfDocument(`{"Namespace":"ns","Dimensions":[[`+strings.Join(tooManyDimensions, ",")+`]],"Metrics":[]}`, `,"Service":"checkout"`), "Dimensions[0] has 31 dimensions, the limit is 30"},
+		{"missing dimension", emfDocument(`{"Namespace":"ns","Dimensions":[["Service"]],"Metrics":[]}`, ``), "the dimension Service of Dimensions[0] must reference a string member"},
+		{"empty dimension", emfDocument(`{"Namespace":"ns","Dimensions":[["Service"]],"Metrics":[]}`, `,"Service":""`), "the value of the dimension Service must have 1 to 1024 characters"},
+		{"no metrics", emfDocument(`{"Namespace":"ns","Dimensions":[]}`, ``), "Metrics is required"},
+		{"bad unit", emfDocument(`{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"Latency","Unit":"Hours"}]}`, `,"Latency":1`), "Metrics[0]: Unit Hours is not a CloudWatch unit"},
+		{"bad resolution", emfDocument(`{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"Latency","StorageResolution":5}]}`, `,"Latency":1`), "StorageResolution must be 1 or 60"},
+		{"no value", emfDocument(`{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"Latency"}]}`, ``), "the metric Latency has no value member"},
+		{"string value", emfDocument(`{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"Latency"}]}`, `,"Latency":"1"`), "must be a number or an array of numbers"},
+		{"too many values", emfDocument(`{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"Latency"}]}`, `,"Latency":[`+strings.TrimSuffix(strings.Repeat("1,", 101), ",")+`]`), "the metric Latency has 101 values, the limit is 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.line)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+func TestValidateDoesNotEchoTheDocument(t *testing.T) {
+	line := `{"_aws":{"LogGroupName":"` + strings.Repeat("a", 1024)
+	err := Validate(line)
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "aaaa")
+	}
+}
@@ -9,7 +9,9 @@ import (
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/processors/ec2tagger"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/processors/ecsdecorator"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/processors/emfProcessor"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins/processors/emfextractor"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/processors/k8sdecorator"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins/processors/relabel"
 
 	// Enabled parsers registry
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/parsers"
@@ -18,10 +20,14 @@ import (
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/awscsm"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/cadvisor"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/demo"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/emf_http"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/k8sapiserver"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/k8sevents"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/otlp"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/prometheus_scraper"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/statsd"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/syslog"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/win_perf_counters"
 	_ "github.com/aws/amazon-cloudwatch-agent/plugins/inputs/windows_event_log"
 
@@ -74,18 +74,19 @@ const sampleConfig = `
 `
 
 const (
-	ec2InstanceTagKeyASG = "aws:autoscaling:groupName"
-	cwDimensionASG       = "AutoScalingGroupName"
-	mdKeyInstanceId      = "InstanceId"
-	mdKeyImageId         = "ImageId"
-	mdKeyInstaneType     = "InstanceType"
+	// The EC2 Instance Tag and Metadata keys, shared with the {ec2_tag:<key>} placeholders of the logfile plugin
+	EC2InstanceTagKeyASG = "aws:autoscaling:groupName"
+	CWDimensionASG       = "AutoScalingGroupName"
+	MdKeyInstanceId      = "InstanceId"
+	MdKeyImageId         = "ImageId"
+	MdKeyInstanceType    = "InstanceType"
 	ebsVolumeId          = "EBSVolumeId"
 )
 
 var (
 	defaultRefreshInterval = 180 * time.Second
 	// backoff retry for ec2 describe instances API call. Assuming the throttle limit is 20 per second. 10 mins allow 12000 API calls.
-	backoffSleepArray = []time.Duration{0, 1 * time.Minute, 1 * time.Minute, 3 * time.Minute, 3 * time.Minute, 3 * time.Minute, 10 * time.Minute}
+	BackoffSleepArray = []time.Duration{0, 1 * time.Minute, 1 * time.Minute, 3 * time.Minute, 3 * time.Minute, 3 * time.Minute, 10 * time.Minute}
 )
 
 type metadataLookup struct {
@@ -165,13 +166,13 @@ func (t *Tagger) Apply(in ...telegraf.Metric) []telegraf.Metric {
 			}
 		}
 		if t.metadataLookup.instanceId {
-			metric.AddTag(mdKeyInstanceId, t.instanceId)
+			metric.AddTag(MdKeyInstanceId, t.instanceId)
 		}
 		if t.metadataLookup.imageId {
-			metric.AddTag(mdKeyImageId, t.imageId)
+			metric.AddTag(MdKeyImageId, t.imageId)
 		}
 		if t.metadataLookup.instanceType {
-			metric.AddTag(mdKeyInstaneType, t.instanceType)
+			metric.AddTag(MdKeyInstanceType, t.instanceType)
 		}
 		if t.ebsVolume != nil && metric.HasTag(t.DiskDeviceTagKey) {
 			devName := metric.Tags()[t.DiskDeviceTagKey]
@@ -184,23 +185,59 @@ func (t *Tagger) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	return in
 }
 
-// updateTags calls EC2 Describe Tags and replaces the Tagger's tagCache with the newly retrieved values
-func (t *Tagger) updateTags() error {
+// TagFilters returns the EC2 Describe Tags filters of the EC2 Instance Tags of the keys of the instance, all its tags
+// are described when the keys are ["*"]
+func TagFilters(instanceId string, keys []string) []*ec2.Filter {
+	filters := []*ec2.Filter{
+		{
+			Name:   aws.String("resource-type"),
+			Values: aws.StringSlice([]string{"instance"}),
+		},
+		{
+			Name:   aws.String("resource-id"),
+			Values: aws.StringSlice([]string{instanceId}),
+		},
+	}
+
+	useAllTags := len(keys) == 1 && keys[0] == "*"
+
+	if !useAllTags && len(keys) > 0 {
+		// if the customer said 'AutoScalingGroupName' (the CW dimension), do what they mean not what they said
+		// and filter for the EC2 tag name called 'aws:autoscaling:groupName'
+		tagKeys := make([]string, len(keys))
+		for i, key := range keys {
+			if CWDimensionASG == key {
+				key = EC2InstanceTagKeyASG
+			}
+			tagKeys[i] = key
+		}
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("key"),
+			Values: aws.StringSlice(tagKeys),
+		})
+	}
+	return filters
+}
+
+// DescribeTags calls EC2 Describe Tags with the filters and returns the EC2 Instance Tags, the
+// "aws:autoscaling:groupName" key is renamed "AutoScalingGroupName"
+func DescribeTags(client ec2iface.EC2API, filters []*ec2.Filter) (map[string]string, error) {
 	tags := make(map[string]string)
 	input := &ec2.DescribeTagsInput{
-		Filters: t.tagFilters,
+		Filters: filters,
 	}
 
 	for {
-		result, err := t.ec2.DescribeTags(input)
+		result, err := client.DescribeTags(input)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		for _, tag := range result.Tags {
 			key := *tag.Key
-			if ec2InstanceTagKeyASG == key {
+			if EC2InstanceTagKeyASG == key {
 				// rename to match CW dimension as applied by AutoScaling service, not the EC2 tag
-				key = cwDimensionASG
+				key = CWDimensionASG
 			}
 			tags[key] = *tag.Value
 		}
@@ -209,6 +246,15 @@ func (t *Tagger) updateTags() error {
 		}
 		input.SetNextToken(*result.NextToken)
 	}
+	return tags, nil
+}
+
+// updateTags calls EC2 Describe Tags and replaces the Tagger's tagCache with the newly retrieved values
+func (t *Tagger) updateTags() error {
+	tags, err := DescribeTags(t.ec2, t.tagFilters)
+	if err != nil {
+		return err
+	}
 	t.Lock()
 	defer t.Unlock()
 	t.ec2TagCache = tags
@@ -266,8 +312,8 @@ func (t *Tagger) ec2TagsRetrieved() bool {
 	defer t.RUnlock()
 	if t.ec2TagCache != nil {
 		for _, key := range t.EC2InstanceTagKeys {
-			if key == ec2InstanceTagKeyASG {
-				key = cwDimensionASG
+			if key == EC2InstanceTagKeyASG {
+				key = CWDimensionASG
 			}
 			if key == "*" {
 				continue
@@ -305,11 +351,11 @@ func (t *Tagger) Init() error {
 
 	for _, tag := range t.EC2MetadataTags {
 		switch tag {
-		case mdKeyInstanceId:
+		case MdKeyInstanceId:
 			t.metadataLookup.instanceId = true
-		case mdKeyImageId:
+		case MdKeyImageId:
 			t.metadataLookup.imageId = true
-		case mdKeyInstaneType:
+		case MdKeyInstanceType:
 			t.metadataLookup.instanceType = true
 		default:
 			t.Log.Errorf("ec2tagger: Unsupported EC2 Metadata key: %s", tag)
@@ -334,33 +380,7 @@ func (t *Tagger) Init() error {
 	t.instanceType = doc.InstanceType
 	t.imageId = doc.ImageID
 
-	t.tagFilters = []*ec2.Filter{
-		{
-			Name:   aws.String("resource-type"),
-			Values: aws.StringSlice([]string{"instance"}),
-		},
-		{
-			Name:   aws.String("resource-id"),
-			Values: aws.StringSlice([]string{t.instanceId}),
-		},
-	}
-
-	useAllTags := len(t.EC2InstanceTagKeys) == 1 && t.EC2InstanceTagKeys[0] == "*"
-
-	if !useAllTags && len(t.EC2InstanceTagKeys) > 0 {
-		// if the customer said 'AutoScalingGroupName' (the CW dimension), do what they mean not what they said
-		// and filter for the EC2 tag name called 'aws:autoscaling:groupName'
-		for i, key := range t.EC2InstanceTagKeys {
-			if cwDimensionASG == key {
-				t.EC2InstanceTagKeys[i] = ec2InstanceTagKeyASG
-			}
-		}
-
-		t.tagFilters = append(t.tagFilters, &ec2.Filter{
-			Name:   aws.String("key"),
-			Values: aws.StringSlice(t.EC2InstanceTagKeys),
-		})
-	}
+	t.tagFilters = TagFilters(t.instanceId, t.EC2InstanceTagKeys)
 
 	if len(t.EC2InstanceTagKeys) > 0 || len(t.EBSDeviceKeys) > 0 {
 		ec2CredentialConfig := &internalaws.CredentialConfig{
@@ -465,10 +485,10 @@ func (t *Tagger) initialRetrievalOfTagsAndVolumes() {
 	retry := 0
 	for {
 		var waitDuration time.Duration
-		if retry < len(backoffSleepArray) {
-			waitDuration = backoffSleepArray[retry]
+		if retry < len(BackoffSleepArray) {
+			waitDuration = BackoffSleepArray[retry]
 		} else {
-			waitDuration = backoffSleepArray[len(backoffSleepArray)-1]
+			waitDuration = BackoffSleepArray[len(BackoffSleepArray)-1]
 		}
 
 		wait := time.NewTimer(waitDuration)
@@ -256,7 +256,7 @@ func TestInitSuccessWithNoTagsVolumesUpdate(t *testing.T) {
 	ec2Provider := func(*internalaws.CredentialConfig) ec2iface.EC2API {
 		return ec2Client
 	}
-	backoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	BackoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
 	defaultRefreshInterval = 50 * time.Millisecond
 	tagger := Tagger{
 		Log:                    testutil.Logger{},
@@ -308,7 +308,7 @@ func TestInitSuccessWithTagsVolumesUpdate(t *testing.T) {
 	ec2Provider := func(*internalaws.CredentialConfig) ec2iface.EC2API {
 		return ec2Client
 	}
-	backoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	BackoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
 	defaultRefreshInterval = 10 * time.Millisecond
 	tagger := Tagger{
 		Log: testutil.Logger{},
@@ -379,7 +379,7 @@ func TestInitSuccessWithWildcardTagVolumeKey(t *testing.T) {
 	ec2Provider := func(*internalaws.CredentialConfig) ec2iface.EC2API {
 		return ec2Client
 	}
-	backoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	BackoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
 	defaultRefreshInterval = 50 * time.Millisecond
 	tagger := Tagger{
 		Log:                    testutil.Logger{},
@@ -429,7 +429,7 @@ func TestApplyWithTagsVolumesUpdate(t *testing.T) {
 	ec2Provider := func(*internalaws.CredentialConfig) ec2iface.EC2API {
 		return ec2Client
 	}
-	backoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	BackoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
 	defaultRefreshInterval = 50 * time.Millisecond
 	tagger := Tagger{
 		Log: testutil.Logger{},
@@ -611,7 +611,7 @@ func TestMetricsDroppedBeforeStarted(t *testing.T) {
 	ec2Provider := func(*internalaws.CredentialConfig) ec2iface.EC2API {
 		return ec2Client
 	}
-	backoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	BackoffSleepArray = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
 	defaultRefreshInterval = 50 * time.Millisecond
 	tagger := Tagger{
 		Log:                    testutil.Logger{},
@@ -705,7 +705,7 @@ func TestTaggerInitDoesNotBlock(t *testing.T) {
 	ec2Provider := func(*internalaws.CredentialConfig) ec2iface.EC2API {
 		return ec2Client
 	}
-	backoffSleepArray = []time.Duration{1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 3 * time.Minute, 3 * time.Minute, 3 * time.Minute, 10 * time.Minute}
+	BackoffSleepArray = []time.Duration{1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 3 * time.Minute, 3 * time.Minute, 3 * time.Minute, 10 * time.Minute}
 	defaultRefreshInterval = 180 * time.Second
 	tagger := Tagger{
 		Log:                    testutil.Logger{},
@@ -19,29 +19,36 @@ import (
 const (
 	kernelMagicCodeNotSet      = int64(9223372036854771712) // infinity magic number for cgroup: https://unix.stackexchange.com/questions/420906/what-is-the-value-for-the-cgroups-limit-in-bytes-if-the-memory-is-not-restricte
 	ecsInstanceMountConfigPath = "/proc/self/mountinfo"
+
+	// the task cgroups of the ECS agent on the cgroup v2 hosts are systemd slices, e.g. ecstasks.slice/ecstasks-<task id>.slice
+	ecsTasksSliceV2 = "ecstasks.slice"
 )
 
 type cgroupScanner struct {
 	mountPoint string
+	// v2 is true on the hosts running the unified cgroup v2 hierarchy, the hybrid hosts keep the cpu and memory
+	// controllers on the v1 hierarchy
+	v2 bool
 }
 
 func newCGroupScanner(mountConfigPath string) (c *cgroupScanner) {
-	mp, err := getCGroupMountPoint(mountConfigPath)
-	if err != nil {
-		log.Printf("D! failed to get the cgroup mount point, error: %v, fallback to /cgroup", err)
-		mp = "/cgroup"
+	if mp, err := getCGroupMountPoint(mountConfigPath); err == nil {
+		return &cgroupScanner{mountPoint: mp}
 	}
-
-	c = &cgroupScanner{
-		mountPoint: mp,
+	if mp, err := getCGroupV2MountPoint(mountConfigPath); err == nil {
+		return &cgroupScanner{mountPoint: mp, v2: true}
 	}
-	return c
+	log.Printf("D! failed to get the cgroup mount point, fallback to /cgroup")
+	return &cgroupScanner{mountPoint: "/cgroup"}
 }
 func newCGroupScannerForContainer() *cgroupScanner {
 	return newCGroupScanner(ecsInstanceMountConfigPath)
 }
 
 func (c *cgroupScanner) getCPUReserved(taskID string, clusterName string) int64 {
+	if c.v2 {
+		return c.getCPUReservedV2(taskID, clusterName)
+	}
 	cpuPath, err := getCGroupPathForTask(c.mountPoint, "cpu", taskID, clusterName)
 	if err != nil {
 		log.Printf("E! failed to get cpu cgroup path for task: %v", err)
@@ -63,6 +70,9 @@ func (c *cgroupScanner) getCPUReserved(taskID string, clusterName string) int64
 }
 
 func (c *cgroupScanner) getMEMReserved(taskID string, clusterName string, containers []ECSContainer) int64 {
+	if c.v2 {
+		return c.getMEMReservedV2(taskID, clusterName, containers)
+	}
 	memPath, err := getCGroupPathForTask(c.mountPoint, "memory", taskID, clusterName)
 	if err != nil {
 		log.Printf("E! failed to get memory cgroup path for task: %v", err)
@@ -93,6 +103,63 @@ func (c *cgroupScanner) getMEMReserved(taskID string, clusterName string, contai
 	return sum
 }
 
+// getCPUReservedV2 returns the cpu units of the task from cpu.max and cpu.weight, the weight is converted back to the
+// cpu shares it is set from, see https://github.com/opencontainers/runc/blob/main/docs/systemd.md
+func (c *cgroupScanner) getCPUReservedV2(taskID string, clusterName string) int64 {
+	taskPath, err := getCGroupV2PathForTask(c.mountPoint, taskID, clusterName)
+	if err != nil {
+		log.Printf("E! failed to get cgroup v2 path for task: %v", err)
+		return int64(0)
+	}
+
+	// check if hard limit is configured, the format is "$MAX $PERIOD" and $MAX is "max" when there is no limit
+	if cpuMax, err := readString(taskPath, "cpu.max"); err == nil {
+		if fields := strings.Fields(cpuMax); len(fields) == 2 && fields[0] != "max" {
+			quota, qerr := strconv.ParseInt(fields[0], 10, 64)
+			period, perr := strconv.ParseInt(fields[1], 10, 64)
+			if qerr == nil && perr == nil && period > 0 {
+				return int64(math.Ceil(float64(1024*quota) / float64(period)))
+			}
+		}
+	}
+
+	if weight, err := readInt64(taskPath, "cpu.weight"); err == nil && weight > 0 {
+		return 2 + (weight-1)*262142/9999
+	}
+
+	return int64(0)
+}
+
+// getMEMReservedV2 returns the memory of the task from memory.max, or the sum of the memory.low, the memory
+// reservation, or memory.max of its containers
+func (c *cgroupScanner) getMEMReservedV2(taskID string, clusterName string, containers []ECSContainer) int64 {
+	taskPath, err := getCGroupV2PathForTask(c.mountPoint, taskID, clusterName)
+	if err != nil {
+		log.Printf("E! failed to get cgroup v2 path for task: %v", err)
+		return int64(0)
+	}
+
+	// readInt64 returns 0 for "max", i.e. no limit
+	if memReserved, err := readInt64(taskPath, "memory.max"); err == nil && memReserved > 0 {
+		return memReserved
+	}
+
+	sum := int64(0)
+	for _, container := range containers {
+		containerPath := getCGroupV2PathForContainer(taskPath, container.DockerId)
+
+		if softLimit, err := readInt64(containerPath, "memory.low"); err == nil && softLimit > 0 {
+			sum += softLimit
+			continue
+		}
+
+		if hardLimit, err := readInt64(containerPath, "memory.max"); err == nil && hardLimit > 0 {
+			sum += hardLimit
+		}
+	}
+	return sum
+}
+
 func readString(dirpath string, file string) (string, error) {
 	cgroupFile := path.Join(dirpath, file)
 
@@ -159,6 +226,58 @@ func getCGroupMountPoint(mountConfigPath string) (string, error) {
 	return "", fmt.Errorf("mount point not existed")
 }
 
+// getCGroupV2MountPoint returns the mount point of the unified cgroup v2 hierarchy, e.g. /sys/fs/cgroup
+func getCGroupV2MountPoint(mountConfigPath string) (string, error) {
+	f, err := os.Open(mountConfigPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// an example: 26 22 0:23 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime - cgroup2 cgroup2 rw,nsdelegate
+		text := scanner.Text()
+		index := strings.Index(text, " - ")
+		if index < 0 {
+			continue
+		}
+		fields := strings.Split(text, " ")
+		postSeparatorFields := strings.Fields(text[index+3:])
+		if len(postSeparatorFields) > 0 && postSeparatorFields[0] == "cgroup2" && len(fields) > 4 {
+			return fields[4], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("cgroup2 mount point not existed")
+}
+
+// getCGroupV2PathForTask returns the cgroup of the task, the controllers share the same path in cgroup v2
+func getCGroupV2PathForTask(cgroupMount, taskID, clusterName string) (string, error) {
+	candidates := []string{
+		path.Join(cgroupMount, ecsTasksSliceV2, fmt.Sprintf("ecstasks-%s.slice", taskID)),
+		path.Join(cgroupMount, "ecs", taskID),
+		path.Join(cgroupMount, "ecs", clusterName, taskID),
+	}
+	for _, taskPath := range candidates {
+		if _, err := os.Stat(taskPath); err == nil {
+			return taskPath, nil
+		}
+	}
+	return "", fmt.Errorf("CGroup v2 Path of task %q does not exist", taskID)
+}
+
+// getCGroupV2PathForContainer returns the cgroup of the container, it is a systemd scope when docker uses the systemd
+// cgroup driver
+func getCGroupV2PathForContainer(taskPath, dockerID string) string {
+	scopePath := path.Join(taskPath, fmt.Sprintf("docker-%s.scope", dockerID))
+	if _, err := os.Stat(scopePath); err == nil {
+		return scopePath
+	}
+	return path.Join(taskPath, dockerID)
+}
+
 func getCGroupPathForTask(cgroupMount, controller, taskID, clusterName string) (string, error) {
 	taskPath := path.Join(cgroupMount, controller, "ecs", taskID)
 	if _, err := os.Stat(taskPath); os.IsNotExist(err) {
@@ -68,3 +68,46 @@ func TestGetCGroupPathForTask(t *testing.T) {
 	result, _ = getCGroupPathForTask(cgroupMount, controller, taskID, clusterName)
 	assert.Equal(t, path.Join(cgroupMount, controller, "ecs", clusterName, taskID), result)
 }
+
+func TestGetCGroupV2MountPoint(t *testing.T) {
+	result, err := getCGroupV2MountPoint("test/cgroupv2/mountinfo")
+	assert.NoError(t, err)
+	assert.Equal(t, "test/cgroupv2", result)
+
+	_, err = getCGroupV2MountPoint("test/mountinfo")
+	assert.Error(t, err)
+}
+
+func TestNewCGroupScanner(t *testing.T) {
+	assert.Equal(t, &cgroupScanner{mountPoint: "test"}, newCGroupScanner("test/mountinfo"))
+	assert.Equal(t, &cgroupScanner{mountPoint: "test/cgroupv2", v2: true}, newCGroupScanner("test/cgroupv2/mountinfo"))
+	// the cpu and memory controllers of the hybrid hosts are on the v1 hierarchy
+	assert.Equal(t, &cgroupScanner{mountPoint: "test/hybrid"}, newCGroupScanner("test/hybrid/mountinfo"))
+}
+
+func TestGetCPUReservedV2(t *testing.T) {
+	cgroup := newCGroupScanner("test/cgroupv2/mountinfo")
+	// from cpu.weight when cpu.max has no limit
+	assert.Equal(t, int64(106), cgroup.getCPUReserved("task1", ""))
+	// from cpu.max
+	assert.Equal(t, int64(256), cgroup.getCPUReserved("task2", ""))
+	// legacy task cgroup path with the cluster name
+	assert.Equal(t, int64(998), cgroup.getCPUReserved("task3", "myCluster"))
+	assert.Equal(t, int64(0), cgroup.getCPUReserved("fake", ""))
+}
+
+func TestGetMEMReservedV2(t *testing.T) {
+	cgroup := newCGroupScanner("test/cgroupv2/mountinfo")
+	containers := []ECSContainer{{DockerId: "container1"}, {DockerId: "container2"}}
+	assert.Equal(t, int64(256), cgroup.getMEMReserved("task1", "", containers))
+	// memory.low of container1 and memory.max of container2 as the task has no limit
+	assert.Equal(t, int64(384), cgroup.getMEMReserved("task2", "", containers))
+	assert.Equal(t, int64(256), cgroup.getMEMReserved("task3", "myCluster", nil))
+	assert.Equal(t, int64(0), cgroup.getMEMReserved("fake", "", containers))
+}
+
+func TestGetReservedHybrid(t *testing.T) {
+	cgroup := newCGroupScanner("test/hybrid/mountinfo")
+	assert.Equal(t, int64(512), cgroup.getCPUReserved("task1", ""))
+	assert.Equal(t, int64(512), cgroup.getMEMReserved("task1", "", nil))
+}
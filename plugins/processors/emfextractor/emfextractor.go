@@ -0,0 +1,199 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emfextractor
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/parsers/emf"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const (
+	// the name and field of the metrics created by the emf parser
+	emfMetricName = "emf"
+	emfField      = "value"
+
+	// the tags handled by the cloudwatch output
+	namespaceTagKey           = "aws:Namespace"
+	aggregationIntervalTagKey = "aws:AggregationInterval"
+
+	// the field name the cloudwatch output translates into the measurement name alone
+	extractedMetricField = "value"
+	invalidDocumentField = "invalid_documents"
+
+	standardResolutionInterval = "60s"
+	highResolutionInterval     = "1s"
+)
+
+// EMFExtractor extracts the metrics of the _aws.CloudWatchMetrics directives of the embedded metric format documents,
+// so they are published by the cloudwatch output rather than by the CloudWatch Logs backend
+type EMFExtractor struct {
+	// DropLogs drops the documents once their metrics are extracted, they are kept as structured logs otherwise
+	DropLogs bool `toml:"drop_logs"`
+	// MetricTags are added to the extracted metrics and to the invalid documents counter, e.g. to route them to the
+	// cloudwatch output
+	MetricTags map[string]string `toml:"metric_tags"`
+
+	Log telegraf.Logger `toml:"-"`
+}
+
+type metricDefinition struct {
+	Name              string `json:"Name"`
+	Unit              string `json:"Unit"`
+	StorageResolution int    `json:"StorageResolution"`
+}
+
+type metricDirective struct {
+	Namespace  string             `json:"Namespace"`
+	Dimensions [][]string         `json:"Dimensions"`
+	Metrics    []metricDefinition `json:"Metrics"`
+}
+
+type metadata struct {
+	Timestamp         float64           `json:"Timestamp"`
+	CloudWatchMetrics []metricDirective `json:"CloudWatchMetrics"`
+}
+
+const sampleConfig = `
+  ## Drop the documents once their metrics are extracted instead of publishing them as structured logs too
+  drop_logs = false
+  ## Tags added to the extracted metrics
+  # [processors.emfextractor.metric_tags]
+  #   metricPath = "metrics"
+`
+
+func (e *EMFExtractor) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EMFExtractor) Description() string {
+	return "Extract the metrics of the embedded metric format documents"
+}
+
+func (e *EMFExtractor) Apply(in ...telegraf.Metric) (result []telegraf.Metric) {
+	for _, m := range in {
+		document, ok := m.GetField(emfField)
+		if !ok || m.Name() != emfMetricName {
+			result = append(result, m)
+			continue
+		}
+		line, ok := document.(string)
+		if !ok {
+			result = append(result, m)
+			continue
+		}
+		extracted, err := e.extract(line)
+		if err != nil {
+			e.Log.Debugf("Cannot extract the metrics of an EMF document: %v", err)
+			if invalid, err := e.invalidDocument(); err == nil {
+				result = append(result, invalid)
+			}
+			if !e.DropLogs {
+				result = append(result, m)
+			}
+			continue
+		}
+		result = append(result, extracted...)
+		// the documents without metric directives are plain structured logs
+		if !e.DropLogs || len(extracted) == 0 {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// extract returns a metric per metric definition and dimension set of the document
+func (e *EMFExtractor) extract(line string) ([]telegraf.Metric, error) {
+	if err := emf.Validate(line); err != nil {
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		return nil, err
+	}
+	raw, ok := doc["_aws"]
+	if !ok {
+		return nil, nil
+	}
+	md := new(metadata)
+	if err := json.Unmarshal(raw, md); err != nil {
+		return nil, err
+	}
+	ts := time.Unix(0, int64(md.Timestamp)*int64(time.Millisecond))
+
+	var metrics []telegraf.Metric
+	for _, directive := range md.CloudWatchMetrics {
+		dimensionSets := directive.Dimensions
+		if len(dimensionSets) == 0 {
+			dimensionSets = [][]string{{}}
+		}
+		for _, set := range dimensionSets {
+			tags := make(map[string]string, len(e.MetricTags)+len(set)+2)
+			for k, v := range e.MetricTags {
+				tags[k] = v
+			}
+			for _, key := range set {
+				var value string
+				json.Unmarshal(doc[key], &value)
+				tags[key] = value
+			}
+			tags[namespaceTagKey] = directive.Namespace
+			for _, definition := range directive.Metrics {
+				m, err := newMetric(definition, doc[definition.Name], tags, ts)
+				if err != nil {
+					return nil, err
+				}
+				metrics = append(metrics, m)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// newMetric returns the values of the metric as a distribution, which keeps their unit and is aggregated by the
+// cloudwatch output over the storage resolution of the metric
+func newMetric(definition metricDefinition, raw json.RawMessage, tags map[string]string, ts time.Time) (telegraf.Metric, error) {
+	var values []float64
+	var value float64
+	if err := json.Unmarshal(raw, &value); err == nil {
+		values = []float64{value}
+	} else if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	dist := distribution.NewDistribution()
+	for _, v := range values {
+		dist.AddEntryWithUnit(v, 1, definition.Unit)
+	}
+
+	metricTags := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		metricTags[k] = v
+	}
+	metricTags[aggregationIntervalTagKey] = standardResolutionInterval
+	if definition.StorageResolution == 1 {
+		metricTags[aggregationIntervalTagKey] = highResolutionInterval
+	}
+	return metric.New(definition.Name, metricTags, map[string]interface{}{extractedMetricField: dist}, ts)
+}
+
+// invalidDocument returns the metric counting an invalid document, which the cloudwatch output sums over a minute
+func (e *EMFExtractor) invalidDocument() (telegraf.Metric, error) {
+	tags := make(map[string]string, len(e.MetricTags)+1)
+	for k, v := range e.MetricTags {
+		tags[k] = v
+	}
+	tags[aggregationIntervalTagKey] = standardResolutionInterval
+	return metric.New(emfMetricName, tags, map[string]interface{}{invalidDocumentField: 1}, time.Now())
+}
+
+func init() {
+	processors.Add("emfextractor", func() telegraf.Processor {
+		return &EMFExtractor{}
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emfextractor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validDocument = `{"_aws":{"Timestamp":1566164466581,"LogGroupName":"lg","CloudWatchMetrics":[{"Namespace":"MyApp","Dimensions":[["Service"],["Service","Operation"]],` +
+		`"Metrics":[{"Name":"Latency","Unit":"Milliseconds","StorageResolution":1},{"Name":"Errors"}]}]},"Service":"checkout","Operation":"pay","Latency":[10,20],"Errors":1}`
+	invalidDocument = `{"_aws":{"Timestamp":1566164466581,"LogGroupName":"lg","CloudWatchMetrics":[{"Namespace":"MyApp","Dimensions":[],"Metrics":[{"Name":"Latency","Unit":"Hours"}]}]},"Latency":1}`
+	plainDocument   = `{"_aws":{"LogGroupName":"lg"},"message":"hello"}`
+)
+
+func emfMetric(document string) telegraf.Metric {
+	m, _ := metric.New(emfMetricName, map[string]string{"log_group_name": "lg"}, map[string]interface{}{emfField: document}, time.Now())
+	return m
+}
+
+func newExtractor(dropLogs bool) *EMFExtractor {
+	distribution.NewDistribution = regular.NewRegularDistribution
+	return &EMFExtractor{DropLogs: dropLogs, MetricTags: map[string]string{"metricPath": "metrics"}, Log: testutil.Logger{}}
+}
+
+func find(metrics []telegraf.Metric, name string, tags map[string]string) telegraf.Metric {
+	for _, m := range metrics {
+		if m.Name() == name && assert.ObjectsAreEqual(tags, m.Tags()) {
+			return m
+		}
+	}
+	return nil
+}
+
+func TestApply_Extract(t *testing.T) {
+	e := newExtractor(false)
+	in := emfMetric(validDocument)
+	result := e.Apply(in)
+	require.Len(t, result, 5)
+	assert.Equal(t, in, result[4])
+
+	ts := time.Unix(0, 1566164466581*int64(time.Millisecond))
+	latency := find(result, "Latency", map[string]string{"metricPath": "metrics", namespaceTagKey: "MyApp", aggregationIntervalTagKey: "1s",
+		"Service": "checkout", "Operation": "pay"})
+	require.NotNil(t, latency)
+	assert.Equal(t, ts, latency.Time())
+	dist := latency.Fields()[extractedMetricField].(distribution.Distribution)
+	assert.Equal(t, float64(2), dist.SampleCount())
+	assert.Equal(t, float64(30), dist.Sum())
+	assert.Equal(t, "Milliseconds", dist.Unit())
+
+	errors := find(result, "Errors", map[string]string{"metricPath": "metrics", namespaceTagKey: "MyApp", aggregationIntervalTagKey: "60s",
+		"Service": "checkout"})
+	require.NotNil(t, errors)
+	dist = errors.Fields()[extractedMetricField].(distribution.Distribution)
+	assert.Equal(t, float64(1), dist.Sum())
+	assert.Equal(t, "", dist.Unit())
+}
+
+func TestApply_DropLogs(t *testing.T) {
+	e := newExtractor(true)
+	valid := emfMetric(validDocument)
+	plain := emfMetric(plainDocument)
+	other, _ := metric.New("cpu", nil, map[string]interface{}{"usage": 1}, time.Now())
+	result := e.Apply(valid, plain, other)
+	require.Len(t, result, 6)
+	assert.NotContains(t, result, valid)
+	assert.Contains(t, result, plain)
+	assert.Contains(t, result, other)
+}
+
+func TestApply_InvalidDocument(t *testing.T) {
+	for _, dropLogs := range []bool{false, true} {
+		e := newExtractor(dropLogs)
+		in := emfMetric(invalidDocument)
+		result := e.Apply(in)
+		counter := find(result, emfMetricName, map[string]string{"metricPath": "metrics", aggregationIntervalTagKey: "60s"})
+		require.NotNil(t, counter)
+		assert.Equal(t, map[string]interface{}{invalidDocumentField: int64(1)}, counter.Fields())
+		if dropLogs {
+			assert.Len(t, result, 1)
+		} else {
+			assert.Equal(t, []telegraf.Metric{counter, in}, result)
+		}
+	}
+}
+
+func TestApply_NoDimensions(t *testing.T) {
+	e := newExtractor(false)
+	result := e.Apply(emfMetric(`{"_aws":{"Timestamp":1566164466581,"CloudWatchMetrics":[{"Namespace":"MyApp","Dimensions":[],"Metrics":[{"Name":"Count"}]}]},"Count":3}`))
+	require.Len(t, result, 2)
+	assert.NotNil(t, find(result, "Count", map[string]string{"metricPath": "metrics", namespaceTagKey: "MyApp", aggregationIntervalTagKey: "60s"}))
+}
@@ -112,6 +112,58 @@ var namespaceMetricRules = []structuredlogscommon.MetricRule{
 	},
 }
 
+func workloadMetricRules(mType, kind string) []structuredlogscommon.MetricRule {
+	return []structuredlogscommon.MetricRule{
+		{
+			Metrics: []structuredlogscommon.MetricAttr{
+				{Unit: Count, Name: MetricName(mType, DesiredReplicas)},
+				{Unit: Count, Name: MetricName(mType, AvailableReplicas)},
+				{Unit: Count, Name: MetricName(mType, UnavailableReplicas)}},
+			DimensionSets: [][]string{{kind, K8sNamespace, ClusterNameKey}},
+			Namespace:     cloudwatchNamespace,
+		},
+	}
+}
+
+var jobMetricRules = []structuredlogscommon.MetricRule{
+	{
+		Metrics: []structuredlogscommon.MetricAttr{
+			{Unit: Count, Name: MetricName(TypeClusterJob, ActiveJobCount)},
+			{Unit: Count, Name: MetricName(TypeClusterJob, SucceededJobCount)},
+			{Unit: Count, Name: MetricName(TypeClusterJob, FailedJobCount)}},
+		DimensionSets: [][]string{{K8sNamespace, ClusterNameKey}},
+		Namespace:     cloudwatchNamespace,
+	},
+}
+
+var pendingPodMetricRules = []structuredlogscommon.MetricRule{
+	{
+		Metrics: []structuredlogscommon.MetricAttr{
+			{Unit: Count, Name: MetricName(TypeClusterPendingPod, PendingPodCount)}},
+		DimensionSets: [][]string{{PendingReasonKey, K8sNamespace, ClusterNameKey}},
+		Namespace:     cloudwatchNamespace,
+	},
+}
+
+var pvcMetricRules = []structuredlogscommon.MetricRule{
+	{
+		Metrics: []structuredlogscommon.MetricAttr{
+			{Unit: Count, Name: MetricName(TypeClusterPVC, PVCCount)}},
+		DimensionSets: [][]string{{PVCPhaseKey, K8sNamespace, ClusterNameKey}},
+		Namespace:     cloudwatchNamespace,
+	},
+}
+
+// every agent reports whether it is the leader, the sum per cluster is expected to be 1
+var leaderElectionMetricRules = []structuredlogscommon.MetricRule{
+	{
+		Metrics: []structuredlogscommon.MetricAttr{
+			{Unit: Count, Name: MetricName(TypeClusterLeaderElection, IsLeader)}},
+		DimensionSets: [][]string{{NodeNameKey, ClusterNameKey}, {ClusterNameKey}},
+		Namespace:     cloudwatchNamespace,
+	},
+}
+
 var staticMetricRule = map[string][]structuredlogscommon.MetricRule{
 	TypeCluster:          clusterMetricRules,
 	TypeClusterService:   serviceMetricRules,
@@ -119,6 +171,15 @@ var staticMetricRule = map[string][]structuredlogscommon.MetricRule{
 	TypeNode:             nodeMetricRules,
 	TypePod:              podMetricRules,
 	TypeNodeFS:           nodeFSMetricRules,
+
+	TypeClusterDeployment:  workloadMetricRules(TypeClusterDeployment, Deployment),
+	TypeClusterDaemonSet:   workloadMetricRules(TypeClusterDaemonSet, DaemonSet),
+	TypeClusterStatefulSet: workloadMetricRules(TypeClusterStatefulSet, StatefulSet),
+	TypeClusterJob:         jobMetricRules,
+	TypeClusterPendingPod:  pendingPodMetricRules,
+	TypeClusterPVC:         pvcMetricRules,
+
+	TypeClusterLeaderElection: leaderElectionMetricRules,
 }
 
 func TagMetricRule(metric telegraf.Metric) {
@@ -38,7 +38,8 @@ func TagMetricSource(metric telegraf.Metric) {
 		sources = append(sources, []string{"cadvisor", "calculated"}...)
 	case TypeContainerDiskIO:
 		sources = append(sources, []string{"cadvisor"}...)
-	case TypeCluster, TypeClusterService, TypeClusterNamespace:
+	case TypeCluster, TypeClusterService, TypeClusterNamespace, TypeClusterDeployment, TypeClusterDaemonSet,
+		TypeClusterStatefulSet, TypeClusterJob, TypeClusterPendingPod, TypeClusterPVC, TypeClusterLeaderElection:
 		sources = append(sources, []string{"apiserver"}...)
 	}
 
@@ -0,0 +1,301 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package relabel
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const (
+	// SectionTagKey is the tag set by the translator on the metrics of the inputs of a metrics_collected section
+	SectionTagKey = "aws:RelabelSection"
+
+	// the labels of the measurement and of the field, the other labels are the tags
+	nameLabel  = "__name__"
+	fieldLabel = "__field__"
+	// the labels starting with the reserved prefix are removed once the rules are applied
+	reservedLabelPrefix = "__"
+
+	// the tags used by the agent itself are not relabeled, e.g. the routing tag and the aws:StorageResolution
+	routingTagKey     = "metricPath"
+	internalTagPrefix = "aws:"
+
+	ActionReplace   = "replace"
+	ActionKeep      = "keep"
+	ActionDrop      = "drop"
+	ActionLabelDrop = "labeldrop"
+	ActionHashMod   = "hashmod"
+
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+// Rule is a Prometheus style relabel rule, see
+// https://prometheus.io/docs/prometheus/latest/configuration/configuration/#relabel_config
+type Rule struct {
+	SourceLabels []string `toml:"source_labels"`
+	Separator    *string  `toml:"separator"`
+	Regex        *string  `toml:"regex"`
+	Modulus      uint64   `toml:"modulus"`
+	TargetLabel  string   `toml:"target_label"`
+	Replacement  *string  `toml:"replacement"`
+	Action       string   `toml:"action"`
+
+	regex *regexp.Regexp
+}
+
+// Relabel applies the rules to the metrics of a metrics_collected section, each field of a metric is relabeled as a
+// series whose labels are its tags, the measurement name and the field name
+type Relabel struct {
+	Section string  `toml:"section"`
+	Rules   []*Rule `toml:"relabel_config"`
+
+	Log telegraf.Logger `toml:"-"`
+}
+
+const sampleConfig = `
+  ## The metrics_collected section whose metrics are relabeled, the metrics are tagged with it by the translator
+  section = "procstat"
+  [[processors.relabel.relabel_config]]
+    ## replace (default), keep, drop, labeldrop or hashmod
+    action = "drop"
+    ## The tags, or __name__ for the measurement and __field__ for the field, whose values are joined by the separator
+    source_labels = ["exe"]
+    ## The anchored regular expression matched against the joined values, or against the tag names for labeldrop
+    regex = "java|python"
+`
+
+func (r *Relabel) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Relabel) Description() string {
+	return "Relabel the metrics of a section with Prometheus style rules"
+}
+
+func (r *Relabel) Init() error {
+	for i, rule := range r.Rules {
+		if err := rule.init(); err != nil {
+			return fmt.Errorf("relabel_config[%d] of section %s: %v", i, r.Section, err)
+		}
+	}
+	return nil
+}
+
+func (rule *Rule) init() error {
+	if rule.Action == "" {
+		rule.Action = ActionReplace
+	}
+	regex := defaultRegex
+	if rule.Regex != nil {
+		regex = *rule.Regex
+	}
+	var err error
+	if rule.regex, err = regexp.Compile("^(?:" + regex + ")$"); err != nil {
+		return err
+	}
+	switch rule.Action {
+	case ActionKeep, ActionDrop, ActionLabelDrop:
+	case ActionReplace:
+		if rule.TargetLabel == "" {
+			return fmt.Errorf("target_label is required by the replace action")
+		}
+	case ActionHashMod:
+		if rule.TargetLabel == "" || rule.Modulus == 0 {
+			return fmt.Errorf("target_label and a positive modulus are required by the hashmod action")
+		}
+	default:
+		return fmt.Errorf("unknown action %s", rule.Action)
+	}
+	return nil
+}
+
+func (r *Relabel) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	result := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if section, ok := m.GetTag(SectionTagKey); !ok || section != r.Section {
+			result = append(result, m)
+			continue
+		}
+		m.RemoveTag(SectionTagKey)
+		result = append(result, r.relabel(m)...)
+	}
+	return result
+}
+
+type series struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+// relabel returns the metrics of the fields kept by the rules, grouped by their new measurement name and tags
+func (r *Relabel) relabel(m telegraf.Metric) []telegraf.Metric {
+	internalTags := map[string]string{}
+	baseLabels := map[string]string{nameLabel: m.Name()}
+	for k, v := range m.Tags() {
+		if k == routingTagKey || strings.HasPrefix(k, internalTagPrefix) {
+			internalTags[k] = v
+		} else {
+			baseLabels[k] = v
+		}
+	}
+
+	changed := false
+	var keys []string
+	grouped := map[string]*series{}
+	for field, value := range m.Fields() {
+		labels := make(map[string]string, len(baseLabels)+1)
+		for k, v := range baseLabels {
+			labels[k] = v
+		}
+		labels[fieldLabel] = field
+		if !r.apply(labels) {
+			changed = true
+			continue
+		}
+
+		s := &series{name: labels[nameLabel], tags: map[string]string{}}
+		newField := labels[fieldLabel]
+		for k, v := range labels {
+			if !strings.HasPrefix(k, reservedLabelPrefix) && v != "" {
+				s.tags[k] = v
+			}
+		}
+		for k, v := range internalTags {
+			s.tags[k] = v
+		}
+		if s.name == "" || newField == "" {
+			// the series cannot be published without a name
+			changed = true
+			continue
+		}
+		if s.name != m.Name() || newField != field || !equalTags(s.tags, m.Tags()) {
+			changed = true
+		}
+		key := seriesKey(s.name, s.tags)
+		if existing, ok := grouped[key]; ok {
+			s = existing
+		} else {
+			s.fields = map[string]interface{}{}
+			grouped[key] = s
+			keys = append(keys, key)
+		}
+		s.fields[newField] = value
+	}
+
+	if !changed {
+		return []telegraf.Metric{m}
+	}
+	sort.Strings(keys)
+	metrics := make([]telegraf.Metric, 0, len(keys))
+	for _, key := range keys {
+		s := grouped[key]
+		newMetric, err := metric.New(s.name, s.tags, s.fields, m.Time(), m.Type())
+		if err != nil {
+			r.Log.Warnf("Failed to relabel the metric %s: %v", m.Name(), err)
+			continue
+		}
+		metrics = append(metrics, newMetric)
+	}
+	return metrics
+}
+
+// apply runs the rules on the labels of a series, it returns false if the series is dropped
+func (r *Relabel) apply(labels map[string]string) bool {
+	for _, rule := range r.Rules {
+		if !rule.apply(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (rule *Rule) apply(labels map[string]string) bool {
+	separator := defaultSeparator
+	if rule.Separator != nil {
+		separator = *rule.Separator
+	}
+	values := make([]string, len(rule.SourceLabels))
+	for i, l := range rule.SourceLabels {
+		values[i] = labels[l]
+	}
+	val := strings.Join(values, separator)
+
+	switch rule.Action {
+	case ActionKeep:
+		return rule.regex.MatchString(val)
+	case ActionDrop:
+		return !rule.regex.MatchString(val)
+	case ActionLabelDrop:
+		for k := range labels {
+			if k != nameLabel && k != fieldLabel && rule.regex.MatchString(k) {
+				delete(labels, k)
+			}
+		}
+	case ActionHashMod:
+		hash := md5.Sum([]byte(val))
+		// the last 8 bytes of the hash give the same shards as Prometheus
+		labels[rule.TargetLabel] = fmt.Sprint(binary.BigEndian.Uint64(hash[8:]) % rule.Modulus)
+	case ActionReplace:
+		indexes := rule.regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			return true
+		}
+		replacement := defaultReplacement
+		if rule.Replacement != nil {
+			replacement = *rule.Replacement
+		}
+		target := string(rule.regex.ExpandString(nil, rule.TargetLabel, val, indexes))
+		res := string(rule.regex.ExpandString(nil, replacement, val, indexes))
+		if res == "" {
+			delete(labels, target)
+		} else {
+			labels[target] = res
+		}
+	}
+	return true
+}
+
+func equalTags(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("," + k + "=" + tags[k])
+	}
+	return b.String()
+}
+
+func init() {
+	processors.Add("relabel", func() telegraf.Processor {
+		return &Relabel{}
+	})
+}
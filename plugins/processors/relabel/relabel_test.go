@@ -0,0 +1,122 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package relabel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func str(s string) *string {
+	return &s
+}
+
+func newRelabel(t *testing.T, rules ...*Rule) *Relabel {
+	r := &Relabel{Section: "procstat", Rules: rules, Log: testutil.Logger{}}
+	require.NoError(t, r.Init())
+	return r
+}
+
+func procstatMetric(exe string) telegraf.Metric {
+	return testutil.MustMetric("procstat",
+		map[string]string{SectionTagKey: "procstat", "exe": exe, "pid": "42", "metricPath": "metrics", "aws:StorageResolution": "true"},
+		map[string]interface{}{"cpu_usage": 1.5, "memory_rss": int64(1024)},
+		time.Unix(0, 0))
+}
+
+func TestRelabel_OtherSection(t *testing.T) {
+	r := newRelabel(t, &Rule{Action: ActionDrop, SourceLabels: []string{"exe"}, Regex: str(".*")})
+	m := testutil.MustMetric("cpu", map[string]string{SectionTagKey: "cpu"}, map[string]interface{}{"usage_idle": 1.0}, time.Unix(0, 0))
+	other := testutil.MustMetric("mem", nil, map[string]interface{}{"used": 1.0}, time.Unix(0, 0))
+	assert.Equal(t, []telegraf.Metric{m, other}, r.Apply(m, other))
+}
+
+func TestRelabel_Unchanged(t *testing.T) {
+	r := newRelabel(t, &Rule{Action: ActionKeep, SourceLabels: []string{"exe"}, Regex: str("java")})
+	m := procstatMetric("java")
+	result := r.Apply(m)
+	require.Len(t, result, 1)
+	assert.True(t, m == result[0])
+	assert.False(t, m.HasTag(SectionTagKey))
+}
+
+func TestRelabel_KeepDrop(t *testing.T) {
+	r := newRelabel(t,
+		&Rule{Action: ActionKeep, SourceLabels: []string{"exe"}, Regex: str("java|python")},
+		&Rule{Action: ActionDrop, SourceLabels: []string{"__name__", "__field__"}, Separator: str("/"), Regex: str("procstat/memory_.*")},
+	)
+	result := r.Apply(procstatMetric("java"), procstatMetric("bash"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("procstat",
+			map[string]string{"exe": "java", "pid": "42", "metricPath": "metrics", "aws:StorageResolution": "true"},
+			map[string]interface{}{"cpu_usage": 1.5},
+			time.Unix(0, 0)),
+	}, result)
+}
+
+func TestRelabel_ReplaceAndLabelDrop(t *testing.T) {
+	r := newRelabel(t,
+		&Rule{SourceLabels: []string{"exe"}, Regex: str("(j.*)"), TargetLabel: "runtime", Replacement: str("${1}vm")},
+		&Rule{SourceLabels: []string{"__field__"}, Regex: str("cpu_(.*)"), TargetLabel: "__field__", Replacement: str("processor_$1")},
+		&Rule{SourceLabels: []string{"__name__"}, TargetLabel: "__name__", Replacement: str("process")},
+		&Rule{Action: ActionLabelDrop, Regex: str("pid|exe|metricPath")},
+	)
+	result := r.Apply(procstatMetric("java"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("process",
+			map[string]string{"runtime": "javavm", "metricPath": "metrics", "aws:StorageResolution": "true"},
+			map[string]interface{}{"processor_usage": 1.5, "memory_rss": int64(1024)},
+			time.Unix(0, 0)),
+	}, result)
+}
+
+func TestRelabel_SplitSeries(t *testing.T) {
+	r := newRelabel(t,
+		&Rule{SourceLabels: []string{"__field__"}, Regex: str("memory_.*"), TargetLabel: "kind", Replacement: str("memory")},
+	)
+	result := r.Apply(procstatMetric("java"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("procstat",
+			map[string]string{"exe": "java", "pid": "42", "metricPath": "metrics", "aws:StorageResolution": "true"},
+			map[string]interface{}{"cpu_usage": 1.5},
+			time.Unix(0, 0)),
+		testutil.MustMetric("procstat",
+			map[string]string{"exe": "java", "pid": "42", "kind": "memory", "metricPath": "metrics", "aws:StorageResolution": "true"},
+			map[string]interface{}{"memory_rss": int64(1024)},
+			time.Unix(0, 0)),
+	}, result, testutil.SortMetrics())
+}
+
+func TestRelabel_HashMod(t *testing.T) {
+	r := newRelabel(t,
+		&Rule{Action: ActionHashMod, SourceLabels: []string{"pid"}, TargetLabel: "__shard", Modulus: 4},
+		&Rule{Action: ActionKeep, SourceLabels: []string{"__shard"}, Regex: str("[0-3]")},
+	)
+	result := r.Apply(procstatMetric("java"))
+	require.Len(t, result, 1)
+	assert.False(t, result[0].HasTag("__shard"))
+
+	r = newRelabel(t, &Rule{Action: ActionHashMod, SourceLabels: []string{"pid"}, TargetLabel: "shard", Modulus: 1})
+	result = r.Apply(procstatMetric("java"))
+	require.Len(t, result, 1)
+	assert.Equal(t, "0", result[0].Tags()["shard"])
+}
+
+func TestRelabel_Init(t *testing.T) {
+	tests := []*Rule{
+		{Action: "labelmap"},
+		{Action: ActionReplace},
+		{Action: ActionHashMod, TargetLabel: "shard"},
+		{Action: ActionKeep, Regex: str("(")},
+	}
+	for _, rule := range tests {
+		r := &Relabel{Section: "cpu", Rules: []*Rule{rule}}
+		assert.Error(t, r.Init())
+	}
+}
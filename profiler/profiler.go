@@ -12,7 +12,8 @@ import (
 
 var (
 	Profiler profiler = profiler{
-		stats: make(map[string]float64),
+		stats:  make(map[string]float64),
+		totals: make(map[string]float64),
 	}
 	noStatsInProfiler = "[no stats is available...]"
 )
@@ -20,6 +21,8 @@ var (
 type profiler struct {
 	sync.Mutex
 	stats map[string]float64
+	// totals are not cleared by the dumps, they are reported by the health endpoint
+	totals map[string]float64
 }
 
 // use slice for key is enough now, could be expand to map if we need dimensions
@@ -32,6 +35,18 @@ func (p *profiler) AddStats(key []string, value float64) {
 		p.stats[k] = 0
 	}
 	p.stats[k] += value
+	p.totals[k] += value
+}
+
+// Snapshot returns a copy of the stats accumulated since the agent started
+func (p *profiler) Snapshot() map[string]float64 {
+	p.Lock()
+	defer p.Unlock()
+	snapshot := make(map[string]float64, len(p.totals))
+	for k, v := range p.totals {
+		snapshot[k] = v
+	}
+	return snapshot
 }
 
 func (p *profiler) ReportAndClear() {
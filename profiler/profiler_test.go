@@ -35,3 +35,15 @@ func TestProfiler(t *testing.T) {
 	assert.True(t, len(Profiler.stats) == 0)
 	assert.Equal(t, noStats, output, "Stats do not match")
 }
+
+func TestProfilerSnapshot(t *testing.T) {
+	Profiler.AddStats([]string{"pluginC", "StatsC"}, 1)
+	Profiler.reportAndClear()
+	Profiler.AddStats([]string{"pluginC", "StatsC"}, 2)
+
+	snapshot := Profiler.Snapshot()
+	assert.Equal(t, float64(3), snapshot["pluginC_StatsC"])
+
+	snapshot["pluginC_StatsC"] = 0
+	assert.Equal(t, float64(3), Profiler.Snapshot()["pluginC_StatsC"])
+}
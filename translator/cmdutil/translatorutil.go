@@ -116,6 +116,19 @@ func checkSchema(inputJsonMap map[string]interface{}) {
 }
 
 func GenerateMergedJsonConfigMap(ctx *context.Context) (map[string]interface{}, error) {
+	mergedJsonConfigMap, err := MergeJsonConfigFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Json Schema Validation by gojsonschema
+	checkSchema(mergedJsonConfigMap)
+	return mergedJsonConfigMap, nil
+}
+
+// MergeJsonConfigFiles merges the json config files of the context with the default config, without validating the
+// schema of the result
+func MergeJsonConfigFiles(ctx *context.Context) (map[string]interface{}, error) {
 	// we use a map instead of an array here because we need to override the config value
 	// for the append operation when the existing file name and new .tmp file name have diff
 	// only for the ".tmp" suffix, i.e. it is override operation even it says append.
@@ -204,12 +217,5 @@ func GenerateMergedJsonConfigMap(ctx *context.Context) (map[string]interface{},
 	if err != nil {
 		return nil, err
 	}
-	mergedJsonConfigMap, err := jsonconfig.MergeJsonConfigMaps(jsonConfigMapMap, defaultConfig, ctx.MultiConfig())
-	if err != nil {
-		return nil, err
-	}
-
-	// Json Schema Validation by gojsonschema
-	checkSchema(mergedJsonConfigMap)
-	return mergedJsonConfigMap, nil
+	return jsonconfig.MergeJsonConfigMaps(jsonConfigMapMap, defaultConfig, ctx.MultiConfig())
 }
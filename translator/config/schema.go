@@ -53,6 +53,12 @@ var schema = `{
           "description": "Specifies running the CloudWatch agent with debug log messages",
           "type": "boolean"
         },
+        "health_address": {
+          "description": "Address of the health and introspection HTTP endpoint, e.g. \"127.0.0.1:8099\", it listens on localhost if the host is omitted",
+          "type": "string",
+          "minLength": 1,
+          "maxLength": 255
+        },
         "credentials": {
           "description": "The credentials with which agent can access aws resources",
           "$ref": "#/definitions/credentialsDefinition"
@@ -153,6 +159,9 @@ var schema = `{
             "statsd": {
               "$ref": "#/definitions/metricsDefinition/definitions/statsdDefinitions"
             },
+            "otlp": {
+              "$ref": "#/definitions/metricsDefinition/definitions/otlpDefinitions"
+            },
             "swap": {
               "$ref": "#/definitions/metricsDefinition/definitions/swapDefinitions"
             },
@@ -187,6 +196,32 @@ var schema = `{
           "description": "Max time to wait before batch publishing the metrics, unit is second.",
           "$ref": "#/definitions/timeIntervalDefinition"
         },
+        "dimension_set_limits": {
+          "description": "The limits of the unique dimension sets published per metric name and per namespace",
+          "type": "object",
+          "properties": {
+            "per_metric": {
+              "type": "integer",
+              "minimum": 1
+            },
+            "per_namespace": {
+              "type": "integer",
+              "minimum": 1
+            },
+            "window": {
+              "description": "The dimension sets not published within the window are not counted, unit is second.",
+              "$ref": "#/definitions/timeIntervalDefinition"
+            },
+            "overflow_action": {
+              "type": "string",
+              "enum": [
+                "drop",
+                "collapse"
+              ]
+            }
+          },
+          "additionalProperties": false
+        },
         "credentials": {
           "description": "The credentials with which agent can access aws resources",
           "$ref": "#/definitions/credentialsDefinition"
@@ -210,6 +245,9 @@ var schema = `{
             "append_dimensions": {
               "$ref": "#/definitions/generalAppendDimensionsDefinition"
             },
+            "relabel_configs": {
+              "$ref": "#/definitions/relabelConfigsDefinition"
+            },
             "measurement": {
               "$ref": "#/definitions/metricsDefinition/definitions/metricsMeasurementDefinition"
             }
@@ -235,6 +273,15 @@ var schema = `{
         "collectdDefinitions": {
           "type": "object",
           "properties": {
+            "statistics": {
+              "$ref": "#/definitions/statisticsDefinition"
+            },
+            "percentiles": {
+              "$ref": "#/definitions/percentilesDefinition"
+            },
+            "relabel_configs": {
+              "$ref": "#/definitions/relabelConfigsDefinition"
+            },
             "service_address": {
               "type": "string",
               "minLength": 1,
@@ -341,6 +388,15 @@ var schema = `{
         "statsdDefinitions": {
           "type": "object",
           "properties": {
+            "statistics": {
+              "$ref": "#/definitions/statisticsDefinition"
+            },
+            "percentiles": {
+              "$ref": "#/definitions/percentilesDefinition"
+            },
+            "relabel_configs": {
+              "$ref": "#/definitions/relabelConfigsDefinition"
+            },
             "allowed_pending_messages": {
               "type": "integer",
               "minimum": 1,
@@ -361,6 +417,53 @@ var schema = `{
               "type": "string",
               "minLength": 1,
               "maxLength": 255
+            },
+            "protocol": {
+              "description": "Protocol of the listener, the service_address is the path of the socket for the unix protocols",
+              "type": "string",
+              "enum": ["udp", "tcp", "unixgram", "unix"]
+            },
+            "max_tcp_connections": {
+              "description": "Maximum number of concurrent connections of the tcp and unix listeners",
+              "type": "integer",
+              "minimum": 1,
+              "maximum": 65535
+            },
+            "events_log_group_name": {
+              "description": "Log group of the DogStatsD events and service checks, they are dropped if it is not set",
+              "$ref": "#/definitions/logsDefinition/definitions/logGroupNameDefinition"
+            },
+            "events_log_stream_name": {
+              "description": "Log stream of the DogStatsD events and service checks, the log_stream_name of the logs section by default",
+              "$ref": "#/definitions/logsDefinition/definitions/logStreamNameDefinition"
+            }
+          },
+          "additionalProperties": false
+        },
+        "otlpDefinitions": {
+          "type": "object",
+          "properties": {
+            "statistics": {
+              "$ref": "#/definitions/statisticsDefinition"
+            },
+            "percentiles": {
+              "$ref": "#/definitions/percentilesDefinition"
+            },
+            "relabel_configs": {
+              "$ref": "#/definitions/relabelConfigsDefinition"
+            },
+            "grpc_service_address": {
+              "description": "Address and port of the OTLP/gRPC server, the server is disabled if empty",
+              "type": "string",
+              "maxLength": 255
+            },
+            "http_service_address": {
+              "description": "Address and port of the OTLP/HTTP server, the server is disabled if empty",
+              "type": "string",
+              "maxLength": 255
+            },
+            "metrics_aggregation_interval": {
+              "$ref": "#/definitions/timeIntervalWithZeroDefinition"
             }
           },
           "additionalProperties": false
@@ -446,6 +549,9 @@ var schema = `{
         "ethtoolDefinitions": {
           "type": "object",
           "properties": {
+            "relabel_configs": {
+              "$ref": "#/definitions/relabelConfigsDefinition"
+            },
             "interface_include": {
               "type": "array",
               "items": {
@@ -524,6 +630,12 @@ var schema = `{
                     "type": "string",
                     "minLength": 1,
                     "maxLength": 256
+                  },
+                  "statistics": {
+                    "$ref": "#/definitions/statisticsDefinition"
+                  },
+                  "percentiles": {
+                    "$ref": "#/definitions/percentilesDefinition"
                   }
                 }
               }
@@ -545,6 +657,9 @@ var schema = `{
             },
             "windows_events": {
               "$ref": "#/definitions/logsDefinition/definitions/logsWindowsEventsDefinition"
+            },
+            "syslog": {
+              "$ref": "#/definitions/logsDefinition/definitions/logsSyslogDefinition"
             }
           },
           "minProperties": 1,
@@ -562,6 +677,24 @@ var schema = `{
               },
               "additionalProperties": false
             },
+            "emf": {
+              "type": "object",
+              "properties": {
+                "service_address": {
+                  "type": "string"
+                },
+                "http_service_address": {
+                  "description": "Address and port of the HTTP endpoint accepting single or newline delimited EMF documents on /emf",
+                  "type": "string",
+                  "minLength": 1
+                },
+                "metric_extraction": {
+                  "description": "Whether the agent extracts the metrics of the EMF documents and publishes them with PutMetricData, along with or instead of the documents",
+                  "type": "string",
+                  "enum": ["disabled", "logs_and_metrics", "metrics_only"]
+                }
+              }
+            },
             "kubernetes": {
               "type": "object",
               "properties": {
@@ -572,6 +705,62 @@ var schema = `{
                 },
                 "metrics_collection_interval": {
                   "$ref": "#/definitions/timeIntervalDefinition"
+                },
+                "leader_election": {
+                  "description": "The leader election of the agents which collects the cluster level metrics from the api server",
+                  "type": "object",
+                  "properties": {
+                    "lock_type": {
+                      "description": "The resource used as the lock, the leases of coordination.k8s.io are lighter on the api server than the config maps",
+                      "type": "string",
+                      "enum": ["configmaps", "leases"]
+                    },
+                    "lease_duration": {
+                      "description": "How long in seconds the other agents wait before taking over the lock of a leader which stopped renewing it",
+                      "type": "integer",
+                      "minimum": 1
+                    },
+                    "renew_deadline": {
+                      "description": "How long in seconds the leader keeps retrying to renew the lock before it gives up the leadership",
+                      "type": "integer",
+                      "minimum": 1
+                    },
+                    "retry_period": {
+                      "description": "How long in seconds the agents wait between the attempts to acquire or renew the lock",
+                      "type": "integer",
+                      "minimum": 1
+                    }
+                  },
+                  "additionalProperties": false
+                },
+                "events": {
+                  "description": "Publishes the Kubernetes events to CloudWatch Logs, the events are watched by the agent elected as the leader",
+                  "type": "object",
+                  "properties": {
+                    "log_group_name": {
+                      "$ref": "#/definitions/logsDefinition/definitions/logGroupNameDefinition"
+                    },
+                    "log_stream_name": {
+                      "$ref": "#/definitions/logsDefinition/definitions/logStreamNameDefinition"
+                    },
+                    "namespaces": {
+                      "$ref": "#/definitions/logsDefinition/definitions/k8sEventsFilterDefinition"
+                    },
+                    "reasons": {
+                      "$ref": "#/definitions/logsDefinition/definitions/k8sEventsFilterDefinition"
+                    },
+                    "event_types": {
+                      "description": "Only the events of these types are published",
+                      "type": "array",
+                      "items": {
+                        "type": "string",
+                        "enum": ["Normal", "Warning"]
+                      },
+                      "minItems": 1,
+                      "uniqueItems": true
+                    }
+                  },
+                  "additionalProperties": false
                 }
               },
               "additionalProperties": true
@@ -588,11 +777,24 @@ var schema = `{
                 "prometheus_config_path": {
                   "type": "string"
                 },
+                "histogram_output": {
+                  "description": "How the buckets of Prometheus histograms are reported, either as the values and counts of the bucket midpoints or as a distribution",
+                  "type": "string",
+                  "enum": ["values_counts", "distribution"]
+                },
+                "untyped_metrics": {
+                  "description": "How Prometheus untyped metrics are handled, they are dropped by default",
+                  "type": "string",
+                  "enum": ["drop", "gauge", "counter"]
+                },
                 "emf_processor": {
                   "$ref": "#/definitions/emfProcessorDefinition"
                 },
                 "ecs_service_discovery": {
                   "$ref": "#/definitions/ecsServiceDiscoveryDefinition"
+                },
+                "kubernetes_service_discovery": {
+                  "$ref": "#/definitions/k8sServiceDiscoveryDefinition"
                 }
               },
               "additionalProperties": false
@@ -614,6 +816,24 @@ var schema = `{
         "endpoint_override": {
           "description": "The override endpoint to use to access cloudwatch logs",
           "$ref": "#/definitions/endpointOverrideDefinition"
+        },
+        "spool": {
+          "description": "On-disk spool which keeps log events until they are accepted by CloudWatch Logs",
+          "type": "object",
+          "properties": {
+            "path": {
+              "description": "Directory of the spool",
+              "type": "string",
+              "minLength": 1,
+              "maxLength": 4096
+            },
+            "max_size_mb": {
+              "description": "Max size of the spool of each log group/stream, unit is MB",
+              "type": "integer",
+              "minimum": 1
+            }
+          },
+          "additionalProperties": false
         }
       },
       "additionalProperties": false,
@@ -630,6 +850,247 @@ var schema = `{
         }
       ],
       "definitions": {
+        "logProcessorsDefinition": {
+          "type": "array",
+          "descriptions": "The processing chain applied in order on the log events before they are published",
+          "items": {
+            "type": "object",
+            "properties": {
+              "type": {
+                "type": "string",
+                "enum": [
+                  "drop",
+                  "mask",
+                  "add_fields",
+                  "json"
+                ]
+              },
+              "pattern": {
+                "description": "drop and mask: regex matched against the log event",
+                "type": "string",
+                "minLength": 1,
+                "maxLength": 4096
+              },
+              "preset": {
+                "description": "mask: predefined pattern used when pattern is not set",
+                "type": "string",
+                "enum": [
+                  "credit_card",
+                  "aws_access_key",
+                  "aws_secret_key"
+                ]
+              },
+              "replacement": {
+                "description": "mask: replacement of the matched content",
+                "type": "string",
+                "maxLength": 4096
+              },
+              "fields": {
+                "description": "add_fields: static fields added to the log event",
+                "type": "object",
+                "additionalProperties": {
+                  "type": "string"
+                },
+                "minProperties": 1
+              },
+              "rename_keys": {
+                "description": "json: keys of the JSON log event to be renamed",
+                "type": "object",
+                "additionalProperties": {
+                  "type": "string"
+                }
+              },
+              "remove_keys": {
+                "description": "json: keys of the JSON log event to be removed",
+                "type": "array",
+                "items": {
+                  "type": "string"
+                }
+              }
+            },
+            "required": [
+              "type"
+            ],
+            "additionalProperties": false
+          }
+        },
+        "logMetricExtractorsDefinition": {
+          "type": "array",
+          "descriptions": "The metrics derived from the log events, published through the metrics section",
+          "items": {
+            "type": "object",
+            "properties": {
+              "metric_name": {
+                "type": "string",
+                "minLength": 1,
+                "maxLength": 255
+              },
+              "metric_type": {
+                "type": "string",
+                "enum": [
+                  "counter",
+                  "gauge",
+                  "timing"
+                ]
+              },
+              "pattern": {
+                "description": "regex selecting the log events, its named groups can be referenced by value and dimensions",
+                "type": "string",
+                "minLength": 1,
+                "maxLength": 4096
+              },
+              "value": {
+                "description": "named group of the pattern or JSON path like $.latency of the metric value",
+                "type": "string",
+                "minLength": 1,
+                "maxLength": 4096
+              },
+              "unit": {
+                "type": "string",
+                "minLength": 1,
+                "maxLength": 255
+              },
+              "dimensions": {
+                "description": "dimension names to named groups of the pattern or JSON paths",
+                "type": "object",
+                "additionalProperties": {
+                  "type": "string",
+                  "minLength": 1
+                },
+                "maxProperties": 10
+              }
+            },
+            "required": [
+              "metric_name"
+            ],
+            "additionalProperties": false
+          }
+        },
+        "logParserDefinition": {
+          "type": "object",
+          "descriptions": "Parses the log events into fields, their timestamp can be taken from a field and they can be published as JSON",
+          "properties": {
+            "type": {
+              "type": "string",
+              "enum": [
+                "json",
+                "logfmt",
+                "regex",
+                "syslog_rfc3164",
+                "syslog_rfc5424",
+                "clf"
+              ]
+            },
+            "pattern": {
+              "description": "regex whose named groups are the fields of the log events, required by the regex parser",
+              "type": "string",
+              "minLength": 1,
+              "maxLength": 4096
+            },
+            "timestamp_field": {
+              "type": "string",
+              "minLength": 1,
+              "maxLength": 255
+            },
+            "timestamp_format": {
+              "type": "string",
+              "minLength": 1,
+              "maxLength": 4096
+            },
+            "output_format": {
+              "type": "string",
+              "enum": [
+                "raw",
+                "json"
+              ]
+            }
+          },
+          "required": [
+            "type"
+          ],
+          "additionalProperties": false
+        },
+        "logsSyslogDefinition": {
+          "type": "object",
+          "descriptions": "Specifies the syslog listeners",
+          "properties": {
+            "collect_list": {
+              "type": "array",
+              "items": {
+                "type": "object",
+                "properties": {
+                  "service_address": {
+                    "description": "The protocol and address of the listener, e.g. udp://:514 or tcp://:6514",
+                    "type": "string",
+                    "pattern": "^(udp|udp4|udp6|tcp|tcp4|tcp6)://.+$"
+                  },
+                  "format": {
+                    "type": "string",
+                    "enum": [
+                      "rfc3164",
+                      "rfc5424"
+                    ]
+                  },
+                  "log_group_name": {
+                    "description": "The log group name, its {json:$.hostname}, {json:$.appname}... placeholders are resolved from the fields of each message",
+                    "type": "string",
+                    "minLength": 1,
+                    "maxLength": 512
+                  },
+                  "log_stream_name": {
+                    "type": "string",
+                    "minLength": 1,
+                    "maxLength": 512
+                  },
+                  "retention_in_days": {
+                    "$ref": "#/definitions/logsDefinition/definitions/retentionInDaysDefinition"
+                  },
+                  "output_format": {
+                    "type": "string",
+                    "enum": [
+                      "raw",
+                      "json"
+                    ]
+                  },
+                  "timezone": {
+                    "type": "string",
+                    "enum": [
+                      "UTC",
+                      "Local"
+                    ]
+                  },
+                  "tls_cert": {
+                    "type": "string",
+                    "minLength": 1
+                  },
+                  "tls_key": {
+                    "type": "string",
+                    "minLength": 1
+                  },
+                  "tls_allowed_cacerts": {
+                    "type": "array",
+                    "items": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "minItems": 1
+                  }
+                },
+                "required": [
+                  "service_address",
+                  "log_group_name"
+                ],
+                "additionalProperties": false
+              },
+              "minItems": 1,
+              "uniqueItems": true
+            }
+          },
+          "required": [
+            "collect_list"
+          ],
+          "additionalProperties": false
+        },
         "logsFilesDefinition": {
           "type": "object",
           "descriptions": "Specifies the log files to be collected",
@@ -675,16 +1136,34 @@ var schema = `{
                   "auto_removal": {
                     "type": "boolean"
                   },
+                  "collect_compressed": {
+                    "type": "boolean"
+                  },
                   "blacklist": {
                     "type": "string",
                     "minLength": 1,
                     "maxLength": 4096
                   },
+                  "file_path_regex": {
+                    "description": "The regex matched against the file names, its named or numbered groups are referenced by the {file_path:<group>} placeholders of the log group and stream names",
+                    "type": "string",
+                    "minLength": 1,
+                    "maxLength": 4096
+                  },
                   "publish_multi_logs": {
                     "type": "boolean"
                   },
                   "retention_in_days": {
                     "$ref": "#/definitions/logsDefinition/definitions/retentionInDaysDefinition"
+                  },
+                  "processors": {
+                    "$ref": "#/definitions/logsDefinition/definitions/logProcessorsDefinition"
+                  },
+                  "metric_extractors": {
+                    "$ref": "#/definitions/logsDefinition/definitions/logMetricExtractorsDefinition"
+                  },
+                  "parser": {
+                    "$ref": "#/definitions/logsDefinition/definitions/logParserDefinition"
                   }
                 },
                 "required": [
@@ -695,6 +1174,14 @@ var schema = `{
               "minItems": 1,
               "maxItems": 16384,
               "uniqueItems": true
+            },
+            "file_watcher": {
+              "description": "How the new log files and the changes of the log files are detected, inotify falls back to polling on the file systems like NFS",
+              "type": "string",
+              "enum": [
+                "polling",
+                "inotify"
+              ]
             }
           },
           "required": [
@@ -780,6 +1267,15 @@ var schema = `{
           "minLength": 1,
           "maxLength": 512
         },
+        "k8sEventsFilterDefinition": {
+          "type": "array",
+          "items": {
+            "type": "string",
+            "minLength": 1
+          },
+          "minItems": 1,
+          "uniqueItems": true
+        },
         "retentionInDaysDefinition": {
           "type": "integer",
           "enum": [
@@ -830,6 +1326,74 @@ var schema = `{
         "maxLength": 255
       }
     },
+    "statisticsDefinition": {
+      "description": "The statistics published for the aggregated metrics",
+      "type": "string",
+      "enum": [
+        "distribution",
+        "statistic_set",
+        "percentiles"
+      ]
+    },
+    "percentilesDefinition": {
+      "description": "The percentiles published for the aggregated metrics with the percentiles statistics",
+      "type": "array",
+      "items": {
+        "type": "number",
+        "minimum": 0,
+        "exclusiveMinimum": true,
+        "maximum": 100
+      },
+      "minItems": 1,
+      "uniqueItems": true
+    },
+    "relabelConfigsDefinition": {
+      "description": "Prometheus style relabel rules applied to the metrics of this section",
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "properties": {
+          "source_labels": {
+            "type": "array",
+            "items": {
+              "type": "string",
+              "minLength": 1,
+              "maxLength": 255
+            }
+          },
+          "separator": {
+            "type": "string"
+          },
+          "regex": {
+            "type": "string"
+          },
+          "modulus": {
+            "type": "integer",
+            "minimum": 1
+          },
+          "target_label": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 255
+          },
+          "replacement": {
+            "type": "string"
+          },
+          "action": {
+            "type": "string",
+            "enum": [
+              "replace",
+              "keep",
+              "drop",
+              "labeldrop",
+              "hashmod"
+            ]
+          }
+        },
+        "additionalProperties": false
+      }
+    },
     "credentialsDefinition": {
       "type": "object",
       "properties": {
@@ -957,6 +1521,25 @@ var schema = `{
         }
       }
     },
+    "k8sServiceDiscoveryDefinition": {
+      "type": "object",
+      "descriptions": "Define Kubernetes pod service discovery for Prometheus based on the prometheus.io annotations",
+      "properties": {
+        "sd_frequency": {
+          "description": "Kubernetes service discovery frequency",
+          "type": "string"
+        },
+        "sd_result_file": {
+          "description": "Kubernetes service discovery result file full path",
+          "type": "string"
+        },
+        "sd_job_name": {
+          "description": "Service discovery result job name",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
     "emfProcessorDefinition": {
       "type": "object",
       "descriptions": "Define EMF Processor to set metric filter",
@@ -11,6 +11,14 @@ import (
 //ErrorMessages will provide detail error messages to user
 var ErrorMessages = []string{}
 var InfoMessages = []string{}
+
+//ErrorDetails are the ErrorMessages with the path of the error kept apart, they are reported by the validate mode
+var ErrorDetails = []ErrorDetail{}
+
+type ErrorDetail struct {
+	Path    string
+	Message string
+}
 var ValidRetentionInDays = []string{"-1", "1", "3", "5", "7", "14", "30", "60", "90", "120", "150", "180", "365", "400", "545", "731", "1827", "3653"}
 
 //IsValid checks whether the mandatory config parameter is valid
@@ -23,6 +31,7 @@ func IsValid(input interface{}, key string, path string) bool {
 		//errMessage := "The path of the error is : " + path + "|" + "Errors :" + err
 		errMessage := fmt.Sprintf("The path of the error is : %s | Errors : %s", path, err)
 		ErrorMessages = append(ErrorMessages, errMessage)
+		ErrorDetails = append(ErrorDetails, ErrorDetail{Path: path, Message: err})
 		return false
 	}
 	//Check if the value for the key is nil
@@ -31,6 +40,7 @@ func IsValid(input interface{}, key string, path string) bool {
 		//errMessage := "The path of the error is : " + path + "|" + "Errors :" + err
 		errMessage := fmt.Sprintf("The path of the error is : %s | Errors : %s", path, err)
 		ErrorMessages = append(ErrorMessages, errMessage)
+		ErrorDetails = append(ErrorDetails, ErrorDetail{Path: path, Message: err})
 		return false
 	}
 	return true
@@ -44,6 +54,7 @@ func AddErrorMessages(path, message string) {
 		errorMessage = fmt.Sprintf("Under path : %s | Error : %s", path, message)
 	}
 	ErrorMessages = append(ErrorMessages, errorMessage)
+	ErrorDetails = append(ErrorDetails, ErrorDetail{Path: path, Message: message})
 }
 
 func AddInfoMessages(path, message string) {
@@ -64,6 +75,7 @@ func IsTranslateSuccess() bool {
 func ResetMessages() {
 	ErrorMessages = make([]string, 0)
 	InfoMessages = make([]string, 0)
+	ErrorDetails = make([]ErrorDetail, 0)
 }
 
 // ValidDays represents the valid possible values for retentionInDays.
@@ -16,8 +16,9 @@ import (
 )
 
 const (
-	userAgentKey = "user_agent"
-	debugKey     = "debug"
+	userAgentKey     = "user_agent"
+	debugKey         = "debug"
+	healthAddressKey = "health_address"
 )
 
 func ToEnvConfig(jsonConfigValue map[string]interface{}) []byte {
@@ -36,6 +37,10 @@ func ToEnvConfig(jsonConfigValue map[string]interface{}) []byte {
 		if isDebug, ok := agentMap[debugKey].(bool); ok && isDebug {
 			envVars[envconfig.CWAGENT_LOG_LEVEL] = "DEBUG"
 		}
+		// Set CWAGENT_HEALTH_ADDR to env config to start the health endpoint if specified in agent section
+		if healthAddress, ok := agentMap[healthAddressKey].(string); ok && healthAddress != "" {
+			envVars[envconfig.CWAGENT_HEALTH_ADDR] = healthAddress
+		}
 	}
 
 	proxy := util.GetHttpProxy(context.CurrentContext().Proxy())
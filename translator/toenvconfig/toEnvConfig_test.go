@@ -189,3 +189,12 @@ func resetContext() {
 
 	os.Setenv("ProgramData", "c:\\ProgramData")
 }
+
+func TestHealthAddress(t *testing.T) {
+	resetContext()
+	var input map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"agent": {"health_address": ":8099"}}`), &input))
+	var actualEnvVars = make(map[string]string)
+	assert.NoError(t, json.Unmarshal(ToEnvConfig(input), &actualEnvVars))
+	assert.Equal(t, ":8099", actualEnvVars["CWAGENT_HEALTH_ADDR"])
+}
@@ -100,6 +100,7 @@ func TestPrometheusConfig(t *testing.T) {
 	os.Setenv(config.HOST_NAME, "host_name_from_env")
 	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/prometheus_config_linux.json"), "./sampleConfig/prometheus_config_linux.conf", "linux")
 	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/prometheus_config_windows.json"), "./sampleConfig/prometheus_config_windows.conf", "windows")
+	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/prometheus_k8s_config_linux.json"), "./sampleConfig/prometheus_k8s_config_linux.conf", "linux")
 	os.Unsetenv(config.HOST_NAME)
 }
 
@@ -151,6 +152,12 @@ func TestDeltaConfigLinux(t *testing.T) {
 	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/delta_config_linux.json"), "./sampleConfig/delta_config_linux.conf", "darwin")
 }
 
+func TestRelabelConfigLinux(t *testing.T) {
+	resetContext()
+	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/relabel_config_linux.json"), "./sampleConfig/relabel_config_linux.conf", "linux")
+	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/relabel_config_linux.json"), "./sampleConfig/relabel_config_linux.conf", "darwin")
+}
+
 func TestCsmServiceAdressesConfig(t *testing.T) {
 	resetContext()
 	checkIfTranslateSucceed(t, ReadFromFile("./sampleConfig/csm_service_addresses.json"), "./sampleConfig/csm_service_addresses_windows.conf", "windows")
@@ -99,8 +99,12 @@ func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interfa
 			translator.SetMetricPathForOneInput(result, SectionKey, "k8sapiserver", []string{"k8sdecorator"})
 		}
 
+		// the emf documents only go through the emfextractor processor, if the metric extraction is enabled
 		if _, ok = inputs["socket_listener"]; ok {
-			translator.SetMetricPathForOneInput(result, SectionKey, "socket_listener", []string{})
+			translator.SetMetricPathForOneInput(result, SectionKey, "socket_listener", []string{"emfextractor"})
+		}
+		if _, ok = inputs["emf_http"]; ok {
+			translator.SetMetricPathForOneInput(result, SectionKey, "emf_http", []string{"emfextractor"})
 		}
 
 		returnKey = SectionKey
@@ -462,6 +462,50 @@ func TestAutoRemoval(t *testing.T) {
 	assert.Equal(t, expectVal, val)
 }
 
+func TestCollectCompressed(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"path1",
+				"collect_compressed": true
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":          "path1",
+		"from_beginning":     true,
+		"pipe":               false,
+		"retention_in_days":  -1,
+		"collect_compressed": true,
+	}}
+	assert.Equal(t, expectVal, val)
+
+	e = json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"path1"
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val = f.ApplyRule(input)
+	expectVal = []interface{}{map[string]interface{}{
+		"file_path":         "path1",
+		"from_beginning":    true,
+		"pipe":              false,
+		"retention_in_days": -1,
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
 func TestFileConfigOutputFile(t *testing.T) {
 	dir, err := ioutil.TempDir("", "")
 	assert.NoError(t, err)
@@ -578,3 +622,111 @@ func TestPublishMultiLogs_WithBlackList(t *testing.T) {
 	}}
 	assert.Equal(t, expectVal, val)
 }
+
+func TestFilePathRegex(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"/var/log/apps/*/*.log",
+				"file_path_regex": "/var/log/apps/(?P<app>[^/]+)/",
+				"log_group_name": "/apps/{file_path:app}",
+				"log_stream_name": "{ec2_tag:Name|untagged}/{json:$.service}"
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":         "/var/log/apps/*/*.log",
+		"file_path_regex":   "/var/log/apps/(?P<app>[^/]+)/",
+		"from_beginning":    true,
+		"log_group_name":    "/apps/{file_path:app}",
+		"log_stream_name":   "{ec2_tag:Name|untagged}/{json:$.service}",
+		"pipe":              false,
+		"retention_in_days": -1,
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
+func TestProcessors(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"collect_list":[{"file_path":"path1","log_group_name":"group1",
+            "processors":[{"type":"drop","pattern":"DEBUG"},
+                          {"type":"mask","preset":"credit_card","replacement":"****"},
+                          {"type":"add_fields","fields":{"env":"prod"}},
+                          {"type":"json","rename_keys":{"msg":"message"},"remove_keys":["password"]}]}]}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":         "path1",
+		"from_beginning":    true,
+		"log_group_name":    "group1",
+		"pipe":              false,
+		"retention_in_days": -1,
+		"processors": []interface{}{
+			map[string]interface{}{"type": "drop", "pattern": "DEBUG"},
+			map[string]interface{}{"type": "mask", "preset": "credit_card", "replacement": "****"},
+			map[string]interface{}{"type": "add_fields", "fields": map[string]interface{}{"env": "prod"}},
+			map[string]interface{}{"type": "json", "rename_keys": map[string]interface{}{"msg": "message"}, "remove_keys": []interface{}{"password"}},
+		},
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
+func TestParser(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"collect_list":[{"file_path":"path1","log_group_name":"group1",
+            "parser":{"type":"regex","pattern":"^(?P<time>\\S+ \\S+) (?P<level>\\w+)","timestamp_field":"time",
+                      "timestamp_format":"%Y-%m-%d %H:%M:%S","output_format":"json"}}]}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":         "path1",
+		"from_beginning":    true,
+		"log_group_name":    "group1",
+		"pipe":              false,
+		"retention_in_days": -1,
+		"parser": map[string]interface{}{
+			"type":             "regex",
+			"pattern":          "^(?P<time>\\S+ \\S+) (?P<level>\\w+)",
+			"timestamp_field":  "time",
+			"timestamp_layout": "2006-01-02 15:04:05",
+			"output_format":    "json",
+		},
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
+func TestMetricExtractors(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"collect_list":[{"file_path":"path1","log_group_name":"group1",
+            "metric_extractors":[{"metric_name":"errors","pattern":"ERROR (?P<code>\\d+)","dimensions":{"Code":"code"}},
+                                 {"metric_name":"latency","metric_type":"timing","value":"$.latency","unit":"Milliseconds"}]}]}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":         "path1",
+		"from_beginning":    true,
+		"log_group_name":    "group1",
+		"pipe":              false,
+		"retention_in_days": -1,
+		"metric_extractors": []interface{}{
+			map[string]interface{}{"metric_name": "errors", "pattern": "ERROR (?P<code>\\d+)", "dimensions": map[string]interface{}{"Code": "code"}},
+			map[string]interface{}{"metric_name": "latency", "metric_type": "timing", "value": "$.latency", "unit": "Milliseconds"},
+		},
+	}}
+	assert.Equal(t, expectVal, val)
+}
@@ -0,0 +1,32 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const CollectCompressedSectionKey = "collect_compressed"
+
+type CollectCompressed struct {
+}
+
+func (r *CollectCompressed) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(CollectCompressedSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = CollectCompressedSectionKey
+	var ok bool
+	if returnVal, ok = returnVal.(bool); !ok {
+		returnVal = false
+	}
+	return
+}
+
+func init() {
+	l := new(CollectCompressed)
+	r := []Rule{l}
+	RegisterRule(CollectCompressedSectionKey, r)
+}
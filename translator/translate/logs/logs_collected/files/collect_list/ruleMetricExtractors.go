@@ -0,0 +1,53 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const MetricExtractorsSectionKey = "metric_extractors"
+
+var metricExtractorKeys = []string{"metric_name", "metric_type", "pattern", "value", "unit", "dimensions"}
+
+type MetricExtractors struct {
+}
+
+func (m *MetricExtractors) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[MetricExtractorsSectionKey]
+	if !ok {
+		return
+	}
+	configs, ok := val.([]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+MetricExtractorsSectionKey, "metric_extractors should be an array")
+		return
+	}
+
+	res := []interface{}{}
+	for _, c := range configs {
+		config, ok := c.(map[string]interface{})
+		if !ok {
+			translator.AddErrorMessages(GetCurPath()+MetricExtractorsSectionKey, "each metric extractor should be an object")
+			return
+		}
+		extractor := map[string]interface{}{}
+		for _, k := range metricExtractorKeys {
+			if v, ok := config[k]; ok {
+				extractor[k] = v
+			}
+		}
+		res = append(res, extractor)
+	}
+	returnKey = MetricExtractorsSectionKey
+	returnVal = res
+	return
+}
+
+func init() {
+	m := new(MetricExtractors)
+	r := []Rule{m}
+	RegisterRule(MetricExtractorsSectionKey, r)
+}
@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const ParserSectionKey = "parser"
+
+// The keys of the parser config copied as they are, the timestamp_format is translated into the timestamp_layout
+var parserKeys = []string{"type", "pattern", "timestamp_field", "output_format"}
+
+type Parser struct {
+}
+
+func (p *Parser) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m := input.(map[string]interface{})
+	val, ok := m[ParserSectionKey]
+	if !ok {
+		return
+	}
+	config, ok := val.(map[string]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+ParserSectionKey, "parser should be an object")
+		return
+	}
+
+	res := map[string]interface{}{}
+	for _, k := range parserKeys {
+		if v, ok := config[k]; ok {
+			res[k] = v
+		}
+	}
+	if format, ok := config["timestamp_format"].(string); ok {
+		res["timestamp_layout"] = checkAndReplace(format, TimeFormatMap)
+	}
+	returnKey = ParserSectionKey
+	returnVal = res
+	return
+}
+
+func init() {
+	p := new(Parser)
+	r := []Rule{p}
+	RegisterRule(ParserSectionKey, r)
+}
@@ -0,0 +1,54 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const ProcessorsSectionKey = "processors"
+
+// The keys of a processor config, the processor type decides which ones are used
+var processorKeys = []string{"type", "pattern", "preset", "replacement", "fields", "rename_keys", "remove_keys"}
+
+type Processors struct {
+}
+
+func (p *Processors) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m := input.(map[string]interface{})
+	val, ok := m[ProcessorsSectionKey]
+	if !ok {
+		return
+	}
+	configs, ok := val.([]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+ProcessorsSectionKey, "processors should be an array")
+		return
+	}
+
+	res := []interface{}{}
+	for _, c := range configs {
+		config, ok := c.(map[string]interface{})
+		if !ok {
+			translator.AddErrorMessages(GetCurPath()+ProcessorsSectionKey, "each processor should be an object")
+			return
+		}
+		processor := map[string]interface{}{}
+		for _, k := range processorKeys {
+			if v, ok := config[k]; ok {
+				processor[k] = v
+			}
+		}
+		res = append(res, processor)
+	}
+	returnKey = ProcessorsSectionKey
+	returnVal = res
+	return
+}
+
+func init() {
+	p := new(Processors)
+	r := []Rule{p}
+	RegisterRule(ProcessorsSectionKey, r)
+}
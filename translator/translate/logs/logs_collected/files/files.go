@@ -15,7 +15,13 @@ var ChildRule = map[string]translator.Rule{}
 type Files struct {
 }
 
-const SectionKey = "files"
+const (
+	SectionKey = "files"
+
+	metricExtractorsKey = "metric_extractors"
+	metricPathTagKey    = "metricPath"
+	metricsSectionKey   = "metrics"
+)
 
 func GetCurPath() string {
 	return parent.GetCurPath() + SectionKey + "/"
@@ -44,7 +50,11 @@ func (f *Files) ApplyRule(input interface{}) (returnKey string, returnVal interf
 
 		// generate tail config only if file_config exists
 		tailInfo := map[string]interface{}{}
-		if _, ok := tailConfig["file_config"]; ok {
+		if fileConfigs, ok := tailConfig["file_config"]; ok {
+			if hasMetricExtractors(fileConfigs) {
+				// route the metrics extracted from the log events to the cloudwatch output instead of the logs one
+				tailConfig["metric_tags"] = map[string]interface{}{metricPathTagKey: metricsSectionKey}
+			}
 			tailInfo["logfile"] = []interface{}{tailConfig}
 			returnKey = "inputs"
 			returnVal = tailInfo
@@ -53,6 +63,21 @@ func (f *Files) ApplyRule(input interface{}) (returnKey string, returnVal interf
 	return
 }
 
+func hasMetricExtractors(fileConfigs interface{}) bool {
+	configs, ok := fileConfigs.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, c := range configs {
+		if config, ok := c.(map[string]interface{}); ok {
+			if _, ok := config[metricExtractorsKey]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 var MergeRuleMap = map[string]mergeJsonRule.MergeRule{}
 
 func (f *Files) Merge(source map[string]interface{}, result map[string]interface{}) {
@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package files
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const FileWatcherKey = "file_watcher"
+
+type FileWatcher struct {
+}
+
+//FileWatcher is only translated when it is set, the logfile plugin polls the files by default
+func (f *FileWatcher) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(FileWatcherKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = FileWatcherKey
+	return
+}
+
+func init() {
+	f := new(FileWatcher)
+	RegisterRule(FileWatcherKey, f)
+}
@@ -0,0 +1,20 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWatcher(t *testing.T) {
+	f := new(FileWatcher)
+	key, val := f.ApplyRule(map[string]interface{}{"file_watcher": "inotify"})
+	assert.Equal(t, "file_watcher", key)
+	assert.Equal(t, "inotify", val)
+
+	key, _ = f.ApplyRule(map[string]interface{}{})
+	assert.Equal(t, "", key)
+}
@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/jsonconfig/mergeJsonRule"
+	"github.com/aws/amazon-cloudwatch-agent/translator/jsonconfig/mergeJsonUtil"
+	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+//
+//   "syslog": {
+//       "collect_list": [
+//           {
+//               "service_address": "udp://:514",
+//               "log_group_name": "/syslog/{json:$.appname|system}"
+//           }
+//       ]
+//   }
+//
+const (
+	SectionKey           = "syslog"
+	CollectListKey       = "collect_list"
+	ServiceAddressKey    = "service_address"
+	syslogInput          = "syslog"
+	syslogDestination    = "cloudwatchlogs"
+	syslogDestinationKey = "destination"
+	RetentionInDaysKey   = "retention_in_days"
+)
+
+// The keys of a listener config copied as they are, the retention_in_days is converted to an integer
+var listenerKeys = []string{ServiceAddressKey, "format", "log_group_name", "log_stream_name", "output_format",
+	"timezone", "tls_cert", "tls_key", "tls_allowed_cacerts"}
+
+type Syslog struct {
+}
+
+func GetCurPath() string {
+	return parent.GetCurPath() + SectionKey + "/"
+}
+
+// ApplyRule translates each listener of the collect_list to a syslog input
+func (s *Syslog) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	section, ok := im[SectionKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	configs, ok := section[CollectListKey].([]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+CollectListKey, "collect_list should be an array")
+		return
+	}
+
+	res := []interface{}{}
+	for _, c := range configs {
+		if _, ok := c.(map[string]interface{}); !ok {
+			translator.AddErrorMessages(GetCurPath()+CollectListKey, "each syslog listener should be an object")
+			return
+		}
+		listener := map[string]interface{}{syslogDestinationKey: syslogDestination}
+		util.SetWithSameKeyIfFound(c, listenerKeys, listener)
+		_, listener[RetentionInDaysKey] = translator.DefaultRetentionInDaysCase(RetentionInDaysKey, float64(-1), c)
+		res = append(res, listener)
+	}
+	returnKey = "inputs"
+	returnVal = map[string]interface{}{syslogInput: res}
+	return
+}
+
+var MergeRuleMap = map[string]mergeJsonRule.MergeRule{}
+
+func (s *Syslog) Merge(source map[string]interface{}, result map[string]interface{}) {
+	mergeJsonUtil.MergeMap(source, result, SectionKey, MergeRuleMap, GetCurPath())
+}
+
+// collectList merges the listeners of the collect_lists of the json configs
+type collectList struct {
+}
+
+func (c *collectList) Merge(source map[string]interface{}, result map[string]interface{}) {
+	mergeJsonUtil.MergeList(source, result, CollectListKey)
+}
+
+func init() {
+	s := new(Syslog)
+	parent.RegisterLinuxRule(SectionKey, s)
+	parent.RegisterDarwinRule(SectionKey, s)
+	parent.RegisterWindowsRule(SectionKey, s)
+	parent.MergeRuleMap[SectionKey] = s
+	MergeRuleMap[CollectListKey] = new(collectList)
+}
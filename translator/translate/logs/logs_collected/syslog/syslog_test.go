@@ -0,0 +1,55 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package syslog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRule(t *testing.T) {
+	s := new(Syslog)
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"syslog":{"collect_list":[
+            {"service_address":"udp://:514","log_group_name":"/syslog/{json:$.appname|system}"},
+            {"service_address":"tcp://:6514","log_group_name":"secure","log_stream_name":"{json:$.hostname}/{json:$.appname}",
+             "format":"rfc5424","output_format":"json","retention_in_days":30,
+             "tls_cert":"/etc/ssl/syslog.pem","tls_key":"/etc/ssl/syslog.key","tls_allowed_cacerts":["/etc/ssl/ca.pem"]}]}}`), &input))
+
+	key, val := s.ApplyRule(input)
+	assert.Equal(t, "inputs", key)
+	assert.Equal(t, map[string]interface{}{
+		"syslog": []interface{}{
+			map[string]interface{}{
+				"destination":       "cloudwatchlogs",
+				"service_address":   "udp://:514",
+				"log_group_name":    "/syslog/{json:$.appname|system}",
+				"retention_in_days": -1,
+			},
+			map[string]interface{}{
+				"destination":         "cloudwatchlogs",
+				"service_address":     "tcp://:6514",
+				"log_group_name":      "secure",
+				"log_stream_name":     "{json:$.hostname}/{json:$.appname}",
+				"format":              "rfc5424",
+				"output_format":       "json",
+				"retention_in_days":   30,
+				"tls_cert":            "/etc/ssl/syslog.pem",
+				"tls_key":             "/etc/ssl/syslog.key",
+				"tls_allowed_cacerts": []interface{}{"/etc/ssl/ca.pem"},
+			},
+		},
+	}, val)
+}
+
+func TestApplyRule_NoSyslog(t *testing.T) {
+	s := new(Syslog)
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"files":{"collect_list":[{"file_path":"path1"}]}}`), &input))
+	key, _ := s.ApplyRule(input)
+	assert.Equal(t, "", key)
+}
@@ -197,3 +197,39 @@ func TestLogs_EndpointOverride(t *testing.T) {
 
 	ctx.SetMode(config.ModeEC2) //reset back to default mode
 }
+
+func TestLogs_Spool(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"logs":{"spool":{"max_size_mb":512}}}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+
+	ctx := context.CurrentContext()
+	ctx.SetMode(config.ModeOnPrem)
+
+	hostname, _ := os.Hostname()
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"log_stream_name":      hostname,
+					"force_flush_interval": "5s",
+					"spool_dir":            "/opt/aws/amazon-cloudwatch-agent/logs/spool",
+					"spool_max_size_mb":    512,
+					"tagexclude":           []string{"metricPath"},
+					"tagpass":              map[string][]string{"metricPath": {"logs"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, actual, "Expected to be equal")
+
+	ctx.SetMode(config.ModeEC2) //reset back to default mode
+}
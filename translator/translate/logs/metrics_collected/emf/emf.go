@@ -46,7 +46,7 @@ func (obj *EMF) ApplyRule(input interface{}) (returnKey string, returnVal interf
 	} else {
 		//If exists, process it
 		//Check if there are some config entry with rules applied
-		if sectionMap, ok := m[SectionKey].(map[string]interface{}); ok && len(sectionMap) == 0 {
+		if sectionMap, ok := m[SectionKey].(map[string]interface{}); ok && !isSocketConfigured(sectionMap) {
 			// not configured
 			defaultEndpointSuffix := "://127.0.0.1:25888"
 			if context.CurrentContext().RunInContainer() {
@@ -74,6 +74,17 @@ func (obj *EMF) ApplyRule(input interface{}) (returnKey string, returnVal interf
 	return
 }
 
+// isSocketConfigured reports whether the section has any key of the socket listener, the http_service_address
+// is for the emf_http input and the metric_extraction for the emfextractor processor.
+func isSocketConfigured(sectionMap map[string]interface{}) bool {
+	for k := range sectionMap {
+		if k != SectionKeyHTTPServiceAddress && k != SectionKeyMetricExtraction {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	obj := new(EMF)
 	parent.RegisterLinuxRule(SectionKey, obj)
@@ -0,0 +1,65 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/metrics_collected"
+)
+
+//
+//   "emf" : {
+//       "metric_extraction": "logs_and_metrics"
+//   }
+//
+const (
+	SectionKeyMetricExtraction = "metric_extraction"
+	emfExtractorProcessor      = "emfextractor"
+
+	MetricExtractionDisabled       = "disabled"
+	MetricExtractionLogsAndMetrics = "logs_and_metrics"
+	MetricExtractionMetricsOnly    = "metrics_only"
+)
+
+type EMFExtraction struct {
+}
+
+// ApplyRule adds the emfextractor processor when the metric extraction is enabled, the extracted metrics are routed
+// to the cloudwatch output of the metrics section and the documents are kept as structured logs unless metrics_only.
+func (obj *EMFExtraction) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m := input.(map[string]interface{})
+	sectionMap, ok := m[SectionKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	_, mode := translator.DefaultCase(SectionKeyMetricExtraction, MetricExtractionDisabled, sectionMap)
+	switch mode {
+	case MetricExtractionDisabled:
+		return
+	case MetricExtractionLogsAndMetrics, MetricExtractionMetricsOnly:
+	default:
+		translator.AddErrorMessages(GetCurPath()+SectionKeyMetricExtraction, "metric_extraction must be disabled, logs_and_metrics or metrics_only")
+		return
+	}
+	returnKey = emfExtractorProcessor
+	returnVal = map[string]map[string]interface{}{
+		"processors": {
+			emfExtractorProcessor: []interface{}{
+				map[string]interface{}{
+					"drop_logs": mode == MetricExtractionMetricsOnly,
+					// the routing tag of the metrics section, see translator.SetMetricPath
+					"metric_tags": map[string]interface{}{"metricPath": "metrics"},
+				},
+			},
+		},
+	}
+	return
+}
+
+func init() {
+	obj := new(EMFExtraction)
+	parent.RegisterLinuxRule(emfExtractorProcessor, obj)
+	parent.RegisterDarwinRule(emfExtractorProcessor, obj)
+	parent.RegisterWindowsRule(emfExtractorProcessor, obj)
+}
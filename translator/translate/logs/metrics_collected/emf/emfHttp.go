@@ -0,0 +1,55 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/metrics_collected"
+)
+
+//
+//   "emf" : {
+//       "http_service_address": "127.0.0.1:25889"
+//   }
+//
+const (
+	SectionKeyHTTPServiceAddress = "http_service_address"
+	emfHTTPInput                 = "emf_http"
+)
+
+type EMFHTTP struct {
+}
+
+// ApplyRule translates the http_service_address of the emf section to the emf_http input, which is configured
+// alongside the socket listeners of the same section.
+func (obj *EMFHTTP) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m := input.(map[string]interface{})
+	sectionMap, ok := m[SectionKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	val, ok := sectionMap[SectionKeyHTTPServiceAddress]
+	if !ok {
+		return
+	}
+	address, ok := val.(string)
+	if !ok || address == "" {
+		translator.AddErrorMessages(GetCurPath()+SectionKeyHTTPServiceAddress, "http_service_address must be a non-empty string")
+		return
+	}
+	returnKey = emfHTTPInput
+	returnVal = []interface{}{
+		map[string]interface{}{
+			"service_address": address,
+		},
+	}
+	return
+}
+
+func init() {
+	obj := new(EMFHTTP)
+	parent.RegisterLinuxRule(emfHTTPInput, obj)
+	parent.RegisterDarwinRule(emfHTTPInput, obj)
+	parent.RegisterWindowsRule(emfHTTPInput, obj)
+}
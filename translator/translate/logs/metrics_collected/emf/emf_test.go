@@ -54,3 +54,56 @@ func TestEMF_MinimumConfig(t *testing.T) {
 
 	assert.Equal(t, expect, actual)
 }
+
+func TestEMF_HTTPOnly(t *testing.T) {
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"emf": {"http_service_address": "127.0.0.1:25889"}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := new(EMF).ApplyRule(input)
+	assert.Len(t, actual, 2)
+
+	key, actual := new(EMFHTTP).ApplyRule(input)
+	assert.Equal(t, "emf_http", key)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"service_address": "127.0.0.1:25889",
+		},
+	}, actual)
+}
+
+func TestEMF_HTTPNotConfigured(t *testing.T) {
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"emf": {}}`), &input)
+	assert.NoError(t, err)
+
+	key, _ := new(EMFHTTP).ApplyRule(input)
+	assert.Equal(t, "", key)
+}
+
+func TestEMF_MetricExtraction(t *testing.T) {
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"emf": {"metric_extraction": "metrics_only"}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := new(EMF).ApplyRule(input)
+	assert.Len(t, actual, 2)
+
+	key, actual := new(EMFExtraction).ApplyRule(input)
+	assert.Equal(t, "emfextractor", key)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"processors": {
+			"emfextractor": []interface{}{
+				map[string]interface{}{
+					"drop_logs":   true,
+					"metric_tags": map[string]interface{}{"metricPath": "metrics"},
+				},
+			},
+		},
+	}, actual)
+
+	err = json.Unmarshal([]byte(`{"emf": {"metric_extraction": "disabled"}}`), &input)
+	assert.NoError(t, err)
+	key, _ = new(EMFExtraction).ApplyRule(input)
+	assert.Equal(t, "", key)
+}
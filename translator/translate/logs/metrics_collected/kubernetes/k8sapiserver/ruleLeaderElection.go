@@ -0,0 +1,61 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sapiserver
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	SectionKeyLeaderElection = "leader_election"
+)
+
+// leaderElection returns the leader_election section of the kubernetes config
+func leaderElection(input interface{}) (map[string]interface{}, bool) {
+	im := input.(map[string]interface{})
+	m, ok := im[SectionKeyLeaderElection].(map[string]interface{})
+	return m, ok
+}
+
+type LockType struct {
+}
+
+func (l *LockType) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m, ok := leaderElection(input)
+	if !ok {
+		return
+	}
+	if _, ok := m["lock_type"]; !ok {
+		return
+	}
+	_, returnVal = translator.DefaultCase("lock_type", "", m)
+	returnKey = "leader_lock_type"
+	return
+}
+
+// LeaderElectionDuration translates a duration of the leader election, in seconds in the json config
+type LeaderElectionDuration struct {
+	jsonKey string
+	tomlKey string
+}
+
+func (l *LeaderElectionDuration) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m, ok := leaderElection(input)
+	if !ok {
+		return
+	}
+	if _, ok := m[l.jsonKey]; !ok {
+		return
+	}
+	_, returnVal = translator.DefaultTimeIntervalCase(l.jsonKey, float64(0), m)
+	returnKey = l.tomlKey
+	return
+}
+
+func init() {
+	RegisterRule("leader_lock_type", new(LockType))
+	RegisterRule("leader_lease_duration", &LeaderElectionDuration{jsonKey: "lease_duration", tomlKey: "leader_lease_duration"})
+	RegisterRule("leader_renew_deadline", &LeaderElectionDuration{jsonKey: "renew_deadline", tomlKey: "leader_renew_deadline"})
+	RegisterRule("leader_retry_period", &LeaderElectionDuration{jsonKey: "retry_period", tomlKey: "leader_retry_period"})
+}
@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sapiserver
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderElection(t *testing.T) {
+	os.Setenv(config.HOST_NAME, "host_name_from_env")
+	defer os.Unsetenv(config.HOST_NAME)
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"leader_election": {"lock_type": "leases", "lease_duration": 30, "renew_deadline": 10, "retry_period": 2}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := new(ApiServer).ApplyRule(input)
+	expected := map[string]interface{}{
+		"node_name":             "host_name_from_env",
+		"leader_lock_type":      "leases",
+		"leader_lease_duration": "30s",
+		"leader_renew_deadline": "10s",
+		"leader_retry_period":   "2s",
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestLeaderElectionDefault(t *testing.T) {
+	os.Setenv(config.HOST_NAME, "host_name_from_env")
+	defer os.Unsetenv(config.HOST_NAME)
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"leader_election": {"retry_period": 3}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := new(ApiServer).ApplyRule(input)
+	expected := map[string]interface{}{
+		"node_name":           "host_name_from_env",
+		"leader_retry_period": "3s",
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -34,6 +34,12 @@ func (c *ClusterName) ApplyRule(input interface{}) (string, interface{}) {
 	return SectionKeyClusterName, clusterName
 }
 
+// GetClusterName returns the cluster name of the kubernetes section, which is read from the ec2 tags of the instance
+// when it is not configured
+func GetClusterName(kubernetesInput map[string]interface{}) string {
+	return getClusterName(kubernetesInput)
+}
+
 // For ASG case, the ec2 tag may be not ready as soon as the node is started up.
 // In this case, the translator will fail and then the pod will restart.
 func getClusterName(kuberneteInput map[string]interface{}) string {
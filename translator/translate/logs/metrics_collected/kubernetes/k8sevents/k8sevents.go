@@ -0,0 +1,61 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/metrics_collected/kubernetes"
+)
+
+type Rule translator.Rule
+
+var ChildRule = map[string]Rule{}
+
+const (
+	SubSectionKey = "k8sevents"
+	SectionKey    = "events"
+)
+
+func GetCurPath() string {
+	curPath := parent.GetCurPath() + SectionKey + "/"
+	return curPath
+}
+
+func RegisterRule(fieldname string, r Rule) {
+	ChildRule[fieldname] = r
+}
+
+// Events translates the events section of kubernetes into the k8sevents input, the Kubernetes events are only
+// published when the section is configured
+type Events struct {
+}
+
+func (e *Events) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if _, ok := im[SectionKey]; !ok {
+		return
+	}
+	result := map[string]interface{}{}
+	for _, rule := range ChildRule {
+		key, val := rule.ApplyRule(im)
+		if key != "" {
+			result[key] = val
+		}
+	}
+	returnKey = SubSectionKey
+	returnVal = result
+	return
+}
+
+// events returns the events section of the kubernetes config
+func events(input interface{}) map[string]interface{} {
+	im := input.(map[string]interface{})
+	m, _ := im[SectionKey].(map[string]interface{})
+	return m
+}
+
+func init() {
+	e := new(Events)
+	parent.RegisterRule(SubSectionKey, e)
+}
@@ -0,0 +1,41 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func applyRule(t *testing.T, jsonStr string) (string, interface{}) {
+	var input interface{}
+	assert.NoError(t, json.Unmarshal([]byte(jsonStr), &input))
+	return new(Events).ApplyRule(input)
+}
+
+func TestEvents(t *testing.T) {
+	key, val := applyRule(t, `{"cluster_name": "TestCluster", "events": {
+		"log_stream_name": "events", "namespaces": ["default"], "reasons": ["BackOff"], "event_types": ["Warning"]}}`)
+	assert.Equal(t, "k8sevents", key)
+	assert.Equal(t, map[string]interface{}{
+		"log_group_name":  "/aws/containerinsights/TestCluster/events",
+		"log_stream_name": "events",
+		"namespaces":      []interface{}{"default"},
+		"reasons":         []interface{}{"BackOff"},
+		"event_types":     []interface{}{"Warning"},
+	}, val)
+}
+
+func TestEventsLogGroupName(t *testing.T) {
+	key, val := applyRule(t, `{"cluster_name": "TestCluster", "events": {"log_group_name": "k8s-events"}}`)
+	assert.Equal(t, "k8sevents", key)
+	assert.Equal(t, map[string]interface{}{"log_group_name": "k8s-events"}, val)
+}
+
+func TestEventsNotConfigured(t *testing.T) {
+	key, _ := applyRule(t, `{"cluster_name": "TestCluster"}`)
+	assert.Equal(t, "", key)
+}
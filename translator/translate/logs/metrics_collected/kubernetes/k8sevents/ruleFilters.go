@@ -0,0 +1,23 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+// Filter translates a filter of the events, the events are published when their value is in the list
+type Filter struct {
+	key string
+}
+
+func (f *Filter) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if val, ok := events(input)[f.key]; ok {
+		returnKey = f.key
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	for _, key := range []string{"namespaces", "reasons", "event_types"} {
+		RegisterRule(key, &Filter{key: key})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/metrics_collected/kubernetes/k8sdecorator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+const (
+	SectionKeyLogGroupName = "log_group_name"
+)
+
+type LogGroupName struct {
+}
+
+// The events are published to the container insights log group of the cluster by default
+func (l *LogGroupName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, val := translator.DefaultCase(SectionKeyLogGroupName, "", events(input))
+	if val != "" {
+		return SectionKeyLogGroupName, util.ResolvePlaceholder(val.(string), util.GetMetadataInfo())
+	}
+	clusterName := k8sdecorator.GetClusterName(input.(map[string]interface{}))
+	if clusterName == "" {
+		return
+	}
+	return SectionKeyLogGroupName, fmt.Sprintf("/aws/containerinsights/%s/events", clusterName)
+}
+
+func init() {
+	RegisterRule(SectionKeyLogGroupName, new(LogGroupName))
+}
@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sevents
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+const (
+	SectionKeyLogStreamName = "log_stream_name"
+)
+
+type LogStreamName struct {
+}
+
+func (l *LogStreamName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, val := translator.DefaultCase(SectionKeyLogStreamName, "", events(input))
+	if val != "" {
+		return SectionKeyLogStreamName, util.ResolvePlaceholder(val.(string), util.GetMetadataInfo())
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeyLogStreamName, new(LogStreamName))
+}
@@ -49,7 +49,7 @@ func (k *Kubernetes) ApplyRule(input interface{}) (returnKey string, returnVal i
 		}
 		for _, rule := range ChildRule {
 			key, val := rule.ApplyRule(im[SectionKey])
-			if key == "cadvisor" || key == "k8sapiserver" {
+			if key == "cadvisor" || key == "k8sapiserver" || key == "k8sevents" {
 				inputs[key] = []interface{}{val}
 			} else if key == "ec2tagger" || key == "k8sdecorator" {
 				processors[key] = []interface{}{val}
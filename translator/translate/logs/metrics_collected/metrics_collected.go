@@ -96,6 +96,14 @@ func (c *CollectMetrics) ApplyRule(input interface{}) (returnKey string, returnV
 						}
 					}
 				}
+			} else if result, ok := val.(map[string]map[string]interface{}); ok {
+				// the rules of the other sections can add processors along with their inputs, e.g. emf
+				for k, v := range result["inputs"] {
+					inputs[k] = v
+				}
+				for k, v := range result["processors"] {
+					processors[k] = v
+				}
 			} else {
 				if key != "" {
 					inputs[key] = val
@@ -0,0 +1,24 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emfprocessor
+
+const (
+	SectionKeyHistogramOutput = "histogram_output"
+)
+
+type HistogramOutput struct {
+}
+
+func (h *HistogramOutput) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeyHistogramOutput]; ok {
+		returnKey = SectionKeyHistogramOutput
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeyHistogramOutput, new(HistogramOutput))
+}
@@ -0,0 +1,24 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emfprocessor
+
+const (
+	SectionKeyUntypedMetrics = "untyped_metrics"
+)
+
+type UntypedMetrics struct {
+}
+
+func (h *UntypedMetrics) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeyUntypedMetrics]; ok {
+		returnKey = SectionKeyUntypedMetrics
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeyUntypedMetrics, new(UntypedMetrics))
+}
@@ -0,0 +1,40 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
+)
+
+const (
+	spoolKey           = "spool"
+	spoolPathKey       = "path"
+	spoolMaxSizeMBKey  = "max_size_mb"
+	defaultSpoolSizeMB = 100
+)
+
+type Spool struct {
+}
+
+// The on-disk spool is opt-in, it is enabled by the presence of the "spool" section.
+func (s *Spool) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	spool, ok := im[spoolKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	res := map[string]interface{}{}
+	_, res["spool_dir"] = translator.DefaultCase(spoolPathKey, util.GetSpoolFolder(), spool)
+	_, res["spool_max_size_mb"] = translator.DefaultIntegralCase(spoolMaxSizeMBKey, float64(defaultSpoolSizeMB), spool)
+
+	returnKey = Output_Cloudwatch_Logs
+	returnVal = res
+	return
+}
+
+func init() {
+	RegisterRule(spoolKey, new(Spool))
+}
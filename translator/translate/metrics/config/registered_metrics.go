@@ -56,4 +56,5 @@ var Registered_Metrics_Windows = map[string][]string{
 var DisableWinPerfCounters = map[string]bool{
 	"statsd":   true,
 	"procstat": true,
+	"otlp":     true,
 }
@@ -29,13 +29,13 @@ func TestMetricDecoration_ApplyRule(t *testing.T) {
 	require.Nil(t, err)
 	_, val := c.ApplyRule(input)
 	expected := []interface{}{
-		map[string]string{
+		map[string]interface{}{
 			"rename":   "CPU",
 			"unit":     "Percent",
 			"category": "cpu",
 			"name":     "usage_idle",
 		},
-		map[string]string{
+		map[string]interface{}{
 			"category": "cpu",
 			"name":     "usage_nice",
 			"unit":     "Percent",
@@ -64,13 +64,13 @@ func TestMetricDecoration_plugin_with_alias_ApplyRule(t *testing.T) {
 	require.Nil(t, err)
 	_, val := c.ApplyRule(input)
 	expected := []interface{}{
-		map[string]string{
+		map[string]interface{}{
 			"rename":   "gpu_usage",
 			"unit":     "Percent",
 			"category": "nvidia_smi",
 			"name":     "utilization_gpu",
 		},
-		map[string]string{
+		map[string]interface{}{
 			"category": "nvidia_smi",
 			"name":     "memory_total",
 			"unit":     "Bytes",
@@ -79,3 +79,33 @@ func TestMetricDecoration_plugin_with_alias_ApplyRule(t *testing.T) {
 	}
 	assert.Equal(t, expected, val)
 }
+
+func TestMetricDecoration_Statistics(t *testing.T) {
+	c := new(MetricDecoration)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{
+			"metrics_collected": {
+				"cpu": {
+					"measurement": [
+						{"name": "cpu_usage_idle", "statistics": "statistic_set"},
+						{"name": "cpu_usage_nice", "statistics": "percentiles", "percentiles": [50, 99.9]}
+					]
+				}
+			}}`), &input)
+	require.Nil(t, err)
+	_, val := c.ApplyRule(input)
+	expected := []interface{}{
+		map[string]interface{}{
+			"category":   "cpu",
+			"name":       "usage_idle",
+			"statistics": "statistic_set",
+		},
+		map[string]interface{}{
+			"category":    "cpu",
+			"name":        "usage_nice",
+			"statistics":  "percentiles",
+			"percentiles": []float64{50, 99.9},
+		},
+	}
+	assert.Equal(t, expected, val)
+}
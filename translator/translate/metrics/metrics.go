@@ -8,6 +8,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/translator/jsonconfig/mergeJsonRule"
 	"github.com/aws/amazon-cloudwatch-agent/translator/jsonconfig/mergeJsonUtil"
 	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate"
+	metricsUtil "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/util"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
 )
 
@@ -53,12 +54,20 @@ func (m *Metrics) ApplyRule(input interface{}) (returnKey string, returnVal inte
 					outputPlugInfo = translator.MergeTwoUniqueMaps(outputPlugInfo, val.(map[string]interface{}))
 				} else if key == "metric_decoration" {
 					addDecorations(key, val, outputPlugInfo)
+				} else if key == "processors" {
+					result[key] = translator.MergeTwoUniqueMaps(processorsOf(result), val.(map[string]interface{}))
 				} else {
 					result[key] = val
 				}
 			}
 		}
 
+		if relabelProcessors := metricsUtil.RelabelProcessors(result["inputs"]); len(relabelProcessors) > 0 {
+			processors := processorsOf(result)
+			processors[metricsUtil.Relabel_Processor_Key] = relabelProcessors
+			result["processors"] = processors
+		}
+
 		cloudwatchInfo := map[string]interface{}{}
 		cloudwatchInfo["cloudwatch"] = []interface{}{outputPlugInfo}
 		result["outputs"] = cloudwatchInfo
@@ -69,6 +78,13 @@ func (m *Metrics) ApplyRule(input interface{}) (returnKey string, returnVal inte
 	return
 }
 
+func processorsOf(result map[string]interface{}) map[string]interface{} {
+	if processors, ok := result["processors"].(map[string]interface{}); ok {
+		return processors
+	}
+	return map[string]interface{}{}
+}
+
 func addDecorations(key string, val interface{}, outputPlugInfo map[string]interface{}) {
 	if len(val.([]interface{})) > 0 {
 		outputPlugInfo[key] = val
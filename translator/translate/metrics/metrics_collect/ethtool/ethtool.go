@@ -6,6 +6,7 @@ package ethtool
 import (
 	"github.com/aws/amazon-cloudwatch-agent/translator"
 	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/util"
 )
 
 var ChildRule = map[string]translator.Rule{}
@@ -48,6 +49,7 @@ func (n *Ethtool) ApplyRule(input interface{}) (returnKey string, returnVal inte
 		//If exists, process it
 		//Check if there are some config entry with rules applied
 		result = translator.ProcessRuleToApply(m[SectionKey_Ethtool], ChildRule, result)
+		util.ProcessRelabelConfigs(m[SectionKey_Ethtool], SectionKey_Ethtool, result)
 		resArr = append(resArr, result)
 		returnKey = SectionKey_Ethtool
 		returnVal = resArr
@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/util"
+)
+
+//
+// Need to import new rule package in src/translator/totomlconfig/toTomlConfig.go
+//
+
+//
+//   "otlp" : {
+//       "grpc_service_address": "127.0.0.1:4317",
+//       "http_service_address": "127.0.0.1:4318",
+//       "metrics_aggregation_interval": 60
+//   }
+//
+const SectionKey = "otlp"
+
+var ChildRule = map[string]translator.Rule{}
+
+func GetCurPath() string {
+	curPath := parent.GetCurPath() + SectionKey + "/"
+	return curPath
+}
+
+func RegisterRule(fieldname string, r translator.Rule) {
+	ChildRule[fieldname] = r
+}
+
+type OTLP struct {
+}
+
+func (obj *OTLP) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	m := input.(map[string]interface{})
+	resArray := []interface{}{}
+	result := map[string]interface{}{}
+	//Check if this plugin exist in the input instance
+	//If not, not process
+	if _, ok := m[SectionKey]; !ok {
+		returnKey = ""
+		returnVal = ""
+	} else {
+		//If exists, process it
+		//Check if there are some config entry with rules applied
+		result = translator.ProcessRuleToApply(m[SectionKey], ChildRule, result)
+		util.ProcessStatistics(m[SectionKey], SectionKey, result)
+		util.ProcessRelabelConfigs(m[SectionKey], SectionKey, result)
+		resArray = append(resArray, result)
+		returnKey = SectionKey
+		returnVal = resArray
+	}
+	return
+}
+
+func init() {
+	obj := new(OTLP)
+	parent.RegisterLinuxRule(SectionKey, obj)
+	parent.RegisterDarwinRule(SectionKey, obj)
+	parent.RegisterWindowsRule(SectionKey, obj)
+}
@@ -0,0 +1,53 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLP_HappyCase(t *testing.T) {
+	obj := new(OTLP)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"otlp": {
+					"grpc_service_address": "0.0.0.0:4317",
+					"http_service_address": "",
+					"metrics_aggregation_interval": 30
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"grpc_service_address": "0.0.0.0:4317",
+			"http_service_address": "",
+			"tags":                 map[string]interface{}{"aws:AggregationInterval": "30s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
+
+func TestOTLP_MinimumConfig(t *testing.T) {
+	obj := new(OTLP)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"otlp": {}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"grpc_service_address": "127.0.0.1:4317",
+			"http_service_address": "127.0.0.1:4318",
+			"tags":                 map[string]interface{}{"aws:AggregationInterval": "60s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
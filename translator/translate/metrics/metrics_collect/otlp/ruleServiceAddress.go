@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otlp
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+type GRPCServiceAddress struct {
+}
+
+type HTTPServiceAddress struct {
+}
+
+const (
+	SectionKey_GRPCServiceAddress = "grpc_service_address"
+	SectionKey_HTTPServiceAddress = "http_service_address"
+)
+
+func (obj *GRPCServiceAddress) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	returnKey, returnVal = translator.DefaultCase(SectionKey_GRPCServiceAddress, "127.0.0.1:4317", input)
+	return
+}
+
+func (obj *HTTPServiceAddress) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	returnKey, returnVal = translator.DefaultCase(SectionKey_HTTPServiceAddress, "127.0.0.1:4318", input)
+	return
+}
+
+func init() {
+	RegisterRule(SectionKey_GRPCServiceAddress, new(GRPCServiceAddress))
+	RegisterRule(SectionKey_HTTPServiceAddress, new(HTTPServiceAddress))
+}
@@ -0,0 +1,42 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+type EventsLogGroupName struct {
+}
+
+const (
+	SectionKey_EventsLogGroupName = "events_log_group_name"
+	SectionKey_EventsDestination  = "events_destination"
+	eventsDestination             = "cloudwatchlogs"
+)
+
+func (obj *EventsLogGroupName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_EventsLogGroupName, "", input)
+	if val != "" {
+		return key, util.ResolvePlaceholder(val.(string), util.GetMetadataInfo())
+	}
+	return
+}
+
+type EventsDestination struct {
+}
+
+// The events are published by the cloudwatchlogs output
+func (obj *EventsDestination) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if _, val := translator.DefaultCase(SectionKey_EventsLogGroupName, "", input); val != "" {
+		return SectionKey_EventsDestination, eventsDestination
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKey_EventsLogGroupName, new(EventsLogGroupName))
+	RegisterRule(SectionKey_EventsDestination, new(EventsDestination))
+}
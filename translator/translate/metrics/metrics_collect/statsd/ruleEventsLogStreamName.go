@@ -0,0 +1,27 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+type EventsLogStreamName struct {
+}
+
+const SectionKey_EventsLogStreamName = "events_log_stream_name"
+
+func (obj *EventsLogStreamName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_EventsLogStreamName, "", input)
+	if val != "" {
+		return key, util.ResolvePlaceholder(val.(string), util.GetMetadataInfo())
+	}
+	return
+}
+
+func init() {
+	obj := new(EventsLogStreamName)
+	RegisterRule(SectionKey_EventsLogStreamName, obj)
+}
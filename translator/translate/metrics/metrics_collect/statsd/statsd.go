@@ -6,6 +6,7 @@ package statsd
 import (
 	"github.com/aws/amazon-cloudwatch-agent/translator"
 	parent "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/util"
 )
 
 //
@@ -48,6 +49,8 @@ func (obj *StatsD) ApplyRule(input interface{}) (returnKey string, returnVal int
 		//If exists, process it
 		//Check if there are some config entry with rules applied
 		result = translator.ProcessRuleToApply(m[SectionKey], ChildRule, result)
+		util.ProcessStatistics(m[SectionKey], SectionKey, result)
+		util.ProcessRelabelConfigs(m[SectionKey], SectionKey, result)
 		resArray = append(resArray, result)
 		returnKey = SectionKey
 		returnVal = resArray
@@ -100,3 +100,61 @@ func TestStatsD_MetricSeparator(t *testing.T) {
 
 	assert.Equal(t, expect, actual)
 }
+
+func TestStatsD_ListenerAndEvents(t *testing.T) {
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {
+					"protocol": "unix",
+					"service_address": "/var/run/statsd.sock",
+					"max_tcp_connections": 100,
+					"events_log_group_name": "statsd-events",
+					"events_log_stream_name": "events"
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"protocol":               "unix",
+			"service_address":        "/var/run/statsd.sock",
+			"max_tcp_connections":    100,
+			"events_log_group_name":  "statsd-events",
+			"events_log_stream_name": "events",
+			"events_destination":     "cloudwatchlogs",
+			"interval":               "10s",
+			"parse_data_dog_tags":    true,
+			"tags":                   map[string]interface{}{"aws:AggregationInterval": "60s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
+
+func TestStatsD_Statistics(t *testing.T) {
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {
+					"statistics": "percentiles",
+					"percentiles": [50, 90, 99.9]
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"service_address":     ":8125",
+			"interval":            "10s",
+			"parse_data_dog_tags": true,
+			"tags": map[string]interface{}{
+				"aws:AggregationInterval": "60s",
+				"aws:Statistics":          "percentiles",
+				"aws:Percentiles":         "50,90,99.9",
+			},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
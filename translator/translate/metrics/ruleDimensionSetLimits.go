@@ -0,0 +1,42 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const dimensionSetLimitsKey = "dimension_set_limits"
+
+// DimensionSetLimits caps the unique dimension sets published by the cloudwatch output per metric name and per namespace
+type DimensionSetLimits struct {
+}
+
+func (d *DimensionSetLimits) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	limits, ok := im[dimensionSetLimitsKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	res := map[string]interface{}{}
+	if _, ok := limits["per_metric"]; ok {
+		_, res["max_dimension_sets_per_metric"] = translator.DefaultIntegralCase("per_metric", float64(0), limits)
+	}
+	if _, ok := limits["per_namespace"]; ok {
+		_, res["max_dimension_sets_per_namespace"] = translator.DefaultIntegralCase("per_namespace", float64(0), limits)
+	}
+	if _, ok := limits["window"]; ok {
+		_, res["dimension_sets_window"] = translator.DefaultTimeIntervalCase("window", float64(3600), limits)
+	}
+	if _, ok := limits["overflow_action"]; ok {
+		_, res["dimension_sets_overflow_action"] = translator.DefaultCase("overflow_action", "drop", limits)
+	}
+	returnKey = "outputs"
+	returnVal = res
+	return
+}
+
+func init() {
+	RegisterRule(dimensionSetLimitsKey, new(DimensionSetLimits))
+}
@@ -0,0 +1,38 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDimensionSetLimits(t *testing.T) {
+	d := new(DimensionSetLimits)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"dimension_set_limits": {"per_metric": 1000, "per_namespace": 10000, "window": 600, "overflow_action": "collapse"}}`), &input)
+	require.NoError(t, err)
+	key, val := d.ApplyRule(input)
+	assert.Equal(t, "outputs", key)
+	assert.Equal(t, map[string]interface{}{
+		"max_dimension_sets_per_metric":    1000,
+		"max_dimension_sets_per_namespace": 10000,
+		"dimension_sets_window":            "600s",
+		"dimension_sets_overflow_action":   "collapse",
+	}, val)
+
+	err = json.Unmarshal([]byte(`{"dimension_set_limits": {"per_metric": 100}}`), &input)
+	require.NoError(t, err)
+	_, val = d.ApplyRule(input)
+	assert.Equal(t, map[string]interface{}{"max_dimension_sets_per_metric": 100}, val)
+
+	err = json.Unmarshal([]byte(`{}`), &input)
+	require.NoError(t, err)
+	key, val = d.ApplyRule(input)
+	assert.Equal(t, "", key)
+	assert.Nil(t, val)
+}
@@ -55,6 +55,8 @@ func ProcessLinuxCommonConfig(input interface{}, pluginName string, path string,
 		util.Cleanup(val)
 	}
 
+	ProcessRelabelConfigs(inputMap, pluginName, result)
+
 	// apply any specific rules for the plugin
 	if m, ok := ApplyPluginSpecificRules(pluginName); ok {
 		for key, val := range m {
@@ -92,6 +94,7 @@ func ProcessWindowsCommonConfig(input interface{}, pluginName string, path strin
 	if val, ok := inputMap[Append_Dimensions_Key]; ok {
 		returnVal[Append_Dimensions_Mapped_Key] = val
 	}
+	ProcessRelabelConfigs(inputMap, pluginName, returnVal)
 
 	// 3. object config
 
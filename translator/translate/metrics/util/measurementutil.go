@@ -85,7 +85,7 @@ func ApplyMeasurementRuleForMetricDecoration(inputs interface{}, pluginName stri
 		formattedMetricName := getValidMetric(targetOs, pluginName, inputMetricName.(string))
 
 		if formattedMetricName != "" {
-			decorationMap := make(map[string]string)
+			decorationMap := make(map[string]interface{})
 			for k, v := range mItemMap {
 				switch k {
 				case measurement_name:
@@ -93,7 +93,13 @@ func ApplyMeasurementRuleForMetricDecoration(inputs interface{}, pluginName stri
 				case measurement_rename:
 					fallthrough
 				case measurement_unit:
+					fallthrough
+				case Statistics_Key:
 					decorationMap[k] = strings.TrimSpace(v.(string))
+				case Percentiles_Key:
+					if percentiles, ok := getPercentiles(v); ok {
+						decorationMap[k] = percentiles
+					}
 				default:
 					fmt.Printf("Warning, detect unexpected field in measurement: %v", k)
 				}
@@ -143,6 +149,12 @@ func isDecorationAvail(observationMap map[string]interface{}) bool {
 	if _, ok := observationMap[measurement_unit]; ok {
 		return true
 	}
+	if _, ok := observationMap[Statistics_Key]; ok {
+		return true
+	}
+	if _, ok := observationMap[Percentiles_Key]; ok {
+		return true
+	}
 	return false
 }
 
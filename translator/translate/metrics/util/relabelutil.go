@@ -0,0 +1,102 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	Relabel_Configs_Key     = "relabel_configs"
+	Relabel_Section_Tag_Key = "aws:RelabelSection"
+	Relabel_Processor_Key   = "relabel"
+)
+
+var relabelRuleKeys = []string{"source_labels", "separator", "regex", "modulus", "target_label", "replacement", "action"}
+
+// ProcessRelabelConfigs tags the metrics of the section with its plugin name, the relabel processor of the section
+// only applies its relabel_configs to the metrics with this tag. The relabel_configs are kept in the result until
+// RelabelProcessors turns them into the relabel processors.
+func ProcessRelabelConfigs(input interface{}, pluginName string, result map[string]interface{}) {
+	inputMap, ok := input.(map[string]interface{})
+	if !ok {
+		return
+	}
+	configs, ok := inputMap[Relabel_Configs_Key].([]interface{})
+	if !ok || len(configs) == 0 {
+		return
+	}
+
+	var rules []interface{}
+	for _, c := range configs {
+		config, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rule := map[string]interface{}{}
+		for _, key := range relabelRuleKeys {
+			if val, ok := config[key]; ok {
+				if floatVal, ok := val.(float64); ok {
+					val = int(floatVal)
+				}
+				rule[key] = val
+			}
+		}
+		rules = append(rules, rule)
+	}
+	result[Relabel_Configs_Key] = rules
+
+	// the tags may be the append_dimensions of the input, which is not changed
+	tags := map[string]interface{}{}
+	if val, ok := result[Append_Dimensions_Mapped_Key].(map[string]interface{}); ok {
+		for k, v := range val {
+			tags[k] = v
+		}
+	}
+	tags[Relabel_Section_Tag_Key] = pluginName
+	result[Append_Dimensions_Mapped_Key] = tags
+}
+
+// RelabelProcessors removes the relabel_configs kept in the translated inputs and returns a relabel processor config
+// per section. The sections of the same plugin, e.g. procstat, are numbered in the order of the inputs.
+func RelabelProcessors(inputs interface{}) []interface{} {
+	var processors []interface{}
+	used := map[string]bool{}
+	var walk func(val interface{})
+	walk = func(val interface{}) {
+		switch v := val.(type) {
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			if rules, ok := v[Relabel_Configs_Key]; ok {
+				delete(v, Relabel_Configs_Key)
+				tags, _ := v[Append_Dimensions_Mapped_Key].(map[string]interface{})
+				pluginName, _ := tags[Relabel_Section_Tag_Key].(string)
+				section := pluginName
+				for i := 2; used[section]; i++ {
+					section = fmt.Sprintf("%s_%d", pluginName, i)
+				}
+				used[section] = true
+				tags[Relabel_Section_Tag_Key] = section
+				processors = append(processors, map[string]interface{}{
+					"section":        section,
+					"relabel_config": rules,
+				})
+			}
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key])
+			}
+		}
+	}
+	walk(inputs)
+	return processors
+}
@@ -0,0 +1,39 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelabelProcessors(t *testing.T) {
+	appendDimensions := map[string]interface{}{"env": "prod"}
+	relabelConfigs := []interface{}{map[string]interface{}{"regex": "env", "action": "labeldrop"}}
+	var procstat []interface{}
+	for i := 0; i < 2; i++ {
+		result := map[string]interface{}{Append_Dimensions_Mapped_Key: appendDimensions}
+		ProcessRelabelConfigs(map[string]interface{}{Relabel_Configs_Key: relabelConfigs}, "procstat", result)
+		procstat = append(procstat, result)
+	}
+	cpu := map[string]interface{}{}
+	ProcessRelabelConfigs(map[string]interface{}{Relabel_Configs_Key: []interface{}{}}, "cpu", cpu)
+	assert.Equal(t, map[string]interface{}{"env": "prod"}, appendDimensions, "the append_dimensions are not changed")
+
+	processors := RelabelProcessors(map[string]interface{}{"procstat": procstat, "cpu": []interface{}{cpu}})
+	rules := []interface{}{map[string]interface{}{"regex": "env", "action": "labeldrop"}}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"section": "procstat", "relabel_config": rules},
+		map[string]interface{}{"section": "procstat_2", "relabel_config": rules},
+	}, processors)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{Append_Dimensions_Mapped_Key: map[string]interface{}{"env": "prod", Relabel_Section_Tag_Key: "procstat"}},
+		map[string]interface{}{Append_Dimensions_Mapped_Key: map[string]interface{}{"env": "prod", Relabel_Section_Tag_Key: "procstat_2"}},
+	}, procstat, "the relabel_configs are removed from the inputs")
+	assert.Empty(t, cpu)
+
+	// the translation is not changed by a previous one
+	assert.Empty(t, RelabelProcessors(map[string]interface{}{"procstat": procstat}))
+}
@@ -0,0 +1,76 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+const (
+	Statistics_Key  = "statistics"
+	Percentiles_Key = "percentiles"
+)
+
+// ProcessStatistics tags the metrics of the section with the statistics published by the cloudwatch output for their
+// aggregated values, the statistics of the metric_decoration of a metric take precedence over them
+func ProcessStatistics(input interface{}, pluginName string, result map[string]interface{}) {
+	inputMap, ok := input.(map[string]interface{})
+	if !ok {
+		return
+	}
+	tags := map[string]interface{}{}
+	if val, ok := inputMap[Statistics_Key]; ok {
+		if statistics, ok := val.(string); ok {
+			tags[util.Statistics_Tag_Key] = statistics
+		} else {
+			translator.AddErrorMessages(
+				fmt.Sprintf("metrics plugin %s", pluginName),
+				fmt.Sprintf("statistics value (%v) in json is not valid.", val))
+		}
+	}
+	if val, ok := inputMap[Percentiles_Key]; ok {
+		if percentiles, ok := getPercentiles(val); ok {
+			formatted := make([]string, len(percentiles))
+			for i, p := range percentiles {
+				formatted[i] = strconv.FormatFloat(p, 'f', -1, 64)
+			}
+			tags[util.Percentiles_Tag_Key] = strings.Join(formatted, ",")
+		} else {
+			translator.AddErrorMessages(
+				fmt.Sprintf("metrics plugin %s", pluginName),
+				fmt.Sprintf("percentiles value (%v) in json is not valid.", val))
+		}
+	}
+	if len(tags) == 0 {
+		return
+	}
+
+	if result[Append_Dimensions_Mapped_Key] == nil {
+		result[Append_Dimensions_Mapped_Key] = map[string]interface{}{}
+	}
+	if resultTags, ok := result[Append_Dimensions_Mapped_Key].(map[string]interface{}); ok {
+		for k, v := range tags {
+			resultTags[k] = v
+		}
+	}
+}
+
+func getPercentiles(val interface{}) ([]float64, bool) {
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	percentiles := make([]float64, len(list))
+	for i, p := range list {
+		if percentiles[i], ok = p.(float64); !ok {
+			return nil, false
+		}
+	}
+	return percentiles, true
+}
@@ -6,9 +6,11 @@ package util
 const (
 	High_Resolution_Tag_Key      = "aws:StorageResolution"
 	Aggregation_Interval_Tag_Key = "aws:AggregationInterval"
+	Statistics_Tag_Key           = "aws:Statistics"
+	Percentiles_Tag_Key          = "aws:Percentiles"
 )
 
-var Reserved_Tag_Keys = []string{High_Resolution_Tag_Key, Aggregation_Interval_Tag_Key}
+var Reserved_Tag_Keys = []string{High_Resolution_Tag_Key, Aggregation_Interval_Tag_Key, Statistics_Tag_Key, Percentiles_Tag_Key}
 
 func AddHighResolutionTag(tags interface{}) {
 	tagMap := tags.(map[string]interface{})
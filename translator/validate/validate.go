@@ -0,0 +1,371 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package validate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	_ "github.com/aws/amazon-cloudwatch-agent/plugins"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/k8sevents"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/statsd"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/syslog"
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/cmdutil"
+	"github.com/aws/amazon-cloudwatch-agent/translator/totomlconfig"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/files/collect_list"
+	"github.com/influxdata/telegraf/config"
+)
+
+const (
+	filesCollectListPointer         = "/logs/logs_collected/files/collect_list"
+	windowsEventsCollectListPointer = "/logs/logs_collected/windows_events/collect_list"
+	syslogCollectListPointer        = "/logs/logs_collected/syslog/collect_list"
+	statsdPointer                   = "/metrics/metrics_collected/statsd"
+	k8sEventsPointer                = "/logs/metrics_collected/kubernetes/events"
+	emfMetricExtractionPointer      = "/logs/metrics_collected/emf/metric_extraction"
+)
+
+// Error is a semantic error of the json config, Pointer is the JSON pointer (RFC 6901) of the offending key, it is
+// empty when the error cannot be attributed to a key
+type Error struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// Validate checks the merged json config without writing any file or calling any AWS API. It runs the schema
+// validation, the semantic checks of the json config, every translator rule, and loads the translated config into the
+// plugins to run their offline checks. The plugins are only loaded when the target os of the translation is the os
+// the validation runs on, as the plugins of the other os are not built in.
+func Validate(jsonConfig map[string]interface{}) []Error {
+	errs := validateSchema(jsonConfig)
+	if len(errs) > 0 {
+		// the semantic checks and the translator rules assume the types defined by the schema
+		return errs
+	}
+	errs = append(errs, validateLogsCollected(jsonConfig)...)
+	errs = append(errs, validateStatsdEvents(jsonConfig)...)
+	errs = append(errs, validateEMFMetricExtraction(jsonConfig)...)
+
+	tomlConfig, translateErrs := translate(jsonConfig)
+	errs = append(errs, translateErrs...)
+	if len(translateErrs) == 0 && translator.GetTargetPlatform() == runtime.GOOS {
+		errs = appendNew(errs, validatePlugins(tomlConfig))
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Pointer < errs[j].Pointer })
+	return errs
+}
+
+func validateSchema(jsonConfig map[string]interface{}) []Error {
+	result, err := cmdutil.RunSchemaValidation(jsonConfig)
+	if err != nil {
+		return []Error{{Message: err.Error()}}
+	}
+	var errs []Error
+	for _, e := range result.Errors() {
+		pointer := strings.TrimPrefix(e.Context().String("/"), "(root)")
+		// the missing and the unknown properties are reported on their parent
+		if property, ok := e.Details()["property"].(string); ok && property != "" {
+			pointer += "/" + escapePointerToken(property)
+		}
+		errs = append(errs, Error{Pointer: pointer, Message: e.Description()})
+	}
+	return errs
+}
+
+// validateLogsCollected checks the entries of the collect_list of files and windows_events
+func validateLogsCollected(jsonConfig map[string]interface{}) []Error {
+	var errs []Error
+	files := collectList(jsonConfig, "files")
+	for i, entry := range files {
+		pointer := fmt.Sprintf("%s/%d", filesCollectListPointer, i)
+		if pattern, ok := entry["multi_line_start_pattern"].(string); ok && pattern != "{timestamp_format}" {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, Error{Pointer: pointer + "/multi_line_start_pattern", Message: err.Error()})
+			}
+		}
+		if pattern, ok := entry["blacklist"].(string); ok {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, Error{Pointer: pointer + "/blacklist", Message: err.Error()})
+			}
+		}
+		if format, ok := entry["timestamp_format"].(string); ok {
+			for _, token := range unknownTimestampTokens(format) {
+				errs = append(errs, Error{Pointer: pointer + "/timestamp_format", Message: fmt.Sprintf("unknown timestamp_format token %s", token)})
+			}
+		}
+	}
+
+	// the agent refuses a log group whose retention is set in multiple places
+	pointers := make(map[string]string)
+	check := func(entries []map[string]interface{}, listPointer string) {
+		for i, entry := range entries {
+			group, _ := entry["log_group_name"].(string)
+			retention, _ := entry["retention_in_days"].(float64)
+			if group == "" || retention <= 0 {
+				continue
+			}
+			pointer := fmt.Sprintf("%s/%d/retention_in_days", listPointer, i)
+			if first, ok := pointers[strings.ToLower(group)]; ok {
+				errs = append(errs, Error{Pointer: pointer, Message: fmt.Sprintf("retention for log group %s is already set at %s", group, first)})
+				continue
+			}
+			pointers[strings.ToLower(group)] = pointer
+		}
+	}
+	check(files, filesCollectListPointer)
+	check(collectList(jsonConfig, "windows_events"), windowsEventsCollectListPointer)
+	check(collectList(jsonConfig, "syslog"), syslogCollectListPointer)
+	return errs
+}
+
+// validateStatsdEvents checks the statsd events are published to a log destination
+func validateStatsdEvents(jsonConfig map[string]interface{}) []Error {
+	statsdConfig, ok := getMap(jsonConfig, "metrics", "metrics_collected", "statsd")
+	if !ok {
+		return nil
+	}
+	if _, ok := statsdConfig["events_log_group_name"]; !ok {
+		return nil
+	}
+	if _, ok := jsonConfig["logs"]; ok {
+		return nil
+	}
+	return []Error{{
+		Pointer: statsdPointer + "/events_log_group_name",
+		Message: "the statsd events are published to cloudwatchlogs which is not configured, add the logs section",
+	}}
+}
+
+// validateEMFMetricExtraction checks the metrics extracted from the EMF documents are published to cloudwatch
+func validateEMFMetricExtraction(jsonConfig map[string]interface{}) []Error {
+	emfConfig, ok := getMap(jsonConfig, "logs", "metrics_collected", "emf")
+	if !ok {
+		return nil
+	}
+	if mode, ok := emfConfig["metric_extraction"]; !ok || mode == "disabled" {
+		return nil
+	}
+	if _, ok := jsonConfig["metrics"]; ok {
+		return nil
+	}
+	return []Error{{
+		Pointer: emfMetricExtractionPointer,
+		Message: "the metrics extracted from the EMF documents are published to cloudwatch which is not configured, add the metrics section",
+	}}
+}
+
+// translate runs every translator rule and collects the errors they report
+func translate(jsonConfig map[string]interface{}) (tomlConfig string, errs []Error) {
+	translator.ResetMessages()
+	defer func() {
+		if r := recover(); r != nil {
+			if len(translator.ErrorDetails) == 0 {
+				errs = append(errs, Error{Message: fmt.Sprint(r)})
+			}
+		}
+		for _, d := range translator.ErrorDetails {
+			errs = append(errs, Error{Pointer: toPointer(jsonConfig, d.Path), Message: d.Message})
+		}
+	}()
+	tomlConfig = totomlconfig.ToTomlConfig(jsonConfig)
+	return
+}
+
+// validatePlugins loads the translated config into the plugins and runs their offline checks, the checks which call
+// the AWS APIs or the instance metadata are not run.
+func validatePlugins(tomlConfig string) []Error {
+	f, err := ioutil.TempFile("", "cwagent-validate-*.toml")
+	if err != nil {
+		return []Error{{Message: fmt.Sprintf("failed to create the temporary toml config: %v", err)}}
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(tomlConfig)
+	f.Close()
+	if err != nil {
+		return []Error{{Message: fmt.Sprintf("failed to write the temporary toml config: %v", err)}}
+	}
+
+	c := config.NewConfig()
+	if err := c.LoadConfig(f.Name()); err != nil {
+		return []Error{{Message: err.Error()}}
+	}
+
+	backends := make(map[string]bool)
+	for _, output := range c.Outputs {
+		if _, ok := output.Output.(logs.LogBackend); !ok {
+			continue
+		}
+		name := output.Config.Alias
+		if name == "" {
+			name = output.Config.Name
+		}
+		backends[name] = true
+	}
+
+	var errs []Error
+	checkDestination := func(pointer, destination string) {
+		if !backends[destination] {
+			errs = append(errs, Error{Pointer: pointer, Message: fmt.Sprintf("the destination %s is not configured", destination)})
+		}
+	}
+	// the syslog inputs are translated in the order of their collect_list
+	syslogIndex := 0
+	for _, input := range c.Inputs {
+		switch p := input.Input.(type) {
+		case *logfile.LogFile:
+			for i, fc := range p.FileConfig {
+				pointer := fmt.Sprintf("%s/%d", filesCollectListPointer, i)
+				if err := fc.Validate(); err != nil {
+					errs = append(errs, Error{Pointer: pointer, Message: err.Error()})
+				}
+				destination := fc.Destination
+				if destination == "" {
+					destination = p.Destination
+				}
+				checkDestination(pointer, destination)
+			}
+		case *syslog.Syslog:
+			pointer := fmt.Sprintf("%s/%d", syslogCollectListPointer, syslogIndex)
+			syslogIndex++
+			if err := p.Validate(); err != nil {
+				errs = append(errs, Error{Pointer: pointer, Message: err.Error()})
+			}
+			checkDestination(pointer, p.Destination)
+		case *statsd.Statsd:
+			if p.EventsLogGroupName != "" {
+				checkDestination(statsdPointer+"/events_log_group_name", p.EventsDestination)
+			}
+		case *k8sevents.K8sEvents:
+			checkDestination(k8sEventsPointer, p.Destination)
+		}
+	}
+	return errs
+}
+
+// unknownTimestampTokens returns the tokens of the timestamp_format which are not supported
+func unknownTimestampTokens(format string) []string {
+	var unknown []string
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+2 < len(format) && format[i+1] == '-' {
+			if _, ok := collect_list.TimeFormatMap[format[i:i+3]]; ok {
+				i += 2
+				continue
+			}
+		}
+		if i+1 < len(format) {
+			if _, ok := collect_list.TimeFormatMap[format[i:i+2]]; ok {
+				i++
+				continue
+			}
+			unknown = append(unknown, format[i:i+2])
+			i++
+			continue
+		}
+		unknown = append(unknown, format[i:])
+	}
+	return unknown
+}
+
+func collectList(jsonConfig map[string]interface{}, section string) []map[string]interface{} {
+	m, ok := getMap(jsonConfig, "logs", "logs_collected", section)
+	if !ok {
+		return nil
+	}
+	list, _ := m["collect_list"].([]interface{})
+	entries := make([]map[string]interface{}, 0, len(list))
+	for _, e := range list {
+		entry, _ := e.(map[string]interface{})
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func getMap(m map[string]interface{}, keys ...string) (map[string]interface{}, bool) {
+	for _, key := range keys {
+		var ok bool
+		if m, ok = m[key].(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+// toPointer converts the path of the translator error messages, e.g. "/logs/logs_collected/files/collect_list/", to
+// the JSON pointer of the key in the json config. The paths do not have the index of the array entries, so the pointer
+// is only returned when the path resolves to a single key, through arrays of a single entry, and the errors are
+// reported without a pointer otherwise.
+func toPointer(jsonConfig map[string]interface{}, path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return ""
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return ""
+	}
+	var pointer strings.Builder
+	var node interface{} = jsonConfig
+	tokens := strings.Split(path, "/")
+	for i, token := range tokens {
+		if entries, ok := node.([]interface{}); ok {
+			if len(entries) != 1 {
+				return ""
+			}
+			node = entries[0]
+			pointer.WriteString("/0")
+		}
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		pointer.WriteString("/" + escapePointerToken(token))
+		// the last key may be missing, e.g. a required key
+		if node, ok = obj[token]; !ok && i < len(tokens)-1 {
+			return ""
+		}
+	}
+	if entries, ok := node.([]interface{}); ok {
+		// the error is about one of the entries, which the path only identifies when there is one
+		if len(entries) != 1 {
+			return ""
+		}
+		pointer.WriteString("/0")
+	}
+	return pointer.String()
+}
+
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointerToken(token string) string {
+	return pointerTokenEscaper.Replace(token)
+}
+
+// appendNew appends the errors whose keys have no error yet, as the plugins report again the issues found by the
+// semantic checks of the json config
+func appendNew(errs []Error, more []Error) []Error {
+	res := errs
+	for _, m := range more {
+		found := false
+		for _, e := range errs {
+			if m.Pointer != "" && (e.Pointer == m.Pointer || strings.HasPrefix(e.Pointer, m.Pointer+"/")) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, m)
+		}
+	}
+	return res
+}
@@ -0,0 +1,137 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package validate
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/context"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+func resetContext() {
+	util.DetectRegion = func(string, map[string]string) string {
+		return "us-west-2"
+	}
+	util.DetectCredentialsPath = func() string {
+		return "fake-path"
+	}
+	context.ResetContext()
+	agent.Global_Config = *new(agent.Agent)
+	translator.SetTargetPlatform(runtime.GOOS)
+}
+
+func validateJson(t *testing.T, jsonStr string) []Error {
+	resetContext()
+	var input map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(jsonStr), &input))
+	return Validate(input)
+}
+
+func TestValidateValidConfig(t *testing.T) {
+	errs := validateJson(t, `{
+		"metrics": {"metrics_collected": {"statsd": {"events_log_group_name": "events"}}},
+		"logs": {"logs_collected": {"files": {"collect_list": [
+			{"file_path": "/tmp/a.log", "log_group_name": "a", "retention_in_days": 3,
+			 "multi_line_start_pattern": "{timestamp_format}", "timestamp_format": "%-d %b %Y %H:%M:%S"},
+			{"file_path": "/tmp/b.log", "log_group_name": "A"}
+		]}}}
+	}`)
+	assert.Empty(t, errs)
+}
+
+func TestValidateSchemaError(t *testing.T) {
+	errs := validateJson(t, `{
+		"logs": {"logs_collected": {"files": {"collect_list": [{"log_group_name": "a"}]}}, "unknown": 1}
+	}`)
+	assert.Contains(t, errs, Error{Pointer: "/logs/logs_collected/files/collect_list/0/file_path", Message: "file_path is required"})
+	assert.Contains(t, errs, Error{Pointer: "/logs/unknown", Message: "Additional property unknown is not allowed"})
+}
+
+func TestValidateLogsCollected(t *testing.T) {
+	errs := validateJson(t, `{
+		"logs": {"logs_collected": {"files": {"collect_list": [
+			{"file_path": "/tmp/a.log", "log_group_name": "a", "retention_in_days": 3,
+			 "multi_line_start_pattern": "([a-z", "timestamp_format": "%Y-%m-%d %Q"},
+			{"file_path": "/tmp/b.log", "log_group_name": "A", "retention_in_days": 5}
+		]}}}
+	}`)
+	assert.Equal(t, []Error{
+		{Pointer: "/logs/logs_collected/files/collect_list/0/multi_line_start_pattern", Message: "error parsing regexp: missing closing ]: `[a-z`"},
+		{Pointer: "/logs/logs_collected/files/collect_list/0/timestamp_format", Message: "unknown timestamp_format token %Q"},
+		{Pointer: "/logs/logs_collected/files/collect_list/1/retention_in_days", Message: "retention for log group A is already set at /logs/logs_collected/files/collect_list/0/retention_in_days"},
+	}, errs)
+}
+
+func TestValidateSyslog(t *testing.T) {
+	errs := validateJson(t, `{
+		"logs": {"logs_collected": {"syslog": {"collect_list": [
+			{"service_address": "udp://:514", "log_group_name": "/syslog/{json:$.appname}", "retention_in_days": 3},
+			{"service_address": "tcp://:514", "log_group_name": "/syslog/{json:$.appname}", "retention_in_days": 5},
+			{"service_address": "tcp://:6514", "log_group_name": "secure", "log_stream_name": "{message:text}"}
+		]}}}
+	}`)
+	assert.Equal(t, []Error{
+		{Pointer: "/logs/logs_collected/syslog/collect_list/1/retention_in_days", Message: "retention for log group /syslog/{json:$.appname} is already set at /logs/logs_collected/syslog/collect_list/0/retention_in_days"},
+		{Pointer: "/logs/logs_collected/syslog/collect_list/2", Message: "log_stream_name has issue: unknown placeholder {message:text}, its kind must be one of json"},
+	}, errs)
+}
+
+func TestValidateUnreachableDestination(t *testing.T) {
+	errs := validateJson(t, `{
+		"metrics": {"metrics_collected": {"statsd": {"events_log_group_name": "events"}}}
+	}`)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/metrics/metrics_collected/statsd/events_log_group_name", errs[0].Pointer)
+}
+
+func TestValidateEMFMetricExtraction(t *testing.T) {
+	errs := validateJson(t, `{
+		"logs": {"metrics_collected": {"emf": {"metric_extraction": "metrics_only"}}}
+	}`)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/logs/metrics_collected/emf/metric_extraction", errs[0].Pointer)
+
+	errs = validateJson(t, `{
+		"metrics": {"metrics_collected": {"mem": {"measurement": ["used_percent"]}}},
+		"logs": {"metrics_collected": {"emf": {"metric_extraction": "logs_and_metrics"}}}
+	}`)
+	assert.Empty(t, errs)
+}
+
+func TestUnknownTimestampTokens(t *testing.T) {
+	assert.Empty(t, unknownTimestampTokens("%-m/%-d/%Y %I:%M:%S %p %z"))
+	assert.Equal(t, []string{"%Q", "%-"}, unknownTimestampTokens("%Q %H %-"))
+	assert.Equal(t, []string{"%"}, unknownTimestampTokens("%H%"))
+}
+
+func TestToPointer(t *testing.T) {
+	var jsonConfig map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"logs": {"logs_collected": {
+			"files": {"collect_list": [{"file_path": "/tmp/a.log"}, {"file_path": "/tmp/b.log"}]},
+			"syslog": {"collect_list": [{"service_address": "udp://:514"}]}
+		}}
+	}`), &jsonConfig))
+
+	assert.Equal(t, "/logs/logs_collected", toPointer(jsonConfig, "/logs/logs_collected/"))
+	// the missing keys are reported on their parent's pointer
+	assert.Equal(t, "/logs/force_flush_interval", toPointer(jsonConfig, "/logs/force_flush_interval"))
+	// the entry of an array is only identified when there is one
+	assert.Equal(t, "/logs/logs_collected/syslog/collect_list/0", toPointer(jsonConfig, "/logs/logs_collected/syslog/collect_list/"))
+	assert.Equal(t, "/logs/logs_collected/syslog/collect_list/0/format", toPointer(jsonConfig, "/logs/logs_collected/syslog/collect_list/format"))
+	assert.Equal(t, "", toPointer(jsonConfig, "/logs/logs_collected/files/collect_list/"))
+	assert.Equal(t, "", toPointer(jsonConfig, "/logs/logs_collected/files/collect_list/file_path"))
+	// the paths of the translator rules which are not keys of the json config
+	assert.Equal(t, "", toPointer(jsonConfig, "/translator/util/sdkutil"))
+	assert.Equal(t, "", toPointer(jsonConfig, "ruleRegion/"))
+	assert.Equal(t, "", toPointer(jsonConfig, ""))
+}